@@ -1,63 +1,203 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/aumbhatt/auto_trade/internal/analytics"
+	"github.com/aumbhatt/auto_trade/internal/auth"
+	"github.com/aumbhatt/auto_trade/internal/clock"
 	"github.com/aumbhatt/auto_trade/internal/config"
 	"github.com/aumbhatt/auto_trade/internal/handler"
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/aumbhatt/auto_trade/internal/service"
+	"github.com/aumbhatt/auto_trade/internal/source"
+	"github.com/aumbhatt/auto_trade/internal/source/csv"
 	"github.com/aumbhatt/auto_trade/internal/source/mock"
+	"github.com/aumbhatt/auto_trade/internal/store"
 	"github.com/aumbhatt/auto_trade/internal/store/memory"
+	"github.com/aumbhatt/auto_trade/internal/store/sqlite"
 	"github.com/aumbhatt/auto_trade/internal/strategy"
 	"github.com/aumbhatt/auto_trade/internal/websocket"
 )
 
 func main() {
-	log.Println("Starting application...")
+	logging.Infof("Starting application...")
 
-	// Load configuration
-	cfg := config.NewDefaultConfig()
+	// Load configuration: defaults, then the -config file, then environment
+	configPath := flag.String("config", "", "path to a JSON config file (optional)")
+	flag.Parse()
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := logging.SetLevel(cfg.App.LogLevel); err != nil {
+		log.Fatal(err)
+	}
 
 	// Create registry and register handlers
 	registry := handler.NewRegistry()
 
-	// Create mock tick source
-	mockSource := mock.NewMockTickSource()
+	// Create tick source
+	tickSource, err := openTickSource(cfg.Feed, cfg.App.Symbols)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Create and start WebSocket hub
-	hub := websocket.NewHub(registry)
+	hub := websocket.NewHub(registry, cfg.Feed.BroadcastBuffer)
+	hub.SetReconnectBackoff(cfg.Server.ReconnectBackoff)
+	hub.SetMaxSubscriptions(cfg.Server.MaxSubscriptions)
+	hub.SetResumeTTL(cfg.Server.ResumeTTL)
 	go hub.Run()
 
-	// Create handlers
-	tradeStore := memory.NewInMemoryTradeStore()
-	strategyStore := memory.NewInMemoryStrategyStore()
+	// Create price cache fed by the tick handler
+	priceCache := memory.NewInMemoryPriceCache()
+	priceGuard := store.NewPriceGuard(priceCache, time.Duration(cfg.Trading.MaxPriceAge), clock.Real{})
+
+	// Symbols with trading halted, shared by trade stores and the runner
+	symbolHalts := store.NewSymbolHalts(cfg.Trading.DisabledSymbols)
+
+	// Create stores
+	tradeStore, strategyStore, closeStorage, err := openStores(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeStorage()
+	tradeStore.SetPriceGuard(priceGuard)
+	tradeStore.SetSymbolHalts(symbolHalts)
+	tradeStore.SetSymbols(cfg.App.Symbols)
+	tradeStore.SetListenerQueueSize(cfg.Trading.ListenerQueueSize)
+	positionLimits := store.PositionLimits{
+		MaxOpenPerSymbol: cfg.Trading.MaxOpenPerSymbol,
+		MaxOpenTotal:     cfg.Trading.MaxOpenTotal,
+	}
+	tradeStore.SetPositionLimits(positionLimits)
+	lossBreaker := store.NewLossBreaker(cfg.Trading.DailyLossLimit, cfg.Trading.DailyLossRollover, clock.Real{})
+	tradeStore.SetLossBreaker(lossBreaker)
+	tradeStore.AddListener(lossBreaker)
+	closePolicy, err := store.ParseClosePolicy(cfg.Trading.ClosePolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tradeStore.SetClosePolicy(closePolicy)
 	strategyRunner := strategy.NewDefaultRunner(strategyStore, tradeStore)
+	strategyRunner.SetSymbolHalts(symbolHalts)
+	strategyRunner.SetErrorBufferSize(cfg.Strategy.ErrorBufferSize)
+	strategyRunner.SetErrorRetention(cfg.Strategy.ErrorRetention)
+	strategyRunner.SetFailurePolicy(cfg.Strategy.MaxErrors, cfg.Strategy.ErrorWindow)
+	strategyRunner.SetAccountBalance(cfg.Trading.AccountBalance)
+	strategyRunner.SetMaxMartingalePositions(cfg.Strategy.MaxMartingalePositions)
+	strategyRunner.SetIsolatedStoreFactory(func() store.TradeStore {
+		isolated := memory.NewInMemoryTradeStore()
+		isolated.SetPriceGuard(priceGuard)
+		isolated.SetSymbolHalts(symbolHalts)
+		isolated.SetSymbols(cfg.App.Symbols)
+		isolated.SetPositionLimits(positionLimits)
+		isolated.SetLossBreaker(lossBreaker)
+		isolated.AddListener(lossBreaker)
+		return isolated
+	})
+
+	// Dry-run strategies paper trade against their own store; it checks
+	// prices, halts and symbols like the real one, but paper trades do not
+	// count towards position limits or the daily loss limit
+	paperStore := memory.NewInMemoryTradeStore()
+	paperStore.SetPriceGuard(priceGuard)
+	paperStore.SetSymbolHalts(symbolHalts)
+	paperStore.SetSymbols(cfg.App.Symbols)
+	strategyRunner.SetPaperTradeStore(paperStore)
+
+	// Create order store; pending limit orders fill into the trade store
+	orderStore := memory.NewInMemoryOrderStore(tradeStore)
+	orderStore.SetSymbols(cfg.App.Symbols)
+	orderStore.SetMaxPendingOrders(cfg.Trading.MaxPendingOrders)
 
 	// Create tick handler
-	tickHandler := handler.NewTickHandler(hub, mockSource)
+	// Ticks pass through the order matcher, so crossed limit orders fill,
+	// the exit monitor, so trades hitting their take-profit or stop-loss
+	// close, then the mark tracker, so open trades stream live P&L
+	orderMatcher := store.NewOrderMatcher(priceCache, orderStore)
+	exitMonitor := store.NewExitMonitor(orderMatcher, tradeStore)
+	markTracker := store.NewMarkTracker(exitMonitor, tradeStore)
+	tickHandler := handler.NewTickHandler(hub, tickSource, markTracker)
+	tickHandler.SetTickInterval(cfg.Feed.TickInterval)
+	strategyRunner.SetTickHistory(tickHandler)
+	strategyRunner.SetPriceCache(priceCache)
 	if err := registry.Register("ticks", tickHandler); err != nil {
 		log.Fatal(err)
 	}
 
 	// Create trade handlers
-	openPositionsHandler := handler.NewOpenPositionsHandler(tradeStore, hub)
-	tradeHistoryHandler := handler.NewTradeHistoryHandler(tradeStore, hub)
-	tradeHandler := handler.NewTradeHandler(tradeStore, hub, openPositionsHandler, tradeHistoryHandler)
+	openPositionsHandler := handler.NewOpenPositionsHandler(tradeStore, hub, priceCache, cfg.Feed.MarketValueMaxAge)
+	openPositionsHandler.SetMarkSource(markTracker)
+	openPositionsHandler.SetBatchWindow(cfg.Feed.OpenPositionsBatchWindow)
+	positionsHandler := handler.NewPositionsHandler(tradeStore, hub, priceCache, cfg.Feed.MarketValueMaxAge)
+	positionsHandler.SetMarkSource(markTracker)
+	positionsHandler.SetBatchWindow(cfg.Feed.OpenPositionsBatchWindow)
+	tradeHistoryHandler := handler.NewTradeHistoryHandler(tradeStore, hub, cfg.Feed.MaxDeltaRetention)
+	tradeHandler := handler.NewTradeHandler(tradeStore, hub, openPositionsHandler, tradeHistoryHandler, priceCache)
+	flatHandler := handler.NewFlatHandler(tradeStore, hub)
+	orderHandler := handler.NewOrderHandler(orderStore)
+	pendingOrdersHandler := handler.NewPendingOrdersHandler(orderStore, hub)
 
 	// Create strategy handlers
-	activeStrategiesHandler := handler.NewActiveStrategiesHandler(strategyStore, hub)
+	activeStrategiesHandler := handler.NewActiveStrategiesHandler(strategyStore, hub, cfg.Strategy.SnapshotInterval)
+	activeStrategiesHandler.SetStateSource(strategyRunner)
 	strategyHistoryHandler := handler.NewStrategyHistoryHandler(strategyStore, hub)
+	strategyErrorsHandler := handler.NewStrategyErrorsHandler(hub)
+	strategyRunner.OnError(strategyErrorsHandler.OnStrategyError)
+	paperTradesHandler := handler.NewPaperTradesHandler(paperStore, hub)
 	strategyHandler := handler.NewStrategyHandler(strategyStore, strategyRunner, tickHandler, hub, activeStrategiesHandler, strategyHistoryHandler)
+	strategyHandler.SetFlattenSource(tradeStore, priceCache)
+
+	// Create risk handler
+	riskHandler := handler.NewRiskHandler(priceCache)
+
+	// Create analytics handler
+	currencyConverter := analytics.NewCurrencyConverter(cfg.Currency.BaseCurrency, cfg.Currency.Rates)
+	analyticsHandler := handler.NewAnalyticsHandler(tradeStore, strategyStore, currencyConverter)
+	equityTracker := analytics.NewEquityTracker(cfg.Analytics.EquityMaxPoints)
+	history, err := tradeStore.GetTradeHistory()
+	if err != nil {
+		log.Fatal(err)
+	}
+	equityTracker.Load(history)
+	tradeStore.AddListener(equityTracker)
+	analyticsHandler.SetEquityTracker(equityTracker)
+
+	// Create metrics handler; it counts trades as a trade store listener
+	metricsHandler := handler.NewMetricsHandler(tradeStore, strategyStore, hub)
+	tradeStore.AddListener(metricsHandler)
+
+	// Create config handler
+	configHandler := handler.NewConfigHandler(cfg)
+
+	// Create admin handler
+	adminHandler := handler.NewAdminHandler(registry, hub, symbolHalts)
 
 	// Register trade message handlers
 	if err := registry.Register("open_positions", openPositionsHandler); err != nil {
 		log.Fatal(err)
 	}
+	if err := registry.Register("positions", positionsHandler); err != nil {
+		log.Fatal(err)
+	}
 	if err := registry.Register("trade_history", tradeHistoryHandler); err != nil {
 		log.Fatal(err)
 	}
+	if err := registry.Register("flat", flatHandler); err != nil {
+		log.Fatal(err)
+	}
+	if err := registry.Register("pending_orders", pendingOrdersHandler); err != nil {
+		log.Fatal(err)
+	}
 
 	// Register strategy message handlers
 	if err := registry.Register("active_strategies", activeStrategiesHandler); err != nil {
@@ -66,27 +206,82 @@ func main() {
 	if err := registry.Register("strategies_history", strategyHistoryHandler); err != nil {
 		log.Fatal(err)
 	}
+	if err := registry.Register("strategy_errors", strategyErrorsHandler); err != nil {
+		log.Fatal(err)
+	}
+	if err := registry.Register("paper_trades", paperTradesHandler); err != nil {
+		log.Fatal(err)
+	}
+
+	// Dead-man switch: stop strategies tied to a disconnected session
+	hub.OnDisconnect(strategyHandler.StopSessionStrategies)
 
 	// Start all handlers
 	if err := registry.StartAll(); err != nil {
 		log.Fatal(err)
 	}
 
+	// Resume strategies left active by a previous run (persistent storage)
+	strategyHandler.ResumeStrategies()
+
 	// Initialize service with hub
 	svc := service.NewService(cfg, hub)
+	svc.SetStores(tradeStore, strategyStore)
+	svc.SetFeed(tickHandler)
+	healthHandler := handler.NewHealthHandler(svc)
 
 	// Create router with CORS middleware
 	mux := http.NewServeMux()
 	
+	// API key required on REST and WebSocket endpoints when auth is
+	// enabled; Load refuses a config without one outside development
+	apiKey := ""
+	if cfg.Server.AuthEnabled {
+		apiKey = cfg.Server.APIKey
+	}
+
+	// Trade-changing endpoints are rate limited per client (when enabled)
+	rateLimiter := auth.NewRateLimiter(cfg.Server.RateLimit, cfg.Server.RateLimitBurst, clock.Real{})
+	limited := func(h http.HandlerFunc) http.Handler {
+		return handler.RateLimitMiddleware(rateLimiter, apiKey, h)
+	}
+
 	// Set up routes
-	mux.HandleFunc("/api/trades/buy", tradeHandler.HandleBuy)
-	mux.HandleFunc("/api/trades/sell", tradeHandler.HandleSell)
-	mux.HandleFunc("/api/strategies/start", strategyHandler.HandleStart)
-	mux.HandleFunc("/api/strategies/stop", strategyHandler.HandleStop)
+	mux.Handle("/api/trades/buy", limited(tradeHandler.HandleBuy))
+	mux.Handle("/api/trades/sell", limited(tradeHandler.HandleSell))
+	mux.Handle("/api/trades/close_symbol", limited(tradeHandler.HandleCloseSymbol))
+	mux.HandleFunc("/api/trades/symbols", tradeHandler.HandleSymbols)
+	mux.HandleFunc("/api/trades/", tradeHandler.HandleGetTrade)
+	mux.Handle("/api/orders/limit", limited(orderHandler.HandleLimitOrder))
+	mux.HandleFunc("/api/orders/pending", orderHandler.HandlePendingOrders)
+	mux.Handle("/api/orders/cancel", limited(orderHandler.HandleCancel))
+	mux.Handle("/api/strategies/start", limited(strategyHandler.HandleStart))
+	mux.Handle("/api/strategies/stop", limited(strategyHandler.HandleStop))
+	mux.Handle("/api/strategies/stop-all", limited(strategyHandler.HandleStopAll))
+	mux.Handle("/api/strategies/pause", limited(strategyHandler.HandlePause))
+	mux.Handle("/api/strategies/resume", limited(strategyHandler.HandleResume))
+	mux.HandleFunc("/api/strategies/available", strategyHandler.HandleAvailableStrategies)
 	mux.HandleFunc("/api/strategies/default", strategyHandler.HandleDefaultStrategies)
+	mux.HandleFunc("/api/strategies/errors", strategyHandler.HandleErrors)
+	mux.HandleFunc("/api/risk/size", riskHandler.HandlePositionSize)
+	mux.HandleFunc("/api/analytics/strategies/compare", analyticsHandler.HandleCompareStrategies)
+	mux.HandleFunc("/api/analytics/equity", analyticsHandler.HandleEquity)
+	mux.HandleFunc("/api/config", configHandler.HandleGetConfig)
+	mux.HandleFunc("/metrics", metricsHandler.HandleMetrics)
+	mux.HandleFunc("/api/admin/feeds/", adminHandler.HandleFeedToggle)
+	mux.HandleFunc("/api/admin/subscriptions/", adminHandler.HandleCancelSubscription)
+	mux.HandleFunc("/api/admin/symbols/", adminHandler.HandleSymbolToggle)
 	
-	// Set up WebSocket route (no CORS middleware needed as it's handled in upgrader)
-	mux.HandleFunc("/ws", websocket.HandleWebSocket(hub))
+	// REST routes check the Authorization header; the WebSocket route checks
+	// it (or ?token=) itself, before upgrading. The health check needs no key
+	wsHandler := websocket.NewHandler(hub, cfg.Server.MaxMessageSize)
+	wsHandler.SetAPIKey(apiKey)
+	wsHandler.SetAllowedOrigins(cfg.Server.AllowedOrigins)
+	wsHandler.SetCompression(cfg.Server.Compression)
+	root := http.NewServeMux()
+	root.Handle("/ws", wsHandler)
+	root.HandleFunc("/healthz", healthHandler.HandleHealth) // Open to load balancer probes
+	root.Handle("/", handler.AuthMiddleware(apiKey, mux))
 
 	// Run the service
 	go func() {
@@ -96,12 +291,125 @@ func main() {
 	}()
 
 	// Create handler chain with CORS middleware
-	handler := handler.CORSMiddleware(mux)
+	handler := handler.CORSMiddleware(cfg.Server.AllowedOrigins, root)
 
 	// Start HTTP server
 	serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
-	log.Printf("Server starting on %s", serverAddr)
-	if err := http.ListenAndServe(serverAddr, handler); err != nil {
-		log.Fatal("ListenAndServe: ", err)
+	server := newHTTPServer(serverAddr, cfg.Server, handler)
+	go func() {
+		logging.Infof("Server starting on %s", serverAddr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("ListenAndServe: ", err)
+		}
+	}()
+
+	// On SIGINT/SIGTERM stop taking requests, stop running strategies (moving
+	// them to history), tell WebSocket clients when to reconnect, then stop
+	// the feed handlers and their tickers
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	<-signalCtx.Done()
+	stopSignals()
+	logging.Infof("Shutting down...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logging.Errorf("HTTP shutdown: %v", err)
+	}
+	strategyHandler.StopAllStrategies()
+	if err := hub.Shutdown(ctx, "server shutting down"); err != nil {
+		logging.Errorf("WebSocket shutdown: %v", err)
+	}
+	if err := registry.StopAll(); err != nil {
+		logging.Errorf("Handler shutdown: %v", err)
+	}
+	logging.Infof("Shutdown complete")
+}
+
+// configurableTradeStore is a trade store with the settings main applies
+type configurableTradeStore interface {
+	store.TradeStore
+	SetPriceGuard(guard *store.PriceGuard)
+	SetSymbolHalts(halts *store.SymbolHalts)
+	SetSymbols(symbols []string)
+	SetPositionLimits(limits store.PositionLimits)
+	SetLossBreaker(breaker *store.LossBreaker)
+	SetListenerQueueSize(size int)
+	SetClosePolicy(policy store.ClosePolicy)
+}
+
+// newHTTPServer creates the HTTP server for addr, enforcing the configured
+// read and write timeouts
+func newHTTPServer(addr string, cfg config.ServerConfig, h http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		Handler:      h,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+}
+
+// openStores creates the trade and strategy stores for the configured
+// storage type, with a func that releases them on shutdown
+func openStores(cfg config.StorageConfig) (configurableTradeStore, store.StrategyStore, func(), error) {
+	switch cfg.Type {
+	case "", "memory":
+		return memory.NewInMemoryTradeStore(), memory.NewInMemoryStrategyStore(), func() {}, nil
+	case "sqlite":
+		db, err := sqlite.Open(cfg.Path)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		logging.Infof("Using SQLite storage: %s", cfg.Path)
+		closeDB := func() {
+			if err := db.Close(); err != nil {
+				logging.Errorf("Closing SQLite storage: %v", err)
+			}
+		}
+		return sqlite.NewTradeStore(db), sqlite.NewStrategyStore(db), closeDB, nil
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown storage type %q: must be memory or sqlite", cfg.Type)
+	}
+}
+
+// openTickSource creates the configured tick source; the mock source
+// generates ticks for symbols
+func openTickSource(cfg config.FeedConfig, symbols []string) (source.TickSource, error) {
+	switch cfg.Source {
+	case "", "mock":
+		if cfg.MockMinPrice <= 0 || cfg.MockMaxPrice < cfg.MockMinPrice {
+			return nil, fmt.Errorf("invalid mock price band [%v, %v]: need 0 < mockMinPrice <= mockMaxPrice", cfg.MockMinPrice, cfg.MockMaxPrice)
+		}
+		src := mock.NewMockTickSourceWithConfig(symbols, cfg.MockMinPrice, cfg.MockMaxPrice)
+		if cfg.MockSeed != 0 {
+			src.SetSeed(cfg.MockSeed)
+		}
+		return src, nil
+	case "random_walk":
+		if cfg.WalkVolatility <= 0 || cfg.WalkVolatility >= 1 {
+			return nil, fmt.Errorf("invalid walkVolatility %v: must be between 0 and 1", cfg.WalkVolatility)
+		}
+		start := make(map[string]float64, len(symbols))
+		for _, symbol := range symbols {
+			start[symbol] = (cfg.MockMinPrice + cfg.MockMaxPrice) / 2
+			if price, ok := cfg.WalkStartPrices[symbol]; ok {
+				start[symbol] = price
+			}
+		}
+		src := mock.NewRandomWalkTickSource(symbols, start, cfg.WalkVolatility)
+		if cfg.MockSeed != 0 {
+			src.SetSeed(cfg.MockSeed)
+		}
+		return src, nil
+	case "csv":
+		src, err := csv.NewCSVTickSource(cfg.CSVPath, cfg.CSVLoop)
+		if err != nil {
+			return nil, err
+		}
+		logging.Infof("Replaying ticks from %s", cfg.CSVPath)
+		return src, nil
+	default:
+		return nil, fmt.Errorf("unknown tick source %q: must be mock, random_walk or csv", cfg.Source)
 	}
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	gws "github.com/gorilla/websocket"
+
+	"github.com/aumbhatt/auto_trade/internal/config"
+	"github.com/aumbhatt/auto_trade/internal/handler"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+// serve runs h behind newHTTPServer on a local port until the test ends
+func serve(t *testing.T, cfg config.ServerConfig, h http.Handler) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	server := newHTTPServer(ln.Addr().String(), cfg, h)
+	go server.Serve(ln)
+	t.Cleanup(func() { server.Close() })
+	return ln.Addr().String()
+}
+
+func TestSlowRequestBodyCutOffAtReadTimeout(t *testing.T) {
+	readErr := make(chan error, 1)
+	addr := serve(t, config.ServerConfig{ReadTimeout: 200 * time.Millisecond}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		readErr <- err
+	}))
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Promise 100 bytes of body but send only a few, then stall
+	fmt.Fprintf(conn, "POST /api/trades/buy HTTP/1.1\r\nHost: %s\r\nContent-Length: 100\r\n\r\n{\"sym", addr)
+
+	select {
+	case err := <-readErr:
+		if err == nil {
+			t.Fatal("truncated body read without error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow body still being read long after the read timeout")
+	}
+
+	// The server hangs up rather than waiting out the client
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.Copy(io.Discard, bufio.NewReader(conn)); err != nil {
+		t.Errorf("connection not closed by the server: %v", err)
+	}
+}
+
+func TestWebSocketOutlivesWriteTimeout(t *testing.T) {
+	hub := websocket.NewHub(handler.NewRegistry(), 16)
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		hub.Shutdown(ctx, "test done")
+	})
+	addr := serve(t, config.ServerConfig{ReadTimeout: 100 * time.Millisecond, WriteTimeout: 100 * time.Millisecond}, websocket.NewHandler(hub, 0))
+
+	conn, _, err := gws.DefaultDialer.Dial("ws://"+addr+"/ws", nil)
+	if err != nil {
+		t.Fatalf("upgrade under a write timeout: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var session websocket.Message
+	if err := conn.ReadJSON(&session); err != nil || session.Type != websocket.MessageTypeSession {
+		t.Fatalf("first message %+v (%v), want the session", session, err)
+	}
+
+	// Past both timeouts the hijacked connection still reads and writes
+	time.Sleep(300 * time.Millisecond)
+	if err := conn.WriteJSON(websocket.Message{
+		Type:    websocket.MessageTypeSubscribe,
+		Payload: websocket.SubscribeRequest{Type: "ticks"},
+	}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var reply websocket.Message
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatalf("no reply after the write timeout elapsed: %v", err)
+	}
+}
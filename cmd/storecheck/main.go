@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/store/sqlite"
+)
+
+/*
+storecheck opens a persistent trade store without starting the server,
+validates its contents and reports open/closed trade counts plus any
+inconsistencies. It exits non-zero when inconsistencies are found.
+
+Usage:
+   storecheck -backend <name> -path <store file>
+*/
+
+// openers maps persistent backend names to functions that open them
+// Backends register here as they are added
+var openers = map[string]func(path string) (store.BasicTradeStore, error){
+	"sqlite": openSQLite,
+}
+
+// openSQLite opens an existing SQLite store; a missing file is an error
+// rather than a new empty store
+func openSQLite(path string) (store.BasicTradeStore, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sqlite.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return sqlite.NewTradeStore(db), nil
+}
+
+func main() {
+	backend := flag.String("backend", "", "persistent store backend")
+	path := flag.String("path", "", "path to the store file")
+	flag.Parse()
+
+	open, ok := openers[*backend]
+	if !ok {
+		log.Fatalf("unknown backend %q (available: %v)", *backend, availableBackends())
+	}
+	if *path == "" {
+		log.Fatal("-path is required")
+	}
+
+	tradeStore, err := open(*path)
+	if err != nil {
+		log.Fatalf("failed to open %s store at %s: %v", *backend, *path, err)
+	}
+
+	report, err := store.CheckTradeStore(tradeStore)
+	if err != nil {
+		log.Fatalf("check failed: %v", err)
+	}
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
+
+// availableBackends returns the registered backend names
+func availableBackends() []string {
+	names := make([]string, 0, len(openers))
+	for name := range openers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
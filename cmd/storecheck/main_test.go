@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/store/sqlite"
+)
+
+// seedStore writes a store file with two open trades and one closed one,
+// then runs extra SQL against it
+func seedStore(t *testing.T, extra string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "trades.db")
+	db, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+
+	trades := sqlite.NewTradeStore(db)
+	for _, symbol := range []string{"AAPL", "MSFT", "GOOGL"} {
+		if _, err := trades.CreateTrade(symbol, 100, 1, models.SideBuy, ""); err != nil {
+			t.Fatalf("CreateTrade: %v", err)
+		}
+	}
+	open, _ := trades.GetOpenTrades()
+	if _, err := trades.CloseTrade(open[0].ID, 101, "test"); err != nil {
+		t.Fatalf("CloseTrade: %v", err)
+	}
+	if extra != "" {
+		if _, err := db.Exec(extra); err != nil {
+			t.Fatalf("Exec: %v", err)
+		}
+	}
+	return path
+}
+
+// check opens a store file the way the command does and checks it
+func check(t *testing.T, path string) *store.CheckReport {
+	t.Helper()
+	tradeStore, err := openers["sqlite"](path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	report, err := store.CheckTradeStore(tradeStore)
+	if err != nil {
+		t.Fatalf("CheckTradeStore: %v", err)
+	}
+	return report
+}
+
+func TestCheckReportsSeededCounts(t *testing.T) {
+	report := check(t, seedStore(t, ""))
+	if report.OpenTrades != 2 || report.ClosedTrades != 1 || !report.OK() {
+		t.Errorf("report %+v, want 2 open, 1 closed and no inconsistencies", report)
+	}
+}
+
+func TestCheckFlagsInconsistentTrade(t *testing.T) {
+	path := seedStore(t, `INSERT INTO trades (id, symbol, side, quantity, entry_price, exit_price, entry_time, exit_time)
+		VALUES ('bad', 'AAPL', 'buy', 1, 100, 101, 2000, 1000)`)
+	report := check(t, path)
+	if report.ClosedTrades != 2 || len(report.Inconsistencies) != 1 || !strings.Contains(report.Inconsistencies[0], "bad exits before it enters") {
+		t.Errorf("report %+v, want trade bad flagged for exiting before it enters", report)
+	}
+}
+
+func TestCheckRefusesMissingFile(t *testing.T) {
+	if _, err := openers["sqlite"](filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("missing store file opened as an empty store")
+	}
+}
@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Currency Conversion Flow:
+
+1. Quote Currency:
+   The quote currency of a symbol is the part after the slash:
+   ├── "BTC/USD" → "USD"
+   ├── "EUR/GBP" → "GBP"
+   └── "AAPL"    → base currency (no explicit quote)
+
+2. Conversion:
+   P&L is earned in the quote currency and converted into the account
+   base currency using the configured rates map:
+   rates = {"GBP": 1.25}    // 1 GBP = 1.25 base units
+   pnl_base = pnl_quote * rates[quote]
+
+   The base currency always converts at 1, so an empty rates map is the
+   identity conversion (everything in USD by default).
+
+3. Usage Example:
+   converter := NewCurrencyConverter("USD", map[string]float64{"EUR": 1.08})
+   total, err := converter.TotalPnL(closedTrades)
+*/
+
+// CurrencyConverter converts amounts from quote currencies into a base currency
+type CurrencyConverter struct {
+	base  string
+	rates map[string]float64 // currency -> value of one unit in base currency
+}
+
+// NewCurrencyConverter creates a converter for the given base currency and rates
+func NewCurrencyConverter(base string, rates map[string]float64) *CurrencyConverter {
+	copied := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		copied[strings.ToUpper(currency)] = rate
+	}
+	return &CurrencyConverter{
+		base:  strings.ToUpper(base),
+		rates: copied,
+	}
+}
+
+// BaseCurrency returns the currency amounts are converted into
+func (c *CurrencyConverter) BaseCurrency() string {
+	return c.base
+}
+
+// QuoteCurrency returns the currency a symbol is priced in
+func (c *CurrencyConverter) QuoteCurrency(symbol string) string {
+	if i := strings.LastIndex(symbol, "/"); i >= 0 && i < len(symbol)-1 {
+		return strings.ToUpper(symbol[i+1:])
+	}
+	return c.base
+}
+
+// Convert converts an amount in the given currency into the base currency
+func (c *CurrencyConverter) Convert(amount float64, currency string) (float64, error) {
+	currency = strings.ToUpper(currency)
+	if currency == c.base {
+		return amount, nil
+	}
+
+	rate, ok := c.rates[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no conversion rate from %s to %s", currency, c.base)
+	}
+	return amount * rate, nil
+}
+
+// TradePnL returns a closed trade's P&L converted into the base currency
+func (c *CurrencyConverter) TradePnL(trade *models.Trade) (float64, error) {
+	return c.Convert(trade.RealizedPnL(), c.QuoteCurrency(trade.Symbol))
+}
+
+// TotalPnL returns the aggregate P&L of closed trades in the base currency
+func (c *CurrencyConverter) TotalPnL(trades []*models.Trade) (float64, error) {
+	total := 0.0
+	for _, trade := range trades {
+		pnl, err := c.TradePnL(trade)
+		if err != nil {
+			return 0, fmt.Errorf("trade %s: %w", trade.ID, err)
+		}
+		total += pnl
+	}
+	return total, nil
+}
@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+// closedTrade returns a long trade closed at exit, entered an hour before closedAt
+func closedTrade(symbol string, entry, exit, quantity float64, closedAt time.Time) *models.Trade {
+	return &models.Trade{
+		ID:         symbol + "-" + closedAt.Format(time.RFC3339Nano),
+		Symbol:     symbol,
+		Side:       models.SideBuy,
+		Quantity:   quantity,
+		EntryPrice: entry,
+		ExitPrice:  exit,
+		EntryTime:  closedAt.Add(-time.Hour),
+		ExitTime:   closedAt,
+	}
+}
+
+func TestTotalPnLConvertsIntoBaseCurrency(t *testing.T) {
+	converter := NewCurrencyConverter("usd", map[string]float64{"gbp": 1.25})
+	now := time.Now()
+	trades := []*models.Trade{
+		closedTrade("AAPL", 100, 110, 2, now),          // +20 USD
+		closedTrade("EUR/GBP", 0.80, 0.90, 100, now),   // +10 GBP = +12.5 USD
+		closedTrade("BTC/USD", 30000, 29900, 0.5, now), // -50 USD
+	}
+
+	total, err := converter.TotalPnL(trades)
+	if err != nil {
+		t.Fatalf("TotalPnL: %v", err)
+	}
+	if math.Abs(total-(20+12.5-50)) > 1e-9 {
+		t.Errorf("total P&L %v USD, want -17.5", total)
+	}
+
+	// A currency without a rate is an error, not a silent 1:1
+	trades = append(trades, closedTrade("USD/JPY", 150, 151, 1, now))
+	if _, err := converter.TotalPnL(trades); err == nil {
+		t.Error("trade quoted in JPY converted without a rate")
+	}
+}
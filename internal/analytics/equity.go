@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Equity Analytics:
+
+   Pure helpers over closed trades (open trades, with no exit time, are
+   ignored); inputs are never modified.
+
+   closed trades by exit time:  +10   -4   -3   +8
+   EquityCurve:                  10    6    3   11   // cumulative P&L
+   MaxDrawdown:                  7                   // 10 → 3
+   WinLoss:                      2 wins, 2 losses    // 0 P&L is neither
+
+   SharpeRatio is mean / standard deviation of per-trade pnl_percent, as
+   in CompareStrategies (per trade, not annualized).
+*/
+
+// ClosedByExit returns the closed trades ordered by exit time, ties by ID
+func ClosedByExit(trades []*models.Trade) []*models.Trade {
+	closed := make([]*models.Trade, 0, len(trades))
+	for _, trade := range trades {
+		if !trade.ExitTime.IsZero() {
+			closed = append(closed, trade)
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool {
+		if !closed[i].ExitTime.Equal(closed[j].ExitTime) {
+			return closed[i].ExitTime.Before(closed[j].ExitTime)
+		}
+		return closed[i].ID < closed[j].ID
+	})
+	return closed
+}
+
+// EquityCurve returns the cumulative realized P&L after each closed trade,
+// in exit-time order
+func EquityCurve(trades []*models.Trade) []float64 {
+	closed := ClosedByExit(trades)
+	curve := make([]float64, len(closed))
+	equity := 0.0
+	for i, trade := range closed {
+		equity += trade.PnL
+		curve[i] = equity
+	}
+	return curve
+}
+
+// MaxDrawdown returns the largest fall of an equity curve below its running
+// peak; the peak starts at 0, the equity before the first trade
+func MaxDrawdown(curve []float64) float64 {
+	peak, maxDrawdown := 0.0, 0.0
+	for _, equity := range curve {
+		if equity > peak {
+			peak = equity
+		}
+		if drawdown := peak - equity; drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+		}
+	}
+	return maxDrawdown
+}
+
+// SharpeRatio returns mean / standard deviation of the closed trades'
+// per-trade returns (pnl_percent); 0 below two trades or with no variance
+func SharpeRatio(trades []*models.Trade) float64 {
+	returns := make([]float64, 0, len(trades))
+	for _, trade := range trades {
+		if !trade.ExitTime.IsZero() {
+			returns = append(returns, trade.PnLPercent)
+		}
+	}
+	_, sharpe := returnStats(returns)
+	return sharpe
+}
+
+// WinLoss counts the closed trades with positive and negative P&L;
+// break-even trades count as neither
+func WinLoss(trades []*models.Trade) (wins, losses int) {
+	for _, trade := range trades {
+		if trade.ExitTime.IsZero() {
+			continue
+		}
+		switch {
+		case trade.PnL > 0:
+			wins++
+		case trade.PnL < 0:
+			losses++
+		}
+	}
+	return wins, losses
+}
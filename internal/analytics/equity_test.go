@@ -0,0 +1,139 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+)
+
+func TestSharpeRatioUsesReturnPercent(t *testing.T) {
+	now := time.Now()
+	trades := []*models.Trade{
+		closedTrade("AAPL", 100, 110, 1, now),                      // +10 = +10%
+		closedTrade("AAPL", 100, 90, 10, now.Add(time.Second)),     // -100 = -10%
+		closedTrade("AAPL", 100, 110, 100, now.Add(2*time.Second)), // +1000 = +10%
+	}
+	for _, trade := range trades {
+		trade.ComputePnL()
+	}
+
+	// The known trade reports its return in history
+	encoded, _ := json.Marshal(trades[0])
+	if !strings.Contains(string(encoded), `"pnl_percent":10`) {
+		t.Errorf("trade JSON %s, want pnl_percent 10", encoded)
+	}
+
+	// Returns 10, -10, 10: mean 10/3 over a population stddev of 20√2/3,
+	// whatever the position sizes
+	want := 1 / (2 * math.Sqrt2)
+	if got := SharpeRatio(trades); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Sharpe ratio %v, want %v", got, want)
+	}
+}
+
+func TestEquityCurveAndDrawdown(t *testing.T) {
+	at := time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC)
+	trade := func(minute int, pnl float64) *models.Trade {
+		return &models.Trade{ID: fmt.Sprintf("trade-%d", minute), PnL: pnl, ExitTime: at.Add(time.Duration(minute) * time.Minute)}
+	}
+	// Out of exit order, with a break-even trade and an open one
+	trades := []*models.Trade{
+		trade(3, -3),
+		trade(1, 10),
+		{ID: "open", PnL: 50},
+		trade(4, 8),
+		trade(2, -4),
+		trade(5, 0),
+	}
+
+	curve := EquityCurve(trades)
+	if want := []float64{10, 6, 3, 11, 11}; fmt.Sprint(curve) != fmt.Sprint(want) {
+		t.Errorf("equity curve %v, want %v", curve, want)
+	}
+	if got := MaxDrawdown(curve); got != 7 {
+		t.Errorf("max drawdown %v, want 7 (10 → 3)", got)
+	}
+	if wins, losses := WinLoss(trades); wins != 2 || losses != 2 {
+		t.Errorf("%d wins and %d losses, want 2 and 2", wins, losses)
+	}
+	if trades[0].ID != "trade-3" {
+		t.Error("EquityCurve reordered its input")
+	}
+
+	// The peak starts at 0, so an early loss is a drawdown
+	if got := MaxDrawdown([]float64{-5, 2, 1}); got != 5 {
+		t.Errorf("max drawdown %v of a curve starting at -5, want 5", got)
+	}
+	if got := MaxDrawdown(nil); got != 0 {
+		t.Errorf("max drawdown %v with no trades, want 0", got)
+	}
+}
+
+func TestEquityTrackerStaysWithinCap(t *testing.T) {
+	at := time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(1))
+	trades := make([]*models.Trade, 10000)
+	for i := range trades {
+		pnl := rng.Float64()*20 - 9
+		if i == 6001 {
+			pnl = -5000 // A sharp drop deep inside what becomes one bucket
+		}
+		trades[i] = &models.Trade{ID: fmt.Sprintf("trade-%05d", i), PnL: pnl, ExitTime: at.Add(time.Duration(i) * time.Second)}
+	}
+
+	const maxPoints = 100
+	tracker := NewEquityTracker(maxPoints)
+	tracker.OnTradeEvent(store.TradeEvent{Type: store.TradeCreated, Trade: &models.Trade{ID: "open"}})
+	for _, trade := range trades {
+		tracker.OnTradeEvent(store.TradeEvent{Type: store.TradeClosed, Trade: trade})
+	}
+	got := tracker.Curve()
+
+	full := EquityCurve(trades)
+	if len(got.Points) > maxPoints {
+		t.Fatalf("%d points, want at most %d", len(got.Points), maxPoints)
+	}
+	if got.TotalTrades != len(trades) || got.NetPnL != full[len(full)-1] {
+		t.Errorf("%d trades netting %v, want %d netting %v", got.TotalTrades, got.NetPnL, len(trades), full[len(full)-1])
+	}
+	if want := MaxDrawdown(full); got.MaxDrawdown != want {
+		t.Errorf("max drawdown %v after downsampling, want %v", got.MaxDrawdown, want)
+	}
+
+	// Buckets cover every trade in order and keep the curve's extremes
+	low, high, count := math.Inf(1), math.Inf(-1), 0
+	for i, point := range got.Points {
+		if i > 0 && !point.Time.After(got.Points[i-1].Time) {
+			t.Fatalf("point %d at %v, not after %v", i, point.Time, got.Points[i-1].Time)
+		}
+		count += point.Trades
+		low, high = math.Min(low, point.Low), math.Max(high, point.High)
+	}
+	if count != len(trades) {
+		t.Errorf("buckets hold %d trades, want %d", count, len(trades))
+	}
+	fullLow, fullHigh := math.Inf(1), math.Inf(-1)
+	for _, equity := range full {
+		fullLow, fullHigh = math.Min(fullLow, equity), math.Max(fullHigh, equity)
+	}
+	if low != fullLow || high != fullHigh {
+		t.Errorf("bucket range %v to %v, want %v to %v", low, high, fullLow, fullHigh)
+	}
+	if last := got.Points[len(got.Points)-1]; last.Equity != got.NetPnL || !last.Time.Equal(trades[len(trades)-1].ExitTime) {
+		t.Errorf("last point %+v, want equity %v at the last exit", last, got.NetPnL)
+	}
+
+	// Loading the same history at startup gives the same curve
+	loaded := NewEquityTracker(maxPoints)
+	loaded.Load(trades)
+	if fmt.Sprint(loaded.Curve()) != fmt.Sprint(got) {
+		t.Error("loaded curve differs from the event-built one")
+	}
+}
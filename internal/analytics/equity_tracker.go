@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+)
+
+/*
+Equity Tracker Flow:
+
+1. Tracking:
+   Trade store → TradeClosed → EquityTracker.OnTradeEvent
+   ├── equity += trade P&L
+   ├── append a point {time, equity, low, high, trades: 1}
+   └── update the running peak and max drawdown
+
+2. Downsampling (maxPoints > 0):
+   When a close pushes the curve past maxPoints, adjacent pairs of points
+   are merged into buckets, halving the curve. Each merge keeps the later
+   point's time and equity, the lowest low and highest high of the pair,
+   and the sum of their trades, so repeated merges leave older history in
+   ever larger buckets and recent trades at full detail:
+
+   trades:  +10  -4  -3  +8      maxPoints 3
+   points:   10   6   3  11   →  {6, low 6, high 10, 2 trades}
+                                 {11, low 3, high 11, 2 trades}
+
+   Max drawdown is updated on every close from the running peak, before
+   any merge, so it stays exact however far the curve is downsampled.
+
+3. Startup:
+   Load replays the closed trades already in the store (e.g. reloaded
+   from SQLite); call it before adding the tracker as a listener.
+*/
+
+// EquityTracker keeps a bounded equity curve of realized P&L over closed trades
+type EquityTracker struct {
+	maxPoints int
+
+	mu          sync.RWMutex
+	points      []models.EquityPoint
+	equity      float64
+	peak        float64
+	maxDrawdown float64
+	trades      int
+}
+
+// NewEquityTracker creates a tracker keeping at most maxPoints curve points;
+// 0 keeps one point per closed trade
+func NewEquityTracker(maxPoints int) *EquityTracker {
+	return &EquityTracker{maxPoints: maxPoints}
+}
+
+// Load resets the tracker to the given trades' closed history
+func (t *EquityTracker) Load(trades []*models.Trade) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.points = nil
+	t.equity, t.peak, t.maxDrawdown, t.trades = 0, 0, 0, 0
+	for _, trade := range ClosedByExit(trades) {
+		t.add(trade)
+	}
+}
+
+// OnTradeEvent implements store.TradeEventListener; closes extend the curve
+func (t *EquityTracker) OnTradeEvent(event store.TradeEvent) {
+	if event.Type != store.TradeClosed || event.Trade == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.add(event.Trade)
+}
+
+// add appends a closed trade to the curve, downsampling past maxPoints
+// Caller must hold t.mu
+func (t *EquityTracker) add(trade *models.Trade) {
+	t.trades++
+	t.equity += trade.PnL
+	if t.equity > t.peak {
+		t.peak = t.equity
+	}
+	if drawdown := t.peak - t.equity; drawdown > t.maxDrawdown {
+		t.maxDrawdown = drawdown
+	}
+
+	t.points = append(t.points, models.EquityPoint{
+		Time:   trade.ExitTime,
+		Equity: t.equity,
+		Low:    t.equity,
+		High:   t.equity,
+		Trades: 1,
+	})
+	if t.maxPoints > 0 && len(t.points) > t.maxPoints {
+		t.points = mergePairs(t.points)
+	}
+}
+
+// mergePairs halves a curve by merging adjacent points into buckets; an odd
+// last point is kept as-is
+func mergePairs(points []models.EquityPoint) []models.EquityPoint {
+	merged := points[:0]
+	for i := 0; i < len(points); i += 2 {
+		if i+1 == len(points) {
+			merged = append(merged, points[i])
+			break
+		}
+		a, b := points[i], points[i+1]
+		bucket := b
+		if a.Low < bucket.Low {
+			bucket.Low = a.Low
+		}
+		if a.High > bucket.High {
+			bucket.High = a.High
+		}
+		bucket.Trades += a.Trades
+		merged = append(merged, bucket)
+	}
+	return merged
+}
+
+// Curve returns a copy of the equity curve and its summary
+func (t *EquityTracker) Curve() models.EquityCurveResponse {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return models.EquityCurveResponse{
+		TotalTrades: t.trades,
+		NetPnL:      t.equity,
+		MaxDrawdown: t.maxDrawdown,
+		Points:      append([]models.EquityPoint{}, t.points...),
+	}
+}
@@ -0,0 +1,40 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+// SymbolExposure aggregates open trades by symbol, sorted by symbol
+func SymbolExposure(trades []*models.Trade) []models.SymbolExposure {
+	bySymbol := make(map[string]*models.SymbolExposure)
+	for _, trade := range trades {
+		exposure, exists := bySymbol[trade.Symbol]
+		if !exists {
+			exposure = &models.SymbolExposure{Symbol: trade.Symbol}
+			bySymbol[trade.Symbol] = exposure
+		}
+		exposure.OpenTrades++
+		if trade.Side == models.SideSell {
+			exposure.Quantity -= trade.Quantity
+		} else {
+			exposure.Quantity += trade.Quantity
+		}
+	}
+
+	results := make([]models.SymbolExposure, 0, len(bySymbol))
+	for _, exposure := range bySymbol {
+		switch {
+		case exposure.Quantity > 0:
+			exposure.NetSide = models.SideBuy
+		case exposure.Quantity < 0:
+			exposure.NetSide = models.SideSell
+		}
+		results = append(results, *exposure)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Symbol < results[j].Symbol
+	})
+	return results
+}
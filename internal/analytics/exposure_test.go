@@ -0,0 +1,33 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+func TestSymbolExposureAggregatesOpenTrades(t *testing.T) {
+	open := func(symbol string, side models.Side, quantity float64) *models.Trade {
+		return &models.Trade{Symbol: symbol, Side: side, Quantity: quantity, EntryPrice: 100}
+	}
+	trades := []*models.Trade{
+		open("MSFT", models.SideBuy, 5),
+		open("AAPL", models.SideBuy, 10),
+		open("AAPL", models.SideSell, 4),
+		open("GOOGL", models.SideSell, 3),
+		open("MSFT", models.SideSell, 5),
+	}
+
+	want := []models.SymbolExposure{
+		{Symbol: "AAPL", OpenTrades: 2, Quantity: 6, NetSide: models.SideBuy},
+		{Symbol: "GOOGL", OpenTrades: 1, Quantity: -3, NetSide: models.SideSell},
+		{Symbol: "MSFT", OpenTrades: 2, Quantity: 0}, // Hedged: flat, still exposed
+	}
+	if got := SymbolExposure(trades); !reflect.DeepEqual(got, want) {
+		t.Errorf("exposure\n%+v\nwant\n%+v", got, want)
+	}
+	if got := SymbolExposure(nil); len(got) != 0 {
+		t.Errorf("exposure with no open trades %+v, want none", got)
+	}
+}
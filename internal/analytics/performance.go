@@ -0,0 +1,111 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Strategy Performance Flow:
+
+1. Grouping:
+   closed trade → StrategyID → strategy name (via lookup) → bucket
+   Manual trades (no StrategyID) and trades whose strategy cannot be
+   resolved are skipped.
+
+2. Per-Name Aggregates:
+   ├── trade_count, wins, losses
+   ├── win_rate = wins / trade_count * 100
+   ├── net_pnl  = Σ P&L (base currency)
+   ├── avg_trade = net_pnl / trade_count
+   ├── avg_return_pct = mean of per-trade pnl_percent (return on entry
+   │                    notional; 0 for zero-notional trades)
+   └── sharpe_ratio   = avg_return_pct / stddev(pnl_percent), per trade,
+                        not annualized; 0 below two trades or with no variance
+
+3. Output:
+   One entry per requested name, in request order. Names without trades
+   are reported with zero values so callers can tell "no data" apart
+   from "not requested".
+*/
+
+// returnStats returns the mean and mean/stddev (population) of per-trade returns
+func returnStats(returns []float64) (float64, float64) {
+	if len(returns) == 0 {
+		return 0, 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	if len(returns) < 2 {
+		return mean, 0
+	}
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)))
+	if std == 0 {
+		return mean, 0
+	}
+	return mean, mean / std
+}
+
+// StrategyNameLookup resolves a strategy ID to its strategy type name
+type StrategyNameLookup func(strategyID string) (string, bool)
+
+// CompareStrategies aggregates closed-trade performance per strategy type
+func CompareStrategies(trades []*models.Trade, lookup StrategyNameLookup, names []string, converter *CurrencyConverter) ([]models.StrategyPerformance, error) {
+	byName := make(map[string]*models.StrategyPerformance, len(names))
+	returns := make(map[string][]float64, len(names))
+	for _, name := range names {
+		byName[name] = &models.StrategyPerformance{Name: name}
+	}
+
+	for _, trade := range trades {
+		if trade.StrategyID == "" || trade.ExitTime.IsZero() {
+			continue
+		}
+		name, ok := lookup(trade.StrategyID)
+		if !ok {
+			continue
+		}
+		perf, requested := byName[name]
+		if !requested {
+			continue
+		}
+
+		pnl, err := converter.TradePnL(trade)
+		if err != nil {
+			return nil, fmt.Errorf("trade %s: %w", trade.ID, err)
+		}
+
+		perf.TradeCount++
+		perf.NetPnL += pnl
+		returns[name] = append(returns[name], trade.PnLPercent)
+		switch {
+		case pnl > 0:
+			perf.Wins++
+		case pnl < 0:
+			perf.Losses++
+		}
+	}
+
+	results := make([]models.StrategyPerformance, 0, len(names))
+	for _, name := range names {
+		perf := byName[name]
+		if perf.TradeCount > 0 {
+			perf.WinRate = float64(perf.Wins) / float64(perf.TradeCount) * 100
+			perf.AvgTrade = perf.NetPnL / float64(perf.TradeCount)
+			perf.AvgReturnPct, perf.SharpeRatio = returnStats(returns[name])
+		}
+		results = append(results, *perf)
+	}
+	return results, nil
+}
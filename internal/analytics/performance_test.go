@@ -0,0 +1,54 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+func TestCompareStrategiesGroupsByType(t *testing.T) {
+	strategyNames := map[string]string{
+		"strat-1": "martingale",
+		"strat-2": "martingale",
+		"strat-3": "repeat",
+	}
+	lookup := func(strategyID string) (string, bool) {
+		name, ok := strategyNames[strategyID]
+		return name, ok
+	}
+
+	now := time.Now()
+	trade := func(strategyID string, entry, exit float64) *models.Trade {
+		now = now.Add(time.Second)
+		trade := closedTrade("AAPL", entry, exit, 1, now)
+		trade.StrategyID = strategyID
+		trade.ComputePnL()
+		return trade
+	}
+	trades := []*models.Trade{
+		trade("strat-1", 100, 110), // +10
+		trade("strat-2", 100, 95),  // -5, same type as strat-1
+		trade("strat-3", 100, 120), // +20
+		trade("strat-3", 100, 120), // +20
+		trade("", 100, 200),        // Manual
+		trade("gone", 100, 200),    // Unknown strategy
+	}
+	open := closedTrade("AAPL", 100, 0, 1, now)
+	open.StrategyID, open.ExitTime = "strat-3", time.Time{}
+	trades = append(trades, open)
+
+	results, err := CompareStrategies(trades, lookup, []string{"repeat", "martingale", "rsi"}, NewCurrencyConverter("USD", nil))
+	if err != nil {
+		t.Fatalf("CompareStrategies: %v", err)
+	}
+	want := []models.StrategyPerformance{
+		{Name: "repeat", TradeCount: 2, Wins: 2, WinRate: 100, NetPnL: 40, AvgTrade: 20, AvgReturnPct: 20},
+		{Name: "martingale", TradeCount: 2, Wins: 1, Losses: 1, WinRate: 50, NetPnL: 5, AvgTrade: 2.5, AvgReturnPct: 2.5, SharpeRatio: 2.5 / 7.5},
+		{Name: "rsi"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results\n%+v\nwant\n%+v", results, want)
+	}
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+/*
+API Key Authentication:
+
+1. Credentials:
+   REST:      Authorization: Bearer <key>
+   WebSocket: Authorization: Bearer <key>, or ?token=<key> on the /ws URL
+              (browsers cannot set headers on WebSocket upgrades)
+
+2. Check:
+   ├── apiKey == ""        → allowed (auth disabled)
+   ├── credential matches  → allowed (constant-time compare)
+   └── otherwise           → rejected; callers answer 401 Unauthorized
+*/
+
+// Authorized reports whether the request carries apiKey as a bearer token
+// (or, with allowQueryToken, as the "token" query parameter)
+// An empty apiKey disables the check
+func Authorized(r *http.Request, apiKey string, allowQueryToken bool) bool {
+	if apiKey == "" {
+		return true
+	}
+	if token, ok := bearerToken(r); ok && matches(token, apiKey) {
+		return true
+	}
+	if allowQueryToken {
+		if token := r.URL.Query().Get("token"); token != "" && matches(token, apiKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// matches compares a token with the key without leaking timing
+func matches(token, apiKey string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) == 1
+}
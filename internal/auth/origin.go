@@ -0,0 +1,32 @@
+package auth
+
+import "strings"
+
+// OriginAllowed reports whether a browser Origin may use the API
+// An empty allow list or a "*" entry allows every origin; otherwise the
+// origin must match an entry exactly (case-insensitive), e.g.
+// "https://app.example.com"
+func OriginAllowed(origin string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, entry := range allowed {
+		if entry == "*" || strings.EqualFold(strings.TrimSuffix(entry, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsAnyOrigin reports whether the allow list is in wildcard mode
+func AllowsAnyOrigin(allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, entry := range allowed {
+		if entry == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+)
+
+/*
+Rate Limiting:
+
+1. Keys:
+   With auth enabled, requests whose "Authorization: Bearer <key>" matches
+   the API key are limited per key; all others are limited per client IP
+   (the connection's remote address; X-Forwarded-For is not trusted, since
+   any client can set it). Unverified tokens never pick the bucket, so a
+   client cannot dodge its limit by sending a fresh token per request.
+
+2. Token Bucket (one per key):
+   ├── holds up to burst tokens, starting full
+   ├── refills at rate tokens per second
+   └── each request takes a token:
+       ├── token available → allowed
+       └── empty           → rejected, with the wait until the next token
+                             (callers answer 429 with Retry-After)
+
+3. Cleanup:
+   Buckets that have refilled completely are dropped at most once a
+   minute; an absent bucket behaves exactly like a full one.
+*/
+
+// rateLimitSweepInterval is how often full buckets are dropped
+const rateLimitSweepInterval = time.Minute
+
+// RateLimiter is a thread-safe set of token buckets keyed by client
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	clock clock.Clock
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket is one client's tokens as of last
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rate requests per second per
+// client with bursts of up to burst requests
+// Returns nil (no limit) when rate is not positive
+func NewRateLimiter(rate float64, burst int, clk clock.Clock) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		clock:   clk,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// ClientKey returns the key a request is limited by: the API key when auth
+// is enabled (apiKey != "") and the request's bearer token matches it,
+// otherwise its client IP
+func ClientKey(r *http.Request, apiKey string) string {
+	if apiKey != "" {
+		if token, ok := bearerToken(r); ok && matches(token, apiKey) {
+			return "key:" + token
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Allow takes a token from the key's bucket; when it is empty it returns
+// false and how long until a token is available
+// A nil limiter allows everything
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+	l.refill(bucket, now)
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// refill adds the tokens earned since the bucket was last updated
+// Caller must hold l.mu
+func (l *RateLimiter) refill(bucket *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed.Seconds()*l.rate)
+	}
+	bucket.last = now
+}
+
+// sweep drops buckets that have refilled completely
+// Caller must hold l.mu
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		l.refill(bucket, now)
+		if bucket.tokens >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+)
+
+func TestRateLimiterRejectsBurstThenRecovers(t *testing.T) {
+	start := time.Date(2025, 1, 23, 11, 0, 0, 0, time.UTC)
+	clk := clock.NewSimulated(start)
+	limiter := NewRateLimiter(2, 3, clk)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow("ip:1.2.3.4"); !allowed {
+			t.Fatalf("request %d within the burst was rejected", i+1)
+		}
+	}
+	allowed, wait := limiter.Allow("ip:1.2.3.4")
+	if allowed {
+		t.Fatal("request past the burst was allowed")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait %v, want 500ms at 2 requests/s", wait)
+	}
+
+	// Other clients have their own bucket
+	if allowed, _ := limiter.Allow("ip:5.6.7.8"); !allowed {
+		t.Error("another client was rejected")
+	}
+
+	clk.Advance(start.Add(500 * time.Millisecond))
+	if allowed, _ := limiter.Allow("ip:1.2.3.4"); !allowed {
+		t.Error("request after the refill was rejected")
+	}
+}
+
+func TestNilRateLimiterAllowsEverything(t *testing.T) {
+	limiter := NewRateLimiter(0, 10, clock.Real{})
+	if limiter != nil {
+		t.Fatal("rate 0 returned a limiter")
+	}
+	if allowed, _ := limiter.Allow("ip:1.2.3.4"); !allowed {
+		t.Error("nil limiter rejected a request")
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		token  string
+		want   string
+	}{
+		{"no auth, no token", "", "", "ip:10.0.0.1"},
+		{"no auth ignores tokens", "", "anything", "ip:10.0.0.1"},
+		{"verified key", "secret", "secret", "key:secret"},
+		{"wrong key falls back to IP", "secret", "guess", "ip:10.0.0.1"},
+		{"missing key falls back to IP", "secret", "", "ip:10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/trades/buy", nil)
+			r.RemoteAddr = "10.0.0.1:54321"
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if got := ClientKey(r, tt.apiKey); got != tt.want {
+				t.Errorf("ClientKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/analytics"
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+	"github.com/aumbhatt/auto_trade/internal/strategy"
+)
+
+/*
+Backtest Flow:
+
+1. Setup:
+   a. Validate the parameters against the strategy's registry metadata
+   b. Simulated clock starting at the first tick, so trade times, cooldowns
+      and error windows follow the data
+   c. Fresh in-memory trade store and DefaultRunner on that clock; nothing
+      is shared with a live server
+
+2. Replay:
+   DefaultRunner.Replay creates the executor from the registry and feeds
+   the ticks in slice order (they should be in timestamp order), through
+   the same executeBuy/executeSell paths as live trading. Executor errors
+   are kept in an ErrorLog and do not stop the backtest.
+
+3. Result (computed with the internal/analytics equity helpers):
+   ├── total_trades    // Closed trades
+   ├── wins, losses    // Closed trades with positive / negative P&L
+   ├── win_rate        // wins / total_trades * 100
+   ├── net_pnl         // Σ realized P&L
+   ├── max_drawdown    // Largest fall of cumulative realized P&L from its
+   │                   // running peak (starting at 0), by exit time
+   ├── sharpe_ratio    // Mean / stddev of per-trade pnl_percent
+   ├── open_trades     // Still open after the last tick, valued at their
+   └── unrealized_pnl  // symbol's last tick price (not in net_pnl)
+
+   Example: closed P&L +10, -4, -3, +8 → equity 10, 6, 3, 11
+            net_pnl 11, max_drawdown 7 (10 → 3)
+
+4. Usage Example:
+   result, err := backtest.Run("repeat", map[string]interface{}{
+       "symbol":     "AAPL",
+       "exit_price": 155.0,
+   }, ticks)
+*/
+
+// BacktestResult summarizes a strategy's trades over a tick series
+type BacktestResult struct {
+	Strategy      string                    `json:"strategy"`
+	Ticks         int                       `json:"ticks"`
+	TotalTrades   int                       `json:"total_trades"`
+	Wins          int                       `json:"wins"`
+	Losses        int                       `json:"losses"`
+	WinRate       float64                   `json:"win_rate"` // Percentage of closed trades with positive P&L
+	NetPnL        float64                   `json:"net_pnl"`
+	MaxDrawdown   float64                   `json:"max_drawdown"`
+	SharpeRatio   float64                   `json:"sharpe_ratio"` // Per trade, see analytics.SharpeRatio
+	OpenTrades    int                       `json:"open_trades"`
+	UnrealizedPnL float64                   `json:"unrealized_pnl"`
+	Trades        []*models.Trade           `json:"trades"` // Closed trades by exit time
+	Errors        models.StrategyErrorStats `json:"errors"`
+}
+
+// Run backtests a registered strategy over ticks and summarizes its trades
+// Returns an error for unknown strategies and invalid parameters
+func Run(strategyName string, params map[string]interface{}, ticks []*models.Tick) (BacktestResult, error) {
+	if err := strategy.GetDefaultRegistry().ValidateParameters(strategyName, params); err != nil {
+		return BacktestResult{}, err
+	}
+
+	var start time.Time
+	for _, tick := range ticks {
+		if tick != nil {
+			start = tick.Timestamp
+			break
+		}
+	}
+	simulated := clock.NewSimulated(start)
+
+	trades := memory.NewInMemoryTradeStore()
+	trades.SetClock(simulated)
+	runner := strategy.NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	runner.SetClock(simulated)
+
+	instance := models.NewStrategy(strategyName, params)
+	errorLog := strategy.NewErrorLog(strategy.DefaultErrorBufferSize)
+	err := runner.Replay(instance, ticks, func(err error) {
+		errorLog.Add(err, simulated.Now())
+	})
+	if err != nil {
+		return BacktestResult{}, err
+	}
+
+	closed, err := trades.GetTradeHistory()
+	if err != nil {
+		return BacktestResult{}, err
+	}
+	open, err := trades.GetOpenTrades()
+	if err != nil {
+		return BacktestResult{}, err
+	}
+
+	result := summarize(closed)
+	result.Strategy = strategyName
+	result.Ticks = len(ticks)
+	result.OpenTrades = len(open)
+	result.UnrealizedPnL = unrealizedPnL(open, ticks)
+	result.Errors = errorLog.Stats(instance.ID)
+	return result, nil
+}
+
+// summarize computes the closed-trade statistics, ordering trades by exit time
+func summarize(closed []*models.Trade) BacktestResult {
+	closed = analytics.ClosedByExit(closed)
+	curve := analytics.EquityCurve(closed)
+
+	result := BacktestResult{
+		TotalTrades: len(closed),
+		MaxDrawdown: analytics.MaxDrawdown(curve),
+		SharpeRatio: analytics.SharpeRatio(closed),
+		Trades:      closed,
+	}
+	result.Wins, result.Losses = analytics.WinLoss(closed)
+	if len(curve) > 0 {
+		result.NetPnL = curve[len(curve)-1]
+	}
+	if result.TotalTrades > 0 {
+		result.WinRate = float64(result.Wins) / float64(result.TotalTrades) * 100
+	}
+	return result
+}
+
+// unrealizedPnL values open trades at the last tick price of their symbol
+func unrealizedPnL(open []*models.Trade, ticks []*models.Tick) float64 {
+	last := make(map[string]float64)
+	for _, tick := range ticks {
+		if tick != nil {
+			last[tick.Symbol] = tick.Price
+		}
+	}
+
+	total := 0.0
+	for _, trade := range open {
+		if price, ok := last[trade.Symbol]; ok {
+			total += trade.UnrealizedPnL(price)
+		}
+	}
+	return total
+}
@@ -0,0 +1,87 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+func TestCooldownFollowsTickTime(t *testing.T) {
+	start := time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC)
+	tick := func(after time.Duration, price float64) *models.Tick {
+		return &models.Tick{Symbol: "AAPL", Price: price, Timestamp: start.Add(after)}
+	}
+
+	// Replayed back to back, but spanning over a minute of data time
+	ticks := []*models.Tick{
+		tick(0, 100),              // Buy
+		tick(time.Second, 110),    // Exit
+		tick(2*time.Second, 100),  // Within the cooldown: no buy
+		tick(61*time.Second, 100), // Cooldown over: buy
+		tick(62*time.Second, 110), // Exit
+	}
+	result, err := Run("repeat", map[string]interface{}{
+		"symbol":                    "AAPL",
+		"exit_price":                110.0,
+		"reversal_cooldown_seconds": 60.0,
+	}, ticks)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if result.TotalTrades != 2 || result.OpenTrades != 0 {
+		t.Fatalf("%d closed and %d open trades, want 2 closed", result.TotalTrades, result.OpenTrades)
+	}
+
+	// Trade times come from the simulated clock, not the wall clock
+	second := result.Trades[1]
+	if !second.EntryTime.Equal(start.Add(61*time.Second)) || !second.ExitTime.Equal(start.Add(62*time.Second)) {
+		t.Errorf("second trade held %v to %v, want from tick 61s to 62s", second.EntryTime, second.ExitTime)
+	}
+}
+
+func TestRepeatBacktestSummary(t *testing.T) {
+	start := time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC)
+	prices := []float64{
+		100, 96, 95, // Buy, trail to 95: -5
+		96, 110, // Buy, exit price: +14
+		104, 108, 101, // Buy, trail to 102.6 and below: -3
+		99, 97, // Buy, still open at 97
+	}
+	ticks := make([]*models.Tick, len(prices))
+	for i, price := range prices {
+		ticks[i] = &models.Tick{Symbol: "AAPL", Price: price, Timestamp: start.Add(time.Duration(i) * time.Second)}
+	}
+
+	result, err := Run("repeat", map[string]interface{}{
+		"symbol":        "AAPL",
+		"exit_price":    110.0,
+		"trailing_stop": 5.0,
+	}, ticks)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Equity -5, 9, 6: the worst fall is 5, from the starting 0
+	if result.TotalTrades != 3 || result.Wins != 1 || result.Losses != 2 {
+		t.Errorf("%d trades, %d wins, %d losses; want 3, 1 and 2", result.TotalTrades, result.Wins, result.Losses)
+	}
+	if result.NetPnL != 6 || result.MaxDrawdown != 5 {
+		t.Errorf("net P&L %v, max drawdown %v; want 6 and 5", result.NetPnL, result.MaxDrawdown)
+	}
+	if want := 100.0 / 3; math.Abs(result.WinRate-want) > 1e-9 {
+		t.Errorf("win rate %v, want %v", result.WinRate, want)
+	}
+	if result.OpenTrades != 1 || result.UnrealizedPnL != -2 {
+		t.Errorf("%d open trades worth %v, want one at -2", result.OpenTrades, result.UnrealizedPnL)
+	}
+	if result.Ticks != len(prices) || len(result.Errors.Errors) != 0 {
+		t.Errorf("%d ticks with %d errors, want %d and none", result.Ticks, len(result.Errors.Errors), len(prices))
+	}
+
+	if _, err := Run("repeat", map[string]interface{}{"symbol": "AAPL"}, ticks); err == nil {
+		t.Error("backtest ran without an exit parameter")
+	}
+}
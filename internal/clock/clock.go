@@ -0,0 +1,18 @@
+package clock
+
+import "time"
+
+// Clock abstracts the current time so time-dependent logic can be tested
+// and driven by data instead of the wall clock
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// Real is a Clock backed by the system wall clock
+type Real struct{}
+
+// Now implements Clock
+func (Real) Now() time.Time {
+	return time.Now()
+}
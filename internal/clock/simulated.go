@@ -0,0 +1,44 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Advancer is a Clock whose time is moved forward by the data flowing
+// through it, e.g. tick timestamps during a backtest
+type Advancer interface {
+	Clock
+
+	// Advance moves the clock to t; earlier times are ignored
+	Advance(t time.Time)
+}
+
+// Simulated is a Clock that only moves when advanced, so time-based logic
+// (cooldowns, windows, runtimes) follows replayed tick timestamps instead of
+// the wall clock
+type Simulated struct {
+	now time.Time
+	mu  sync.RWMutex
+}
+
+// NewSimulated creates a simulated clock starting at start
+func NewSimulated(start time.Time) *Simulated {
+	return &Simulated{now: start}
+}
+
+// Now implements Clock
+func (c *Simulated) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Advance implements Advancer; out-of-order times never move the clock back
+func (c *Simulated) Advance(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
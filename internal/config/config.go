@@ -3,35 +3,247 @@ package config
 import "time"
 
 // Config holds all configuration for the application
+// Fields tagged `secret:"true"` are redacted by Redacted
 type Config struct {
-	Server ServerConfig
-	App    AppConfig
+	Server    ServerConfig    `json:"server"`
+	App       AppConfig       `json:"app"`
+	Currency  CurrencyConfig  `json:"currency"`
+	Strategy  StrategyConfig  `json:"strategy"`
+	Trading   TradingConfig   `json:"trading"`
+	Feed      FeedConfig      `json:"feed"`
+	Storage   StorageConfig   `json:"storage"`
+	Analytics AnalyticsConfig `json:"analytics"`
 }
 
 // ServerConfig holds all server-related configuration
 type ServerConfig struct {
-	Port         int           `json:"port"`
+	Port int `json:"port"`
+	// ReadTimeout bounds reading a whole REST request, body included, and
+	// WriteTimeout writing its response (0 = no limit); WebSocket
+	// connections drop both deadlines once upgraded
 	ReadTimeout  time.Duration `json:"readTimeout"`
 	WriteTimeout time.Duration `json:"writeTimeout"`
+	// ReconnectBackoff is the base reconnect delay advertised to WebSocket
+	// clients when the server closes their connection; each client is told
+	// a jittered delay in [base, 2*base)
+	ReconnectBackoff time.Duration `json:"reconnectBackoff"`
+	// MaxMessageSize is the largest WebSocket message accepted from a
+	// client, in bytes; larger messages close the connection
+	MaxMessageSize int64 `json:"maxMessageSize"`
+	// MaxSubscriptions caps the active subscriptions of each WebSocket
+	// client; further subscribes fail with SUBSCRIPTION_LIMIT (0 = unlimited)
+	MaxSubscriptions int `json:"maxSubscriptions"`
+	// ResumeTTL is how long a dropped WebSocket client can reconnect with
+	// its resume token and get its subscriptions back; 0 disables resuming
+	ResumeTTL time.Duration `json:"resumeTTL"`
+	// Compression negotiates permessage-deflate with WebSocket clients
+	// that offer it; others connect uncompressed
+	Compression bool `json:"compression"`
+	// ShutdownTimeout bounds how long shutdown waits for connections to close
+	ShutdownTimeout time.Duration `json:"shutdownTimeout"`
+	// AuthEnabled requires APIKey on every REST request (Authorization:
+	// Bearer) and WebSocket connection (header or ?token=); it can only be
+	// off when App.Environment is "development" (see Validate)
+	AuthEnabled bool   `json:"authEnabled"`
+	APIKey      string `json:"apiKey" secret:"true"`
+	// AllowedOrigins lists the browser origins (e.g. "https://app.example.com")
+	// allowed by CORS and WebSocket upgrades; empty or "*" allows any origin
+	AllowedOrigins []string `json:"allowedOrigins"`
+	// RateLimit caps trade-changing REST requests (buy, sell, close by
+	// symbol, limit orders and cancels, strategy start/stop/stop-all/
+	// pause/resume) per client, keyed by verified API key or IP, in
+	// requests per second; 0 disables the limit.
+	// RateLimitBurst is how many requests may arrive at once
+	RateLimit      float64 `json:"rateLimit"`
+	RateLimitBurst int     `json:"rateLimitBurst"`
 }
 
 // AppConfig holds application-specific configuration
 type AppConfig struct {
 	Environment string `json:"environment"`
 	LogLevel    string `json:"logLevel"`
+	// Symbols is the allowlist of tradeable symbols (case-insensitive);
+	// empty accepts any symbol
+	Symbols []string `json:"symbols"`
+}
+
+// CurrencyConfig holds P&L currency conversion configuration
+type CurrencyConfig struct {
+	BaseCurrency string             `json:"baseCurrency"`
+	Rates        map[string]float64 `json:"rates"` // currency -> value of one unit in BaseCurrency
+}
+
+// StrategyConfig holds strategy feed configuration
+type StrategyConfig struct {
+	// SnapshotInterval re-broadcasts the active strategies list on a timer
+	// in addition to change-driven updates; 0 means change-only
+	SnapshotInterval time.Duration `json:"snapshotInterval"`
+
+	// MaxMartingalePositions caps the martingale max_positions parameter
+	MaxMartingalePositions int `json:"maxMartingalePositions"`
+
+	// ErrorBufferSize caps the errors retained per strategy; older errors
+	// are dropped and counted as overflow
+	ErrorBufferSize int `json:"errorBufferSize"`
+
+	// ErrorRetention keeps a stopped strategy's errors readable for this
+	// long; 0 drops them when the strategy stops
+	ErrorRetention time.Duration `json:"errorRetention"`
+
+	// MaxErrors auto-stops a strategy that produces more than this many
+	// errors within ErrorWindow; 0 tolerates errors indefinitely
+	MaxErrors   int           `json:"maxErrors"`
+	ErrorWindow time.Duration `json:"errorWindow"`
+}
+
+// TradingConfig holds trade execution configuration
+type TradingConfig struct {
+	// MaxPriceAge rejects opens with STALE_PRICE when the latest cached
+	// price for the symbol is older than this; 0 disables the guard.
+	// Given as a duration string ("5s") or milliseconds (5000)
+	MaxPriceAge Duration `json:"maxPriceAge"`
+
+	// ListenerQueueSize delivers trade events to each listener asynchronously
+	// through its own ordered queue of this size; 0 delivers synchronously
+	ListenerQueueSize int `json:"listenerQueueSize"`
+
+	// ClosePolicy orders lots when closing by symbol: "fifo" (oldest
+	// first) or "lifo" (newest first)
+	ClosePolicy string `json:"closePolicy"`
+
+	// DisabledSymbols starts with trading halted on these symbols; they
+	// can be re-enabled at runtime through the admin API
+	DisabledSymbols []string `json:"disabledSymbols"`
+
+	// MaxPendingOrders caps resting limit orders; placing more is rejected
+	// with PENDING_ORDER_LIMIT. 0 is unlimited
+	MaxPendingOrders int `json:"maxPendingOrders"`
+
+	// MaxOpenPerSymbol and MaxOpenTotal cap open trades per symbol and in
+	// total; opening more is rejected with POSITION_LIMIT. 0 is unlimited
+	MaxOpenPerSymbol int `json:"maxOpenPerSymbol"`
+	MaxOpenTotal     int `json:"maxOpenTotal"`
+
+	// DailyLossLimit rejects new trades with RISK_LIMIT once the trading
+	// day's realized losses reach it; 0 disables the breaker
+	DailyLossLimit float64 `json:"dailyLossLimit"`
+
+	// DailyLossRollover starts the trading day this long after midnight
+	// UTC, resetting realized losses (0-24h, default 0 = midnight UTC)
+	DailyLossRollover time.Duration `json:"dailyLossRollover"`
+
+	// AccountBalance is what a strategy's fractional capital_allocation
+	// (up to 1) is taken of; 0 refuses fractional allocations
+	AccountBalance float64 `json:"accountBalance"`
+}
+
+// FeedConfig holds WebSocket feed configuration
+type FeedConfig struct {
+	// BroadcastBuffer sizes the hub's fan-out queue shared by all feeds;
+	// messages broadcast while it is full are dropped
+	BroadcastBuffer int `json:"broadcastBuffer"`
+	// MaxDeltaRetention caps the trades a delta-mode trade_history
+	// subscriber may have retained server-side for diffing; beyond it the
+	// subscriber falls back to full snapshots
+	MaxDeltaRetention int `json:"maxDeltaRetention"`
+	// MarketValueMaxAge is the oldest cached price used to value open
+	// positions; older or missing prices mark the position stale (0 = any age)
+	MarketValueMaxAge time.Duration `json:"marketValueMaxAge"`
+	// TickInterval is how often the tick source is pulled; ticks
+	// subscriptions can sample the feed with interval_ms no faster than this
+	TickInterval time.Duration `json:"tickInterval"`
+	// OpenPositionsBatchWindow coalesces trade events within this window
+	// into one open_positions (and one positions) update; 0 sends an
+	// update per event
+	OpenPositionsBatchWindow time.Duration `json:"openPositionsBatchWindow"`
+	// Source selects the tick source: "mock" (random prices),
+	// "random_walk" (prices moving at most WalkVolatility per tick) or
+	// "csv" (replay of CSVPath, restarting at the end when CSVLoop is set)
+	Source  string `json:"source"`
+	CSVPath string `json:"csvPath"`
+	CSVLoop bool   `json:"csvLoop"`
+	// MockSeed makes the mock source repeat the same tick sequence on every
+	// run; 0 seeds it from the current time
+	MockSeed int64 `json:"mockSeed"`
+	// MockMinPrice and MockMaxPrice bound the mock source's prices; it
+	// draws symbols from App.Symbols
+	MockMinPrice float64 `json:"mockMinPrice"`
+	MockMaxPrice float64 `json:"mockMaxPrice"`
+	// WalkVolatility is the random walk's largest fractional move per tick;
+	// each symbol starts at WalkStartPrices, or mid-band if absent
+	WalkVolatility  float64            `json:"walkVolatility"`
+	WalkStartPrices map[string]float64 `json:"walkStartPrices"`
+}
+
+// StorageConfig selects where trades and strategies are kept
+type StorageConfig struct {
+	// Type is "memory" (lost on restart) or "sqlite" (persisted to Path)
+	Type string `json:"type"`
+	// Path is the SQLite database file used when Type is "sqlite"
+	Path string `json:"path"`
+}
+
+// AnalyticsConfig holds analytics configuration
+type AnalyticsConfig struct {
+	// EquityMaxPoints caps the points of the live equity curve; older
+	// points are merged into buckets past it (0 = one point per trade)
+	EquityMaxPoints int `json:"equityMaxPoints"`
 }
 
 // NewDefaultConfig returns a Config instance with default values
 func NewDefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:         8080,
-			ReadTimeout:  time.Second * 15,
-			WriteTimeout: time.Second * 15,
+			Port:             8080,
+			ReadTimeout:      time.Second * 15,
+			WriteTimeout:     time.Second * 15,
+			ReconnectBackoff: time.Second,
+			MaxMessageSize:   8192,
+			MaxSubscriptions: 100,
+			ResumeTTL:        time.Second * 30,
+			Compression:      true,
+			ShutdownTimeout:  time.Second * 10,
+			RateLimitBurst:   10,
 		},
 		App: AppConfig{
 			Environment: "development",
 			LogLevel:    "info",
+			Symbols:     []string{"AAPL", "GOOGL", "MSFT", "AMZN"},
+		},
+		Currency: CurrencyConfig{
+			BaseCurrency: "USD",
+			Rates:        map[string]float64{},
+		},
+		Strategy: StrategyConfig{
+			SnapshotInterval:       0,
+			MaxMartingalePositions: 20,
+			ErrorBufferSize:        100,
+			ErrorRetention:         24 * time.Hour,
+			MaxErrors:              10,
+			ErrorWindow:            time.Minute,
+		},
+		Trading: TradingConfig{
+			MaxPriceAge:       0,
+			ListenerQueueSize: 0,
+			ClosePolicy:       "fifo",
+		},
+		Feed: FeedConfig{
+			BroadcastBuffer:          4096,
+			MaxDeltaRetention:        10000,
+			MarketValueMaxAge:        time.Minute,
+			TickInterval:             time.Second,
+			OpenPositionsBatchWindow: 100 * time.Millisecond,
+			Source:                   "mock",
+			MockMinPrice:             100,
+			MockMaxPrice:             1000,
+			WalkVolatility:           0.002,
+		},
+		Storage: StorageConfig{
+			Type: "memory",
+			Path: "auto_trade.db",
+		},
+		Analytics: AnalyticsConfig{
+			EquityMaxPoints: 1000,
 		},
 	}
 }
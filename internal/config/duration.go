@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Duration is a time.Duration that config files may give either as a
+// duration string ("500ms", "5s", "1m") or as a number of milliseconds
+// It is written back as a duration string
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	switch value := raw.(type) {
+	case string:
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %v", value, err)
+		}
+		*d = Duration(parsed)
+	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) || math.Abs(value) > float64(math.MaxInt64/int64(time.Millisecond)) {
+			return fmt.Errorf("invalid duration %v: out of range", value)
+		}
+		*d = Duration(value * float64(time.Millisecond))
+	default:
+		return fmt.Errorf("invalid duration %s: must be a duration string or milliseconds", data)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDurationUnmarshal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{`"5s"`, 5 * time.Second, false},
+		{`"250ms"`, 250 * time.Millisecond, false},
+		{`5000`, 5 * time.Second, false},
+		{`1.5`, 1500 * time.Microsecond, false},
+		{`0`, 0, false},
+		{`"5 seconds"`, 0, true},
+		{`true`, 0, true},
+		{`1e300`, 0, true},
+	}
+	for _, tt := range tests {
+		var d Duration
+		err := json.Unmarshal([]byte(tt.input), &d)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got %v, want an error", tt.input, time.Duration(d))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.input, err)
+			continue
+		}
+		if time.Duration(d) != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.input, time.Duration(d), tt.want)
+		}
+	}
+}
+
+func TestLoadMaxPriceAge(t *testing.T) {
+	for _, value := range []string{`"2s"`, `2000`} {
+		path := filepath.Join(t.TempDir(), "config.json")
+		data := []byte(`{"trading": {"maxPriceAge": ` + value + `}}`)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load with maxPriceAge %s: %v", value, err)
+		}
+		if got := time.Duration(cfg.Trading.MaxPriceAge); got != 2*time.Second {
+			t.Errorf("maxPriceAge %s loaded as %v, want 2s", value, got)
+		}
+
+		// Written back as a duration string
+		out, _ := json.Marshal(cfg.Trading)
+		var fields map[string]interface{}
+		json.Unmarshal(out, &fields)
+		if fields["maxPriceAge"] != "2s" {
+			t.Errorf("maxPriceAge marshalled as %v, want \"2s\"", fields["maxPriceAge"])
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Config Loading Flow:
+
+1. Layers (later layers win):
+   a. Defaults (NewDefaultConfig)
+   b. JSON file, when a path is given; only the fields present in the file
+      are changed, using the same field names GET /api/config returns
+      (durations are nanoseconds, except trading.maxPriceAge, which takes
+      a duration string such as "5s" or milliseconds)
+   c. Environment variables:
+      SERVER_PORT      → server.port
+      APP_ENV          → app.environment
+      LOG_LEVEL        → app.logLevel
+      ALLOWED_ORIGINS  → server.allowedOrigins (comma-separated)
+
+2. Validation:
+   - Unknown fields in the file are rejected, so typos are not silently
+     ignored
+   - server.port must be 1-65535
+   - server.authEnabled needs a server.apiKey, and may only be turned off
+     when app.environment is "development", so a deployed server never
+     starts unauthenticated
+   - server.rateLimit and server.rateLimitBurst must not be negative
+   - app.logLevel must be one of LogLevels
+   - trading.dailyLossLimit must not be negative, and
+     trading.dailyLossRollover must be within a day (0 to under 24h)
+   - trading.accountBalance must not be negative
+
+3. Usage Example:
+   cfg, err := config.Load("config.json") // or Load("") for defaults + env
+*/
+
+// LogLevels are the accepted values of app.logLevel
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// Load returns the default config overlaid with the JSON file at path (if
+// path is not empty) and then environment variables
+func Load(path string) (*Config, error) {
+	cfg := NewDefaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overlays the supported environment variables on cfg
+func applyEnv(cfg *Config) error {
+	if value, ok := os.LookupEnv("SERVER_PORT"); ok {
+		port, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid SERVER_PORT %q: must be a number", value)
+		}
+		cfg.Server.Port = port
+	}
+	if value, ok := os.LookupEnv("APP_ENV"); ok {
+		cfg.App.Environment = value
+	}
+	if value, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		cfg.App.LogLevel = strings.ToLower(strings.TrimSpace(value))
+	}
+	if value, ok := os.LookupEnv("ALLOWED_ORIGINS"); ok {
+		origins := make([]string, 0)
+		for _, origin := range strings.Split(value, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+		cfg.Server.AllowedOrigins = origins
+	}
+	return nil
+}
+
+// Validate checks the settings Load accepts from outside the program
+func (c *Config) Validate() error {
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		return fmt.Errorf("invalid server.port %d: must be 1-65535", c.Server.Port)
+	}
+	if c.Server.AuthEnabled && c.Server.APIKey == "" {
+		return fmt.Errorf("server.apiKey is required when server.authEnabled is true")
+	}
+	if !c.Server.AuthEnabled && c.App.Environment != "development" {
+		return fmt.Errorf("server.authEnabled must be true outside development (app.environment %q)", c.App.Environment)
+	}
+	if c.Server.RateLimit < 0 || c.Server.RateLimitBurst < 0 {
+		return fmt.Errorf("invalid server.rateLimit %v / rateLimitBurst %d: must not be negative", c.Server.RateLimit, c.Server.RateLimitBurst)
+	}
+	if c.Trading.DailyLossLimit < 0 {
+		return fmt.Errorf("invalid trading.dailyLossLimit %v: must not be negative", c.Trading.DailyLossLimit)
+	}
+	if c.Trading.DailyLossRollover < 0 || c.Trading.DailyLossRollover >= 24*time.Hour {
+		return fmt.Errorf("invalid trading.dailyLossRollover %s: must be 0 to under 24h", c.Trading.DailyLossRollover)
+	}
+	if c.Strategy.ErrorRetention < 0 {
+		return fmt.Errorf("invalid strategy.errorRetention %s: must not be negative", c.Strategy.ErrorRetention)
+	}
+	if c.Feed.TickInterval <= 0 {
+		return fmt.Errorf("invalid feed.tickInterval %s: must be positive", c.Feed.TickInterval)
+	}
+	if c.Analytics.EquityMaxPoints < 0 {
+		return fmt.Errorf("invalid analytics.equityMaxPoints %d: must not be negative", c.Analytics.EquityMaxPoints)
+	}
+	if c.Trading.AccountBalance < 0 {
+		return fmt.Errorf("invalid trading.accountBalance %v: must not be negative", c.Trading.AccountBalance)
+	}
+	for _, level := range LogLevels {
+		if c.App.LogLevel == level {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid app.logLevel %q: must be one of %s", c.App.LogLevel, strings.Join(LogLevels, ", "))
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRequiresAuthOutsideDevelopment(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     string
+		wantErr string
+	}{
+		{
+			name: "development without auth",
+			file: `{}`,
+			env:  "development",
+		},
+		{
+			name:    "production without auth",
+			file:    `{}`,
+			env:     "production",
+			wantErr: "server.authEnabled must be true outside development",
+		},
+		{
+			name:    "production auth without a key",
+			file:    `{"server": {"authEnabled": true}}`,
+			env:     "production",
+			wantErr: "server.apiKey is required",
+		},
+		{
+			name: "production with a key",
+			file: `{"server": {"authEnabled": true, "apiKey": "secret"}}`,
+			env:  "production",
+		},
+		{
+			name:    "development auth without a key",
+			file:    `{"server": {"authEnabled": true}}`,
+			env:     "development",
+			wantErr: "server.apiKey is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			t.Setenv("APP_ENV", tt.env)
+
+			_, err := Load(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// unsetEnv clears the Load environment variables for the test
+func unsetEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"SERVER_PORT", "APP_ENV", "LOG_LEVEL", "ALLOWED_ORIGINS"} {
+		t.Setenv(name, "") // Restores the variable when the test ends
+		os.Unsetenv(name)
+	}
+}
+
+func TestLoadLayersFileAndEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		check   func(t *testing.T, cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "file only",
+			file: `{"server": {"port": 9090}, "app": {"logLevel": "debug"}}`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9090 || cfg.App.LogLevel != "debug" {
+					t.Errorf("port %d, log level %q; want the file's 9090 and debug", cfg.Server.Port, cfg.App.LogLevel)
+				}
+				if cfg.App.Environment != "development" {
+					t.Errorf("environment %q, want the default kept", cfg.App.Environment)
+				}
+			},
+		},
+		{
+			name: "env overrides file",
+			file: `{"server": {"port": 9090}, "app": {"logLevel": "debug"}}`,
+			env:  map[string]string{"SERVER_PORT": "7070", "LOG_LEVEL": "WARN", "ALLOWED_ORIGINS": " https://a.example.com, ,https://b.example.com"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 7070 || cfg.App.LogLevel != "warn" {
+					t.Errorf("port %d, log level %q; want the env's 7070 and warn", cfg.Server.Port, cfg.App.LogLevel)
+				}
+				if got := strings.Join(cfg.Server.AllowedOrigins, " "); got != "https://a.example.com https://b.example.com" {
+					t.Errorf("allowed origins %q, want the two listed", got)
+				}
+			},
+		},
+		{
+			name:    "port out of range",
+			file:    `{"server": {"port": 70000}}`,
+			wantErr: "invalid server.port 70000",
+		},
+		{
+			name:    "port not a number",
+			file:    `{}`,
+			env:     map[string]string{"SERVER_PORT": "http"},
+			wantErr: `invalid SERVER_PORT "http"`,
+		},
+		{
+			name:    "unknown log level",
+			file:    `{}`,
+			env:     map[string]string{"LOG_LEVEL": "verbose"},
+			wantErr: "app.logLevel",
+		},
+		{
+			name:    "unknown field",
+			file:    `{"server": {"prot": 9090}}`,
+			wantErr: `unknown field "prot"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetEnv(t)
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package config
+
+import "reflect"
+
+// RedactedValue replaces secret values in redacted configs
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the config that is safe to expose, with every
+// non-empty string (or string slice element) tagged `secret:"true"` replaced
+// by RedactedValue
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redactStruct(reflect.ValueOf(&redacted).Elem())
+	return &redacted
+}
+
+// redactStruct redacts secret fields of an addressable struct value in place,
+// copying slices and maps first so the original config is never modified
+func redactStruct(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		secret := t.Field(i).Tag.Get("secret") == "true"
+
+		switch field.Kind() {
+		case reflect.Struct:
+			redactStruct(field)
+		case reflect.String:
+			if secret && field.String() != "" {
+				field.SetString(RedactedValue)
+			}
+		case reflect.Slice:
+			if !secret || field.IsNil() || field.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			values := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+			for j := 0; j < field.Len(); j++ {
+				values.Index(j).SetString(RedactedValue)
+			}
+			field.Set(values)
+		case reflect.Map:
+			if !secret || field.IsNil() || field.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			values := reflect.MakeMapWithSize(field.Type(), field.Len())
+			iter := field.MapRange()
+			for iter.Next() {
+				values.SetMapIndex(iter.Key(), reflect.ValueOf(RedactedValue).Convert(field.Type().Elem()))
+			}
+			field.Set(values)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+/*
+Admin Handler Flow:
+
+1. Toggle Feed (POST /api/admin/feeds/{type}/disable, POST /api/admin/feeds/{type}/enable):
+   Disabling a feed rejects new subscribes with FEED_DISABLED and stops
+   broadcasts of that message type; enabling restores both.
+
+   Success Response: (200 OK)
+   {
+       "type": "ticks",
+       "enabled": false
+   }
+
+   Error Response: (404 Not Found)
+   no handler registered for message type 'foo'
+
+2. Cancel Subscription (POST /api/admin/subscriptions/{id}/cancel):
+   Unsubscribes the subscription through its message handler and notifies
+   the owning client, e.g. to cut off a runaway feed.
+
+   Client Notification:
+   {
+       "type": "unsubscribed",
+       "payload": {
+           "subscribe_id": "123e4567-e89b-12d3-a456-426614174000",
+           "type": "ticks",
+           "reason": "cancelled by server"
+       }
+   }
+
+   Success Response: (200 OK)
+   {
+       "subscribe_id": "123e4567-e89b-12d3-a456-426614174000",
+       "cancelled": true
+   }
+
+   Error Response: (404 Not Found)
+   subscription not found: 123e4567-e89b-12d3-a456-426614174000
+
+3. Toggle Symbol (POST /api/admin/symbols/{symbol}/disable, POST /api/admin/symbols/{symbol}/enable):
+   Disabling a symbol halts trading on it: trade opens and closes are
+   rejected with SYMBOL_DISABLED and strategies stop receiving its ticks
+   (paused) until it is enabled again. Other symbols are unaffected.
+
+   Success Response: (200 OK)
+   {
+       "symbol": "AAPL",
+       "enabled": false,
+       "disabled_symbols": ["AAPL"]
+   }
+*/
+
+// AdminHandler handles operator HTTP requests
+type AdminHandler struct {
+	registry *Registry
+	hub      *websocket.Hub
+	halts    *store.SymbolHalts
+}
+
+// NewAdminHandler creates a new AdminHandler instance
+func NewAdminHandler(registry *Registry, hub *websocket.Hub, halts *store.SymbolHalts) *AdminHandler {
+	return &AdminHandler{
+		registry: registry,
+		hub:      hub,
+		halts:    halts,
+	}
+}
+
+// feedStatusResponse reports the enabled state of a feed
+type feedStatusResponse struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// symbolStatusResponse reports the trading state of a symbol
+type symbolStatusResponse struct {
+	Symbol          string   `json:"symbol"`
+	Enabled         bool     `json:"enabled"`
+	DisabledSymbols []string `json:"disabled_symbols"`
+}
+
+// cancelSubscriptionResponse reports a server-side subscription cancel
+type cancelSubscriptionResponse struct {
+	SubscribeID string `json:"subscribe_id"`
+	Cancelled   bool   `json:"cancelled"`
+}
+
+// HandleFeedToggle enables or disables a feed at runtime
+func (h *AdminHandler) HandleFeedToggle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Path: /api/admin/feeds/{type}/{enable|disable}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/feeds/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	msgType, action := parts[0], parts[1]
+	var enabled bool
+	switch action {
+	case "enable":
+		enabled = true
+	case "disable":
+		enabled = false
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if err := h.registry.SetEnabled(msgType, enabled); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(feedStatusResponse{
+		Type:    msgType,
+		Enabled: enabled,
+	})
+}
+
+// HandleCancelSubscription cancels a client subscription server-side
+func (h *AdminHandler) HandleCancelSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Path: /api/admin/subscriptions/{id}/cancel
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/subscriptions/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "cancel" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	subscribeID := parts[0]
+	if err := h.hub.CancelSubscription(subscribeID, "cancelled by server"); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(cancelSubscriptionResponse{
+		SubscribeID: subscribeID,
+		Cancelled:   true,
+	})
+}
+
+// HandleSymbolToggle halts or resumes trading on a symbol at runtime
+func (h *AdminHandler) HandleSymbolToggle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Path: /api/admin/symbols/{symbol}/{enable|disable}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/symbols/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	symbol, action := strings.ToUpper(parts[0]), parts[1]
+	var enabled bool
+	switch action {
+	case "enable":
+		enabled = true
+		h.halts.Enable(symbol)
+	case "disable":
+		enabled = false
+		h.halts.Disable(symbol)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(symbolStatusResponse{
+		Symbol:          symbol,
+		Enabled:         enabled,
+		DisabledSymbols: h.halts.Disabled(),
+	})
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+	"github.com/aumbhatt/auto_trade/internal/strategy"
+)
+
+func TestDisabledSymbolRejectsTradesOnlyOnThatSymbol(t *testing.T) {
+	halts := store.NewSymbolHalts(nil)
+	trades := memory.NewInMemoryTradeStore()
+	trades.SetSymbolHalts(halts)
+	h := NewAdminHandler(nil, nil, halts)
+
+	toggle := func(symbol, action string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.HandleSymbolToggle(w, httptest.NewRequest(http.MethodPost, "/api/admin/symbols/"+symbol+"/"+action, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s: status %d (%s), want 200", action, symbol, w.Code, w.Body)
+		}
+	}
+	open := func(symbol string) (*models.Trade, error) {
+		return trades.CreateTrade(symbol, 100, 1, models.SideBuy, "")
+	}
+	isDisabled := func(err error) bool {
+		e, ok := err.(*models.TradeError)
+		return ok && e.Code == models.ErrSymbolDisabled
+	}
+
+	held, err := open("AAPL")
+	if err != nil {
+		t.Fatalf("CreateTrade: %v", err)
+	}
+	toggle("AAPL", "disable")
+
+	if _, err := open("AAPL"); !isDisabled(err) {
+		t.Errorf("open on a disabled symbol: got %v, want %s", err, models.ErrSymbolDisabled)
+	}
+	if _, err := trades.CloseTrade(held.ID, 101, models.CloseReasonManual); !isDisabled(err) {
+		t.Errorf("close on a disabled symbol: got %v, want %s", err, models.ErrSymbolDisabled)
+	}
+	if _, err := open("MSFT"); err != nil {
+		t.Errorf("open on another symbol: %v", err)
+	}
+
+	// Strategies on the symbol see none of its ticks
+	runner := strategy.NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	runner.SetSymbolHalts(halts)
+	repeat := models.NewStrategy("repeat", map[string]interface{}{"symbol": "AAPL", "exit_price": 110.0})
+	ticks := []*models.Tick{{Symbol: "AAPL", Price: 100, Timestamp: time.Now()}}
+	if err := runner.Replay(repeat, ticks, func(err error) { t.Errorf("executor error: %v", err) }); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if positions, _ := trades.GetOpenTrades(); len(positions) != 2 {
+		t.Errorf("%d open trades after a halted strategy saw an AAPL tick, want the 2 opened before", len(positions))
+	}
+
+	toggle("AAPL", "enable")
+	if _, err := open("AAPL"); err != nil {
+		t.Errorf("open after re-enabling: %v", err)
+	}
+}
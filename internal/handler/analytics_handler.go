@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aumbhatt/auto_trade/internal/analytics"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/strategy"
+)
+
+/*
+Analytics Handler Flow:
+
+1. Compare Strategy Types (GET /api/analytics/strategies/compare?names=martingale,repeat):
+   Processing:
+   1. Parse names (all registered strategies if omitted)
+   2. Reject names that are not registered strategies
+   3. Group closed trades by the strategy name behind their StrategyID
+   4. Aggregate per-name performance in the base currency
+
+   Success Response: (200 OK)
+   {
+       "base_currency": "USD",
+       "strategies": [
+           {
+               "name": "martingale",
+               "trade_count": 12,
+               "wins": 8,
+               "losses": 4,
+               "win_rate": 66.67,
+               "net_pnl": 42.5,
+               "avg_trade": 3.54,
+               "avg_return_pct": 0.35,
+               "sharpe_ratio": 0.42
+           },
+           {
+               "name": "repeat",
+               "trade_count": 0,
+               "wins": 0,
+               "losses": 0,
+               "win_rate": 0,
+               "net_pnl": 0,
+               "avg_trade": 0,
+               "avg_return_pct": 0,
+               "sharpe_ratio": 0
+           }
+       ]
+   }
+
+   Error Response: (400 Bad Request)
+   Unknown strategy type: foo
+
+2. Equity Curve (GET /api/analytics/equity):
+   Processing:
+   1. Read the EquityTracker's curve, kept up to date as trades close
+   2. Older points are merged into buckets once the curve passes its cap
+      (see analytics.EquityTracker); max_drawdown still covers every trade
+
+   Success Response: (200 OK)
+   {
+       "total_trades": 4,
+       "net_pnl": 11,
+       "max_drawdown": 7,
+       "points": [
+           {"time": "2025-01-23T09:02:00Z", "equity": 6, "low": 6, "high": 10, "trades": 2},
+           {"time": "2025-01-23T09:04:00Z", "equity": 11, "low": 3, "high": 11, "trades": 2}
+       ]
+   }
+
+   Error Response: (501 Not Implemented)
+   Equity tracking not enabled
+*/
+
+// AnalyticsHandler handles analytics HTTP requests
+type AnalyticsHandler struct {
+	tradeStore    store.TradeStore
+	strategyStore store.StrategyStore
+	converter     *analytics.CurrencyConverter
+	equity        *analytics.EquityTracker
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler instance
+func NewAnalyticsHandler(tradeStore store.TradeStore, strategyStore store.StrategyStore, converter *analytics.CurrencyConverter) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		tradeStore:    tradeStore,
+		strategyStore: strategyStore,
+		converter:     converter,
+	}
+}
+
+// SetEquityTracker serves the equity curve from tracker; call before serving
+func (h *AnalyticsHandler) SetEquityTracker(tracker *analytics.EquityTracker) {
+	h.equity = tracker
+}
+
+// HandleEquity returns the realized P&L equity curve
+func (h *AnalyticsHandler) HandleEquity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.equity == nil {
+		http.Error(w, "Equity tracking not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	json.NewEncoder(w).Encode(h.equity.Curve())
+}
+
+// HandleCompareStrategies compares closed-trade performance across strategy types
+func (h *AnalyticsHandler) HandleCompareStrategies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	available := strategy.GetDefaultRegistry().GetAvailableStrategies()
+	names, err := parseStrategyNames(r.URL.Query().Get("names"), available)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trades, err := h.tradeStore.GetTradeHistory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	results, err := analytics.CompareStrategies(trades, h.lookupStrategyName, names, h.converter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(models.StrategyComparisonResponse{
+		BaseCurrency: h.converter.BaseCurrency(),
+		Strategies:   results,
+	})
+}
+
+// lookupStrategyName resolves a strategy ID to its strategy type name
+func (h *AnalyticsHandler) lookupStrategyName(strategyID string) (string, bool) {
+	s, err := h.strategyStore.GetStrategyByID(strategyID)
+	if err != nil {
+		return "", false
+	}
+	return s.Name, true
+}
+
+// parseStrategyNames parses a comma-separated names list, defaulting to all
+// available strategies and rejecting unknown names
+func parseStrategyNames(raw string, available []string) ([]string, error) {
+	known := make(map[string]struct{}, len(available))
+	for _, name := range available {
+		known[name] = struct{}{}
+	}
+
+	if strings.TrimSpace(raw) == "" {
+		names := append([]string(nil), available...)
+		sort.Strings(names)
+		return names, nil
+	}
+
+	names := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("Unknown strategy type: %s", name)
+		}
+		if _, dup := seen[name]; dup {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aumbhatt/auto_trade/internal/auth"
+)
+
+// AuthMiddleware rejects requests without "Authorization: Bearer <apiKey>"
+// with 401 Unauthorized; an empty apiKey disables the check
+func AuthMiddleware(apiKey string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !auth.Authorized(r, apiKey, false) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Call the next handler
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+Coalescer Flow:
+
+   Trigger ──(none pending)──→ schedule fn after window
+   Trigger ──(pending)───────→ nothing (folded into the scheduled call)
+   window elapses ───────────→ clear pending, run fn
+
+   fn reads the latest state when it runs, so a burst of triggers within
+   one window produces a single call that reflects all of them. A trigger
+   arriving while fn runs schedules another call, so the final state is
+   always delivered. With a zero window every Trigger runs fn immediately.
+*/
+
+// coalescer collapses bursts of triggers into one call per window
+type coalescer struct {
+	window time.Duration
+	fn     func()
+
+	mu      sync.Mutex
+	pending *time.Timer
+}
+
+// newCoalescer creates a coalescer running fn at most once per window
+func newCoalescer(window time.Duration, fn func()) *coalescer {
+	return &coalescer{
+		window: window,
+		fn:     fn,
+	}
+}
+
+// Trigger requests a call of fn
+func (c *coalescer) Trigger() {
+	if c.window <= 0 {
+		c.fn()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = time.AfterFunc(c.window, c.flush)
+	}
+}
+
+// flush runs fn for the triggers received so far
+func (c *coalescer) flush() {
+	c.mu.Lock()
+	c.pending = nil
+	c.mu.Unlock()
+
+	c.fn()
+}
+
+// Stop cancels a scheduled call
+func (c *coalescer) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending != nil {
+		c.pending.Stop()
+		c.pending = nil
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aumbhatt/auto_trade/internal/config"
+)
+
+/*
+Config Handler Flow:
+
+1. Get Effective Config (GET /api/config):
+   Returns the configuration the server is running with, to confirm which
+   overrides took effect. Fields tagged `secret:"true"` are redacted.
+
+   Success Response: (200 OK)
+   {
+       "server": {
+           "port": 8080,
+           "readTimeout": 15000000000,
+           "writeTimeout": 15000000000
+       },
+       "app": {
+           "environment": "development",
+           "logLevel": "info"
+       },
+       ...
+   }
+*/
+
+// ConfigHandler exposes the effective configuration
+type ConfigHandler struct {
+	cfg *config.Config
+}
+
+// NewConfigHandler creates a new ConfigHandler instance
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{
+		cfg: cfg,
+	}
+}
+
+// HandleGetConfig returns the effective configuration with secrets redacted
+func (h *ConfigHandler) HandleGetConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.cfg.Redacted())
+}
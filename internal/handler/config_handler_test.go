@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/config"
+)
+
+func TestGetConfigRedactsSecrets(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Server.Port = 9090
+	cfg.Server.AuthEnabled = true
+	cfg.Server.APIKey = "s3cret"
+	h := AuthMiddleware(cfg.Server.APIKey, http.HandlerFunc(NewConfigHandler(cfg).HandleGetConfig))
+
+	get := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get(""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("status %d without a token, want 401", w.Code)
+	}
+
+	w := get("s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d (%s), want 200", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "s3cret") {
+		t.Fatalf("response leaks the API key: %s", w.Body)
+	}
+	var got config.Config
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Server.Port != 9090 {
+		t.Errorf("port %d, want the configured 9090", got.Server.Port)
+	}
+	if got.Server.APIKey != config.RedactedValue {
+		t.Errorf("API key %q, want %s", got.Server.APIKey, config.RedactedValue)
+	}
+
+	// Redacting works on a copy
+	if cfg.Server.APIKey != "s3cret" {
+		t.Errorf("serving the config changed its API key to %q", cfg.Server.APIKey)
+	}
+}
@@ -1,12 +1,35 @@
 package handler
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/aumbhatt/auto_trade/internal/auth"
+)
 
 // CORSMiddleware adds CORS headers to responses
-func CORSMiddleware(next http.Handler) http.Handler {
+// allowedOrigins lists the browser origins allowed to call the API; empty
+// (or a "*" entry) allows any origin. Disallowed origins get no CORS
+// headers, so browsers block their requests
+func CORSMiddleware(allowedOrigins []string, next http.Handler) http.Handler {
+	anyOrigin := auth.AllowsAnyOrigin(allowedOrigins)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Add CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		origin := r.Header.Get("Origin")
+		switch {
+		case anyOrigin:
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && auth.OriginAllowed(origin, allowedOrigins):
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		default:
+			w.Header().Add("Vary", "Origin")
+			if r.Method == "OPTIONS" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSEchoesOnlyAllowedOrigins(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	request := func(allowed []string, method, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/trades", nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		CORSMiddleware(allowed, ok).ServeHTTP(w, r)
+		return w
+	}
+	allowed := []string{"https://app.example.com"}
+
+	w := request(allowed, http.MethodGet, "https://app.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("allowed origin got Access-Control-Allow-Origin %q, want it echoed", got)
+	}
+
+	// Disallowed origins get no CORS headers, and their preflights fail
+	w = request(allowed, http.MethodGet, "https://evil.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("disallowed origin got Access-Control-Allow-Origin %q, want none", got)
+	}
+	if w := request(allowed, http.MethodOptions, "https://evil.example.com"); w.Code != http.StatusForbidden {
+		t.Errorf("disallowed preflight status %d, want 403", w.Code)
+	}
+
+	// An empty list keeps the allow-all default
+	for _, list := range [][]string{nil, {"*"}} {
+		if got := request(list, http.MethodGet, "https://evil.example.com").Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("allow list %v got Access-Control-Allow-Origin %q, want *", list, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aumbhatt/auto_trade/internal/service"
+)
+
+/*
+Health Handler Flow:
+
+1. Health Check (GET /healthz):
+   Processing:
+   1. Ask the service for its health (store counts, tick source status)
+   2. 200 when healthy, 503 when degraded
+
+   Success Response: (200 OK)
+   {
+       "status": "ok",
+       "strategies_active": 2,
+       "open_trades": 3,
+       "uptime_seconds": 3600.5
+   }
+
+   Degraded Response: (503 Service Unavailable)
+   {
+       "status": "degraded",
+       "strategies_active": 2,
+       "open_trades": 3,
+       "uptime_seconds": 3600.5,
+       "errors": ["tick source: EOF"]
+   }
+*/
+
+// HealthHandler serves the service health check
+type HealthHandler struct {
+	service *service.Service
+}
+
+// NewHealthHandler creates a new HealthHandler instance
+func NewHealthHandler(svc *service.Service) *HealthHandler {
+	return &HealthHandler{
+		service: svc,
+	}
+}
+
+// HandleHealth reports the service health
+func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	health := h.service.Health()
+
+	if health.Status != service.HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/config"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/service"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+// feedStatus is a service.FeedStatus reporting a fixed error
+type feedStatus struct {
+	err error
+}
+
+func (f *feedStatus) SourceError() error {
+	return f.err
+}
+
+func TestHealthReportsDegradedFeed(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	strategies := memory.NewInMemoryStrategyStore()
+	feed := &feedStatus{}
+	svc := service.NewService(config.NewDefaultConfig(), nil)
+	svc.SetStores(trades, strategies)
+	svc.SetFeed(feed)
+	h := NewHealthHandler(svc)
+
+	trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	strategies.CreateStrategy("repeat", map[string]interface{}{"symbol": "AAPL", "exit_price": 110.0})
+
+	check := func() (int, service.Health) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.HandleHealth(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var health service.Health
+		if err := json.NewDecoder(w.Body).Decode(&health); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return w.Code, health
+	}
+
+	code, health := check()
+	if code != http.StatusOK || health.Status != service.HealthOK {
+		t.Fatalf("healthy: status %d %q, want 200 ok", code, health.Status)
+	}
+	if health.OpenTrades != 1 || health.StrategiesActive != 1 || health.UptimeSeconds <= 0 {
+		t.Errorf("health %+v, want 1 open trade, 1 active strategy and a positive uptime", health)
+	}
+
+	feed.err = errors.New("EOF")
+	code, health = check()
+	if code != http.StatusServiceUnavailable || health.Status != service.HealthDegraded {
+		t.Fatalf("failing feed: status %d %q, want 503 degraded", code, health.Status)
+	}
+	if len(health.Errors) != 1 || health.Errors[0] != "tick source: EOF" {
+		t.Errorf("errors %v, want the tick source error", health.Errors)
+	}
+
+	// The feed recovers on its next good tick
+	feed.err = nil
+	if code, _ := check(); code != http.StatusOK {
+		t.Errorf("recovered feed: status %d, want 200", code)
+	}
+}
@@ -14,3 +14,11 @@ type MessageHandler interface {
 	// Stop stops the handler's processing
 	Stop() error
 }
+
+// SubscribedHandler is implemented by handlers that send a subscription's
+// initial messages after its subscribe_response has been queued
+type SubscribedHandler interface {
+	// HandleSubscribed is called after a successful HandleSubscribe, once
+	// the client has been sent the subscribe_response
+	HandleSubscribed(subscribeID string)
+}
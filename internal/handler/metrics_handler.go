@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+/*
+Metrics Handler Flow:
+
+1. Scrape (GET /metrics):
+   Prometheus text exposition format (version 0.0.4)
+
+   Gauges (read from the stores and hub at scrape time):
+   ├── auto_trade_open_trades              // Open trades in the trade store
+   ├── auto_trade_active_strategies        // Active strategies in the strategy store
+   └── auto_trade_websocket_clients        // Connected WebSocket clients
+
+   Counters (since process start):
+   ├── auto_trade_trades_created_total     // TradeCreated events (includes
+   │                                       // remainders of split lots)
+   ├── auto_trade_trades_closed_total      // TradeClosed events
+   ├── auto_trade_broadcasts_total         // Messages queued by the hub
+   └── auto_trade_broadcasts_dropped_total // Messages dropped (buffer full)
+
+2. Counting:
+   The handler is a trade store listener, so trade counters follow the
+   same events the feeds do, whichever store backs them.
+
+3. Usage Example:
+   metrics := handler.NewMetricsHandler(tradeStore, strategyStore, hub)
+   tradeStore.AddListener(metrics)
+   mux.HandleFunc("/metrics", metrics.HandleMetrics)
+*/
+
+// MetricsHandler serves operational metrics for Prometheus
+type MetricsHandler struct {
+	trades     store.TradeStore
+	strategies store.StrategyStore
+	hub        *websocket.Hub
+
+	tradesCreated uint64 // atomic
+	tradesClosed  uint64 // atomic
+}
+
+// NewMetricsHandler creates a new MetricsHandler
+// Register it as a trade store listener to count trades
+func NewMetricsHandler(trades store.TradeStore, strategies store.StrategyStore, hub *websocket.Hub) *MetricsHandler {
+	return &MetricsHandler{
+		trades:     trades,
+		strategies: strategies,
+		hub:        hub,
+	}
+}
+
+// OnTradeEvent implements store.TradeEventListener
+func (h *MetricsHandler) OnTradeEvent(event store.TradeEvent) {
+	switch event.Type {
+	case store.TradeCreated:
+		atomic.AddUint64(&h.tradesCreated, 1)
+	case store.TradeClosed:
+		atomic.AddUint64(&h.tradesClosed, 1)
+	}
+}
+
+// HandleMetrics writes the metrics in Prometheus text format
+func (h *MetricsHandler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	openTrades, err := h.trades.GetOpenTrades()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	activeStrategies, err := h.strategies.GetActiveStrategies()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetric(w, "auto_trade_open_trades", "gauge", "Open trades.", uint64(len(openTrades)))
+	writeMetric(w, "auto_trade_active_strategies", "gauge", "Active strategies.", uint64(len(activeStrategies)))
+	writeMetric(w, "auto_trade_websocket_clients", "gauge", "Connected WebSocket clients.", uint64(h.hub.ClientCount()))
+	writeMetric(w, "auto_trade_trades_created_total", "counter", "Trades opened.", atomic.LoadUint64(&h.tradesCreated))
+	writeMetric(w, "auto_trade_trades_closed_total", "counter", "Trades closed.", atomic.LoadUint64(&h.tradesClosed))
+	writeMetric(w, "auto_trade_broadcasts_total", "counter", "WebSocket messages queued for broadcast.", h.hub.BroadcastCount())
+	writeMetric(w, "auto_trade_broadcasts_dropped_total", "counter", "WebSocket messages dropped because the broadcast buffer was full.", h.hub.DroppedBroadcasts())
+}
+
+// writeMetric writes one metric with its HELP and TYPE lines
+func writeMetric(w io.Writer, name, metricType, help string, value uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value)
+}
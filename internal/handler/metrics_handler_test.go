@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestMetricsCountTrades(t *testing.T) {
+	server := newTestServer(t, nil)
+	trades := memory.NewInMemoryTradeStore()
+	strategies := memory.NewInMemoryStrategyStore()
+	h := NewMetricsHandler(trades, strategies, server.hub)
+	trades.AddListener(h)
+
+	scrape := func() string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.HandleMetrics(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d (%s), want 200", w.Code, w.Body)
+		}
+		return w.Body.String()
+	}
+	expect := func(body string, samples ...string) {
+		t.Helper()
+		for _, sample := range samples {
+			if !strings.Contains(body, "\n"+sample+"\n") {
+				t.Errorf("metrics missing %q:\n%s", sample, body)
+			}
+		}
+	}
+
+	expect(scrape(), "auto_trade_trades_created_total 0", "auto_trade_open_trades 0")
+
+	trade, _ := trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	trades.CreateTrade("MSFT", 300, 1, models.SideBuy, "")
+	trades.CloseTrade(trade.ID, 101, models.CloseReasonManual)
+	strategies.CreateStrategy("repeat", map[string]interface{}{"symbol": "AAPL", "exit_price": 110.0})
+	server.dial(t)
+
+	body := scrape()
+	expect(body,
+		"auto_trade_trades_created_total 2",
+		"auto_trade_trades_closed_total 1",
+		"auto_trade_open_trades 1",
+		"auto_trade_active_strategies 1",
+		"auto_trade_websocket_clients 1",
+		"# TYPE auto_trade_trades_created_total counter",
+	)
+}
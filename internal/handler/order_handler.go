@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+/*
+Order Handler Flow:
+
+1. Place Limit Order (POST /api/orders/limit):
+   Request:
+   {
+       "symbol": "AAPL",
+       "side": "buy",             // Optional: defaults to buy
+       "quantity": 10,            // Optional: defaults to 1
+       "limit_price": 149.00
+   }
+
+   Success Response: (200 OK)
+   {
+       "order_id": "order-abc123",
+       "symbol": "AAPL",
+       "side": "buy",
+       "quantity": 10,
+       "limit_price": 149.00,
+       "placed_time": "2025-01-23T14:23:38Z"
+   }
+
+   Error Response: (409 Conflict)
+   PENDING_ORDER_LIMIT: Pending order limit reached: 100
+
+   The order rests until a tick crosses the limit (see models.PendingOrder),
+   then opens a trade at the tick price like a buy request would.
+
+2. Pending Orders (GET /api/orders/pending):
+   Success Response: (200 OK)
+   [ { "order_id": "order-abc123", ... } ]   // oldest first
+
+3. Cancel Order (POST /api/orders/cancel):
+   Request:
+   {
+       "order_id": "order-abc123"
+   }
+
+   Success Response: (200 OK) the cancelled order
+
+   Error Response: (404 Not Found)
+   ORDER_NOT_FOUND: Pending order not found: order-abc123
+
+4. Pending Orders Feed (WebSocket "pending_orders"):
+   Subscribe → current pending orders
+   Order placed, cancelled or filled → pending orders re-broadcast to
+   every subscriber
+   {
+       "type": "pending_orders",
+       "subscribe_id": "sub-123",
+       "payload": [ { "order_id": "order-abc123", ... } ]
+   }
+*/
+
+// OrderHandler handles limit order HTTP requests
+type OrderHandler struct {
+	orders store.OrderStore
+}
+
+// NewOrderHandler creates a new OrderHandler instance
+func NewOrderHandler(orders store.OrderStore) *OrderHandler {
+	return &OrderHandler{
+		orders: orders,
+	}
+}
+
+// HandleLimitOrder handles limit order placement requests
+func (h *OrderHandler) HandleLimitOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.LimitOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	side := req.Side
+	if side == "" {
+		side = models.SideBuy
+	}
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = models.DefaultQuantity
+	}
+
+	order, err := h.orders.PlacePendingOrder(req.Symbol, side, quantity, req.LimitPrice)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(order)
+}
+
+// HandlePendingOrders returns the pending orders
+func (h *OrderHandler) HandlePendingOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	orders, err := h.orders.GetPendingOrders()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(orders)
+}
+
+// HandleCancel handles pending order cancellation requests
+func (h *OrderHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CancelOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.orders.CancelPendingOrder(req.OrderID)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(order)
+}
+
+// writeOrderError maps order store errors to HTTP statuses
+func writeOrderError(w http.ResponseWriter, err error) {
+	e, ok := err.(*models.TradeError)
+	if !ok {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	switch e.Code {
+	case models.ErrOrderNotFound:
+		http.Error(w, e.Error(), http.StatusNotFound)
+	case models.ErrPendingOrderLimit:
+		http.Error(w, e.Error(), http.StatusConflict)
+	default:
+		http.Error(w, e.Error(), http.StatusBadRequest)
+	}
+}
+
+// PendingOrdersHandler handles pending orders subscriptions
+type PendingOrdersHandler struct {
+	store store.OrderStore
+	hub   *websocket.Hub
+	// Track subscriptions
+	subscriptions sync.Map     // map[string]struct{} // subscribeID -> struct{}
+	subMutex      sync.RWMutex // Protects subscription operations
+}
+
+// NewPendingOrdersHandler creates a new PendingOrdersHandler
+func NewPendingOrdersHandler(store store.OrderStore, hub *websocket.Hub) *PendingOrdersHandler {
+	return &PendingOrdersHandler{
+		store: store,
+		hub:   hub,
+	}
+}
+
+// OnOrderEvent implements store.OrderEventListener
+// Placements, cancellations and fills all re-broadcast the pending orders
+func (h *PendingOrdersHandler) OnOrderEvent(event store.OrderEvent) {
+	orders, err := h.store.GetPendingOrders()
+	if err != nil {
+		logging.Errorf("Error getting pending orders: %v", err)
+		return
+	}
+
+	h.BroadcastUpdate(orders)
+}
+
+// HandleSubscribe handles subscription requests
+func (h *PendingOrdersHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
+	h.subMutex.Lock()
+	h.subscriptions.Store(subscribeID, struct{}{})
+	h.subMutex.Unlock()
+
+	orders, err := h.store.GetPendingOrders()
+	if err != nil {
+		// Return empty list instead of error
+		orders = []*models.PendingOrder{}
+	}
+
+	h.hub.Broadcast(websocket.Message{
+		Type:        "pending_orders",
+		SubscribeID: subscribeID,
+		Payload:     orders,
+	})
+	return nil
+}
+
+// HandleUnsubscribe handles unsubscribe requests
+func (h *PendingOrdersHandler) HandleUnsubscribe(subscribeID string) error {
+	h.subMutex.Lock()
+	h.subscriptions.Delete(subscribeID)
+	h.subMutex.Unlock()
+	return nil
+}
+
+// BroadcastUpdate sends the pending orders to all subscribers
+func (h *PendingOrdersHandler) BroadcastUpdate(orders []*models.PendingOrder) {
+	// Collect subscribers under read lock
+	h.subMutex.RLock()
+	subscribers := make([]string, 0)
+	h.subscriptions.Range(func(key, value interface{}) bool {
+		subscribers = append(subscribers, key.(string))
+		return true
+	})
+	h.subMutex.RUnlock()
+
+	// Broadcast outside lock
+	for _, subscribeID := range subscribers {
+		h.hub.Broadcast(websocket.Message{
+			Type:        "pending_orders",
+			SubscribeID: subscribeID,
+			Payload:     orders,
+		})
+	}
+}
+
+// Start starts the handler and registers it as an order store listener
+func (h *PendingOrdersHandler) Start() error {
+	h.store.AddListener(h)
+	return nil
+}
+
+// Stop stops the handler and deregisters it from the order store
+func (h *PendingOrdersHandler) Stop() error {
+	h.store.RemoveListener(h)
+	return nil
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestLimitOrdersStopAtPendingCap(t *testing.T) {
+	orders := memory.NewInMemoryOrderStore(memory.NewInMemoryTradeStore())
+	orders.SetMaxPendingOrders(2)
+	h := NewOrderHandler(orders)
+
+	post := func(handle http.HandlerFunc, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handle(w, httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(body)))
+		return w
+	}
+	place := func() *httptest.ResponseRecorder {
+		return post(h.HandleLimitOrder, `{"symbol": "AAPL", "limit_price": 95}`)
+	}
+
+	var first models.PendingOrder
+	for i := 0; i < 2; i++ {
+		w := place()
+		if w.Code != http.StatusOK {
+			t.Fatalf("order %d: status %d (%s), want 200", i+1, w.Code, w.Body)
+		}
+		if i == 0 {
+			json.NewDecoder(w.Body).Decode(&first)
+		}
+	}
+
+	w := place()
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), models.ErrPendingOrderLimit) {
+		t.Fatalf("order over the cap: status %d %q, want 409 %s", w.Code, w.Body, models.ErrPendingOrderLimit)
+	}
+
+	// Cancelling frees a slot
+	if w := post(h.HandleCancel, `{"order_id": "`+first.ID+`"}`); w.Code != http.StatusOK {
+		t.Fatalf("cancel: status %d (%s), want 200", w.Code, w.Body)
+	}
+	if w := place(); w.Code != http.StatusOK {
+		t.Errorf("order after a cancel: status %d (%s), want 200", w.Code, w.Body)
+	}
+}
+
+func TestPendingOrdersFeedBroadcastsPendingSet(t *testing.T) {
+	orders := memory.NewInMemoryOrderStore(memory.NewInMemoryTradeStore())
+	h := NewPendingOrdersHandler(orders, nil)
+	server := newTestServer(t, map[string]MessageHandler{"pending_orders": h})
+	h.hub = server.hub
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	resting, _ := orders.PlacePendingOrder("AAPL", models.SideBuy, 1, 95)
+
+	client := server.dial(t)
+	client.subscribe("pending_orders", nil)
+	pending := func() []string {
+		t.Helper()
+		var got []models.PendingOrder
+		client.decode(client.next(), &got)
+		ids := make([]string, len(got))
+		for i, order := range got {
+			ids[i] = order.ID
+		}
+		return ids
+	}
+	expect := func(what string, want ...string) {
+		t.Helper()
+		if got := pending(); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("pending orders %v %s, want %v", got, what, want)
+		}
+	}
+	expect("on subscribe", resting.ID)
+
+	placed, _ := orders.PlacePendingOrder("MSFT", models.SideBuy, 1, 290)
+	expect("after a placement", resting.ID, placed.ID)
+
+	orders.CancelPendingOrder(resting.ID)
+	expect("after a cancel", placed.ID)
+
+	orders.FillCrossedOrders(&models.Tick{Symbol: "MSFT", Price: 289})
+	expect("after a fill")
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+/*
+Paper Trades Handler Flow:
+
+1. Purpose:
+   Streams the trades of dry-run strategies ("dry_run": true), which
+   trade against a separate paper trade store instead of the real one.
+   Paper trades never appear in open_positions, positions or
+   trade_history, and do not count towards position limits or the daily
+   loss limit.
+
+2. Event Flow:
+   Paper store events (create, close)
+   → broadcast the trade to every subscriber (filtered by strategy_id)
+
+3. WebSocket Messages:
+   a. Subscribe:
+      Options: "strategy_id" limits the feed to one strategy's trades
+      Request:
+      {
+          "type": "subscribe",
+          "payload": {
+              "type": "paper_trades",
+              "options": {"strategy_id": "martingale-abc123"}
+          }
+      }
+
+      Snapshot of the paper ledger on subscribe:
+      {
+          "type": "paper_trades",
+          "subscribe_id": "sub-123",
+          "payload": {
+              "open": [{"trade_id": "trade-abc123", ...}],     // Oldest entry first
+              "closed": [{"trade_id": "trade-def456", ...}]    // Newest exit first
+          }
+      }
+
+      Then one message per paper trade opened or closed:
+      {
+          "type": "paper_trades",
+          "subscribe_id": "sub-123",
+          "payload": {
+              "event": "opened",                // or "closed"
+              "trade": {"trade_id": "trade-ghi789", ...}
+          }
+      }
+
+   The ledger is kept in memory for the life of the server.
+*/
+
+// PaperTradesHandler streams the paper trade store's trades to subscribers
+type PaperTradesHandler struct {
+	store store.TradeStore
+	hub   *websocket.Hub
+	// Track subscriptions
+	subscriptions sync.Map // map[string]*string // subscribeID -> strategy ID filter (nil = all)
+}
+
+// NewPaperTradesHandler creates a new PaperTradesHandler over the paper
+// trade store
+func NewPaperTradesHandler(store store.TradeStore, hub *websocket.Hub) *PaperTradesHandler {
+	return &PaperTradesHandler{
+		store: store,
+		hub:   hub,
+	}
+}
+
+// ledger returns the paper trades of one strategy (nil filter = all)
+func (h *PaperTradesHandler) ledger(strategyID *string) models.PaperLedger {
+	ledger := models.PaperLedger{
+		Open:   []*models.Trade{},
+		Closed: []*models.Trade{},
+	}
+
+	open, err := h.store.GetOpenTrades()
+	if err != nil {
+		logging.Errorf("Error getting open paper trades: %v", err)
+	}
+	for _, trade := range open {
+		if strategyID == nil || trade.StrategyID == *strategyID {
+			ledger.Open = append(ledger.Open, trade)
+		}
+	}
+	sort.Slice(ledger.Open, func(i, j int) bool {
+		return ledger.Open[i].EntryTime.Before(ledger.Open[j].EntryTime)
+	})
+
+	closed, err := h.store.GetTradeHistoryFiltered(store.TradeHistoryQuery{})
+	if err != nil {
+		logging.Errorf("Error getting closed paper trades: %v", err)
+	}
+	for _, trade := range closed {
+		if strategyID == nil || trade.StrategyID == *strategyID {
+			ledger.Closed = append(ledger.Closed, trade)
+		}
+	}
+	return ledger
+}
+
+// OnTradeEvent implements store.TradeEventListener
+func (h *PaperTradesHandler) OnTradeEvent(event store.TradeEvent) {
+	var name string
+	switch event.Type {
+	case store.TradeCreated:
+		name = models.PaperTradeOpened
+	case store.TradeClosed:
+		name = models.PaperTradeClosed
+	default:
+		return // Mark updates are not paper trades
+	}
+
+	payload := models.PaperTradeEvent{Event: name, Trade: event.Trade}
+	h.subscriptions.Range(func(key, value interface{}) bool {
+		if filter := value.(*string); filter != nil && event.Trade.StrategyID != *filter {
+			return true
+		}
+		h.hub.Broadcast(websocket.Message{
+			Type:        "paper_trades",
+			SubscribeID: key.(string),
+			Payload:     payload,
+		})
+		return true
+	})
+}
+
+// HandleSubscribe handles subscription requests
+// Options:
+//   - "strategy_id": only send this strategy's paper trades
+func (h *PaperTradesHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
+	var filter *string
+	if raw, ok := options["strategy_id"]; ok && raw != nil {
+		strategyID, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("invalid strategy_id option: must be a string")
+		}
+		filter = &strategyID
+	}
+
+	h.subscriptions.Store(subscribeID, filter)
+
+	h.hub.Broadcast(websocket.Message{
+		Type:        "paper_trades",
+		SubscribeID: subscribeID,
+		Payload:     h.ledger(filter),
+	})
+	return nil
+}
+
+// HandleUnsubscribe handles unsubscribe requests
+func (h *PaperTradesHandler) HandleUnsubscribe(subscribeID string) error {
+	h.subscriptions.Delete(subscribeID)
+	return nil
+}
+
+// Start starts the handler and registers it as a paper store listener
+func (h *PaperTradesHandler) Start() error {
+	h.store.AddListener(h)
+	return nil
+}
+
+// Stop stops the handler and deregisters it from the paper store
+func (h *PaperTradesHandler) Stop() error {
+	h.store.RemoveListener(h)
+	return nil
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestPaperTradesStreamPaperStoreOnly(t *testing.T) {
+	paper := memory.NewInMemoryTradeStore()
+	trades := memory.NewInMemoryTradeStore()
+	h := NewPaperTradesHandler(paper, nil)
+	server := newTestServer(t, map[string]MessageHandler{"paper_trades": h})
+	h.hub = server.hub
+	h.Start()
+	defer h.Stop()
+
+	held, _ := paper.CreateTrade("AAPL", 100, 1, models.SideBuy, "martingale-a")
+	all := server.dial(t)
+	all.subscribe("paper_trades", nil)
+	var ledger models.PaperLedger
+	all.decode(all.next(), &ledger)
+	if len(ledger.Open) != 1 || ledger.Open[0].ID != held.ID || len(ledger.Closed) != 0 {
+		t.Fatalf("snapshot %+v, want the one open paper trade", ledger)
+	}
+
+	other := server.dial(t)
+	other.subscribe("paper_trades", map[string]interface{}{"strategy_id": "martingale-b"})
+	other.decode(other.next(), &ledger)
+	if len(ledger.Open) != 0 {
+		t.Errorf("filtered snapshot has %d open trades of another strategy, want 0", len(ledger.Open))
+	}
+
+	// Real trades never reach the feed
+	trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "martingale-a")
+	paper.CloseTrade(held.ID, 105, models.CloseReasonManual)
+
+	var event models.PaperTradeEvent
+	all.decode(all.next(), &event)
+	if event.Event != models.PaperTradeClosed || event.Trade.ID != held.ID || event.Trade.ExitPrice != 105 {
+		t.Errorf("got %s event for %+v, want the paper trade closed at 105", event.Event, event.Trade)
+	}
+	all.expectNone(100 * time.Millisecond)
+	other.expectNone(100 * time.Millisecond)
+}
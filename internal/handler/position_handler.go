@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+/*
+Positions Handler Flow:
+
+1. Purpose:
+   Streams net positions, one per strategy, symbol and side: open
+   quantity, weighted average entry price and realized/unrealized P&L.
+   Multi-entry strategies (martingale) show as one position instead of
+   one open trade per buy.
+
+2. Event Flow:
+   Trade store events (create, close) and mark updates (TradeUpdated)
+   → coalesced within the batch window (SetBatchWindow)
+   → GetPositions → mark each at the latest cached price
+   → broadcast to every subscriber (filtered by strategy_id)
+
+3. WebSocket Messages:
+   a. Subscribe:
+      Options: "strategy_id" limits the feed to one strategy's positions
+      ("" selects manual trades)
+      Request:
+      {
+          "type": "subscribe",
+          "payload": {
+              "type": "positions",
+              "options": {"strategy_id": "martingale-abc123"}
+          }
+      }
+
+      Snapshot on subscribe, then on every change:
+      {
+          "type": "positions",
+          "subscribe_id": "sub-123",
+          "payload": [
+              {
+                  "strategy_id": "martingale-abc123",
+                  "symbol": "AAPL",
+                  "side": "buy",
+                  "quantity": 7,
+                  "avg_entry_price": 92.857,
+                  "open_trades": 3,
+                  "realized_pnl": -12.5,
+                  "current_price": 94.10,
+                  "unrealized_pnl": 8.7
+              }
+          ]
+      }
+      current_price and unrealized_pnl are omitted (and price_stale set)
+      when the symbol has no price newer than the max price age
+*/
+
+// PositionsHandler streams net positions to subscribers
+type PositionsHandler struct {
+	store store.TradeStore
+	hub   *websocket.Hub
+	// Track subscriptions
+	subscriptions sync.Map // map[string]*string // subscribeID -> strategy ID filter (nil = all)
+	// Market valuation
+	priceCache  store.PriceCache
+	maxPriceAge time.Duration
+	clock       clock.Clock
+	// Optional source of TradeUpdated events (live marks)
+	marks store.TradeEventEmitter
+	// Coalesces trade events into broadcasts
+	updates *coalescer
+}
+
+// NewPositionsHandler creates a new PositionsHandler
+// Positions are valued at priceCache prices no older than maxPriceAge (0 = any age)
+func NewPositionsHandler(store store.TradeStore, hub *websocket.Hub, priceCache store.PriceCache, maxPriceAge time.Duration) *PositionsHandler {
+	h := &PositionsHandler{
+		store:       store,
+		hub:         hub,
+		priceCache:  priceCache,
+		maxPriceAge: maxPriceAge,
+		clock:       clock.Real{},
+	}
+	h.updates = newCoalescer(0, h.BroadcastUpdate)
+	return h
+}
+
+// SetBatchWindow coalesces trade events arriving within window into a
+// single broadcast of the latest positions; 0 broadcasts on every event.
+// Call before Start
+func (h *PositionsHandler) SetBatchWindow(window time.Duration) {
+	h.updates = newCoalescer(window, h.BroadcastUpdate)
+}
+
+// SetMarkSource re-broadcasts positions whenever the emitter reports a
+// trade marked at a new price; call before Start
+func (h *PositionsHandler) SetMarkSource(marks store.TradeEventEmitter) {
+	h.marks = marks
+}
+
+// positions returns the store's positions marked at the latest prices
+func (h *PositionsHandler) positions() []*models.Position {
+	positions, err := h.store.GetPositions()
+	if err != nil {
+		logging.Errorf("Error getting positions: %v", err)
+		return []*models.Position{}
+	}
+
+	now := h.clock.Now()
+	for _, position := range positions {
+		tick, ok := h.priceCache.GetLatestTick(position.Symbol)
+		if !ok || (h.maxPriceAge > 0 && now.Sub(tick.Timestamp) > h.maxPriceAge) {
+			position.PriceStale = true
+			continue
+		}
+		position.Mark(tick.Price)
+	}
+	return positions
+}
+
+// filterPositions keeps the positions of one strategy (nil filter = all)
+func filterPositions(positions []*models.Position, strategyID *string) []*models.Position {
+	if strategyID == nil {
+		return positions
+	}
+	filtered := make([]*models.Position, 0)
+	for _, position := range positions {
+		if position.StrategyID == *strategyID {
+			filtered = append(filtered, position)
+		}
+	}
+	return filtered
+}
+
+// OnTradeEvent implements store.TradeEventListener
+// Creates, closes and mark updates all re-broadcast the positions,
+// coalesced within the batch window
+func (h *PositionsHandler) OnTradeEvent(event store.TradeEvent) {
+	h.updates.Trigger()
+}
+
+// HandleSubscribe handles subscription requests
+// Options:
+//   - "strategy_id": only send this strategy's positions
+func (h *PositionsHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
+	var filter *string
+	if raw, ok := options["strategy_id"]; ok && raw != nil {
+		strategyID, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("invalid strategy_id option: must be a string")
+		}
+		filter = &strategyID
+	}
+
+	h.subscriptions.Store(subscribeID, filter)
+
+	h.hub.Broadcast(websocket.Message{
+		Type:        "positions",
+		SubscribeID: subscribeID,
+		Payload:     filterPositions(h.positions(), filter),
+	})
+	return nil
+}
+
+// HandleUnsubscribe handles unsubscribe requests
+func (h *PositionsHandler) HandleUnsubscribe(subscribeID string) error {
+	h.subscriptions.Delete(subscribeID)
+	return nil
+}
+
+// BroadcastUpdate sends the current positions to all subscribers
+func (h *PositionsHandler) BroadcastUpdate() {
+	var positions []*models.Position
+	h.subscriptions.Range(func(key, value interface{}) bool {
+		if positions == nil {
+			positions = h.positions() // Only computed when someone subscribes
+		}
+		h.hub.Broadcast(websocket.Message{
+			Type:        "positions",
+			SubscribeID: key.(string),
+			Payload:     filterPositions(positions, value.(*string)),
+		})
+		return true
+	})
+}
+
+// Start starts the handler and registers it as a trade store (and mark
+// source) listener
+func (h *PositionsHandler) Start() error {
+	h.store.AddListener(h)
+	if h.marks != nil {
+		h.marks.AddListener(h)
+	}
+	return nil
+}
+
+// Stop stops the handler and deregisters it from the trade store (and mark
+// source)
+func (h *PositionsHandler) Stop() error {
+	h.store.RemoveListener(h)
+	if h.marks != nil {
+		h.marks.RemoveListener(h)
+	}
+	h.updates.Stop()
+	return nil
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestPositionsCoalescesTradeEvents(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	h := NewPositionsHandler(trades, nil, memory.NewInMemoryPriceCache(), 0)
+	h.SetBatchWindow(100 * time.Millisecond)
+	server := newTestServer(t, map[string]MessageHandler{"positions": h})
+	h.hub = server.hub
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	client := server.dial(t)
+	client.subscribe("positions", nil)
+	client.next() // Initial snapshot
+
+	for i := 0; i < 20; i++ {
+		if _, err := trades.CreateTrade("AAPL", 100, 1, models.SideBuy, ""); err != nil {
+			t.Fatalf("CreateTrade: %v", err)
+		}
+	}
+
+	// One update for the whole burst, carrying its final state
+	var positions []models.Position
+	client.decode(client.next(), &positions)
+	if len(positions) != 1 || positions[0].Quantity != 20 {
+		t.Fatalf("coalesced update %+v, want one AAPL position of 20", positions)
+	}
+	client.expectNone(250 * time.Millisecond)
+}
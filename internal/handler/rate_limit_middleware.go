@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/aumbhatt/auto_trade/internal/auth"
+)
+
+// RateLimitMiddleware rejects requests beyond the limiter's rate for their
+// client (verified API key or IP, see auth.ClientKey) with 429 Too Many
+// Requests and a Retry-After header in whole seconds; a nil limiter
+// disables the check
+func RateLimitMiddleware(limiter *auth.RateLimiter, apiKey string, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowed, wait := limiter.Allow(auth.ClientKey(r, apiKey)); !allowed {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		// Call the next handler
+		next.ServeHTTP(w, r)
+	})
+}
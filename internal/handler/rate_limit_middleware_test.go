@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/auth"
+	"github.com/aumbhatt/auto_trade/internal/clock"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	start := time.Date(2025, 1, 23, 11, 0, 0, 0, time.UTC)
+	clk := clock.NewSimulated(start)
+	limiter := auth.NewRateLimiter(1, 2, clk)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := RateLimitMiddleware(limiter, "", ok)
+
+	request := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/trades/buy", nil)
+		r.RemoteAddr = "10.0.0.1:54321"
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := request(""); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i+1, w.Code)
+		}
+	}
+	w := request("")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request past the burst: status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After %q, want 1", got)
+	}
+
+	// Fresh tokens do not buy a fresh bucket
+	for i := 0; i < 3; i++ {
+		if w := request(fmt.Sprintf("token-%d", i)); w.Code != http.StatusTooManyRequests {
+			t.Errorf("request with made-up token %d: status %d, want 429", i, w.Code)
+		}
+	}
+
+	clk.Advance(start.Add(time.Second))
+	if w := request(""); w.Code != http.StatusOK {
+		t.Errorf("request after the refill: status %d, want 200", w.Code)
+	}
+}
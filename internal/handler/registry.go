@@ -42,11 +42,20 @@ This structure allows:
 - Efficient message routing
 - Clean separation of concerns
 - Easy addition of new message types
+
+Runtime Feed Toggling:
+- Each message type can be disabled at runtime (e.g. via the admin API)
+- Disabled types reject new subscribes with FEED_DISABLED
+- The hub drops broadcasts for disabled types (see IsEnabled)
 */
 
+// ErrFeedDisabled is the error code returned when subscribing to a disabled feed
+const ErrFeedDisabled = "FEED_DISABLED"
+
 // Registry manages all message type handlers
 type Registry struct {
 	handlers map[string]MessageHandler
+	disabled map[string]bool // msgType -> disabled at runtime
 	mutex    sync.RWMutex
 }
 
@@ -54,6 +63,7 @@ type Registry struct {
 func NewRegistry() *Registry {
 	return &Registry{
 		handlers: make(map[string]MessageHandler),
+		disabled: make(map[string]bool),
 	}
 }
 
@@ -70,19 +80,59 @@ func (r *Registry) Register(msgType string, handler MessageHandler) error {
 	return nil
 }
 
+// SetEnabled enables or disables a message type at runtime
+func (r *Registry) SetEnabled(msgType string, enabled bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.handlers[msgType]; !exists {
+		return fmt.Errorf("no handler registered for message type '%s'", msgType)
+	}
+
+	if enabled {
+		delete(r.disabled, msgType)
+	} else {
+		r.disabled[msgType] = true
+	}
+	return nil
+}
+
+// IsEnabled reports whether messages of the given type may be delivered
+func (r *Registry) IsEnabled(msgType string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return !r.disabled[msgType]
+}
+
 // HandleSubscribe routes subscription requests to appropriate handler
 func (r *Registry) HandleSubscribe(msgType string, subscribeID string, options map[string]interface{}) error {
 	r.mutex.RLock()
 	handler, exists := r.handlers[msgType]
+	disabled := r.disabled[msgType]
 	r.mutex.RUnlock()
 
 	if !exists {
 		return fmt.Errorf("no handler registered for message type '%s'", msgType)
 	}
+	if disabled {
+		return fmt.Errorf("%s: feed '%s' is disabled", ErrFeedDisabled, msgType)
+	}
 
 	return handler.HandleSubscribe(subscribeID, options)
 }
 
+// HandleSubscribed tells the handler of a message type that a subscription's
+// subscribe_response was sent, if the handler implements SubscribedHandler
+func (r *Registry) HandleSubscribed(msgType string, subscribeID string) {
+	r.mutex.RLock()
+	handler, exists := r.handlers[msgType]
+	r.mutex.RUnlock()
+
+	if subscribed, ok := handler.(SubscribedHandler); exists && ok {
+		subscribed.HandleSubscribed(subscribeID)
+	}
+}
+
 // HandleUnsubscribe routes unsubscribe requests to appropriate handler
 func (r *Registry) HandleUnsubscribe(msgType string, subscribeID string) error {
 	r.mutex.RLock()
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+func TestDisabledFeedRejectsSubscribes(t *testing.T) {
+	h := NewFlatHandler(memory.NewInMemoryTradeStore(), nil)
+	server := newTestServer(t, map[string]MessageHandler{"flat": h})
+	h.hub = server.hub
+	client := server.dial(t)
+	existing := client.subscribe("flat", nil)
+
+	if err := server.registry.SetEnabled("flat", false); err != nil {
+		t.Fatalf("SetEnabled: %v", err)
+	}
+	client.send(websocket.Message{
+		Type:    websocket.MessageTypeSubscribe,
+		Payload: websocket.SubscribeRequest{Type: "flat"},
+	})
+	msg := client.next()
+	var errPayload map[string]string
+	client.decode(msg, &errPayload)
+	if msg.Type != websocket.MessageTypeError || !strings.Contains(errPayload["error"], ErrFeedDisabled) {
+		t.Fatalf("got %s message %s, want a FEED_DISABLED error", msg.Type, msg.Payload)
+	}
+
+	// Existing subscribers get nothing while the feed is disabled
+	h.BroadcastFlat(models.FlatEvent{LastTradeID: "trade-1"})
+	client.expectNone(100 * time.Millisecond)
+
+	if err := server.registry.SetEnabled("flat", true); err != nil {
+		t.Fatalf("SetEnabled: %v", err)
+	}
+	client.subscribe("flat", nil)
+	h.BroadcastFlat(models.FlatEvent{LastTradeID: "trade-2"})
+	got := map[string]bool{client.next().SubscribeID: true, client.next().SubscribeID: true}
+	if !got[existing] || len(got) != 2 {
+		t.Errorf("after re-enabling, flat events went to %v, want both subscriptions", got)
+	}
+
+	if err := server.registry.SetEnabled("unknown", false); err == nil {
+		t.Error("disabled a type with no handler")
+	}
+}
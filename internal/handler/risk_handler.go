@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+)
+
+/*
+Risk Handler Flow:
+
+1. Position Sizing (POST /api/risk/size):
+   Request:
+   {
+       "symbol": "AAPL",
+       "stop_loss": 145.00,
+       "risk_amount": 100.00
+   }
+
+   Processing:
+   1. Validate inputs
+   2. Look up current price (entry) in the price cache
+   3. quantity = risk_amount / |entry - stop_loss|
+
+   Success Response: (200 OK)
+   {
+       "symbol": "AAPL",
+       "entry_price": 150.00,
+       "stop_loss": 145.00,
+       "risk_amount": 100.00,
+       "quantity": 20,
+       "position_value": 3000.00
+   }
+
+   Error Response: (400 Bad Request)
+   ZERO_STOP_DISTANCE: Stop loss equals current price for AAPL
+
+   Error Response: (404 Not Found)
+   PRICE_UNAVAILABLE: No current price for symbol: AAPL
+*/
+
+// RiskHandler handles risk-related HTTP requests
+type RiskHandler struct {
+	priceCache store.PriceCache
+}
+
+// NewRiskHandler creates a new RiskHandler instance
+func NewRiskHandler(priceCache store.PriceCache) *RiskHandler {
+	return &RiskHandler{
+		priceCache: priceCache,
+	}
+}
+
+// HandlePositionSize handles position sizing suggestion requests
+func (h *RiskHandler) HandlePositionSize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.PositionSizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.suggestPositionSize(req)
+	if err != nil {
+		if e, ok := err.(*models.RiskError); ok {
+			switch e.Code {
+			case models.ErrPriceUnavailable:
+				http.Error(w, e.Error(), http.StatusNotFound)
+			default:
+				http.Error(w, e.Error(), http.StatusBadRequest)
+			}
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// suggestPositionSize computes the quantity that risks req.RiskAmount if the stop loss is hit
+func (h *RiskHandler) suggestPositionSize(req models.PositionSizeRequest) (*models.PositionSizeResponse, error) {
+	if req.Symbol == "" {
+		return nil, &models.RiskError{
+			Code:    models.ErrInvalidRiskParameters,
+			Message: "symbol is required",
+		}
+	}
+	if req.StopLoss <= 0 {
+		return nil, &models.RiskError{
+			Code:    models.ErrInvalidRiskParameters,
+			Message: "stop_loss must be greater than 0",
+		}
+	}
+	if req.RiskAmount <= 0 {
+		return nil, &models.RiskError{
+			Code:    models.ErrInvalidRiskParameters,
+			Message: "risk_amount must be greater than 0",
+		}
+	}
+
+	tick, ok := h.priceCache.GetLatestTick(req.Symbol)
+	if !ok {
+		return nil, &models.RiskError{
+			Code:    models.ErrPriceUnavailable,
+			Message: fmt.Sprintf("No current price for symbol: %s", req.Symbol),
+		}
+	}
+
+	distance := math.Abs(tick.Price - req.StopLoss)
+	if distance == 0 {
+		return nil, &models.RiskError{
+			Code:    models.ErrZeroStopDistance,
+			Message: fmt.Sprintf("Stop loss equals current price for %s", req.Symbol),
+		}
+	}
+
+	quantity := req.RiskAmount / distance
+	return &models.PositionSizeResponse{
+		Symbol:        req.Symbol,
+		EntryPrice:    tick.Price,
+		StopLoss:      req.StopLoss,
+		RiskAmount:    req.RiskAmount,
+		Quantity:      quantity,
+		PositionValue: quantity * tick.Price,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestPositionSizeSuggestion(t *testing.T) {
+	prices := memory.NewInMemoryPriceCache()
+	prices.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: time.Now()})
+	h := NewRiskHandler(prices)
+
+	request := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/risk/size", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.HandlePositionSize(w, r)
+		return w
+	}
+
+	// Risking 100 with a stop 5 below the price buys 20
+	w := request(`{"symbol": "AAPL", "stop_loss": 145, "risk_amount": 100}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d (%s), want 200", w.Code, w.Body)
+	}
+	var resp models.PositionSizeResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.EntryPrice != 150 || resp.Quantity != 20 || resp.PositionValue != 3000 {
+		t.Errorf("suggestion %+v, want 20 at 150 worth 3000", resp)
+	}
+
+	// A stop above the price sizes a short the same way
+	w = request(`{"symbol": "AAPL", "stop_loss": 160, "risk_amount": 100}`)
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Quantity != 10 {
+		t.Errorf("quantity %v with a stop 10 above, want 10", resp.Quantity)
+	}
+
+	for _, tc := range []struct {
+		body   string
+		status int
+		code   string
+	}{
+		{`{"symbol": "AAPL", "stop_loss": 150, "risk_amount": 100}`, http.StatusBadRequest, models.ErrZeroStopDistance},
+		{`{"symbol": "AAPL", "stop_loss": 145, "risk_amount": 0}`, http.StatusBadRequest, models.ErrInvalidRiskParameters},
+		{`{"symbol": "MSFT", "stop_loss": 145, "risk_amount": 100}`, http.StatusNotFound, models.ErrPriceUnavailable},
+	} {
+		w := request(tc.body)
+		if w.Code != tc.status || !strings.Contains(w.Body.String(), tc.code) {
+			t.Errorf("%s: status %d %q, want %d %s", tc.body, w.Code, w.Body, tc.status, tc.code)
+		}
+	}
+}
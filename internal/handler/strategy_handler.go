@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/aumbhatt/auto_trade/internal/models"
 	"github.com/aumbhatt/auto_trade/internal/store"
 	"github.com/aumbhatt/auto_trade/internal/strategy"
@@ -29,10 +33,16 @@ Strategy Handler Flow and Structure:
           "parameters": {
               "symbol": "AAPL",
               "period": 20,
-              "threshold": 0.02
+              "threshold": 0.02,
+              "trade_store": "isolated",   // Optional, defaults to "shared"
+              "stop_on_disconnect": true,  // Optional dead-man switch, requires session_id
+              "session_id": "uuid-abc"     // WebSocket session ID (from the "session" message)
           }
       }
 
+      With stop_on_disconnect the session must be connected at start, and
+      the strategy is stopped as soon as that WebSocket session disconnects
+
       Success Response: (200 OK)
       {
           "id": "moving_average-abc123",
@@ -43,13 +53,16 @@ Strategy Handler Flow and Structure:
       Error Response: (400 Bad Request)
       {
           "code": "INVALID_STRATEGY",
-          "message": "Invalid strategy parameters"
+          "message": "Missing required parameter: symbol"
       }
+      Parameters are validated against the strategy metadata first, so
+      missing or wrongly typed parameters fail here rather than in the runner
 
    b. Stop Strategy (POST /api/strategies/stop):
       Request:
       {
-          "id": "moving_average-abc123"
+          "id": "moving_average-abc123",
+          "flatten": true            // Optional: close its open trades too
       }
 
       Success Response: (200 OK)
@@ -57,16 +70,65 @@ Strategy Handler Flow and Structure:
           "id": "moving_average-abc123",
           "start_time": "2025-01-23T14:23:38Z",
           "stop_time": "2025-01-23T14:30:00Z",
-          "status": "stopped"
+          "status": "stopped",
+          "closed_trades": ["trade-abc123"]   // Only with flatten
       }
 
+      With flatten the strategy is stopped first, so it cannot open new
+      trades, then its open trades are closed at the latest cached price
+      (close_reason "strategy_stopped"); trades without a cached price stay
+      open. Flattening an already stopped strategy closes the trades it
+      left open in the shared trade store.
+
       Error Response: (404 Not Found)
       {
           "code": "STRATEGY_NOT_FOUND",
           "message": "Strategy not found: moving_average-abc123"
       }
 
-   c. Get Default Strategies (GET /api/strategies/default):
+   c. Pause/Resume Strategy (POST /api/strategies/pause, /api/strategies/resume):
+      Request:
+      {
+          "id": "moving_average-abc123"
+      }
+
+      A paused strategy ignores ticks but keeps its open trades and
+      in-memory state, and stays in the active list with status "paused"
+
+      Success Response: (200 OK)
+      {
+          "id": "moving_average-abc123",
+          "name": "moving_average",
+          "parameters": {...},
+          "start_time": "2025-01-23T14:23:38Z",
+          "stop_time": null,
+          "status": "paused"             // "active" after resume
+      }
+
+      Error Responses:
+      404 STRATEGY_NOT_FOUND (unknown, stopped or not running)
+      409 ALREADY_PAUSED (pause) / NOT_PAUSED (resume)
+
+   d. Stop All Strategies (POST /api/strategies/stop-all?flatten=true):
+      Kill switch: stops every active and paused strategy, then with
+      flatten=true closes every open trade (shared and isolated stores)
+      at the latest cached price with close_reason "stop_all"
+      Idempotent: a repeated call stops and closes nothing
+
+      Success Response: (200 OK)
+      {
+          "stopped_strategies": ["moving_average-abc123"],
+          "closed_trades": ["trade-1", "trade-2"],
+          "errors": ["trade trade-3: no price for MSFT"]  // Left as they were
+      }
+
+      Error Responses:
+      400 invalid flatten value
+      501 flatten without a configured trade store and price cache
+
+   e. Get Available Strategies (GET /api/strategies/available):
+      Also served at the older GET /api/strategies/default
+      Strategies are sorted by name
       Success Response: (200 OK)
       [
           {
@@ -81,15 +143,21 @@ Strategy Handler Flow and Structure:
                   {
                       "name": "exit_price",
                       "type": "number",
-                      "required": true,
-                      "description": "Price at which to sell and restart cycle"
+                      "required": false,
+                      "description": "Price at which to sell and restart cycle (required unless trailing_stop is set)"
+                  },
+                  {
+                      "name": "trailing_stop",
+                      "type": "number",
+                      "required": false,
+                      "description": "Sell when price falls this percentage below its high since entry (e.g. 5 = 5%)"
                   }
               ],
               "strategy_flow": [
                   "1. Wait for no active position",
                   "2. Enter trade immediately at market price",
-                  "3. Hold position until price reaches exit_price",
-                  "4. Sell position when price >= exit_price",
+                  "3. Hold position, tracking the highest price since entry",
+                  "4. Sell position when price >= exit_price or price falls trailing_stop% below the high",
                   "5. Return to step 1"
               ]
           }
@@ -98,6 +166,23 @@ Strategy Handler Flow and Structure:
       Error Response: (405 Method Not Allowed)
       Method not allowed
 
+   f. Get Strategy Errors (GET /api/strategies/errors?id=moving_average-abc123):
+      Success Response: (200 OK)
+      {
+          "strategy_id": "moving_average-abc123",
+          "capacity": 100,
+          "overflow": 3,                // Older errors dropped from the buffer
+          "errors": [
+              {
+                  "time": "2025-01-23T14:25:00Z",
+                  "message": "failed to execute buy: ..."
+              }
+          ]
+      }
+
+      Error Response: (404 Not Found)
+      Strategy not found: moving_average-abc123
+
 3. WebSocket Messages:
 
    a. Subscribe to Active Strategies:
@@ -130,11 +215,20 @@ Strategy Handler Flow and Structure:
                       "threshold": 0.02
                   },
                   "start_time": "2025-01-23T14:23:38Z",
-                  "status": "active"
+                  "status": "active",
+                  "runtime": {            // Executor state, when it reports any
+                      "position_count": 1,
+                      "current_size": 100,
+                      "in_position": true
+                  }
               }
           ]
       }
 
+      runtime is read when the update is sent (on subscribe, on strategy
+      changes and on each snapshot interval); it is omitted for strategies
+      whose executor reports no state or is not running yet.
+
    b. Subscribe to Strategy History:
       Request:
       {
@@ -171,7 +265,31 @@ Strategy Handler Flow and Structure:
           ]
       }
 
-   c. Unsubscribe:
+   c. Subscribe to Strategy Errors:
+      Streams executor errors as they happen; nothing is sent on subscribe
+      (use GET /api/strategies/errors for the retained errors)
+      Options: "strategy_id" limits the stream to one strategy
+      Request:
+      {
+          "type": "subscribe",
+          "payload": {
+              "type": "strategy_errors",
+              "options": {"strategy_id": "moving_average-abc123"}
+          }
+      }
+
+      Updates (one message per error):
+      {
+          "type": "strategy_errors",
+          "subscribe_id": "sub-789",
+          "payload": {
+              "strategy_id": "moving_average-abc123",
+              "error": "failed to execute buy: STALE_PRICE: ...",
+              "timestamp": "2025-01-23T14:23:38Z"
+          }
+      }
+
+   d. Unsubscribe:
       Request:
       {
           "type": "unsubscribe",
@@ -196,6 +314,9 @@ type StrategyHandler struct {
 	hub                   *websocket.Hub
 	activeStrategiesHandler  *ActiveStrategiesHandler
 	strategyHistoryHandler   *StrategyHistoryHandler
+	// Optional stop-all flatten support
+	trades store.TradeStore
+	prices store.PriceCache
 }
 
 // NewStrategyHandler creates a new StrategyHandler instance
@@ -210,6 +331,13 @@ func NewStrategyHandler(store store.StrategyStore, runner strategy.Runner, tickH
 	}
 }
 
+// SetFlattenSource sets the shared trade store and the prices used by
+// stop-all requests with flatten; without it flatten is not supported
+func (h *StrategyHandler) SetFlattenSource(trades store.TradeStore, prices store.PriceCache) {
+	h.trades = trades
+	h.prices = prices
+}
+
 // HandleStart handles strategy start requests
 func (h *StrategyHandler) HandleStart(w http.ResponseWriter, r *http.Request) {
 	var req models.StartStrategyRequest
@@ -218,6 +346,30 @@ func (h *StrategyHandler) HandleStart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validate parameters before creating the strategy, so a doomed
+	// strategy is rejected instead of failing later in the runner
+	if err := strategy.GetDefaultRegistry().ValidateParameters(req.Name, req.Parameters); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := models.TradeStoreMode(req.Parameters); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := models.CapitalAllocation(req.Parameters); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sessionID, err := models.DisconnectSession(req.Parameters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if sessionID != "" && !h.hub.HasSession(sessionID) {
+		http.Error(w, fmt.Sprintf("Unknown session: %s", sessionID), http.StatusBadRequest)
+		return
+	}
+
 	// Create strategy
 	strategy, err := h.store.CreateStrategy(req.Name, req.Parameters)
 	if err != nil {
@@ -235,10 +387,28 @@ func (h *StrategyHandler) HandleStart(w http.ResponseWriter, r *http.Request) {
 	// Start strategy
 	if err := h.runner.Start(strategy, tickChan); err != nil {
 		h.tickHandler.RemoveStrategy(strategy.ID)
+		// Parameters the runner refuses, e.g. a fractional capital
+		// allocation without an account balance
+		if e, ok := err.(*models.StrategyError); ok {
+			h.store.StopStrategy(strategy.ID)
+			http.Error(w, e.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// The session may have disconnected since it was checked, its
+	// disconnect hook running before the strategy existed. Check again now
+	// that the strategy is active: any later disconnect hook will find it
+	if sessionID != "" && !h.hub.HasSession(sessionID) {
+		if err := h.stopStrategy(strategy); err != nil {
+			logging.Errorf("Error stopping strategy %s of disconnected session %s: %v", strategy.ID, sessionID, err)
+		}
+		http.Error(w, fmt.Sprintf("Unknown session: %s", sessionID), http.StatusBadRequest)
+		return
+	}
+
 	// Broadcast updates
 	activeStrategies, _ := h.store.GetActiveStrategies()
 	h.activeStrategiesHandler.BroadcastActiveStrategiesUpdate(activeStrategies)
@@ -260,6 +430,15 @@ func (h *StrategyHandler) HandleStop(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var flattener strategy.Flattener
+	if req.Flatten {
+		var ok bool
+		if flattener, ok = h.runner.(strategy.Flattener); !ok {
+			http.Error(w, "Flatten not supported", http.StatusNotImplemented)
+			return
+		}
+	}
+
 	// Get strategy
 	strategy, err := h.store.GetStrategyByID(req.ID)
 	if err != nil {
@@ -276,12 +455,114 @@ func (h *StrategyHandler) HandleStop(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Stop strategy
-	if err := h.runner.Stop(strategy); err != nil {
+	// Stop strategy (succeeds if it is already stopped)
+	var closed []string
+	if flattener != nil {
+		closed, err = flattener.StopAndFlatten(strategy)
+		if err == nil {
+			h.strategyStopped(strategy)
+		}
+	} else {
+		err = h.stopStrategy(strategy)
+	}
+	if err != nil {
+		if e, ok := err.(*models.StrategyError); ok && e.Code == models.ErrStrategyNotFound {
+			http.Error(w, e.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Re-read the strategy, since stores may hand out copies
+	strategy, err = h.store.GetStrategyByID(req.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return response
+	resp := models.StopStrategyResponse{
+		ID:           strategy.ID,
+		StartTime:    strategy.StartTime,
+		StopTime:     *strategy.StopTime,
+		Status:       strategy.Status,
+		ClosedTrades: closed,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandlePause handles strategy pause requests
+func (h *StrategyHandler) HandlePause(w http.ResponseWriter, r *http.Request) {
+	h.handlePauseResume(w, r, h.runner.Pause)
+}
+
+// HandleResume handles strategy resume requests
+func (h *StrategyHandler) HandleResume(w http.ResponseWriter, r *http.Request) {
+	h.handlePauseResume(w, r, h.runner.Resume)
+}
+
+// handlePauseResume applies a runner pause or resume to the requested
+// strategy and broadcasts the updated active strategies
+func (h *StrategyHandler) handlePauseResume(w http.ResponseWriter, r *http.Request, apply func(*models.Strategy) error) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.PauseStrategyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	strategy, err := h.store.GetStrategyByID(req.ID)
+	if err == nil {
+		err = apply(strategy)
+	}
+	if err != nil {
+		if e, ok := err.(*models.StrategyError); ok {
+			switch e.Code {
+			case models.ErrStrategyNotFound:
+				http.Error(w, e.Error(), http.StatusNotFound)
+			case models.ErrAlreadyPaused, models.ErrNotPaused:
+				http.Error(w, e.Error(), http.StatusConflict)
+			default:
+				http.Error(w, e.Error(), http.StatusBadRequest)
+			}
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// Re-read the strategy, since stores may hand out copies
+	strategy, err = h.store.GetStrategyByID(req.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Broadcast updates
+	activeStrategies, _ := h.store.GetActiveStrategies()
+	h.activeStrategiesHandler.BroadcastActiveStrategiesUpdate(activeStrategies)
+
+	json.NewEncoder(w).Encode(strategy)
+}
+
+// stopStrategy stops a strategy, removes its tick feed and broadcasts the
+// updated strategy lists
+func (h *StrategyHandler) stopStrategy(strategy *models.Strategy) error {
+	if err := h.runner.Stop(strategy); err != nil {
+		return err
+	}
+	h.strategyStopped(strategy)
+	return nil
+}
+
+// strategyStopped releases a stopped strategy's tick channel and broadcasts
+// the updated strategy lists
+func (h *StrategyHandler) strategyStopped(strategy *models.Strategy) {
 	// Remove strategy's tick channel
 	h.tickHandler.RemoveStrategy(strategy.ID)
 
@@ -290,31 +571,240 @@ func (h *StrategyHandler) HandleStop(w http.ResponseWriter, r *http.Request) {
 	historyStrategies, _ := h.store.GetStrategyHistory()
 	h.activeStrategiesHandler.BroadcastActiveStrategiesUpdate(activeStrategies)
 	h.strategyHistoryHandler.BroadcastStrategyHistoryUpdate(historyStrategies)
+}
 
-	// Return response
-	resp := models.StopStrategyResponse{
-		ID:        strategy.ID,
-		StartTime: strategy.StartTime,
-		StopTime:  *strategy.StopTime,
-		Status:    strategy.Status,
+// StopSessionStrategies stops the active strategies started with
+// stop_on_disconnect for the given session; used as a hub disconnect hook
+func (h *StrategyHandler) StopSessionStrategies(sessionID string) {
+	active, err := h.store.GetActiveStrategies()
+	if err != nil {
+		logging.Errorf("Error getting active strategies for session %s: %v", sessionID, err)
+		return
+	}
+
+	for _, strategy := range active {
+		owner, err := models.DisconnectSession(strategy.Parameters)
+		if err != nil || owner != sessionID {
+			continue
+		}
+		if err := h.stopStrategy(strategy); err != nil {
+			logging.Errorf("Error stopping strategy %s on session %s disconnect: %v", strategy.ID, sessionID, err)
+			continue
+		}
+		logging.Infof("Stopped strategy %s: session %s disconnected", strategy.ID, sessionID)
+	}
+}
+
+// ResumeStrategies restarts the strategies the store still lists as active,
+// e.g. ones persisted by a process that did not shut down cleanly; paused
+// strategies restart paused
+// Strategies tied to a WebSocket session are stopped instead, since that
+// session cannot survive a restart
+func (h *StrategyHandler) ResumeStrategies() {
+	active, err := h.store.GetActiveStrategies()
+	if err != nil {
+		logging.Errorf("Error getting active strategies to resume: %v", err)
+		return
+	}
+
+	for _, strategy := range active {
+		if sessionID, _ := models.DisconnectSession(strategy.Parameters); sessionID != "" {
+			if err := h.stopStrategy(strategy); err != nil {
+				logging.Errorf("Error stopping strategy %s of ended session %s: %v", strategy.ID, sessionID, err)
+			}
+			continue
+		}
+
+		tickChan := h.tickHandler.AddStrategy(strategy.ID)
+		if err := h.runner.Start(strategy, tickChan); err != nil {
+			h.tickHandler.RemoveStrategy(strategy.ID)
+			logging.Errorf("Error resuming strategy %s: %v", strategy.ID, err)
+			continue
+		}
+		logging.Infof("Resumed strategy %s", strategy.ID)
+	}
+}
+
+// StopAllStrategies stops every active strategy, moving each to history;
+// used on server shutdown so no strategy goroutine outlives the process
+func (h *StrategyHandler) StopAllStrategies() {
+	h.stopActive("server shutting down")
+}
+
+// stopActive stops every active or paused strategy, returning the IDs
+// stopped and the errors of those that could not be
+func (h *StrategyHandler) stopActive(reason string) ([]string, []error) {
+	active, err := h.store.GetActiveStrategies()
+	if err != nil {
+		logging.Errorf("Error getting active strategies to stop: %v", err)
+		return nil, []error{err}
+	}
+
+	stopped := make([]string, 0, len(active))
+	var errs []error
+	for _, strategy := range active {
+		if err := h.stopStrategy(strategy); err != nil {
+			logging.Errorf("Error stopping strategy %s (%s): %v", strategy.ID, reason, err)
+			errs = append(errs, fmt.Errorf("strategy %s: %v", strategy.ID, err))
+			continue
+		}
+		logging.Infof("Stopped strategy %s: %s", strategy.ID, reason)
+		stopped = append(stopped, strategy.ID)
+	}
+	return stopped, errs
+}
+
+// HandleStopAll handles kill switch requests: it stops every active strategy
+// and, with ?flatten=true, closes every open trade
+func (h *StrategyHandler) HandleStopAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flatten := false
+	if raw := r.URL.Query().Get("flatten"); raw != "" {
+		var err error
+		if flatten, err = strconv.ParseBool(raw); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid flatten: %s", raw), http.StatusBadRequest)
+			return
+		}
+	}
+	if flatten && (h.trades == nil || h.prices == nil) {
+		http.Error(w, "Flatten not supported", http.StatusNotImplemented)
+		return
+	}
+
+	// Isolated trade stores are only reachable while their strategy runs,
+	// so collect them before stopping
+	var tradeStores []store.TradeStore
+	if flatten {
+		tradeStores = append([]store.TradeStore{h.trades}, h.isolatedTradeStores()...)
+	}
+
+	// Stop strategies first; the runner waits for each strategy loop to
+	// exit, so none opens a trade while flattening
+	stopped, errs := h.stopActive("stop-all requested")
+	resp := models.StopAllResponse{
+		StoppedStrategies: stopped,
+		ClosedTrades:      []string{},
 	}
+	for _, tradeStore := range tradeStores {
+		closed, flattenErrs := h.flatten(tradeStore)
+		resp.ClosedTrades = append(resp.ClosedTrades, closed...)
+		errs = append(errs, flattenErrs...)
+	}
+	for _, err := range errs {
+		resp.Errors = append(resp.Errors, err.Error())
+	}
+
 	json.NewEncoder(w).Encode(resp)
 }
 
+// isolatedTradeStores returns the trade stores of running strategies that
+// do not trade against the shared store
+func (h *StrategyHandler) isolatedTradeStores() []store.TradeStore {
+	lookup, ok := h.runner.(interface {
+		TradeStore(strategyID string) (store.TradeStore, bool)
+	})
+	if !ok {
+		return nil
+	}
+
+	active, err := h.store.GetActiveStrategies()
+	if err != nil {
+		logging.Errorf("Error getting active strategies to flatten: %v", err)
+		return nil
+	}
+
+	var stores []store.TradeStore
+	seen := make(map[store.TradeStore]bool)
+	for _, strategy := range active {
+		tradeStore, ok := lookup.TradeStore(strategy.ID)
+		if !ok || tradeStore == h.trades || seen[tradeStore] {
+			continue // Dry-run strategies share the paper store
+		}
+		seen[tradeStore] = true
+		stores = append(stores, tradeStore)
+	}
+	return stores
+}
+
+// flatten closes every open trade of a trade store at the latest cached
+// price, returning the IDs closed and the errors of trades left open
+func (h *StrategyHandler) flatten(tradeStore store.TradeStore) ([]string, []error) {
+	open, err := tradeStore.GetOpenTrades()
+	if err != nil {
+		logging.Errorf("Error getting open trades to flatten: %v", err)
+		return nil, []error{err}
+	}
+
+	var closed []string
+	var errs []error
+	for _, trade := range open {
+		tick, ok := h.prices.GetLatestTick(trade.Symbol)
+		if !ok {
+			errs = append(errs, fmt.Errorf("trade %s: no price for %s", trade.ID, trade.Symbol))
+			continue
+		}
+		if _, err := tradeStore.CloseTrade(trade.ID, tick.Price, models.CloseReasonStopAll); err != nil {
+			if e, ok := err.(*models.TradeError); ok && (e.Code == models.ErrTradeNotFound || e.Code == models.ErrTradeAlreadyClosed) {
+				continue // Closed since GetOpenTrades
+			}
+			logging.Errorf("Error flattening trade %s: %v", trade.ID, err)
+			errs = append(errs, fmt.Errorf("trade %s: %v", trade.ID, err))
+			continue
+		}
+		closed = append(closed, trade.ID)
+	}
+	return closed, errs
+}
+
 // ActiveStrategiesHandler handles active strategies subscriptions
 type ActiveStrategiesHandler struct {
 	store store.StrategyStore
 	hub   *websocket.Hub
 	// Track subscriptions
 	subscriptions sync.Map // map[string]struct{} // subscribeID -> struct{}
+	// Periodic snapshots (0 = change-driven updates only)
+	snapshotInterval time.Duration
+	done             chan struct{}
+	running          bool
+	mutex            sync.Mutex // Guards done and running
+	// Optional source of executor runtime state
+	state strategy.StateReporter
 }
 
 // NewActiveStrategiesHandler creates a new ActiveStrategiesHandler
-func NewActiveStrategiesHandler(store store.StrategyStore, hub *websocket.Hub) *ActiveStrategiesHandler {
+// A non-zero snapshotInterval re-broadcasts the active list on that interval
+// so late-joining or lossy clients stay current between changes
+func NewActiveStrategiesHandler(store store.StrategyStore, hub *websocket.Hub, snapshotInterval time.Duration) *ActiveStrategiesHandler {
 	return &ActiveStrategiesHandler{
-		store: store,
-		hub:   hub,
+		store:            store,
+		hub:              hub,
+		snapshotInterval: snapshotInterval,
+	}
+}
+
+// SetStateSource includes the runtime state reported by the source in
+// updates; call before Start
+func (h *ActiveStrategiesHandler) SetStateSource(state strategy.StateReporter) {
+	h.state = state
+}
+
+// withRuntime attaches each strategy's runtime state, when known
+func (h *ActiveStrategiesHandler) withRuntime(strategies []*models.Strategy) []*models.ActiveStrategy {
+	active := make([]*models.ActiveStrategy, 0, len(strategies))
+	for _, s := range strategies {
+		entry := &models.ActiveStrategy{Strategy: s}
+		if h.state != nil {
+			if runtime, ok := h.state.RuntimeState(s.ID); ok {
+				entry.Runtime = runtime
+			}
+		}
+		active = append(active, entry)
 	}
+	return active
 }
 
 // HandleSubscribe handles subscription requests for active strategies
@@ -331,7 +821,7 @@ func (h *ActiveStrategiesHandler) HandleSubscribe(subscribeID string, options ma
 	msg := websocket.Message{
 		Type:        "active_strategies",
 		SubscribeID: subscribeID,
-		Payload:     strategies,
+		Payload:     h.withRuntime(strategies),
 	}
 	h.hub.Broadcast(msg)
 	return nil
@@ -345,25 +835,62 @@ func (h *ActiveStrategiesHandler) HandleUnsubscribe(subscribeID string) error {
 
 // BroadcastActiveStrategiesUpdate sends updates to all active strategies subscribers
 func (h *ActiveStrategiesHandler) BroadcastActiveStrategiesUpdate(strategies []*models.Strategy) {
+	active := h.withRuntime(strategies)
 	h.subscriptions.Range(func(key, value interface{}) bool {
 		subscribeID := key.(string)
 		h.hub.Broadcast(websocket.Message{
 			Type:        "active_strategies",
 			SubscribeID: subscribeID,
-			Payload:     strategies,
+			Payload:     active,
 		})
 		return true
 	})
 }
 
-// Start starts the handler
+// Start starts the periodic snapshot broadcaster if an interval is configured
 func (h *ActiveStrategiesHandler) Start() error {
-	return nil // No startup needed
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.snapshotInterval <= 0 || h.running {
+		return nil
+	}
+
+	done := make(chan struct{})
+	h.done = done
+	h.running = true
+
+	go func() {
+		ticker := time.NewTicker(h.snapshotInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				strategies, err := h.store.GetActiveStrategies()
+				if err != nil {
+					continue
+				}
+				h.BroadcastActiveStrategiesUpdate(strategies)
+			}
+		}
+	}()
+
+	return nil
 }
 
-// Stop stops the handler
+// Stop stops the periodic snapshot broadcaster
 func (h *ActiveStrategiesHandler) Stop() error {
-	return nil // No cleanup needed
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if !h.running {
+		return nil
+	}
+
+	close(h.done)
+	h.running = false
+	return nil
 }
 
 // StrategyHistoryHandler handles strategy history subscriptions
@@ -431,13 +958,111 @@ func (h *StrategyHistoryHandler) Stop() error {
 	return nil // No cleanup needed
 }
 
-// HandleDefaultStrategies returns information about available strategies
-func (h *StrategyHandler) HandleDefaultStrategies(w http.ResponseWriter, r *http.Request) {
+// StrategyErrorsHandler streams strategy executor errors to subscribers
+type StrategyErrorsHandler struct {
+	hub *websocket.Hub
+	// Track subscriptions
+	subscriptions sync.Map // map[string]string // subscribeID -> strategy ID filter ("" = all)
+}
+
+// NewStrategyErrorsHandler creates a new StrategyErrorsHandler
+// Register OnStrategyError as a runner error hook to feed it
+func NewStrategyErrorsHandler(hub *websocket.Hub) *StrategyErrorsHandler {
+	return &StrategyErrorsHandler{
+		hub: hub,
+	}
+}
+
+// OnStrategyError broadcasts an executor error to matching subscribers
+func (h *StrategyErrorsHandler) OnStrategyError(event models.StrategyErrorEvent) {
+	h.subscriptions.Range(func(key, value interface{}) bool {
+		if filter := value.(string); filter != "" && filter != event.StrategyID {
+			return true
+		}
+		h.hub.Broadcast(websocket.Message{
+			Type:        "strategy_errors",
+			SubscribeID: key.(string),
+			Payload:     event,
+		})
+		return true
+	})
+}
+
+// HandleSubscribe handles subscription requests
+// Options:
+//   - "strategy_id": only stream errors of this strategy
+func (h *StrategyErrorsHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
+	filter := ""
+	if raw, ok := options["strategy_id"]; ok && raw != nil {
+		strategyID, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("invalid strategy_id option: must be a string")
+		}
+		filter = strategyID
+	}
+
+	h.subscriptions.Store(subscribeID, filter)
+	return nil
+}
+
+// HandleUnsubscribe handles unsubscribe requests
+func (h *StrategyErrorsHandler) HandleUnsubscribe(subscribeID string) error {
+	h.subscriptions.Delete(subscribeID)
+	return nil
+}
+
+// Start starts the handler
+func (h *StrategyErrorsHandler) Start() error {
+	return nil // Fed by runner error hooks
+}
+
+// Stop stops the handler
+func (h *StrategyErrorsHandler) Stop() error {
+	return nil // No cleanup needed
+}
+
+// HandleAvailableStrategies returns the metadata of every registered strategy
+// so clients can build parameter forms dynamically
+func (h *StrategyHandler) HandleAvailableStrategies(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	metadata := strategy.GetDefaultRegistry().GetStrategyMetadata()
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(metadata)
 }
+
+// HandleDefaultStrategies serves the older /api/strategies/default path
+func (h *StrategyHandler) HandleDefaultStrategies(w http.ResponseWriter, r *http.Request) {
+	h.HandleAvailableStrategies(w, r)
+}
+
+// HandleErrors returns a strategy's retained errors and overflow count
+func (h *StrategyHandler) HandleErrors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	strategyID := r.URL.Query().Get("id")
+	if strategyID == "" {
+		http.Error(w, "Missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	reporter, ok := h.runner.(strategy.ErrorReporter)
+	if !ok {
+		http.Error(w, "Strategy errors not available", http.StatusNotImplemented)
+		return
+	}
+
+	stats, exists := reporter.ErrorStats(strategyID)
+	if !exists {
+		http.Error(w, "Strategy not found: "+strategyID, http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
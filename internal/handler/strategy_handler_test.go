@@ -0,0 +1,490 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+	"github.com/aumbhatt/auto_trade/internal/store/sqlite"
+	"github.com/aumbhatt/auto_trade/internal/strategy"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+// hookedStrategyStore runs beforeCreate ahead of every CreateStrategy
+type hookedStrategyStore struct {
+	*memory.InMemoryStrategyStore
+	beforeCreate func()
+}
+
+func (s *hookedStrategyStore) CreateStrategy(name string, params map[string]interface{}) (*models.Strategy, error) {
+	s.beforeCreate()
+	return s.InMemoryStrategyStore.CreateStrategy(name, params)
+}
+
+func TestStartStopsStrategyOfSessionThatDisconnectedWhileStarting(t *testing.T) {
+	server := newTestServer(t, nil)
+	strategies := &hookedStrategyStore{InMemoryStrategyStore: memory.NewInMemoryStrategyStore()}
+	runner := strategy.NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	h := NewStrategyHandler(strategies, runner,
+		NewTickHandler(server.hub, nil, memory.NewInMemoryPriceCache()), server.hub,
+		NewActiveStrategiesHandler(strategies, server.hub, 0), NewStrategyHistoryHandler(strategies, server.hub))
+
+	hooked := make(chan struct{})
+	server.hub.OnDisconnect(h.StopSessionStrategies)
+	server.hub.OnDisconnect(func(string) { close(hooked) })
+
+	// The session disconnects after it is checked, and its disconnect
+	// hook has run before the strategy exists
+	client := server.dial(t)
+	strategies.beforeCreate = func() {
+		client.conn.Close()
+		select {
+		case <-hooked:
+		case <-time.After(2 * time.Second):
+			t.Error("disconnect hook did not run")
+		}
+	}
+
+	body, _ := json.Marshal(models.StartStrategyRequest{
+		Name: "martingale",
+		Parameters: map[string]interface{}{
+			"symbol": "AAPL", "base_position": 1.0, "take_profit": 5.0, "stop_loss": 5.0, "max_positions": 3.0,
+			"stop_on_disconnect": true, "session_id": client.session,
+		},
+	})
+	rec := httptest.NewRecorder()
+	h.HandleStart(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/start", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", rec.Code)
+	}
+	if active, _ := strategies.GetActiveStrategies(); len(active) != 0 {
+		t.Errorf("%d strategies left running after their session disconnected", len(active))
+	}
+}
+
+func TestActiveStrategiesSnapshotsUntilStopped(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	h := NewActiveStrategiesHandler(strategies, nil, 20*time.Millisecond)
+	server := newTestServer(t, map[string]MessageHandler{"active_strategies": h})
+	h.hub = server.hub
+
+	// Start and Stop may race, e.g. shutdown during startup
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); h.Start() }()
+		go func() { defer wg.Done(); h.Stop() }()
+	}
+	wg.Wait()
+
+	h.Start()
+	client := server.dial(t)
+	client.send(websocket.Message{
+		Type:    websocket.MessageTypeSubscribe,
+		Payload: websocket.SubscribeRequest{Type: "active_strategies"},
+	})
+	// The subscribe response, the initial list and two periodic snapshots
+	for snapshots := 0; snapshots < 3; {
+		if client.next().Type == "active_strategies" {
+			snapshots++
+		}
+	}
+
+	h.Stop()
+	time.Sleep(50 * time.Millisecond) // Let a snapshot already queued arrive
+	for len(client.messages) > 0 {
+		<-client.messages
+	}
+	client.expectNone(100 * time.Millisecond)
+}
+
+func TestActiveStrategiesCarryRuntimeState(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := strategy.NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	h := NewActiveStrategiesHandler(strategies, nil, 0)
+	h.SetStateSource(runner)
+	server := newTestServer(t, map[string]MessageHandler{"active_strategies": h})
+	h.hub = server.hub
+
+	params := map[string]interface{}{
+		"symbol": "AAPL", "base_position": 1.0, "take_profit": 5.0, "stop_loss": 5.0, "max_positions": 3.0,
+	}
+	running, _ := strategies.CreateStrategy("martingale", params)
+	idle, _ := strategies.CreateStrategy("martingale", params) // Stored, never started
+	ticks := make(chan *models.Tick)
+	if err := runner.Start(running, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer runner.Stop(running)
+
+	// Enter, stop out and re-enter at double size; the last tick only
+	// waits for the re-entry to be processed
+	for _, price := range []float64{100, 95, 100, 100} {
+		ticks <- &models.Tick{Symbol: "AAPL", Price: price, Timestamp: time.Now()}
+	}
+
+	client := server.dial(t)
+	client.subscribe("active_strategies", nil)
+	var active []struct {
+		ID      string                 `json:"id"`
+		Runtime map[string]interface{} `json:"runtime"`
+	}
+	client.decode(client.next(), &active)
+	if len(active) != 2 {
+		t.Fatalf("%d active strategies, want 2", len(active))
+	}
+	for _, entry := range active {
+		switch entry.ID {
+		case running.ID:
+			if entry.Runtime["position_count"] != 2.0 || entry.Runtime["current_size"] != 2.0 || entry.Runtime["in_position"] != true {
+				t.Errorf("running martingale reports %v, want 2 positions taken and a size of 2, in position", entry.Runtime)
+			}
+		case idle.ID:
+			if entry.Runtime != nil {
+				t.Errorf("strategy that is not running reports %v, want no runtime", entry.Runtime)
+			}
+		}
+	}
+}
+
+func TestAvailableStrategiesListsMetadata(t *testing.T) {
+	h := &StrategyHandler{} // Served from the default registry alone
+
+	for _, path := range []string{"/api/strategies/available", "/api/strategies/default"} {
+		rec := httptest.NewRecorder()
+		h.HandleAvailableStrategies(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, want 200", path, rec.Code)
+		}
+		var metadata []models.StrategyMetadata
+		if err := json.NewDecoder(rec.Body).Decode(&metadata); err != nil {
+			t.Fatalf("%s: decode: %v", path, err)
+		}
+
+		var required []string
+		for _, m := range metadata {
+			if m.Name != "martingale" {
+				continue
+			}
+			for _, p := range m.Parameters {
+				if p.Required {
+					required = append(required, p.Name)
+				}
+			}
+		}
+		want := []string{"symbol", "base_position", "take_profit", "stop_loss", "max_positions"}
+		if strings.Join(required, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: martingale requires %v, want %v", path, required, want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.HandleAvailableStrategies(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/available", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status %d, want 405", rec.Code)
+	}
+}
+
+func TestStartValidatesParametersBeforeStoring(t *testing.T) {
+	server := newTestServer(t, nil)
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := strategy.NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	h := NewStrategyHandler(strategies, runner,
+		NewTickHandler(server.hub, nil, memory.NewInMemoryPriceCache()), server.hub,
+		NewActiveStrategiesHandler(strategies, server.hub, 0), NewStrategyHistoryHandler(strategies, server.hub))
+
+	start := func(params map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.StartStrategyRequest{Name: "rsi", Parameters: params})
+		rec := httptest.NewRecorder()
+		h.HandleStart(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/start", bytes.NewReader(body)))
+		return rec
+	}
+	stored := func() int {
+		active, _ := strategies.GetActiveStrategies()
+		stopped, _ := strategies.GetStrategyHistory()
+		return len(active) + len(stopped)
+	}
+
+	// StrategyError bodies carry the message, which names the parameter
+	for _, tc := range []struct {
+		params map[string]interface{}
+		bad    string
+	}{
+		{map[string]interface{}{"period": 14.0, "oversold": 30.0, "overbought": 70.0}, "symbol"},
+		{map[string]interface{}{"symbol": "AAPL", "period": "14", "oversold": 30.0, "overbought": 70.0}, "period"},
+	} {
+		rec := start(tc.params)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tc.bad) {
+			t.Errorf("bad %s: status %d %q, want 400 naming %s", tc.bad, rec.Code, rec.Body, tc.bad)
+		}
+	}
+	if n := stored(); n != 0 {
+		t.Fatalf("%d strategies stored from invalid requests, want 0", n)
+	}
+
+	rec := start(map[string]interface{}{"symbol": "AAPL", "period": 14.0, "oversold": 30.0, "overbought": 70.0})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid request: status %d (%s), want 200", rec.Code, rec.Body)
+	}
+	var started models.Strategy
+	json.NewDecoder(rec.Body).Decode(&started)
+	defer runner.Stop(&started)
+	if n := stored(); n != 1 || started.Status != "active" {
+		t.Errorf("valid request stored %d strategies with status %q, want 1 active", n, started.Status)
+	}
+}
+
+func TestOwnerDisconnectStopsOnlyFlaggedStrategies(t *testing.T) {
+	server := newTestServer(t, nil)
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := strategy.NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	h := NewStrategyHandler(strategies, runner,
+		NewTickHandler(server.hub, nil, memory.NewInMemoryPriceCache()), server.hub,
+		NewActiveStrategiesHandler(strategies, server.hub, 0), NewStrategyHistoryHandler(strategies, server.hub))
+	server.hub.OnDisconnect(h.StopSessionStrategies)
+
+	owner := server.dial(t)
+	start := func(stopOnDisconnect bool) *models.Strategy {
+		t.Helper()
+		params := map[string]interface{}{
+			"symbol": "AAPL", "base_position": 1.0, "take_profit": 5.0, "stop_loss": 5.0, "max_positions": 3.0,
+			"stop_on_disconnect": stopOnDisconnect, "session_id": owner.session,
+		}
+		body, _ := json.Marshal(models.StartStrategyRequest{Name: "martingale", Parameters: params})
+		rec := httptest.NewRecorder()
+		h.HandleStart(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/start", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("start: status %d (%s), want 200", rec.Code, rec.Body)
+		}
+		var started models.Strategy
+		json.NewDecoder(rec.Body).Decode(&started)
+		return &started
+	}
+	status := func(s *models.Strategy) string {
+		got, _ := strategies.GetStrategyByID(s.ID)
+		return got.Status
+	}
+
+	flagged := start(true)
+	unflagged := start(false)
+	defer runner.Stop(unflagged)
+
+	owner.conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for status(flagged) != "stopped" {
+		if time.Now().After(deadline) {
+			t.Fatalf("flagged strategy %q after its owner disconnected, want stopped", status(flagged))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if s := status(unflagged); s != "active" {
+		t.Errorf("unflagged strategy %q after the owner disconnected, want active", s)
+	}
+}
+
+func TestShutdownStopsRunningStrategies(t *testing.T) {
+	server := newTestServer(t, nil)
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := strategy.NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	h := NewStrategyHandler(strategies, runner,
+		NewTickHandler(server.hub, nil, memory.NewInMemoryPriceCache()), server.hub,
+		NewActiveStrategiesHandler(strategies, server.hub, 0), NewStrategyHistoryHandler(strategies, server.hub))
+
+	var ids []string
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		body, _ := json.Marshal(models.StartStrategyRequest{
+			Name:       "repeat",
+			Parameters: map[string]interface{}{"symbol": symbol, "exit_price": 110.0},
+		})
+		rec := httptest.NewRecorder()
+		h.HandleStart(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/start", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("start: status %d (%s), want 200", rec.Code, rec.Body)
+		}
+		var started models.Strategy
+		json.NewDecoder(rec.Body).Decode(&started)
+		ids = append(ids, started.ID)
+	}
+
+	h.StopAllStrategies()
+
+	if active, _ := strategies.GetActiveStrategies(); len(active) != 0 {
+		t.Errorf("%d strategies active after shutdown, want 0", len(active))
+	}
+	history, _ := strategies.GetStrategyHistory()
+	stopped := make(map[string]string)
+	for _, s := range history {
+		stopped[s.ID] = s.Status
+	}
+	for _, id := range ids {
+		if stopped[id] != "stopped" {
+			t.Errorf("strategy %s has history status %q, want stopped", id, stopped[id])
+		}
+		if _, running := runner.TradeStore(id); running {
+			t.Errorf("strategy %s still running after shutdown", id)
+		}
+	}
+}
+
+func TestStrategyErrorsStreamToSubscribers(t *testing.T) {
+	h := NewStrategyErrorsHandler(nil)
+	server := newTestServer(t, map[string]MessageHandler{"strategy_errors": h})
+	h.hub = server.hub
+
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := strategy.NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	runner.OnError(h.OnStrategyError)
+	failing, _ := strategies.CreateStrategy("martingale", map[string]interface{}{
+		"symbol": "AAPL", "base_position": 1.0, "take_profit": 5.0, "stop_loss": 5.0, "max_positions": 3.0,
+	})
+	ticks := make(chan *models.Tick)
+	if err := runner.Start(failing, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer runner.Stop(failing)
+
+	all := server.dial(t)
+	all.subscribe("strategy_errors", nil)
+	mine := server.dial(t)
+	mine.subscribe("strategy_errors", map[string]interface{}{"strategy_id": failing.ID})
+	other := server.dial(t)
+	other.subscribe("strategy_errors", map[string]interface{}{"strategy_id": "martingale-other"})
+
+	// Martingale rejects a zero price
+	ticks <- &models.Tick{Symbol: "AAPL", Price: 0, Timestamp: time.Now()}
+
+	for name, client := range map[string]*testClient{"unfiltered": all, "filtered to the strategy": mine} {
+		var event models.StrategyErrorEvent
+		client.decode(client.next(), &event)
+		if event.StrategyID != failing.ID || !strings.Contains(event.Error, "invalid tick price") || event.Timestamp.IsZero() {
+			t.Errorf("%s subscriber got %+v, want the strategy's tick price error", name, event)
+		}
+	}
+	other.expectNone(100 * time.Millisecond)
+}
+
+func TestStopAllWithFlattenStopsAndClosesEverything(t *testing.T) {
+	server := newTestServer(t, nil)
+	strategies := memory.NewInMemoryStrategyStore()
+	trades := memory.NewInMemoryTradeStore()
+	prices := memory.NewInMemoryPriceCache()
+	runner := strategy.NewDefaultRunner(strategies, trades)
+	h := NewStrategyHandler(strategies, runner,
+		NewTickHandler(server.hub, nil, prices), server.hub,
+		NewActiveStrategiesHandler(strategies, server.hub, 0), NewStrategyHistoryHandler(strategies, server.hub))
+	h.SetFlattenSource(trades, prices)
+
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		body, _ := json.Marshal(models.StartStrategyRequest{
+			Name:       "repeat",
+			Parameters: map[string]interface{}{"symbol": symbol, "exit_price": 500.0},
+		})
+		rec := httptest.NewRecorder()
+		h.HandleStart(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/start", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("start: status %d (%s), want 200", rec.Code, rec.Body)
+		}
+		prices.UpdatePrice(&models.Tick{Symbol: symbol, Price: 120, Timestamp: time.Now()})
+		trades.CreateTrade(symbol, 100, 1, models.SideBuy, "")
+	}
+
+	stopAll := func() models.StopAllResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.HandleStopAll(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/stop-all?flatten=true", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("stop-all: status %d (%s), want 200", rec.Code, rec.Body)
+		}
+		var resp models.StopAllResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return resp
+	}
+
+	resp := stopAll()
+	if len(resp.StoppedStrategies) != 2 || len(resp.ClosedTrades) != 2 || len(resp.Errors) != 0 {
+		t.Errorf("stop-all summary %+v, want 2 strategies stopped and 2 trades closed", resp)
+	}
+	if active, _ := strategies.GetActiveStrategies(); len(active) != 0 {
+		t.Errorf("%d strategies active after stop-all, want 0", len(active))
+	}
+	if open, _ := trades.GetOpenTrades(); len(open) != 0 {
+		t.Errorf("%d trades open after stop-all, want 0", len(open))
+	}
+	history, _ := trades.GetTradeHistory()
+	for _, trade := range history {
+		if trade.ExitPrice != 120 || trade.CloseReason != models.CloseReasonStopAll {
+			t.Errorf("trade %s closed at %v (%q), want the latest price by stop-all", trade.ID, trade.ExitPrice, trade.CloseReason)
+		}
+	}
+
+	// Again: nothing left to do
+	if resp := stopAll(); len(resp.StoppedStrategies) != 0 || len(resp.ClosedTrades) != 0 || len(resp.Errors) != 0 {
+		t.Errorf("second stop-all summary %+v, want nothing stopped or closed", resp)
+	}
+}
+
+func TestStopRunningStrategy(t *testing.T) {
+	for name, open := range map[string]func(t *testing.T) store.StrategyStore{
+		"memory": func(t *testing.T) store.StrategyStore { return memory.NewInMemoryStrategyStore() },
+		"sqlite": func(t *testing.T) store.StrategyStore {
+			db, err := sqlite.Open(":memory:")
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+			return sqlite.NewStrategyStore(db)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Both stores hand out copies, so the strategy read before
+			// stopping it never sees its stop time
+			server := newTestServer(t, nil)
+			strategies := open(t)
+			prices := memory.NewInMemoryPriceCache()
+			runner := strategy.NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+			h := NewStrategyHandler(strategies, runner,
+				NewTickHandler(server.hub, nil, prices), server.hub,
+				NewActiveStrategiesHandler(strategies, server.hub, 0), NewStrategyHistoryHandler(strategies, server.hub))
+
+			body, _ := json.Marshal(models.StartStrategyRequest{
+				Name:       "repeat",
+				Parameters: map[string]interface{}{"symbol": "AAPL", "exit_price": 110.0},
+			})
+			rec := httptest.NewRecorder()
+			h.HandleStart(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/start", bytes.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("start: status %d (%s), want 200", rec.Code, rec.Body)
+			}
+			active, _ := strategies.GetActiveStrategies()
+			if len(active) != 1 {
+				t.Fatalf("%d active strategies after start, want 1", len(active))
+			}
+
+			body, _ = json.Marshal(models.StopStrategyRequest{ID: active[0].ID})
+			rec = httptest.NewRecorder()
+			h.HandleStop(rec, httptest.NewRequest(http.MethodPost, "/api/strategies/stop", bytes.NewReader(body)))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("stop: status %d (%s), want 200", rec.Code, rec.Body)
+			}
+			var resp models.StopStrategyResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.ID != active[0].ID || resp.Status != "stopped" || resp.StopTime.IsZero() {
+				t.Errorf("stop response %+v, want the strategy stopped with a stop time", resp)
+			}
+			if _, running := runner.TradeStore(resp.ID); running {
+				t.Error("strategy still running after stop")
+			}
+		})
+	}
+}
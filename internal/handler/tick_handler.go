@@ -2,11 +2,14 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/aumbhatt/auto_trade/internal/models"
 	"github.com/aumbhatt/auto_trade/internal/source"
+	"github.com/aumbhatt/auto_trade/internal/store"
 	"github.com/aumbhatt/auto_trade/internal/websocket"
 )
 
@@ -21,34 +24,58 @@ TickHandler Flow:
    └── done: chan struct{}       // For graceful shutdown
    └── running: bool             // Handler state
    └── tickDelay: time.Duration  // Interval between ticks
+   └── samplers: map[string]*tickSampler // Per-subscription rate samplers
+   └── replays: map[string]*tickReplay // Subscriptions waiting for their replay
+   └── history: []*Tick          // Recent ticks (replayable sources only)
+   └── recent: map[string][]*Tick // Last ticks per symbol for initial bursts
+   └── priceCache: PriceCache    // Latest price per symbol
+   └── sourceErr: error          // Error of the latest GetTick (nil = healthy)
 
 2. Subscription Flow:
    Client → WebSocket → Registry → TickHandler
    a. Client sends subscribe request for "ticks"
    b. Registry routes to TickHandler.HandleSubscribe
    c. TickHandler adds subscription ID to subs map
-   d. Client receives subscription confirmation
+   d. If an "interval_ms" option (50-60000) is given, the subscription
+      samples the live feed at that rate instead of receiving every tick:
+      each interval it gets the latest tick of every symbol that ticked
+      since its previous send (nothing if none did). The source is only
+      ever pulled by the shared tickDelay ticker, so sampling never takes
+      ticks from the price cache, strategies or the other subscribers;
+      intervals shorter than tickDelay could not be honoured and are
+      rejected (set the feed rate with SetTickInterval)
+   e. Client receives subscription confirmation
+   f. If a "since" option is given and the source is replayable,
+      retained ticks newer than "since" are replayed, then live ticks follow
+      (HandleSubscribed, after the confirmation)
+   g. Otherwise, if a "history" count is given, the last N ticks (across
+      all symbols) are sent, then live ticks follow
+   h. A replay sends at most maxTickReplay (128) ticks, the newest ones, so
+      it fits in the client's send queue; a client whose first replayed
+      tick is not the one after its cursor missed the older ticks
 
 3. Data Flow:
    TickSource → TickHandler → Hub → Subscribers
    a. Ticker triggers every tickDelay
    b. TickHandler calls source.GetTick()
-   c. For each subscribeID in subs map:
-      - Creates Message with tick data
-      - Adds subscribeID to Message
-      - Broadcasts via Hub
+   c. Latest price is recorded in the price cache
+   d. For each subscribeID in subs map:
+      - With a sampler: keeps the tick as its symbol's latest unsent tick
+      - Otherwise: creates Message with tick data, adds subscribeID to
+        Message and broadcasts via Hub
 
 4. Unsubscribe Flow:
    Client → WebSocket → Registry → TickHandler
    a. Client sends unsubscribe request
    b. Registry routes to TickHandler.HandleUnsubscribe
-   c. TickHandler removes subscription ID from subs map
+   c. TickHandler removes subscription ID from subs map and stops its
+      sampler, if any
    d. Client stops receiving tick messages
 
 5. Shutdown Flow:
    a. Stop() is called
-   b. done channel is closed
-   c. Ticker goroutine exits
+   b. done channel and sampler stop channels are closed
+   c. Ticker goroutines exit
    d. Resources are cleaned up
 
 Example Message Flow:
@@ -71,30 +98,104 @@ Example Message Flow:
 3. Unsubscribe:
    → Client: {"type": "unsubscribe", "payload": {"subscribe_id": "uuid1"}}
    ← Server: {"type": "unsubscribe_response", "subscribe_id": "uuid1", "status": "success"}
+
+4. Resume After Reconnect (replayable sources):
+   → Client: {"type": "subscribe", "payload": {"type": "ticks", "options": {"since": "2025-01-23T11:34:23Z"}}}
+   ← Server: subscribe_response, then every retained tick with timestamp
+     after "since" (the newest 128 at most), then live ticks
+
+5. Initial Burst for Charts:
+   → Client: {"type": "subscribe", "payload": {"type": "ticks", "options": {"history": 10}}}
+   ← Server: subscribe_response, the last 10 ticks (oldest first), then live ticks
+
+6. Custom Rate:
+   → Client: {"type": "subscribe", "payload": {"type": "ticks", "options": {"interval_ms": 250}}}
+   ← Server: every 250ms, the latest tick of each symbol that ticked since
+     the previous send, for this subscription only
 */
 
+// defaultTickHistorySize is the number of recent ticks retained for resumption
+const defaultTickHistorySize = 1000
+
+// recentTicksPerSymbol caps the per-symbol buffer used for initial bursts
+const recentTicksPerSymbol = 100
+
+// Bounds of the per-subscription "interval_ms" option
+const (
+	minTickInterval = 50 * time.Millisecond
+	maxTickInterval = 60 * time.Second
+)
+
+// maxTickReplay caps the ticks replayed to a subscription ("since" or
+// "history") at half a client's send queue, so a replay never gets the
+// client dropped as a slow consumer
+const maxTickReplay = websocket.ClientSendBuffer / 2
+
+// tickReplay is a subscription waiting for its replay (see HandleSubscribed)
+type tickReplay struct {
+	since    time.Time
+	hasSince bool
+	history  int
+	interval time.Duration
+}
+
+// tickSampler conflates the live feed for an interval_ms subscription
+type tickSampler struct {
+	stop    chan struct{}
+	pending map[string]*models.Tick // symbol -> latest tick not yet sent (guarded by h.mutex)
+}
+
+// strategyTickBuffer lets a busy strategy fall briefly behind the live feed
+// before ticks are dropped for it
+const strategyTickBuffer = 64
+
 // TickHandler handles tick message subscriptions and broadcasting
 type TickHandler struct {
 	hub              *websocket.Hub
 	source           source.TickSource
-	subs             map[string]struct{} // Map of subscribeID to empty struct (set implementation)
+	subs             map[string]struct{}     // Map of subscribeID to empty struct (set implementation)
+	samplers         map[string]*tickSampler // subscribeID -> sampler of an interval_ms subscription
+	replays          map[string]*tickReplay  // subscribeID -> replay sent once subscribed
 	mutex            sync.RWMutex
 	done             chan struct{}
 	running          bool
-	tickDelay        time.Duration // Delay between ticks
+	tickDelay        time.Duration                // Delay between ticks
 	strategyChannels map[string]chan *models.Tick // strategyID -> tick channel
 	strategyMutex    sync.RWMutex
+	history          []*models.Tick            // Recent ticks, oldest first (guarded by mutex)
+	historySize      int                       // Max retained ticks; 0 disables resumption
+	recent           map[string][]*models.Tick // symbol -> recent ticks, oldest first (guarded by mutex)
+	priceCache       store.PriceCache
+	sourceErr        error // Error of the latest GetTick call (guarded by sourceMu)
+	sourceMu         sync.Mutex
 }
 
 // NewTickHandler creates a new TickHandler instance
-func NewTickHandler(hub *websocket.Hub, source source.TickSource) *TickHandler {
-	return &TickHandler{
+func NewTickHandler(hub *websocket.Hub, tickSource source.TickSource, priceCache store.PriceCache) *TickHandler {
+	h := &TickHandler{
 		hub:              hub,
-		source:           source,
+		source:           tickSource,
+		priceCache:       priceCache,
 		subs:             make(map[string]struct{}),
+		samplers:         make(map[string]*tickSampler),
+		replays:          make(map[string]*tickReplay),
+		recent:           make(map[string][]*models.Tick),
 		tickDelay:        time.Second, // Default to 1 second between ticks
 		strategyChannels: make(map[string]chan *models.Tick),
 	}
+
+	// Only retain history when the source's timestamps can be resumed from
+	if replayable, ok := tickSource.(source.ReplayableSource); ok && replayable.Replayable() {
+		h.historySize = defaultTickHistorySize
+		h.history = make([]*models.Tick, 0, defaultTickHistorySize)
+	}
+	return h
+}
+
+// SetTickInterval sets how often the source is pulled, which is the fastest
+// rate an interval_ms subscription can get; call before Start
+func (h *TickHandler) SetTickInterval(interval time.Duration) {
+	h.tickDelay = interval
 }
 
 // AddStrategy creates and returns a new tick channel for a strategy
@@ -102,7 +203,7 @@ func (h *TickHandler) AddStrategy(strategyID string) chan *models.Tick {
 	h.strategyMutex.Lock()
 	defer h.strategyMutex.Unlock()
 
-	ch := make(chan *models.Tick)
+	ch := make(chan *models.Tick, strategyTickBuffer)
 	h.strategyChannels[strategyID] = ch
 	return ch
 }
@@ -119,20 +220,267 @@ func (h *TickHandler) RemoveStrategy(strategyID string) {
 }
 
 // HandleSubscribe adds a new subscription
+// Options:
+//   - "since": RFC3339 cursor; replays retained ticks newer than it before
+//     live ticks (no-op for non-replayable sources)
+//   - "history": send the last N ticks before live ticks
+//   - "interval_ms": sample the live feed at this rate
 func (h *TickHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
+	since, hasSince, err := parseSinceOption(options)
+	if err != nil {
+		return err
+	}
+	history, err := parseHistoryOption(options)
+	if err != nil {
+		return err
+	}
+	interval, err := parseIntervalOption(options)
+	if err != nil {
+		return err
+	}
+	if interval > 0 && interval < h.tickDelay {
+		return fmt.Errorf("invalid interval_ms option: must be at least the feed's tick interval of %d", h.tickDelay.Milliseconds())
+	}
+
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
-	h.subs[subscribeID] = struct{}{}
+	// Replaying subscriptions join the live feed once their
+	// subscribe_response is out (HandleSubscribed)
+	if hasSince || history > 0 {
+		h.replays[subscribeID] = &tickReplay{
+			since:    since,
+			hasSince: hasSince,
+			history:  history,
+			interval: interval,
+		}
+		return nil
+	}
+
+	h.join(subscribeID, interval)
 	return nil
 }
 
+// HandleSubscribed implements SubscribedHandler: a "since" or "history"
+// subscription gets its replay, then joins the live feed
+func (h *TickHandler) HandleSubscribed(subscribeID string) {
+	// Holding the lock while replaying keeps replayed and live ticks in order
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	replay, pending := h.replays[subscribeID]
+	if !pending {
+		return
+	}
+	delete(h.replays, subscribeID)
+
+	var ticks []*models.Tick
+	if replay.hasSince {
+		for _, tick := range h.history {
+			if tick.Timestamp.After(replay.since) {
+				ticks = append(ticks, tick)
+			}
+		}
+	} else {
+		// The newest N across symbols are among the newest N of each
+		ticks = h.recentTicks(nil, replay.history)
+		if len(ticks) > replay.history {
+			ticks = ticks[len(ticks)-replay.history:]
+		}
+	}
+
+	// Keep the newest ticks that fit comfortably in the client's queue
+	if len(ticks) > maxTickReplay {
+		ticks = ticks[len(ticks)-maxTickReplay:]
+	}
+	for _, tick := range ticks {
+		h.hub.Broadcast(websocket.Message{
+			Type:        "ticks",
+			SubscribeID: subscribeID,
+			Payload:     tick,
+		})
+	}
+
+	h.join(subscribeID, replay.interval)
+}
+
+// join adds a subscription to the live feed
+// Caller must hold h.mutex
+func (h *TickHandler) join(subscribeID string, interval time.Duration) {
+	h.subs[subscribeID] = struct{}{}
+	if interval > 0 {
+		sampler := &tickSampler{
+			stop:    make(chan struct{}),
+			pending: make(map[string]*models.Tick),
+		}
+		h.samplers[subscribeID] = sampler
+		go h.runSampler(subscribeID, interval, sampler)
+	}
+}
+
+// runSampler sends an interval_ms subscription the latest tick of each
+// symbol that ticked since its previous send, once per interval
+func (h *TickHandler) runSampler(subscribeID string, interval time.Duration, sampler *tickSampler) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sampler.stop:
+			return
+		case <-ticker.C:
+			h.mutex.Lock()
+			ticks := make([]*models.Tick, 0, len(sampler.pending))
+			for symbol, tick := range sampler.pending {
+				ticks = append(ticks, tick)
+				delete(sampler.pending, symbol)
+			}
+			sort.Slice(ticks, func(i, j int) bool {
+				return ticks[i].Timestamp.Before(ticks[j].Timestamp)
+			})
+			for _, tick := range ticks {
+				h.hub.Broadcast(websocket.Message{
+					Type:        "ticks",
+					SubscribeID: subscribeID,
+					Payload:     tick,
+				})
+			}
+			h.mutex.Unlock()
+		}
+	}
+}
+
+// RecentTicks returns all retained recent ticks for the given symbols (all
+// symbols if none are given), oldest first; used for strategy warmup
+func (h *TickHandler) RecentTicks(symbols []string) []*models.Tick {
+	var filter map[string]struct{}
+	if len(symbols) > 0 {
+		filter = make(map[string]struct{}, len(symbols))
+		for _, symbol := range symbols {
+			filter[symbol] = struct{}{}
+		}
+	}
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.recentTicks(filter, recentTicksPerSymbol)
+}
+
+// recentTicks returns up to count recent ticks per symbol, oldest first
+// Caller must hold h.mutex
+func (h *TickHandler) recentTicks(symbols map[string]struct{}, count int) []*models.Tick {
+	var ticks []*models.Tick
+	for symbol, buffer := range h.recent {
+		if !matchesSymbols(symbols, symbol) {
+			continue
+		}
+		if len(buffer) > count {
+			buffer = buffer[len(buffer)-count:]
+		}
+		ticks = append(ticks, buffer...)
+	}
+	sort.SliceStable(ticks, func(i, j int) bool {
+		return ticks[i].Timestamp.Before(ticks[j].Timestamp)
+	})
+	return ticks
+}
+
+// matchesSymbols reports whether a symbol passes a filter (nil = all symbols)
+func matchesSymbols(symbols map[string]struct{}, symbol string) bool {
+	if symbols == nil {
+		return true
+	}
+	_, ok := symbols[symbol]
+	return ok
+}
+
+// parseHistoryOption extracts the initial burst size from subscribe options
+// Larger counts are capped at recentTicksPerSymbol
+func parseHistoryOption(options map[string]interface{}) (int, error) {
+	raw, ok := options["history"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+
+	count, ok := raw.(float64)
+	if !ok || count < 0 || count != float64(int(count)) {
+		return 0, fmt.Errorf("invalid history option: must be a non-negative whole number")
+	}
+	if count > recentTicksPerSymbol {
+		count = recentTicksPerSymbol
+	}
+	return int(count), nil
+}
+
+// parseIntervalOption extracts the per-subscription tick rate from subscribe options
+// Returns 0 when the option is absent (use the shared ticker)
+func parseIntervalOption(options map[string]interface{}) (time.Duration, error) {
+	raw, ok := options["interval_ms"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+
+	ms, ok := raw.(float64)
+	if !ok || ms != float64(int64(ms)) {
+		return 0, fmt.Errorf("invalid interval_ms option: must be a whole number of milliseconds")
+	}
+	interval := time.Duration(ms) * time.Millisecond
+	if interval < minTickInterval || interval > maxTickInterval {
+		return 0, fmt.Errorf("invalid interval_ms option: must be between %d and %d", minTickInterval.Milliseconds(), maxTickInterval.Milliseconds())
+	}
+	return interval, nil
+}
+
+// parseSinceOption extracts the "since" resume cursor from subscribe options
+func parseSinceOption(options map[string]interface{}) (time.Time, bool, error) {
+	raw, ok := options["since"]
+	if !ok || raw == nil {
+		return time.Time{}, false, nil
+	}
+
+	str, ok := raw.(string)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("invalid since option: must be an RFC3339 timestamp string")
+	}
+
+	since, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid since option: %v", err)
+	}
+	return since, true, nil
+}
+
+// recordTick appends a tick to the retained buffers, dropping the oldest
+// Caller must hold h.mutex
+func (h *TickHandler) recordTick(tick *models.Tick) {
+	buffer := h.recent[tick.Symbol]
+	if len(buffer) == recentTicksPerSymbol {
+		copy(buffer, buffer[1:])
+		buffer = buffer[:len(buffer)-1]
+	}
+	h.recent[tick.Symbol] = append(buffer, tick)
+
+	if h.historySize == 0 {
+		return
+	}
+	if len(h.history) == h.historySize {
+		copy(h.history, h.history[1:])
+		h.history = h.history[:len(h.history)-1]
+	}
+	h.history = append(h.history, tick)
+}
+
 // HandleUnsubscribe removes a subscription
 func (h *TickHandler) HandleUnsubscribe(subscribeID string) error {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
 	delete(h.subs, subscribeID)
+	delete(h.replays, subscribeID)
+	if sampler, exists := h.samplers[subscribeID]; exists {
+		close(sampler.stop)
+		delete(h.samplers, subscribeID)
+	}
 	return nil
 }
 
@@ -170,21 +518,53 @@ func (h *TickHandler) Stop() error {
 
 	close(h.done)
 	h.running = false
+
+	h.mutex.Lock()
+	for subscribeID, sampler := range h.samplers {
+		close(sampler.stop)
+		delete(h.samplers, subscribeID)
+	}
+	h.mutex.Unlock()
 	return nil
 }
 
+// nextTick pulls a tick from the source and records whether it succeeded
+func (h *TickHandler) nextTick() (*models.Tick, error) {
+	tick, err := h.source.GetTick()
+	h.sourceMu.Lock()
+	h.sourceErr = err
+	h.sourceMu.Unlock()
+	return tick, err
+}
+
+// SourceError returns the error of the latest GetTick call, or nil if it
+// succeeded (or no tick has been pulled yet)
+func (h *TickHandler) SourceError() error {
+	h.sourceMu.Lock()
+	defer h.sourceMu.Unlock()
+	return h.sourceErr
+}
+
 // processTick gets a new tick and broadcasts it to subscribers
 func (h *TickHandler) processTick() {
-	tick, err := h.source.GetTick()
+	tick, err := h.nextTick()
 	if err != nil {
 		// Log error or handle it appropriately
 		return
 	}
 
-	// Send to WebSocket subscribers
-	h.mutex.RLock()
+	// Keep the latest price available to request handlers
+	h.priceCache.UpdatePrice(tick)
+
+	// Record and send to WebSocket subscribers
+	h.mutex.Lock()
+	h.recordTick(tick)
 	if len(h.subs) > 0 {
 		for subID := range h.subs {
+			if sampler, sampled := h.samplers[subID]; sampled {
+				sampler.pending[tick.Symbol] = tick
+				continue
+			}
 			msg := websocket.Message{
 				Type:        "ticks",
 				SubscribeID: subID,
@@ -193,7 +573,7 @@ func (h *TickHandler) processTick() {
 			h.hub.Broadcast(msg)
 		}
 	}
-	h.mutex.RUnlock()
+	h.mutex.Unlock()
 
 	// Send to strategies
 	h.strategyMutex.RLock()
@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+	"github.com/aumbhatt/auto_trade/internal/strategy"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+// tickBase is the timestamp of the first test tick
+var tickBase = time.Date(2025, 1, 23, 11, 0, 0, 0, time.UTC)
+
+// sliceSource returns its ticks in order, then errors
+type sliceSource struct {
+	mu         sync.Mutex
+	ticks      []*models.Tick
+	replayable bool
+}
+
+func (s *sliceSource) GetTick() (*models.Tick, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.ticks) == 0 {
+		return nil, errors.New("no more ticks")
+	}
+	tick := s.ticks[0]
+	s.ticks = s.ticks[1:]
+	return tick, nil
+}
+
+func (s *sliceSource) Replayable() bool {
+	return s.replayable
+}
+
+// testTicks returns n one-second-apart ticks cycling through the symbols,
+// priced by their index
+func testTicks(n int, symbols ...string) []*models.Tick {
+	ticks := make([]*models.Tick, n)
+	for i := range ticks {
+		ticks[i] = &models.Tick{
+			Symbol:    symbols[i%len(symbols)],
+			Price:     float64(100 + i),
+			Timestamp: tickBase.Add(time.Duration(i) * time.Second),
+		}
+	}
+	return ticks
+}
+
+// newTestTickHandler serves a tick handler over the source, with no ticker
+// running; tests pull ticks with processTick
+func newTestTickHandler(t *testing.T, src *sliceSource) (*TickHandler, *testServer) {
+	t.Helper()
+	h := NewTickHandler(nil, src, memory.NewInMemoryPriceCache())
+	server := newTestServer(t, map[string]MessageHandler{"ticks": h})
+	h.hub = server.hub
+	return h, server
+}
+
+// readTicks reads n tick messages
+func readTicks(c *testClient, n int) []models.Tick {
+	c.t.Helper()
+	ticks := make([]models.Tick, 0, n)
+	for len(ticks) < n {
+		msg := c.next()
+		if msg.Type != "ticks" {
+			c.t.Fatalf("got %s message, want ticks", msg.Type)
+		}
+		var tick models.Tick
+		c.decode(msg, &tick)
+		ticks = append(ticks, tick)
+	}
+	return ticks
+}
+
+func TestTickSinceReplaysOnlyNewerTicks(t *testing.T) {
+	src := &sliceSource{ticks: testTicks(10, "AAPL"), replayable: true}
+	h, server := newTestTickHandler(t, src)
+	for i := 0; i < 10; i++ {
+		h.processTick()
+	}
+
+	client := server.dial(t)
+	since := tickBase.Add(4 * time.Second)
+	client.subscribe("ticks", map[string]interface{}{"since": since.Format(time.RFC3339)})
+
+	ticks := readTicks(client, 5)
+	for i, tick := range ticks {
+		if want := tickBase.Add(time.Duration(5+i) * time.Second); !tick.Timestamp.Equal(want) {
+			t.Errorf("replayed tick %d at %v, want %v", i, tick.Timestamp, want)
+		}
+	}
+	client.expectNone(100 * time.Millisecond)
+}
+
+func TestTickSinceReplayIsCapped(t *testing.T) {
+	src := &sliceSource{ticks: testTicks(500, "AAPL"), replayable: true}
+	h, server := newTestTickHandler(t, src)
+	for i := 0; i < 500; i++ {
+		h.processTick()
+	}
+
+	client := server.dial(t)
+	client.subscribe("ticks", map[string]interface{}{"since": tickBase.Add(-time.Second).Format(time.RFC3339)})
+
+	ticks := readTicks(client, maxTickReplay)
+	if first := ticks[0].Price; first != float64(100+500-maxTickReplay) {
+		t.Errorf("first replayed price %v, want the newest %d ticks", first, maxTickReplay)
+	}
+	if last := ticks[len(ticks)-1].Price; last != 599 {
+		t.Errorf("last replayed price %v, want 599", last)
+	}
+	client.expectNone(100 * time.Millisecond)
+}
+
+func TestTickSinceIsNoOpForRandomSources(t *testing.T) {
+	src := &sliceSource{ticks: testTicks(5, "AAPL")}
+	h, server := newTestTickHandler(t, src)
+	for i := 0; i < 3; i++ {
+		h.processTick()
+	}
+
+	client := server.dial(t)
+	client.subscribe("ticks", map[string]interface{}{"since": tickBase.Add(-time.Second).Format(time.RFC3339)})
+	client.expectNone(100 * time.Millisecond)
+
+	// Live ticks follow the (empty) replay
+	h.processTick()
+	if tick := readTicks(client, 1)[0]; tick.Price != 103 {
+		t.Errorf("live tick price %v, want 103", tick.Price)
+	}
+}
+
+func TestTickHistorySendsLastTicksBeforeLive(t *testing.T) {
+	src := &sliceSource{ticks: testTicks(31, "AAPL", "MSFT", "GOOG")}
+	h, server := newTestTickHandler(t, src)
+	for i := 0; i < 30; i++ {
+		h.processTick()
+	}
+
+	client := server.dial(t)
+	client.subscribe("ticks", map[string]interface{}{"history": 10})
+
+	// The ten newest ticks across all symbols, oldest first
+	for i, tick := range readTicks(client, 10) {
+		if want := float64(120 + i); tick.Price != want {
+			t.Errorf("history tick %d has price %v, want %v", i, tick.Price, want)
+		}
+	}
+
+	h.processTick()
+	if tick := readTicks(client, 1)[0]; tick.Price != 130 {
+		t.Errorf("first live tick has price %v, want 130", tick.Price)
+	}
+}
+
+// countingSource returns an endless AAPL series priced 100, 101, ...
+type countingSource struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (s *countingSource) GetTick() (*models.Tick, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tick := &models.Tick{
+		Symbol:    "AAPL",
+		Price:     float64(100 + s.n),
+		Timestamp: tickBase.Add(time.Duration(s.n) * time.Millisecond),
+	}
+	s.n++
+	return tick, nil
+}
+
+func TestTickIntervalSamplesLiveFeed(t *testing.T) {
+	h := NewTickHandler(nil, &countingSource{}, memory.NewInMemoryPriceCache())
+	h.SetTickInterval(10 * time.Millisecond)
+	server := newTestServer(t, map[string]MessageHandler{"ticks": h})
+	h.hub = server.hub
+
+	client := server.dial(t)
+	fast := client.subscribe("ticks", map[string]interface{}{"interval_ms": 250})
+	slow := client.subscribe("ticks", map[string]interface{}{"interval_ms": 1000})
+	all := client.subscribe("ticks", nil)
+
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	counts := make(map[string]int)
+	var prices []float64
+	deadline := time.After(2100 * time.Millisecond)
+	for done := false; !done; {
+		select {
+		case msg := <-client.messages:
+			counts[msg.SubscribeID]++
+			if msg.SubscribeID == all {
+				var tick models.Tick
+				client.decode(msg, &tick)
+				prices = append(prices, tick.Price)
+			}
+		case <-deadline:
+			done = true
+		}
+	}
+
+	if counts[slow] < 1 || counts[fast] < 3*counts[slow] || counts[fast] > 5*counts[slow] {
+		t.Errorf("250ms subscription got %d ticks and 1s subscription %d, want about 4x", counts[fast], counts[slow])
+	}
+
+	// Sampling never pulls the source, so the full feed has no gaps
+	for i := 1; i < len(prices); i++ {
+		if prices[i] != prices[i-1]+1 {
+			t.Fatalf("full feed skipped from %v to %v", prices[i-1], prices[i])
+		}
+	}
+	if len(prices) < 50 {
+		t.Errorf("full feed got %d ticks, want one per 10ms", len(prices))
+	}
+}
+
+func TestTickIntervalBelowFeedRateRejected(t *testing.T) {
+	h := NewTickHandler(nil, &countingSource{}, memory.NewInMemoryPriceCache())
+	h.SetTickInterval(500 * time.Millisecond)
+
+	err := h.HandleSubscribe("sub-fast", map[string]interface{}{"interval_ms": 250.0})
+	if err == nil || !strings.Contains(err.Error(), "tick interval of 500") {
+		t.Errorf("250ms on a 500ms feed: got %v, want an error naming the feed's rate", err)
+	}
+	if err := h.HandleSubscribe("sub-slow", map[string]interface{}{"interval_ms": 500.0}); err != nil {
+		t.Errorf("interval equal to the feed rate rejected: %v", err)
+	}
+	h.HandleUnsubscribe("sub-slow")
+}
+
+func TestStrategyTradesOnLiveTick(t *testing.T) {
+	ticks := []*models.Tick{
+		{Symbol: "MSFT", Price: 300, Timestamp: tickBase},
+		{Symbol: "AAPL", Price: 150, Timestamp: tickBase.Add(time.Second)},
+	}
+	h, _ := newTestTickHandler(t, &sliceSource{ticks: ticks})
+
+	strategies := memory.NewInMemoryStrategyStore()
+	trades := memory.NewInMemoryTradeStore()
+	runner := strategy.NewDefaultRunner(strategies, trades)
+	instance, err := strategies.CreateStrategy("repeat", map[string]interface{}{"symbol": "AAPL", "exit_price": 200.0})
+	if err != nil {
+		t.Fatalf("CreateStrategy: %v", err)
+	}
+	if err := runner.Start(instance, h.AddStrategy(instance.ID)); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() {
+		runner.Stop(instance)
+		h.RemoveStrategy(instance.ID)
+	}()
+
+	h.processTick()
+	h.processTick()
+
+	// Only the AAPL tick reaches the strategy, which buys at its price
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		open, _ := trades.GetOpenTrades()
+		if len(open) > 0 {
+			if len(open) != 1 || open[0].Symbol != "AAPL" || open[0].EntryPrice != 150 || open[0].StrategyID != instance.ID {
+				t.Fatalf("open trades %+v, want one AAPL buy at 150 by the strategy", open)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("strategy did not trade on the live tick")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestInvalidTickIntervalRejected(t *testing.T) {
+	h := NewTickHandler(nil, &countingSource{}, memory.NewInMemoryPriceCache())
+	h.SetTickInterval(minTickInterval) // So the lower bound is reachable
+	server := newTestServer(t, map[string]MessageHandler{"ticks": h})
+	h.hub = server.hub
+	client := server.dial(t)
+
+	for _, interval := range []interface{}{49, 60001, 250.5, "250"} {
+		client.send(websocket.Message{
+			Type:    websocket.MessageTypeSubscribe,
+			Payload: websocket.SubscribeRequest{Type: "ticks", Options: map[string]interface{}{"interval_ms": interval}},
+		})
+		msg := client.next()
+		var errPayload map[string]string
+		client.decode(msg, &errPayload)
+		if msg.Type != websocket.MessageTypeError || !strings.Contains(errPayload["error"], "interval_ms") {
+			t.Errorf("interval_ms %v: got %s message %s, want an interval_ms error", interval, msg.Type, msg.Payload)
+		}
+	}
+
+	// The bounds themselves are accepted
+	client.subscribe("ticks", map[string]interface{}{"interval_ms": 50})
+	client.subscribe("ticks", map[string]interface{}{"interval_ms": 60000})
+}
+
+func TestDisconnectReleasesHandlerSubscriptions(t *testing.T) {
+	ticks := NewTickHandler(nil, &sliceSource{}, memory.NewInMemoryPriceCache())
+	positions := NewOpenPositionsHandler(memory.NewInMemoryTradeStore(), nil, memory.NewInMemoryPriceCache(), 0)
+	server := newTestServer(t, map[string]MessageHandler{"ticks": ticks, "open_positions": positions})
+	ticks.hub, positions.hub = server.hub, server.hub
+
+	counts := func() (int, int) {
+		ticks.mutex.RLock()
+		tickSubs := len(ticks.subs)
+		ticks.mutex.RUnlock()
+		positionSubs := 0
+		positions.subscriptions.Range(func(key, value interface{}) bool {
+			positionSubs++
+			return true
+		})
+		return tickSubs, positionSubs
+	}
+
+	client := server.dial(t)
+	client.subscribe("ticks", nil)
+	client.subscribe("open_positions", nil)
+	client.next() // Open positions snapshot
+	if tickSubs, positionSubs := counts(); tickSubs != 1 || positionSubs != 1 {
+		t.Fatalf("%d tick and %d open positions subscriptions, want 1 each", tickSubs, positionSubs)
+	}
+
+	client.conn.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		tickSubs, positionSubs := counts()
+		if tickSubs == 0 && positionSubs == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d tick and %d open positions subscriptions left after disconnect, want 0", tickSubs, positionSubs)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
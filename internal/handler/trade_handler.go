@@ -2,10 +2,17 @@ package handler
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
+	"math"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/aumbhatt/auto_trade/internal/analytics"
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/aumbhatt/auto_trade/internal/models"
 	"github.com/aumbhatt/auto_trade/internal/store"
 	"github.com/aumbhatt/auto_trade/internal/websocket"
@@ -17,7 +24,11 @@ Trade Handler Flow and Examples:
 1. Components and Event Flow:
    ├── TradeHandler: Main HTTP handler
    ├── OpenPositionsHandler: WebSocket handler for open trades
-   └── TradeHistoryHandler: WebSocket handler for trade history
+   ├── TradeHistoryHandler: WebSocket handler for trade history
+   └── FlatHandler: WebSocket handler for account-flat events
+
+   The WebSocket handlers register as trade store listeners in Start and
+   deregister in Stop, so recreated handlers never leave stale listeners.
 
    Event Processing:
    a. Trade Creation:
@@ -33,21 +44,35 @@ Trade Handler Flow and Examples:
       3. Store emits TradeClosed event
       4. Both handlers receive event
       5. Updates broadcast to WebSocket subscribers
+      6. FlatHandler broadcasts "flat" if no open trades remain
 
 2. REST Endpoints:
    a. Buy Trade (POST /api/trades/buy):
       Request:
       {
           "symbol": "AAPL",
-          "entry_price": 150.25
+          "side": "buy",             // Optional: "buy" (default) or "sell"
+          "entry_price": 150.25,
+          "quantity": 10,            // Optional: defaults to 1
+          "take_profit": 155.00,     // Optional: close at this level in profit
+          "stop_loss": 148.00        // Optional: close at this level at a loss
       }
 
+      With take_profit/stop_loss the trade is closed automatically (at the
+      level, close_reason "take_profit"/"stop_loss") by the first tick that
+      crosses it; levels must be on the correct side of the entry price
+      (INVALID_TAKE_PROFIT / INVALID_STOP_LOSS otherwise)
+
       Success Response: (200 OK)
       {
           "trade_id": "trade-abc123",
           "symbol": "AAPL",
+          "side": "buy",
+          "quantity": 10,
           "entry_price": 150.25,
-          "entry_time": "2025-01-23T14:23:38Z"
+          "entry_time": "2025-01-23T14:23:38Z",
+          "take_profit": 155.00,
+          "stop_loss": 148.00
       }
 
       Error Response: (400 Bad Request)
@@ -55,11 +80,17 @@ Trade Handler Flow and Examples:
           "code": "INVALID_SYMBOL",
           "message": "Invalid trading symbol: XYZ"
       }
+      Also 400 with INVALID_ENTRY_PRICE for a missing, zero or negative
+      entry_price.
+      409 Conflict with POSITION_LIMIT when the symbol or the store is at
+      its open trade limit, or RISK_LIMIT once the day's realized losses
+      reach the daily loss limit
 
    b. Sell Trade (POST /api/trades/sell):
       Request:
       {
-          "trade_id": "trade-abc123"
+          "trade_id": "trade-abc123",
+          "exit_price": 151.50       // Optional: defaults to the latest tick price
       }
 
       Success Response: (200 OK)
@@ -78,13 +109,69 @@ Trade Handler Flow and Examples:
           "message": "Trade not found: trade-abc123"
       }
 
+   c. Close by Symbol (POST /api/trades/close_symbol):
+      Closes open lots of a symbol in close-policy order (FIFO or LIFO by
+      entry time). A partly needed lot is split; its remainder stays open
+      under a new trade ID.
+      Request:
+      {
+          "symbol": "AAPL",
+          "quantity": 15,            // Optional: omit to close every lot
+          "exit_price": 151.25       // Optional: defaults to the latest tick price
+      }
+
+      Success Response: (200 OK)
+      [
+          {
+              "trade_id": "trade-abc123",
+              "symbol": "AAPL",
+              "side": "buy",
+              "quantity": 10,
+              "entry_price": 150.25,
+              "exit_price": 151.25,
+              "entry_time": "2025-01-23T14:23:38Z",
+              "exit_time": "2025-01-23T14:30:00Z"
+          },
+          ...
+      ]
+
+      Error Response: (404 Not Found)
+      {
+          "code": "NO_OPEN_LOTS",
+          "message": "No open lots for symbol: AAPL"
+      }
+
+   d. Get Trade (GET /api/trades/{id}):
+      Success Response: (200 OK)
+      The trade, open or closed, in the format above
+
+      Error Response: (404 Not Found)
+      {
+          "code": "TRADE_NOT_FOUND",
+          "message": "Trade not found: trade-abc123"
+      }
+
+   e. Open Exposure by Symbol (GET /api/trades/symbols):
+      Success Response: (200 OK)
+      [
+          {
+              "symbol": "AAPL",
+              "open_trades": 2,
+              "quantity": 2,
+              "net_side": "buy"
+          }
+      ]
+
 3. WebSocket Messages:
    a. Subscribe to Open Positions:
       Request:
       {
           "type": "subscribe",
           "payload": {
-              "type": "open_positions"
+              "type": "open_positions",
+              "options": {
+                  "mode": "diff"    // Optional: "snapshot" (default) or "diff"
+              }
           }
       }
 
@@ -96,12 +183,42 @@ Trade Handler Flow and Examples:
               {
                   "trade_id": "trade-abc123",
                   "symbol": "AAPL",
+                  "quantity": 10,
                   "entry_price": 150.25,
-                  "entry_time": "2025-01-23T14:23:38Z"
+                  "entry_time": "2025-01-23T14:23:38Z",
+                  "current_price": 151.00,   // Latest cached price
+                  "market_value": 1510.00    // quantity * current_price
               }
           ]
       }
 
+      Positions whose symbol has no cached price within marketValueMaxAge
+      omit current_price/market_value and carry "price_stale": true.
+      Values are computed when the payload is sent (on subscribe and on
+      trade events), not on every tick.
+      Trade events arriving within the batch window (SetBatchWindow) are
+      coalesced into one update carrying the latest open trades.
+
+      Snapshot subscribers receive the full list on every change. Diff
+      subscribers receive the full list once, then only the positions
+      opened, closed or changed (quantity, levels, price) since their last
+      update, keyed by trade ID:
+      {
+          "type": "open_positions",
+          "subscribe_id": "sub-123",
+          "payload": {
+              "seq": 1,
+              "added": [{"trade_id": "trade-new123", ...}],
+              "removed": ["trade-abc123"],
+              "updated": [{"trade_id": "trade-def456", ...}]
+          }
+      }
+      Updates with no changes are not sent. seq counts the diffs since the
+      last full list (1, 2, ...); a gap means a diff was lost, and the
+      client should resubscribe. When the hub drops a subscriber's
+      message (broadcast buffer full) its next update is the full list
+      again (array payload), after which seq restarts at 1.
+
       Error Response:
       {
           "type": "error",
@@ -116,9 +233,37 @@ Trade Handler Flow and Examples:
       {
           "type": "subscribe",
           "payload": {
-              "type": "trade_history"
+              "type": "trade_history",
+              "options": {
+                  "mode": "delta",                      // Optional: "snapshot" (default) or "delta"
+                  "symbol": "AAPL",                     // Optional: one symbol only
+                  "from": "2025-01-23T00:00:00Z",       // Optional: exit time >= from (RFC 3339)
+                  "to": "2025-01-24T00:00:00Z",         // Optional: exit time < to (RFC 3339)
+                  "limit": 50,                          // Optional: page size (default all)
+                  "offset": 0                           // Optional: trades to skip
+              }
+          }
+      }
+
+      Trades are sorted by exit time, newest first. Every update re-runs
+      the subscription's query, so a limited page always holds the newest
+      matching trades (delta subscribers see older ones as "removed").
+
+      Snapshot subscribers receive the full history on every change. Delta
+      subscribers receive the full history once, then only the changes:
+      {
+          "type": "trade_history",
+          "subscribe_id": "sub-456",
+          "payload": {
+              "added": [{"trade_id": "trade-new123", ...}],
+              "removed": []
           }
       }
+      Diffing means retaining each delta subscriber's last-known trade IDs.
+      To bound memory, once a subscriber's set would exceed the configured
+      maxDeltaRetention it is switched to snapshot mode for good: it gets a
+      full history (array payload) and full snapshots from then on, trading
+      bandwidth for bounded server memory.
 
       Success Response:
       {
@@ -144,6 +289,26 @@ Trade Handler Flow and Examples:
               "message": "No trade history found"
           }
       }
+
+   c. Subscribe to Flat Events:
+      Request:
+      {
+          "type": "subscribe",
+          "payload": {
+              "type": "flat"
+          }
+      }
+
+      Update (sent when the last open trade is closed):
+      {
+          "type": "flat",
+          "subscribe_id": "sub-789",
+          "payload": {
+              "last_trade_id": "trade-xyz789",
+              "symbol": "GOOGL",
+              "timestamp": "2025-01-23T14:00:00Z"
+          }
+      }
 */
 
 // TradeHandler handles trade-related requests
@@ -152,19 +317,18 @@ type TradeHandler struct {
 	hub               *websocket.Hub
 	openPosHandler    *OpenPositionsHandler
 	tradeHistHandler  *TradeHistoryHandler
+	priceCache        store.PriceCache
 }
 
 // NewTradeHandler creates a new TradeHandler instance
-func NewTradeHandler(store store.TradeStore, hub *websocket.Hub, openPosHandler *OpenPositionsHandler, tradeHistHandler *TradeHistoryHandler) *TradeHandler {
-	// Register handlers as trade event listeners
-	store.AddListener(openPosHandler)
-	store.AddListener(tradeHistHandler)
-
+// priceCache supplies the exit price when a close request omits one
+func NewTradeHandler(store store.TradeStore, hub *websocket.Hub, openPosHandler *OpenPositionsHandler, tradeHistHandler *TradeHistoryHandler, priceCache store.PriceCache) *TradeHandler {
 	return &TradeHandler{
 		store:             store,
 		hub:              hub,
 		openPosHandler:    openPosHandler,
 		tradeHistHandler:  tradeHistHandler,
+		priceCache:        priceCache,
 	}
 }
 
@@ -176,9 +340,33 @@ func (h *TradeHandler) HandleBuy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	trade, err := h.store.CreateTrade(req.Symbol, req.EntryPrice)
+	side := req.Side
+	if side == "" {
+		side = models.SideBuy
+	}
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = models.DefaultQuantity
+	}
+
+	var trade *models.Trade
+	var err error
+	if req.TakeProfit != 0 || req.StopLoss != 0 {
+		creator, ok := h.store.(store.ExitTradeCreator)
+		if !ok {
+			http.Error(w, "Take profit and stop loss not supported", http.StatusNotImplemented)
+			return
+		}
+		trade, err = creator.CreateTradeWithExits(req.Symbol, req.EntryPrice, quantity, side, "", req.TakeProfit, req.StopLoss)
+	} else {
+		trade, err = h.store.CreateTrade(req.Symbol, req.EntryPrice, quantity, side, "")
+	}
 	if err != nil {
 		if e, ok := err.(*models.TradeError); ok {
+			if e.Code == models.ErrPositionLimit || e.Code == models.ErrRiskLimit {
+				http.Error(w, e.Error(), http.StatusConflict)
+				return
+			}
 			http.Error(w, e.Error(), http.StatusBadRequest)
 			return
 		}
@@ -197,7 +385,21 @@ func (h *TradeHandler) HandleSell(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	trade, err := h.store.CloseTrade(req.TradeID)
+	// Fall back to the latest tick price for the trade's symbol; unknown
+	// trades are left to the store to report as not found
+	exitPrice := req.ExitPrice
+	if exitPrice == 0 {
+		if symbol, ok := h.openTradeSymbol(req.TradeID); ok {
+			price, err := h.latestPrice(symbol)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			exitPrice = price
+		}
+	}
+
+	trade, err := h.store.CloseTrade(req.TradeID, exitPrice, models.CloseReasonManual)
 	if err != nil {
 		if e, ok := err.(*models.TradeError); ok {
 			switch e.Code {
@@ -215,29 +417,212 @@ func (h *TradeHandler) HandleSell(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(trade)
 }
 
+// HandleCloseSymbol closes open lots of a symbol using the store's close policy
+func (h *TradeHandler) HandleCloseSymbol(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CloseSymbolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	closer, ok := h.store.(store.SymbolCloser)
+	if !ok {
+		http.Error(w, "Close by symbol not supported", http.StatusNotImplemented)
+		return
+	}
+
+	exitPrice := req.ExitPrice
+	if exitPrice == 0 {
+		price, err := h.latestPrice(req.Symbol)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		exitPrice = price
+	}
+
+	trades, err := closer.CloseSymbol(req.Symbol, req.Quantity, exitPrice, models.CloseReasonManual)
+	if err != nil {
+		if e, ok := err.(*models.TradeError); ok {
+			switch e.Code {
+			case models.ErrNoOpenLots:
+				http.Error(w, e.Error(), http.StatusNotFound)
+			default:
+				http.Error(w, e.Error(), http.StatusBadRequest)
+			}
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(trades)
+}
+
+// openTradeSymbol returns the symbol of an open trade
+func (h *TradeHandler) openTradeSymbol(tradeID string) (string, bool) {
+	trade, err := h.store.GetTradeByID(tradeID)
+	if err != nil || !trade.ExitTime.IsZero() {
+		return "", false
+	}
+	return trade.Symbol, true
+}
+
+// latestPrice returns the last known tick price for a symbol
+func (h *TradeHandler) latestPrice(symbol string) (float64, error) {
+	if h.priceCache != nil {
+		if tick, ok := h.priceCache.GetLatestTick(symbol); ok {
+			return tick.Price, nil
+		}
+	}
+	return 0, &models.TradeError{
+		Code:    models.ErrInvalidExitPrice,
+		Message: fmt.Sprintf("No exit price given and no price available for symbol: %s", symbol),
+	}
+}
+
+// HandleGetTrade returns a single trade, open or closed
+// Path: /api/trades/{id}
+func (h *TradeHandler) HandleGetTrade(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/trades/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	trade, err := h.store.GetTradeByID(id)
+	if err != nil {
+		if e, ok := err.(*models.TradeError); ok && e.Code == models.ErrTradeNotFound {
+			http.Error(w, e.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(trade)
+}
+
+// HandleSymbols returns the symbols with open exposure and their aggregates
+func (h *TradeHandler) HandleSymbols(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	trades, err := h.store.GetOpenTrades()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(analytics.SymbolExposure(trades))
+}
+
 // OpenPositionsHandler handles open positions subscriptions
 type OpenPositionsHandler struct {
 	store store.TradeStore
 	hub   *websocket.Hub
 	// Track subscriptions
-	subscriptions sync.Map // map[string]struct{} // subscribeID -> struct{}
+	subscriptions sync.Map // map[string]*positionsSubscriber // subscribeID -> state
 	subMutex     sync.RWMutex // Protects subscription operations
+	// Market valuation
+	priceCache  store.PriceCache
+	maxPriceAge time.Duration
+	clock       clock.Clock
+	// Optional source of TradeUpdated events (live marks)
+	marks store.TradeEventEmitter
+	// Coalesces trade events into broadcasts
+	updates *coalescer
+}
+
+// positionsSubscriber is the state of one open positions subscription
+type positionsSubscriber struct {
+	diff   bool                     // Send diffs instead of full snapshots
+	known  map[string]positionState // Positions last sent (diff mode only)
+	seq    uint64                   // Diffs sent since the last full snapshot
+	resync bool                     // A message was dropped; send a full snapshot next
+}
+
+// positionState is the part of a sent position a diff compares
+type positionState struct {
+	trade        models.Trade
+	currentPrice float64
+	priceStale   bool
 }
 
 // NewOpenPositionsHandler creates a new OpenPositionsHandler
-func NewOpenPositionsHandler(store store.TradeStore, hub *websocket.Hub) *OpenPositionsHandler {
-	return &OpenPositionsHandler{
-		store: store,
-		hub:   hub,
+// Positions are valued at priceCache prices no older than maxPriceAge (0 = any age)
+func NewOpenPositionsHandler(store store.TradeStore, hub *websocket.Hub, priceCache store.PriceCache, maxPriceAge time.Duration) *OpenPositionsHandler {
+	h := &OpenPositionsHandler{
+		store:       store,
+		hub:         hub,
+		priceCache:  priceCache,
+		maxPriceAge: maxPriceAge,
+		clock:       clock.Real{},
+	}
+	h.updates = newCoalescer(0, h.broadcastOpenTrades)
+	return h
+}
+
+// SetBatchWindow coalesces trade events arriving within window into a
+// single broadcast of the latest open trades; 0 broadcasts on every event.
+// Call before Start
+func (h *OpenPositionsHandler) SetBatchWindow(window time.Duration) {
+	h.updates = newCoalescer(window, h.broadcastOpenTrades)
+}
+
+// SetMarkSource re-broadcasts open positions whenever the emitter reports
+// a trade marked at a new price; call before Start
+func (h *OpenPositionsHandler) SetMarkSource(marks store.TradeEventEmitter) {
+	h.marks = marks
+}
+
+// valuePositions attaches the current price and market value to open trades
+func (h *OpenPositionsHandler) valuePositions(trades []*models.Trade) []*models.OpenPosition {
+	positions := make([]*models.OpenPosition, 0, len(trades))
+	now := h.clock.Now()
+	for _, trade := range trades {
+		position := &models.OpenPosition{Trade: trade}
+		tick, ok := h.priceCache.GetLatestTick(trade.Symbol)
+		if !ok || (h.maxPriceAge > 0 && now.Sub(tick.Timestamp) > h.maxPriceAge) {
+			position.PriceStale = true
+		} else {
+			price := tick.Price
+			value := trade.Quantity * price
+			pnl := trade.UnrealizedPnL(price)
+			position.CurrentPrice = &price
+			position.MarketValue = &value
+			position.UnrealizedPnL = &pnl
+		}
+		positions = append(positions, position)
 	}
+	return positions
 }
 
 // OnTradeEvent implements store.TradeEventListener
+// Creates, closes and mark updates all re-broadcast the open positions,
+// coalesced within the batch window
 func (h *OpenPositionsHandler) OnTradeEvent(event store.TradeEvent) {
+	h.updates.Trigger()
+}
+
+// broadcastOpenTrades broadcasts the store's current open trades
+func (h *OpenPositionsHandler) broadcastOpenTrades() {
 	// Get updated open trades list
 	trades, err := h.store.GetOpenTrades()
 	if err != nil {
-		log.Printf("Error getting open trades: %v", err)
+		logging.Errorf("Error getting open trades: %v", err)
 		return
 	}
 
@@ -246,23 +631,36 @@ func (h *OpenPositionsHandler) OnTradeEvent(event store.TradeEvent) {
 }
 
 // HandleSubscribe handles subscription requests
+// Options:
+//   - "mode": "snapshot" (default) or "diff"
 func (h *OpenPositionsHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
-	h.subMutex.Lock()
-	h.subscriptions.Store(subscribeID, struct{}{})
-	h.subMutex.Unlock()
+	diff, err := parsePositionsModeOption(options)
+	if err != nil {
+		return err
+	}
 
 	trades, err := h.store.GetOpenTrades()
 	if err != nil {
 		// Return empty list instead of error
 		trades = []*models.Trade{}
 	}
+	positions := h.valuePositions(trades)
+
+	sub := &positionsSubscriber{diff: diff}
+	if diff {
+		sub.known = positionStates(positions)
+	}
 
+	// Queue the snapshot under the lock, so no diff can overtake it
+	h.subMutex.Lock()
+	defer h.subMutex.Unlock()
+	h.subscriptions.Store(subscribeID, sub)
 	msg := websocket.Message{
 		Type:        "open_positions",
 		SubscribeID: subscribeID,
-		Payload:     trades,
+		Payload:     positions,
 	}
-	h.hub.Broadcast(msg)
+	sub.resync = !h.hub.Broadcast(msg)
 	return nil
 }
 
@@ -275,34 +673,119 @@ func (h *OpenPositionsHandler) HandleUnsubscribe(subscribeID string) error {
 }
 
 // BroadcastUpdate sends updates to all subscribers
+// Diff subscribers get only the changes since their last update, numbered
+// by seq; one whose last message was dropped gets a full snapshot instead
 func (h *OpenPositionsHandler) BroadcastUpdate(trades []*models.Trade) {
-	// Collect subscribers under read lock
-	h.subMutex.RLock()
-	subscribers := make([]string, 0)
+	positions := h.valuePositions(trades)
+
+	// Build and queue each subscriber's message under the lock, since it
+	// updates diff state and diffs must reach the hub in the order built
+	h.subMutex.Lock()
+	defer h.subMutex.Unlock()
 	h.subscriptions.Range(func(key, value interface{}) bool {
-		subscribers = append(subscribers, key.(string))
-		return true
-	})
-	h.subMutex.RUnlock()
+		subscribeID := key.(string)
+		sub := value.(*positionsSubscriber)
 
-	// Broadcast outside lock
-	for _, subscribeID := range subscribers {
-		h.hub.Broadcast(websocket.Message{
+		var payload interface{} = positions
+		if sub.diff {
+			current := positionStates(positions)
+			if sub.resync {
+				sub.seq = 0
+			} else {
+				diff := diffOpenPositions(sub.known, current, positions)
+				if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Updated) == 0 {
+					return true
+				}
+				sub.seq++
+				diff.Seq = sub.seq
+				payload = diff
+			}
+			sub.known = current
+		}
+
+		sub.resync = !h.hub.Broadcast(websocket.Message{
 			Type:        "open_positions",
 			SubscribeID: subscribeID,
-			Payload:     trades,
+			Payload:     payload,
 		})
+		return true
+	})
+}
+
+// diffOpenPositions returns the positions added, removed and changed
+// between the known and current states
+func diffOpenPositions(known, current map[string]positionState, positions []*models.OpenPosition) models.OpenPositionsDelta {
+	diff := models.OpenPositionsDelta{
+		Added:   make([]*models.OpenPosition, 0),
+		Removed: make([]string, 0),
+		Updated: make([]*models.OpenPosition, 0),
+	}
+	for _, position := range positions {
+		previous, seen := known[position.ID]
+		switch {
+		case !seen:
+			diff.Added = append(diff.Added, position)
+		case previous != current[position.ID]:
+			diff.Updated = append(diff.Updated, position)
+		}
+	}
+	for id := range known {
+		if _, still := current[id]; !still {
+			diff.Removed = append(diff.Removed, id)
+		}
 	}
+	sort.Strings(diff.Removed)
+	return diff
 }
 
-// Start starts the handler
+// positionStates returns the comparable state of each position by trade ID
+func positionStates(positions []*models.OpenPosition) map[string]positionState {
+	states := make(map[string]positionState, len(positions))
+	for _, position := range positions {
+		state := positionState{trade: *position.Trade, priceStale: position.PriceStale}
+		if position.CurrentPrice != nil {
+			state.currentPrice = *position.CurrentPrice
+		}
+		states[position.ID] = state
+	}
+	return states
+}
+
+// parsePositionsModeOption reports whether an open_positions subscribe asks for diff mode
+func parsePositionsModeOption(options map[string]interface{}) (bool, error) {
+	raw, ok := options["mode"]
+	if !ok || raw == nil {
+		return false, nil
+	}
+
+	switch raw {
+	case "snapshot":
+		return false, nil
+	case "diff":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid mode option: must be \"snapshot\" or \"diff\"")
+}
+
+// Start starts the handler and registers it as a trade store (and mark
+// source) listener
 func (h *OpenPositionsHandler) Start() error {
-	return nil // No startup needed
+	h.store.AddListener(h)
+	if h.marks != nil {
+		h.marks.AddListener(h)
+	}
+	return nil
 }
 
-// Stop stops the handler
+// Stop stops the handler and deregisters it from the trade store (and mark
+// source)
 func (h *OpenPositionsHandler) Stop() error {
-	return nil // No cleanup needed
+	h.store.RemoveListener(h)
+	if h.marks != nil {
+		h.marks.RemoveListener(h)
+	}
+	h.updates.Stop()
+	return nil
 }
 
 // TradeHistoryHandler handles trade history subscriptions
@@ -310,15 +793,26 @@ type TradeHistoryHandler struct {
 	store store.TradeStore
 	hub   *websocket.Hub
 	// Track subscriptions
-	subscriptions sync.Map // map[string]struct{} // subscribeID -> struct{}
+	subscriptions sync.Map // map[string]*historySubscriber // subscribeID -> subscriber state
 	subMutex     sync.RWMutex // Protects subscription operations
+	// Largest retained set per delta subscriber before falling back to snapshots
+	maxDeltaRetention int
+}
+
+// historySubscriber is the state of one trade history subscription
+type historySubscriber struct {
+	query store.TradeHistoryQuery // Filter and page for this subscription
+	delta bool                    // Send diffs instead of full snapshots
+	known map[string]struct{} // Trade IDs last sent (delta mode only)
 }
 
 // NewTradeHistoryHandler creates a new TradeHistoryHandler
-func NewTradeHistoryHandler(store store.TradeStore, hub *websocket.Hub) *TradeHistoryHandler {
+// maxDeltaRetention caps the trades retained per delta-mode subscriber
+func NewTradeHistoryHandler(store store.TradeStore, hub *websocket.Hub, maxDeltaRetention int) *TradeHistoryHandler {
 	return &TradeHistoryHandler{
-		store: store,
-		hub:   hub,
+		store:             store,
+		hub:               hub,
+		maxDeltaRetention: maxDeltaRetention,
 	}
 }
 
@@ -329,29 +823,43 @@ func (h *TradeHistoryHandler) OnTradeEvent(event store.TradeEvent) {
 		return
 	}
 
-	// Get updated trade history
-	trades, err := h.store.GetTradeHistory()
-	if err != nil {
-		log.Printf("Error getting trade history: %v", err)
-		return
-	}
-
 	// Broadcast update to all subscribers
-	h.BroadcastUpdate(trades)
+	h.BroadcastUpdate()
 }
 
 // HandleSubscribe handles subscription requests
+// Options:
+//   - "mode": "snapshot" (default) or "delta"
+//   - "symbol", "from", "to", "limit", "offset": history query
 func (h *TradeHistoryHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
-	h.subMutex.Lock()
-	h.subscriptions.Store(subscribeID, struct{}{})
-	h.subMutex.Unlock()
+	delta, err := parseHistoryModeOption(options)
+	if err != nil {
+		return err
+	}
+	query, err := parseHistoryQueryOptions(options)
+	if err != nil {
+		return err
+	}
 
-	trades, err := h.store.GetTradeHistory()
+	trades, err := h.store.GetTradeHistoryFiltered(query)
 	if err != nil {
 		// Return empty list instead of error
 		trades = []*models.Trade{}
 	}
 
+	sub := &historySubscriber{query: query, delta: delta}
+	if delta {
+		if len(trades) > h.maxDeltaRetention {
+			sub.delta = false // Too large to retain; snapshots only
+		} else {
+			sub.known = tradeIDSet(trades)
+		}
+	}
+
+	h.subMutex.Lock()
+	h.subscriptions.Store(subscribeID, sub)
+	h.subMutex.Unlock()
+
 	msg := websocket.Message{
 		Type:        "trade_history",
 		SubscribeID: subscribeID,
@@ -369,8 +877,221 @@ func (h *TradeHistoryHandler) HandleUnsubscribe(subscribeID string) error {
 	return nil
 }
 
-// BroadcastUpdate sends updates to all subscribers
-func (h *TradeHistoryHandler) BroadcastUpdate(trades []*models.Trade) {
+// BroadcastUpdate sends each subscriber the result of its history query
+// Delta subscribers get only the changes since their last update
+func (h *TradeHistoryHandler) BroadcastUpdate() {
+	// Build each subscriber's message under the lock, since it updates
+	// delta state
+	h.subMutex.Lock()
+	messages := make([]websocket.Message, 0)
+	h.subscriptions.Range(func(key, value interface{}) bool {
+		subscribeID := key.(string)
+		sub := value.(*historySubscriber)
+
+		trades, err := h.store.GetTradeHistoryFiltered(sub.query)
+		if err != nil {
+			logging.Errorf("Error getting trade history: %v", err)
+			return true
+		}
+
+		var payload interface{} = trades
+		if sub.delta {
+			if len(trades) > h.maxDeltaRetention {
+				// Retained set would exceed the cap: fall back to snapshots
+				logging.Warnf("trade_history subscriber %s exceeded %d retained trades, switching to snapshot mode", subscribeID, h.maxDeltaRetention)
+				sub.delta = false
+				sub.known = nil
+			} else {
+				diff := diffTradeHistory(sub.known, trades)
+				sub.known = tradeIDSet(trades)
+				if len(diff.Added) == 0 && len(diff.Removed) == 0 {
+					return true
+				}
+				payload = diff
+			}
+		}
+
+		messages = append(messages, websocket.Message{
+			Type:        "trade_history",
+			SubscribeID: subscribeID,
+			Payload:     payload,
+		})
+		return true
+	})
+	h.subMutex.Unlock()
+
+	// Broadcast outside lock
+	for _, msg := range messages {
+		h.hub.Broadcast(msg)
+	}
+}
+
+// diffTradeHistory returns the trades added to and IDs removed from a known set
+func diffTradeHistory(known map[string]struct{}, trades []*models.Trade) models.TradeHistoryDelta {
+	diff := models.TradeHistoryDelta{
+		Added:   make([]*models.Trade, 0),
+		Removed: make([]string, 0),
+	}
+	current := make(map[string]struct{}, len(trades))
+	for _, trade := range trades {
+		current[trade.ID] = struct{}{}
+		if _, seen := known[trade.ID]; !seen {
+			diff.Added = append(diff.Added, trade)
+		}
+	}
+	for id := range known {
+		if _, still := current[id]; !still {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	return diff
+}
+
+// tradeIDSet returns the IDs of the given trades
+func tradeIDSet(trades []*models.Trade) map[string]struct{} {
+	ids := make(map[string]struct{}, len(trades))
+	for _, trade := range trades {
+		ids[trade.ID] = struct{}{}
+	}
+	return ids
+}
+
+// parseHistoryQueryOptions builds the history query from subscribe options
+func parseHistoryQueryOptions(options map[string]interface{}) (store.TradeHistoryQuery, error) {
+	var query store.TradeHistoryQuery
+
+	if raw, ok := options["symbol"]; ok && raw != nil {
+		symbol, ok := raw.(string)
+		if !ok {
+			return query, fmt.Errorf("invalid symbol option: must be a string")
+		}
+		query.Symbol = symbol
+	}
+
+	for _, bound := range []struct {
+		name   string
+		target *time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		raw, ok := options[bound.name]
+		if !ok || raw == nil {
+			continue
+		}
+		text, ok := raw.(string)
+		if !ok {
+			return query, fmt.Errorf("invalid %s option: must be an RFC 3339 timestamp", bound.name)
+		}
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return query, fmt.Errorf("invalid %s option: must be an RFC 3339 timestamp", bound.name)
+		}
+		*bound.target = t
+	}
+
+	for _, page := range []struct {
+		name   string
+		target *int
+	}{{"limit", &query.Limit}, {"offset", &query.Offset}} {
+		raw, ok := options[page.name]
+		if !ok || raw == nil {
+			continue
+		}
+		n, ok := raw.(float64)
+		if !ok || n < 0 || n != math.Trunc(n) {
+			return query, fmt.Errorf("invalid %s option: must be a whole number >= 0", page.name)
+		}
+		*page.target = int(n)
+	}
+
+	return query, nil
+}
+
+// parseHistoryModeOption reports whether a trade_history subscribe asks for delta mode
+func parseHistoryModeOption(options map[string]interface{}) (bool, error) {
+	raw, ok := options["mode"]
+	if !ok || raw == nil {
+		return false, nil
+	}
+
+	switch raw {
+	case "snapshot":
+		return false, nil
+	case "delta":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid mode option: must be \"snapshot\" or \"delta\"")
+}
+
+// Start starts the handler and registers it as a trade store listener
+func (h *TradeHistoryHandler) Start() error {
+	h.store.AddListener(h)
+	return nil
+}
+
+// Stop stops the handler and deregisters it from the trade store
+func (h *TradeHistoryHandler) Stop() error {
+	h.store.RemoveListener(h)
+	return nil
+}
+
+// FlatHandler notifies subscribers when the account goes fully flat
+type FlatHandler struct {
+	store store.TradeStore
+	hub   *websocket.Hub
+	// Track subscriptions
+	subscriptions sync.Map // map[string]struct{} // subscribeID -> struct{}
+	subMutex     sync.RWMutex // Protects subscription operations
+}
+
+// NewFlatHandler creates a new FlatHandler
+func NewFlatHandler(store store.TradeStore, hub *websocket.Hub) *FlatHandler {
+	return &FlatHandler{
+		store: store,
+		hub:   hub,
+	}
+}
+
+// OnTradeEvent implements store.TradeEventListener
+func (h *FlatHandler) OnTradeEvent(event store.TradeEvent) {
+	// Only a close can take the account flat
+	if event.Type != store.TradeClosed {
+		return
+	}
+
+	trades, err := h.store.GetOpenTrades()
+	if err != nil {
+		logging.Errorf("Error getting open trades: %v", err)
+		return
+	}
+	if len(trades) > 0 {
+		return
+	}
+
+	logging.Infof("Account flat after closing trade: %s", event.Trade.ID)
+	h.BroadcastFlat(models.FlatEvent{
+		LastTradeID: event.Trade.ID,
+		Symbol:      event.Trade.Symbol,
+		Timestamp:   event.Trade.ExitTime,
+	})
+}
+
+// HandleSubscribe handles subscription requests
+func (h *FlatHandler) HandleSubscribe(subscribeID string, options map[string]interface{}) error {
+	h.subMutex.Lock()
+	h.subscriptions.Store(subscribeID, struct{}{})
+	h.subMutex.Unlock()
+	return nil
+}
+
+// HandleUnsubscribe handles unsubscribe requests
+func (h *FlatHandler) HandleUnsubscribe(subscribeID string) error {
+	h.subMutex.Lock()
+	h.subscriptions.Delete(subscribeID)
+	h.subMutex.Unlock()
+	return nil
+}
+
+// BroadcastFlat sends a flat event to all subscribers
+func (h *FlatHandler) BroadcastFlat(event models.FlatEvent) {
 	// Collect subscribers under read lock
 	h.subMutex.RLock()
 	subscribers := make([]string, 0)
@@ -383,19 +1104,21 @@ func (h *TradeHistoryHandler) BroadcastUpdate(trades []*models.Trade) {
 	// Broadcast outside lock
 	for _, subscribeID := range subscribers {
 		h.hub.Broadcast(websocket.Message{
-			Type:        "trade_history",
+			Type:        "flat",
 			SubscribeID: subscribeID,
-			Payload:     trades,
+			Payload:     event,
 		})
 	}
 }
 
-// Start starts the handler
-func (h *TradeHistoryHandler) Start() error {
-	return nil // No startup needed
+// Start starts the handler and registers it as a trade store listener
+func (h *FlatHandler) Start() error {
+	h.store.AddListener(h)
+	return nil
 }
 
-// Stop stops the handler
-func (h *TradeHistoryHandler) Stop() error {
-	return nil // No cleanup needed
+// Stop stops the handler and deregisters it from the trade store
+func (h *FlatHandler) Stop() error {
+	h.store.RemoveListener(h)
+	return nil
 }
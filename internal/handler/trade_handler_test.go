@@ -0,0 +1,703 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+	"github.com/aumbhatt/auto_trade/internal/strategy"
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+)
+
+// newTestOpenPositionsHandler serves an open positions handler over a fresh
+// trade store
+func newTestOpenPositionsHandler(t *testing.T) (*OpenPositionsHandler, *memory.InMemoryTradeStore, *testServer) {
+	t.Helper()
+	trades := memory.NewInMemoryTradeStore()
+	h := NewOpenPositionsHandler(trades, nil, memory.NewInMemoryPriceCache(), 0)
+	server := newTestServer(t, map[string]MessageHandler{"open_positions": h})
+	h.hub = server.hub
+	return h, trades, server
+}
+
+// broadcastOpen broadcasts the store's open trades
+func broadcastOpen(t *testing.T, h *OpenPositionsHandler, trades *memory.InMemoryTradeStore) {
+	t.Helper()
+	open, err := trades.GetOpenTrades()
+	if err != nil {
+		t.Fatalf("GetOpenTrades: %v", err)
+	}
+	h.BroadcastUpdate(open)
+}
+
+func TestOpenPositionsDiffsAreNumbered(t *testing.T) {
+	h, trades, server := newTestOpenPositionsHandler(t)
+	client := server.dial(t)
+	client.subscribe("open_positions", map[string]interface{}{"mode": "diff"})
+
+	var snapshot []json.RawMessage
+	client.decode(client.next(), &snapshot)
+	if len(snapshot) != 0 {
+		t.Fatalf("initial snapshot has %d positions, want 0", len(snapshot))
+	}
+
+	// nextDelta reads a diff, which must carry the given seq
+	nextDelta := func(seq uint64) models.OpenPositionsDelta {
+		t.Helper()
+		var delta models.OpenPositionsDelta
+		client.decode(client.next(), &delta)
+		if delta.Seq != seq {
+			t.Fatalf("diff seq %d, want %d", delta.Seq, seq)
+		}
+		return delta
+	}
+
+	first, _ := trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	broadcastOpen(t, h, trades)
+	trades.CreateTrade("MSFT", 200, 1, models.SideBuy, "")
+	broadcastOpen(t, h, trades)
+	broadcastOpen(t, h, trades) // No change, not sent
+	nextDelta(1)
+	nextDelta(2)
+
+	trades.CloseTrade(first.ID, 101, "test")
+	broadcastOpen(t, h, trades)
+	if delta := nextDelta(3); len(delta.Removed) != 1 || delta.Removed[0] != first.ID {
+		t.Errorf("third diff removed %v, want %s", delta.Removed, first.ID)
+	}
+	client.expectNone(100 * time.Millisecond)
+}
+
+func TestOpenPositionsConcurrentDiffsArriveInOrder(t *testing.T) {
+	h, trades, server := newTestOpenPositionsHandler(t)
+	client := server.dial(t)
+	client.subscribe("open_positions", map[string]interface{}{"mode": "diff"})
+	client.next() // Initial snapshot
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+				broadcastOpen(t, h, trades)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Replaying the diffs in seq order must rebuild the final open set
+	open := make(map[string]bool)
+	for seq := uint64(1); len(open) < 40; seq++ {
+		var delta models.OpenPositionsDelta
+		client.decode(client.next(), &delta)
+		if delta.Seq != seq {
+			t.Fatalf("diff seq %d, want %d", delta.Seq, seq)
+		}
+		for _, position := range delta.Added {
+			open[position.ID] = true
+		}
+	}
+	client.expectNone(100 * time.Millisecond)
+}
+
+func TestOpenPositionsResyncAfterDrop(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	registry := NewRegistry()
+	hub := websocket.NewHub(registry, 1) // Not running: the queue fills up
+	h := NewOpenPositionsHandler(trades, hub, memory.NewInMemoryPriceCache(), 0)
+
+	if err := h.HandleSubscribe("sub-1", map[string]interface{}{"mode": "diff"}); err != nil {
+		t.Fatalf("HandleSubscribe: %v", err)
+	}
+	value, _ := h.subscriptions.Load("sub-1")
+	sub := value.(*positionsSubscriber)
+
+	trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	broadcastOpen(t, h, trades) // Dropped
+	if !sub.resync {
+		t.Fatal("dropped diff did not mark the subscriber for resync")
+	}
+
+	// Once the hub drains, the next update is a full snapshot
+	go hub.Run()
+	defer hub.Shutdown(context.Background(), "test done")
+	deadline := time.Now().Add(2 * time.Second)
+	for sub.resync {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber never resynced")
+		}
+		time.Sleep(time.Millisecond)
+		broadcastOpen(t, h, trades)
+	}
+	if sub.seq != 0 || len(sub.known) != 1 {
+		t.Errorf("after resync seq=%d known=%d, want seq 0 and the one open trade", sub.seq, len(sub.known))
+	}
+
+	trades.CreateTrade("MSFT", 200, 1, models.SideBuy, "")
+	broadcastOpen(t, h, trades)
+	if sub.seq != 1 {
+		t.Errorf("first diff after resync has seq %d, want 1", sub.seq)
+	}
+}
+
+func TestIsolatedStrategyTradesStayOutOfOpenPositions(t *testing.T) {
+	h, trades, server := newTestOpenPositionsHandler(t)
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+	client := server.dial(t)
+	client.subscribe("open_positions", nil)
+	client.next() // Initial snapshot
+
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := strategy.NewDefaultRunner(strategies, trades)
+	isolated := memory.NewInMemoryTradeStore()
+	runner.SetIsolatedStoreFactory(func() store.TradeStore { return isolated })
+
+	instance, err := strategies.CreateStrategy("repeat", map[string]interface{}{
+		"symbol":      "AAPL",
+		"exit_price":  200.0,
+		"trade_store": models.TradeStoreIsolated,
+	})
+	if err != nil {
+		t.Fatalf("CreateStrategy: %v", err)
+	}
+	ticks := make(chan *models.Tick)
+	if err := runner.Start(instance, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer runner.Stop(instance)
+	ticks <- &models.Tick{Symbol: "AAPL", Price: 100, Timestamp: time.Now()}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		open, _ := isolated.GetOpenTrades()
+		if len(open) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("isolated strategy did not open a trade")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	client.expectNone(100 * time.Millisecond)
+
+	// A shared trade updates the feed, still without the isolated one
+	shared, _ := trades.CreateTrade("MSFT", 200, 1, models.SideBuy, "")
+	var positions []models.OpenPosition
+	client.decode(client.next(), &positions)
+	if len(positions) != 1 || positions[0].ID != shared.ID {
+		t.Errorf("open positions %+v, want only the shared trade %s", positions, shared.ID)
+	}
+}
+
+func TestFlatFiresOnlyWhenLastTradeCloses(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	h := NewFlatHandler(trades, nil)
+	server := newTestServer(t, map[string]MessageHandler{"flat": h})
+	h.hub = server.hub
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	client := server.dial(t)
+	client.subscribe("flat", nil)
+
+	first, _ := trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	second, _ := trades.CreateTrade("MSFT", 200, 1, models.SideBuy, "")
+	trades.CloseTrade(first.ID, 101, "test")
+	client.expectNone(100 * time.Millisecond) // MSFT still open
+
+	trades.CloseTrade(second.ID, 201, "test")
+	var event models.FlatEvent
+	client.decode(client.next(), &event)
+	if event.LastTradeID != second.ID || event.Symbol != "MSFT" {
+		t.Errorf("flat event %+v, want one naming %s on MSFT", event, second.ID)
+	}
+	client.expectNone(100 * time.Millisecond)
+}
+
+func TestSellStoresExitPrice(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	prices := memory.NewInMemoryPriceCache()
+	prices.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: time.Now()})
+	h := NewTradeHandler(trades, nil, nil, nil, prices)
+
+	sell := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/trades/sell", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.HandleSell(w, r)
+		return w
+	}
+	open := func(symbol string) string {
+		t.Helper()
+		trade, err := trades.CreateTrade(symbol, 140, 1, models.SideBuy, "")
+		if err != nil {
+			t.Fatalf("CreateTrade: %v", err)
+		}
+		return trade.ID
+	}
+	storedExit := func(id string) float64 {
+		t.Helper()
+		trade, err := trades.GetTradeByID(id)
+		if err != nil {
+			t.Fatalf("GetTradeByID: %v", err)
+		}
+		return trade.ExitPrice
+	}
+
+	// The given exit price is stored as is
+	id := open("AAPL")
+	if w := sell(`{"trade_id": "` + id + `", "exit_price": 143.25}`); w.Code != http.StatusOK {
+		t.Fatalf("status %d (%s), want 200", w.Code, w.Body)
+	}
+	if got := storedExit(id); got != 143.25 {
+		t.Errorf("stored exit price %v, want 143.25", got)
+	}
+
+	// Without one the latest tick price is used
+	id = open("AAPL")
+	if w := sell(`{"trade_id": "` + id + `"}`); w.Code != http.StatusOK {
+		t.Fatalf("status %d (%s), want 200", w.Code, w.Body)
+	}
+	if got := storedExit(id); got != 150 {
+		t.Errorf("stored exit price %v, want the tick price 150", got)
+	}
+
+	// Without either the trade stays open
+	id = open("MSFT")
+	if w := sell(`{"trade_id": "` + id + `"}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), models.ErrInvalidExitPrice) {
+		t.Errorf("status %d %q with no price for MSFT, want 400 %s", w.Code, w.Body, models.ErrInvalidExitPrice)
+	}
+	if trade, _ := trades.GetTradeByID(id); !trade.ExitTime.IsZero() {
+		t.Error("trade closed by a failed sell")
+	}
+
+	if w := sell(`{"trade_id": "unknown", "exit_price": 1}`); w.Code != http.StatusNotFound {
+		t.Errorf("status %d selling an unknown trade, want 404", w.Code)
+	}
+}
+
+func TestBuyRejectsNonPositiveEntryPrice(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	h := NewTradeHandler(trades, nil, nil, nil, nil)
+	buy := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.HandleBuy(w, httptest.NewRequest(http.MethodPost, "/api/trades/buy", strings.NewReader(body)))
+		return w
+	}
+
+	for _, body := range []string{
+		`{"symbol": "AAPL"}`,
+		`{"symbol": "AAPL", "entry_price": 0}`,
+		`{"symbol": "AAPL", "entry_price": -5}`,
+	} {
+		if w := buy(body); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), models.ErrInvalidEntryPrice) {
+			t.Errorf("%s: status %d %q, want 400 %s", body, w.Code, w.Body, models.ErrInvalidEntryPrice)
+		}
+	}
+	if w := buy(`{"symbol": "AAPL", "entry_price": 150}`); w.Code != http.StatusOK {
+		t.Errorf("status %d (%s) for a valid entry price, want 200", w.Code, w.Body)
+	}
+}
+
+func TestBuyRecordsQuantityAndSide(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	h := NewTradeHandler(trades, nil, nil, nil, nil)
+	buy := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.HandleBuy(w, httptest.NewRequest(http.MethodPost, "/api/trades/buy", strings.NewReader(body)))
+		return w
+	}
+
+	tests := []struct {
+		body     string
+		quantity float64
+		side     models.Side
+	}{
+		{body: `{"symbol": "AAPL", "entry_price": 100}`, quantity: models.DefaultQuantity, side: models.SideBuy},
+		{body: `{"symbol": "AAPL", "entry_price": 100, "quantity": 2.5}`, quantity: 2.5, side: models.SideBuy},
+		{body: `{"symbol": "AAPL", "entry_price": 100, "quantity": 3, "side": "sell"}`, quantity: 3, side: models.SideSell},
+	}
+	for _, tt := range tests {
+		w := buy(tt.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d (%s), want 200", tt.body, w.Code, w.Body)
+		}
+		var trade models.Trade
+		if err := json.NewDecoder(w.Body).Decode(&trade); err != nil {
+			t.Fatal(err)
+		}
+		if trade.Quantity != tt.quantity || trade.Side != tt.side {
+			t.Errorf("%s: recorded %v %s, want %v %s", tt.body, trade.Quantity, trade.Side, tt.quantity, tt.side)
+		}
+	}
+
+	if w := buy(`{"symbol": "AAPL", "entry_price": 100, "quantity": -1}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), models.ErrInvalidQuantity) {
+		t.Errorf("status %d %q for a negative quantity, want 400 %s", w.Code, w.Body, models.ErrInvalidQuantity)
+	}
+
+	// P&L is weighted by quantity: 3 units short from 100 to 90 gains 30
+	open, _ := trades.GetOpenTrades()
+	for _, trade := range open {
+		if trade.Side != models.SideSell {
+			continue
+		}
+		closed, err := trades.CloseTrade(trade.ID, 90, models.CloseReasonManual)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if closed.PnL != 30 {
+			t.Errorf("short P&L %v, want 30", closed.PnL)
+		}
+	}
+}
+
+func TestGetTradeEndpoint(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	h := NewTradeHandler(trades, nil, nil, nil, nil)
+	open, _ := trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	closed, _ := trades.CreateTrade("MSFT", 200, 1, models.SideBuy, "")
+	trades.CloseTrade(closed.ID, 210, models.CloseReasonManual)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.HandleGetTrade(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	for _, want := range []*models.Trade{open, closed} {
+		w := get("/api/trades/" + want.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d (%s) for %s, want 200", w.Code, w.Body, want.ID)
+		}
+		var got models.Trade
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.ID != want.ID || got.Symbol != want.Symbol {
+			t.Errorf("got trade %s on %s, want %s on %s", got.ID, got.Symbol, want.ID, want.Symbol)
+		}
+	}
+
+	if w := get("/api/trades/missing"); w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "Trade not found") {
+		t.Errorf("status %d %q for a missing trade, want 404", w.Code, w.Body)
+	}
+	if w := get("/api/trades/" + open.ID + "/extra"); w.Code != http.StatusNotFound {
+		t.Errorf("status %d for a nested path, want 404", w.Code)
+	}
+}
+
+// listenerCountingStore counts the listeners registered with a trade store
+type listenerCountingStore struct {
+	*memory.InMemoryTradeStore
+	mu        sync.Mutex
+	listeners int
+}
+
+func (s *listenerCountingStore) AddListener(listener store.TradeEventListener) {
+	s.mu.Lock()
+	s.listeners++
+	s.mu.Unlock()
+	s.InMemoryTradeStore.AddListener(listener)
+}
+
+func (s *listenerCountingStore) RemoveListener(listener store.TradeEventListener) {
+	s.mu.Lock()
+	s.listeners--
+	s.mu.Unlock()
+	s.InMemoryTradeStore.RemoveListener(listener)
+}
+
+func (s *listenerCountingStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.listeners
+}
+
+func TestStoppedHandlersStopListening(t *testing.T) {
+	trades := &listenerCountingStore{InMemoryTradeStore: memory.NewInMemoryTradeStore()}
+	openPos := NewOpenPositionsHandler(trades, nil, memory.NewInMemoryPriceCache(), 0)
+	history := NewTradeHistoryHandler(trades, nil, 0)
+	server := newTestServer(t, map[string]MessageHandler{"open_positions": openPos, "trade_history": history})
+	openPos.hub = server.hub
+	history.hub = server.hub
+
+	for _, h := range []MessageHandler{openPos, history} {
+		if err := h.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+	}
+	if n := trades.count(); n != 2 {
+		t.Fatalf("%d listeners after starting both handlers, want 2", n)
+	}
+
+	client := server.dial(t)
+	drain := func() {
+		for {
+			select {
+			case <-client.messages:
+			case <-time.After(100 * time.Millisecond):
+				return
+			}
+		}
+	}
+
+	// Subscribe and skip each feed's snapshot
+	ids := make(map[string]bool)
+	for _, msgType := range []string{"open_positions", "trade_history"} {
+		ids[client.subscribe(msgType, nil)] = true
+		drain()
+	}
+
+	// While started, a trade reaches both feeds
+	trade, _ := trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	trades.CloseTrade(trade.ID, 101, "test")
+	seen := make(map[string]bool)
+	for len(seen) < len(ids) {
+		msg := client.next()
+		if ids[msg.SubscribeID] {
+			seen[msg.SubscribeID] = true
+		}
+	}
+	drain()
+
+	for _, h := range []MessageHandler{openPos, history} {
+		if err := h.Stop(); err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+	}
+	if n := trades.count(); n != 0 {
+		t.Errorf("%d listeners after stopping both handlers, want 0", n)
+	}
+
+	trade, _ = trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	trades.CloseTrade(trade.ID, 101, "test")
+	client.expectNone(200 * time.Millisecond)
+}
+
+func TestDeltaSubscriberOverCapSwitchesToSnapshots(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	h := NewTradeHistoryHandler(trades, nil, 2)
+	server := newTestServer(t, map[string]MessageHandler{"trade_history": h})
+	h.hub = server.hub
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	client := server.dial(t)
+	subscribe := func(options map[string]interface{}) string {
+		t.Helper()
+		id := client.subscribe("trade_history", options)
+		client.next() // Initial snapshot
+		return id
+	}
+	all := subscribe(map[string]interface{}{"mode": "delta"})
+	msft := subscribe(map[string]interface{}{"mode": "delta", "symbol": "MSFT"})
+
+	// closeTrade closes a new trade and returns each subscription's update,
+	// as "delta" or "snapshot"
+	closeTrade := func(symbol string, updates int) map[string]string {
+		t.Helper()
+		trade, _ := trades.CreateTrade(symbol, 100, 1, models.SideBuy, "")
+		trades.CloseTrade(trade.ID, 101, "test")
+		got := make(map[string]string)
+		for i := 0; i < updates; i++ {
+			msg := client.next()
+			if strings.HasPrefix(string(msg.Payload), "[") {
+				got[msg.SubscribeID] = "snapshot"
+			} else {
+				got[msg.SubscribeID] = "delta"
+			}
+		}
+		return got
+	}
+
+	for i := 0; i < 2; i++ {
+		if got := closeTrade("AAPL", 1); got[all] != "delta" {
+			t.Fatalf("update %d within the cap is a %s, want a delta", i+1, got[all])
+		}
+	}
+
+	// The third trade puts one subscriber over the cap, not the other
+	got := closeTrade("MSFT", 2)
+	if got[all] != "snapshot" || got[msft] != "delta" {
+		t.Fatalf("updates %v, want a snapshot for the subscriber over the cap and a delta for %s", got, msft)
+	}
+
+	// Snapshot mode is kept from then on
+	if got := closeTrade("AAPL", 1); got[all] != "snapshot" {
+		t.Errorf("update after the switch is a %s, want a snapshot", got[all])
+	}
+}
+
+func TestOpenPositionsCarryMarketValue(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	prices := memory.NewInMemoryPriceCache()
+	h := NewOpenPositionsHandler(trades, nil, prices, time.Minute)
+	server := newTestServer(t, map[string]MessageHandler{"open_positions": h})
+	h.hub = server.hub
+
+	now := time.Now()
+	prices.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: now})
+	prices.UpdatePrice(&models.Tick{Symbol: "MSFT", Price: 300, Timestamp: now.Add(-2 * time.Minute)})
+	for _, symbol := range []string{"AAPL", "MSFT", "GOOG"} {
+		trades.CreateTrade(symbol, 100, 2.5, models.SideBuy, "")
+	}
+
+	client := server.dial(t)
+	client.subscribe("open_positions", nil)
+	var positions []models.OpenPosition
+	client.decode(client.next(), &positions)
+	bySymbol := make(map[string]models.OpenPosition)
+	for _, position := range positions {
+		bySymbol[position.Symbol] = position
+	}
+
+	if p := bySymbol["AAPL"]; p.PriceStale || p.MarketValue == nil || *p.MarketValue != 2.5*150 || *p.CurrentPrice != 150 {
+		t.Errorf("AAPL position %+v, want a market value of 2.5 x 150", p)
+	}
+
+	// An old price or none at all marks the position instead of valuing it
+	for _, symbol := range []string{"MSFT", "GOOG"} {
+		if p := bySymbol[symbol]; !p.PriceStale || p.MarketValue != nil {
+			t.Errorf("%s position %+v, want it marked stale with no market value", symbol, p)
+		}
+	}
+}
+
+func TestHistorySubscribeOptionsMapToQuery(t *testing.T) {
+	query, err := parseHistoryQueryOptions(map[string]interface{}{
+		"symbol": "AAPL",
+		"from":   "2025-01-23T09:00:00Z",
+		"to":     "2025-01-24T09:00:00Z",
+		"limit":  20.0,
+		"offset": 40.0,
+	})
+	if err != nil {
+		t.Fatalf("parseHistoryQueryOptions: %v", err)
+	}
+	want := store.TradeHistoryQuery{
+		Symbol: "AAPL",
+		From:   time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC),
+		To:     time.Date(2025, 1, 24, 9, 0, 0, 0, time.UTC),
+		Limit:  20,
+		Offset: 40,
+	}
+	if query != want {
+		t.Errorf("query %+v, want %+v", query, want)
+	}
+
+	for _, options := range []map[string]interface{}{
+		{"symbol": 1.0},
+		{"from": "yesterday"},
+		{"limit": -1.0},
+		{"offset": 2.5},
+	} {
+		if _, err := parseHistoryQueryOptions(options); err == nil {
+			t.Errorf("options %v accepted", options)
+		}
+	}
+}
+
+func TestOpenPositionsStreamMarkMoves(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	marks := store.NewMarkTracker(memory.NewInMemoryPriceCache(), trades)
+	h := NewOpenPositionsHandler(trades, nil, marks, time.Minute)
+	h.SetMarkSource(marks)
+	server := newTestServer(t, map[string]MessageHandler{"open_positions": h})
+	h.hub = server.hub
+
+	trades.CreateTrade("AAPL", 100, 2, models.SideBuy, "")
+	marks.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 100, Timestamp: time.Now()})
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	client := server.dial(t)
+	client.subscribe("open_positions", nil)
+	unrealized := func() float64 {
+		t.Helper()
+		var positions []models.OpenPosition
+		client.decode(client.next(), &positions)
+		if len(positions) != 1 || positions[0].UnrealizedPnL == nil {
+			t.Fatalf("positions %+v, want the marked AAPL trade", positions)
+		}
+		return *positions[0].UnrealizedPnL
+	}
+	if pnl := unrealized(); pnl != 0 {
+		t.Fatalf("unrealized P&L %v at the entry price, want 0", pnl)
+	}
+
+	// A tick moving the mark re-broadcasts without any trade opening or closing
+	marks.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 105, Timestamp: time.Now()})
+	if pnl := unrealized(); pnl != 10 {
+		t.Errorf("unrealized P&L %v after a move to 105, want 2 x 5", pnl)
+	}
+
+	// An unchanged mark, or another symbol's tick, is not an update
+	marks.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 105, Timestamp: time.Now()})
+	marks.UpdatePrice(&models.Tick{Symbol: "MSFT", Price: 300, Timestamp: time.Now()})
+	client.expectNone(100 * time.Millisecond)
+}
+
+func TestOpenPositionsCoalesceRapidEvents(t *testing.T) {
+	h, trades, server := newTestOpenPositionsHandler(t)
+	h.SetBatchWindow(100 * time.Millisecond)
+	if err := h.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer h.Stop()
+
+	client := server.dial(t)
+	client.subscribe("open_positions", nil)
+	client.next() // Initial snapshot
+
+	// 50 events: 30 opens and 20 closes
+	var opened []*models.Trade
+	for i := 0; i < 30; i++ {
+		trade, err := trades.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+		if err != nil {
+			t.Fatalf("CreateTrade: %v", err)
+		}
+		opened = append(opened, trade)
+	}
+	for _, trade := range opened[:20] {
+		if _, err := trades.CloseTrade(trade.ID, 101, models.CloseReasonManual); err != nil {
+			t.Fatalf("CloseTrade: %v", err)
+		}
+	}
+
+	// Read broadcasts until the feed goes quiet
+	var last []models.OpenPosition
+	broadcasts := 0
+	for quiet := false; !quiet; {
+		select {
+		case msg := <-client.messages:
+			broadcasts++
+			client.decode(msg, &last)
+		case <-time.After(300 * time.Millisecond):
+			quiet = true
+		}
+	}
+	if broadcasts == 0 || broadcasts > 5 {
+		t.Errorf("%d broadcasts for 50 trade events, want a handful", broadcasts)
+	}
+	if len(last) != 10 {
+		t.Errorf("last broadcast has %d open positions, want the final 10", len(last))
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/websocket"
+	gws "github.com/gorilla/websocket"
+)
+
+// testServer is a hub and registry served over a real WebSocket endpoint
+type testServer struct {
+	hub      *websocket.Hub
+	registry *Registry
+	url      string
+}
+
+// newTestServer registers the handlers (without starting them) and serves
+// them on an httptest server, torn down when the test ends
+func newTestServer(t *testing.T, handlers map[string]MessageHandler) *testServer {
+	t.Helper()
+	registry := NewRegistry()
+	for msgType, h := range handlers {
+		if err := registry.Register(msgType, h); err != nil {
+			t.Fatalf("Register(%s): %v", msgType, err)
+		}
+	}
+	hub := websocket.NewHub(registry, 0)
+	go hub.Run()
+
+	server := httptest.NewServer(websocket.HandleWebSocket(hub, 0))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hub.Shutdown(ctx, "test done")
+		server.Close()
+	})
+	return &testServer{
+		hub:      hub,
+		registry: registry,
+		url:      "ws" + strings.TrimPrefix(server.URL, "http"),
+	}
+}
+
+// received is a message read by a test client, payload left encoded
+type received struct {
+	Type        string          `json:"type"`
+	SubscribeID string          `json:"subscribe_id"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// testClient is a WebSocket client connected to a testServer
+type testClient struct {
+	t        *testing.T
+	conn     *gws.Conn
+	session  string
+	messages chan received // Read by a background reader; closed on error
+}
+
+// dial connects a client and reads its session message
+func (s *testServer) dial(t *testing.T) *testClient {
+	t.Helper()
+	conn, _, err := gws.DefaultDialer.Dial(s.url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := &testClient{t: t, conn: conn, messages: make(chan received, 1024)}
+	go c.readLoop()
+
+	msg := c.next()
+	if msg.Type != websocket.MessageTypeSession {
+		t.Fatalf("first message is %q, want session", msg.Type)
+	}
+	var session websocket.SessionInfo
+	c.decode(msg, &session)
+	c.session = session.SessionID
+	return c
+}
+
+// readLoop reads messages until the connection fails
+func (c *testClient) readLoop() {
+	defer close(c.messages)
+	for {
+		var msg received
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		c.messages <- msg
+	}
+}
+
+// send writes a message to the server
+func (c *testClient) send(msg websocket.Message) {
+	c.t.Helper()
+	if err := c.conn.WriteJSON(msg); err != nil {
+		c.t.Fatalf("WriteJSON: %v", err)
+	}
+}
+
+// next returns the next message, failing the test after two seconds
+func (c *testClient) next() received {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("connection closed")
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		c.t.Fatal("timed out waiting for a message")
+	}
+	return received{}
+}
+
+// expectNone fails the test if a message arrives within timeout
+func (c *testClient) expectNone(timeout time.Duration) {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if ok {
+			c.t.Fatalf("unexpected %s message: %s", msg.Type, msg.Payload)
+		}
+	case <-time.After(timeout):
+	}
+}
+
+// subscribe subscribes to a feed and returns the subscribe ID from the
+// subscribe_response, which must be the next message
+func (c *testClient) subscribe(msgType string, options map[string]interface{}) string {
+	c.t.Helper()
+	c.send(websocket.Message{
+		Type:    websocket.MessageTypeSubscribe,
+		Payload: websocket.SubscribeRequest{Type: msgType, Options: options},
+	})
+	msg := c.next()
+	if msg.Type != websocket.MessageTypeSubscribeResponse {
+		c.t.Fatalf("got %s message %s, want subscribe_response", msg.Type, msg.Payload)
+	}
+	var response websocket.SubscribeResponse
+	c.decode(msg, &response)
+	return response.SubscribeID
+}
+
+// decode unmarshals a message payload
+func (c *testClient) decode(msg received, target interface{}) {
+	c.t.Helper()
+	if err := json.Unmarshal(msg.Payload, target); err != nil {
+		c.t.Fatalf("decode %s payload %s: %v", msg.Type, msg.Payload, err)
+	}
+}
@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+/*
+Leveled Logging Flow:
+
+1. Levels:
+   debug < info < warn < error
+   Messages below the configured level (app.logLevel, default "info") are
+   dropped before they are formatted.
+
+2. What goes where:
+   - Debug: per-trade and per-tick activity (trade opens/closes, strategy
+     signals) — useful when tracing, too noisy for production
+   - Info:  lifecycle events (strategy started/stopped/resumed, startup)
+   - Warn:  degraded but handled conditions (dropped messages, fallbacks)
+   - Error: failed operations
+
+3. Output:
+   Records are written by a log/slog text handler to stderr:
+   time=2025-01-23T14:23:38.000Z level=INFO msg="Resumed strategy strategy-abc"
+   The handler is also installed as slog's default, so anything still
+   logging through the standard log package shares the format (at info).
+
+4. Usage Example:
+   logging.SetLevel(cfg.App.LogLevel)
+   logging.Debugf("Trade opened: %s", trade.ID)
+*/
+
+var (
+	level  = new(slog.LevelVar) // Defaults to info
+	logger atomic.Pointer[slog.Logger]
+)
+
+func init() {
+	SetOutput(os.Stderr)
+}
+
+// newLogger creates a text logger writing to w at the shared level
+func newLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// SetLevel sets the minimum level logged: "debug", "info", "warn" or "error"
+func SetLevel(name string) error {
+	parsed, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+// ParseLevel converts a level name (case-insensitive) to a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q: must be debug, info, warn or error", name)
+	}
+}
+
+// SetOutput redirects log records to w (e.g. a buffer in tests)
+func SetOutput(w io.Writer) {
+	l := newLogger(w)
+	logger.Store(l)
+	slog.SetDefault(l)
+}
+
+// Debugf logs a formatted message at debug level
+func Debugf(format string, args ...interface{}) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// Infof logs a formatted message at info level
+func Infof(format string, args ...interface{}) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a formatted message at warn level
+func Warnf(format string, args ...interface{}) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// Errorf logs a formatted message at error level
+func Errorf(format string, args ...interface{}) {
+	logf(slog.LevelError, format, args...)
+}
+
+// logf formats and logs a message if lvl is enabled
+func logf(lvl slog.Level, format string, args ...interface{}) {
+	l := logger.Load()
+	ctx := context.Background()
+	if !l.Enabled(ctx, lvl) {
+		return
+	}
+	l.Log(ctx, lvl, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDebugSuppressedAtInfo(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	t.Cleanup(func() {
+		SetOutput(os.Stderr)
+		SetLevel("info")
+	})
+
+	if err := SetLevel("INFO"); err != nil {
+		t.Fatalf("SetLevel: %v", err)
+	}
+	Debugf("tick %d", 1)
+	Infof("trade %s opened", "trade-1")
+	out := buf.String()
+	if strings.Contains(out, "tick 1") {
+		t.Errorf("debug message logged at info level: %q", out)
+	}
+	if !strings.Contains(out, "level=INFO") || !strings.Contains(out, `msg="trade trade-1 opened"`) {
+		t.Errorf("output %q, want the info message", out)
+	}
+
+	buf.Reset()
+	SetLevel("debug")
+	Debugf("tick %d", 2)
+	if !strings.Contains(buf.String(), `msg="tick 2"`) {
+		t.Errorf("output %q, want the debug message at debug level", buf.String())
+	}
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("SetLevel accepted an unknown level")
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// StrategyPerformance aggregates closed-trade performance for a strategy type
+type StrategyPerformance struct {
+	Name       string  `json:"name"`
+	TradeCount int     `json:"trade_count"`
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	WinRate    float64 `json:"win_rate"`  // Percentage of trades with positive P&L
+	NetPnL     float64 `json:"net_pnl"`   // In the configured base currency
+	AvgTrade   float64 `json:"avg_trade"` // NetPnL / TradeCount
+	// AvgReturnPct is the mean per-trade return (pnl_percent), which
+	// normalizes for position size
+	AvgReturnPct float64 `json:"avg_return_pct"`
+	// SharpeRatio is mean / standard deviation of per-trade returns
+	// (not annualized; 0 with fewer than two trades or no variance)
+	SharpeRatio float64 `json:"sharpe_ratio"`
+}
+
+// StrategyComparisonResponse represents the strategy comparison response body
+type StrategyComparisonResponse struct {
+	BaseCurrency string                `json:"base_currency"`
+	Strategies   []StrategyPerformance `json:"strategies"`
+}
+
+// EquityPoint is the cumulative realized P&L after a closed trade, or after
+// the last trade of a bucket once older points have been downsampled
+type EquityPoint struct {
+	Time   time.Time `json:"time"`   // Exit time of the bucket's last trade
+	Equity float64   `json:"equity"` // Cumulative realized P&L at Time
+	Low    float64   `json:"low"`    // Lowest equity within the bucket
+	High   float64   `json:"high"`   // Highest equity within the bucket
+	Trades int       `json:"trades"` // Closed trades in the bucket
+}
+
+// EquityCurveResponse represents the equity curve response body
+type EquityCurveResponse struct {
+	TotalTrades int           `json:"total_trades"`
+	NetPnL      float64       `json:"net_pnl"`
+	MaxDrawdown float64       `json:"max_drawdown"` // Over every trade, not just the points kept
+	Points      []EquityPoint `json:"points"`
+}
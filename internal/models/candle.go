@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Candle is an OHLC bar aggregated from the ticks of one symbol over
+// [Start, End)
+type Candle struct {
+	Symbol string    `json:"symbol"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume int64     `json:"volume"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+/*
+Pending Order Model Flow:
+
+1. Memory Structure:
+   PendingOrder
+   ├── ID: string          // Format: "order-{uuid}"
+   ├── Symbol: string      // Trading symbol (e.g., "AAPL")
+   ├── Side: Side          // Side of the trade opened on fill
+   ├── Quantity: float64   // Units of the trade opened on fill
+   ├── LimitPrice: float64 // Worst price the order may fill at
+   └── PlacedTime: time.Time
+
+2. Crossing:
+   A buy limit fills when a tick trades at or below the limit, a sell
+   limit when a tick trades at or above it. The fill opens a trade at the
+   tick's price, which is never worse than the limit.
+   Example:
+   Buy limit 149.00 → tick 149.50 (pending) → tick 148.90 (fills at 148.90)
+
+3. Data Flow:
+   Request: {"symbol": "AAPL", "side": "buy", "quantity": 10, "limit_price": 149.00}
+   Internal: PendingOrder{ID: "order-abc", LimitPrice: 149.00, ...}
+   Response: {"order_id": "order-abc", ...}
+*/
+
+// PendingOrder is a limit order resting until a tick crosses its price
+type PendingOrder struct {
+	ID         string    `json:"order_id"`
+	Symbol     string    `json:"symbol"`
+	Side       Side      `json:"side"`
+	Quantity   float64   `json:"quantity"`
+	LimitPrice float64   `json:"limit_price"`
+	PlacedTime time.Time `json:"placed_time"`
+}
+
+// CrossedBy reports whether a trade at price reaches the order's limit
+func (o *PendingOrder) CrossedBy(price float64) bool {
+	if o.Side == SideSell {
+		return price >= o.LimitPrice
+	}
+	return price <= o.LimitPrice
+}
+
+// Pending order error codes (returned as TradeError)
+const (
+	ErrInvalidLimitPrice = "INVALID_LIMIT_PRICE"
+	ErrOrderNotFound     = "ORDER_NOT_FOUND"
+	ErrPendingOrderLimit = "PENDING_ORDER_LIMIT"
+)
+
+// LimitOrderRequest represents the request body for placing a limit order
+type LimitOrderRequest struct {
+	Symbol     string  `json:"symbol"`
+	Side       Side    `json:"side,omitempty"`     // Defaults to SideBuy
+	Quantity   float64 `json:"quantity,omitempty"` // Defaults to DefaultQuantity
+	LimitPrice float64 `json:"limit_price"`
+}
+
+// CancelOrderRequest represents the request body for cancelling a pending order
+type CancelOrderRequest struct {
+	OrderID string `json:"order_id"`
+}
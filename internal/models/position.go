@@ -0,0 +1,44 @@
+package models
+
+/*
+Position Model:
+
+   A Position combines the open trades one strategy holds on one symbol
+   and side, so multi-entry strategies (e.g. martingale adding to a
+   losing position) can be read as a single holding.
+
+   Open lots of strategy "martingale-abc" on AAPL (long):
+   ├── 1 @ 100
+   ├── 2 @ 95
+   └── 4 @ 90
+   Position{Quantity: 7, AvgEntryPrice: (100 + 190 + 360) / 7 = 92.857}
+
+   RealizedPnL sums the P&L of the same strategy's closed trades on that
+   symbol and side. CurrentPrice and UnrealizedPnL are set by Mark and
+   omitted when no recent price is known (PriceStale).
+*/
+
+// Position is the net holding of a strategy on a symbol and side
+type Position struct {
+	StrategyID    string   `json:"strategy_id,omitempty"` // Empty for manual trades
+	Symbol        string   `json:"symbol"`
+	Side          Side     `json:"side"`
+	Quantity      float64  `json:"quantity"`        // Total open quantity
+	AvgEntryPrice float64  `json:"avg_entry_price"` // Quantity-weighted entry price of the open trades
+	OpenTrades    int      `json:"open_trades"`
+	RealizedPnL   float64  `json:"realized_pnl"`
+	CurrentPrice  *float64 `json:"current_price,omitempty"`
+	UnrealizedPnL *float64 `json:"unrealized_pnl,omitempty"`
+	PriceStale    bool     `json:"price_stale,omitempty"`
+}
+
+// Mark values the position at price, setting CurrentPrice and UnrealizedPnL
+func (p *Position) Mark(price float64) {
+	pnl := (price - p.AvgEntryPrice) * p.Quantity
+	if p.Side == SideSell {
+		pnl = -pnl
+	}
+	p.CurrentPrice = &price
+	p.UnrealizedPnL = &pnl
+	p.PriceStale = false
+}
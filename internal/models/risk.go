@@ -0,0 +1,54 @@
+package models
+
+import "fmt"
+
+/*
+Risk Model Flow:
+
+1. Position Sizing:
+   Request → current price (entry) → quantity = risk_amount / |entry - stop_loss|
+   Example:
+   Request: {"symbol": "AAPL", "stop_loss": 145.00, "risk_amount": 100}
+   Current price: 150.00
+   Response: {"quantity": 20, "entry_price": 150.00, ...}
+
+2. Error Handling:
+   - Invalid symbol / stop loss / risk amount
+   - No current price for symbol
+   - Zero distance between entry and stop loss
+*/
+
+// PositionSizeRequest represents the request body for a position sizing suggestion
+type PositionSizeRequest struct {
+	Symbol     string  `json:"symbol"`
+	StopLoss   float64 `json:"stop_loss"`
+	RiskAmount float64 `json:"risk_amount"`
+}
+
+// PositionSizeResponse represents a suggested position size
+type PositionSizeResponse struct {
+	Symbol        string  `json:"symbol"`
+	EntryPrice    float64 `json:"entry_price"`
+	StopLoss      float64 `json:"stop_loss"`
+	RiskAmount    float64 `json:"risk_amount"`
+	Quantity      float64 `json:"quantity"`
+	PositionValue float64 `json:"position_value"`
+}
+
+// RiskError represents risk-calculation errors
+type RiskError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Error codes
+const (
+	ErrInvalidRiskParameters = "INVALID_RISK_PARAMETERS"
+	ErrPriceUnavailable      = "PRICE_UNAVAILABLE"
+	ErrZeroStopDistance      = "ZERO_STOP_DISTANCE"
+)
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,10 +18,13 @@ Strategy Model Flow and Structure:
    ├── Parameters: map[string]any    // Strategy configuration
    │   ├── symbol: string           // Trading symbol
    │   ├── period: int             // Time period for calculations
-   │   └── threshold: float64      // Trading threshold
+   │   ├── threshold: float64      // Trading threshold
+   │   ├── trade_store: string     // Optional: "shared" (default) or "isolated"
+   │   ├── dry_run: bool           // Optional: paper trade instead (see DryRun)
+   │   └── flatten_on_stop: bool   // Optional: close its open trades on stop
    ├── StartTime: time.Time         // When strategy started
    ├── StopTime: *time.Time         // When strategy stopped (nil if active)
-   └── Status: string               // "active" or "stopped"
+   └── Status: string               // "active", "paused" or "stopped"
 
 2. Object Lifecycle:
    a. Creation:
@@ -34,8 +38,14 @@ Strategy Model Flow and Structure:
       2. Status indicates if strategy is running
       3. ID used for lookups and references
 
-   c. Stopping:
-      1. Client requests stop by ID
+   c. Pausing:
+      1. Client requests pause by ID
+      2. Pause() sets status to paused; the strategy stays active but
+         ignores ticks
+      3. Resume() sets status back to active
+
+   d. Stopping:
+      1. Client requests stop by ID (active or paused)
       2. Stop() sets stop time
       3. Updates status to stopped
 
@@ -62,7 +72,14 @@ type Strategy struct {
 	Parameters map[string]interface{} `json:"parameters"`  // Strategy parameters
 	StartTime  time.Time             `json:"start_time"`  // When strategy started
 	StopTime   *time.Time            `json:"stop_time"`   // When strategy stopped (nil if active)
-	Status     string                `json:"status"`      // "active" or "stopped"
+	Status     string                `json:"status"`      // "active", "paused" or "stopped"
+}
+
+// ActiveStrategy is an active strategy with its executor's runtime state
+// Runtime is omitted when the executor reports none
+type ActiveStrategy struct {
+	*Strategy
+	Runtime map[string]interface{} `json:"runtime,omitempty"`
 }
 
 // NewStrategy creates a new strategy instance
@@ -83,6 +100,155 @@ func (s *Strategy) Stop() {
 	s.Status = "stopped"
 }
 
+// Pause marks the strategy as paused
+func (s *Strategy) Pause() {
+	s.Status = "paused"
+}
+
+// Resume marks a paused strategy as active again
+func (s *Strategy) Resume() {
+	s.Status = "active"
+}
+
+// Trade store modes selectable through the "trade_store" strategy parameter
+const (
+	ParamTradeStore = "trade_store"
+
+	// TradeStoreShared runs the strategy against the shared trade store (default)
+	TradeStoreShared = "shared"
+
+	// TradeStoreIsolated runs the strategy against its own in-memory trade store
+	TradeStoreIsolated = "isolated"
+)
+
+// ParamFlattenOnStop closes the strategy's open trades when it is stopped
+const ParamFlattenOnStop = "flatten_on_stop"
+
+// FlattenOnStop reports whether the strategy parameters request closing the
+// strategy's open trades on stop (default false)
+func FlattenOnStop(params map[string]interface{}) bool {
+	enabled, _ := params[ParamFlattenOnStop].(bool)
+	return enabled
+}
+
+// symbolParams are the strategy parameters naming a traded symbol:
+// "symbol", or "symbol_a" and "symbol_b" for pairs strategies
+var symbolParams = []string{"symbol", "symbol_a", "symbol_b"}
+
+// TradesSymbol reports whether the strategy parameters name symbol as one
+// of the strategy's traded symbols (case-insensitive)
+func TradesSymbol(params map[string]interface{}, symbol string) bool {
+	for _, name := range symbolParams {
+		if value, ok := params[name].(string); ok && strings.EqualFold(value, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParamDryRun runs the strategy against the paper trade store: its trades
+// are recorded and broadcast on the paper_trades feed but never reach the
+// real trade store (overrides trade_store)
+const ParamDryRun = "dry_run"
+
+// DryRun reports whether the strategy parameters request paper trading
+// (default false)
+func DryRun(params map[string]interface{}) bool {
+	enabled, _ := params[ParamDryRun].(bool)
+	return enabled
+}
+
+// TradeStoreMode returns the trade store mode requested by the strategy
+// parameters, defaulting to TradeStoreShared
+func TradeStoreMode(params map[string]interface{}) (string, error) {
+	raw, ok := params[ParamTradeStore]
+	if !ok || raw == nil {
+		return TradeStoreShared, nil
+	}
+
+	mode, ok := raw.(string)
+	if !ok || (mode != TradeStoreShared && mode != TradeStoreIsolated) {
+		return "", &StrategyError{
+			Code:    ErrInvalidStrategy,
+			Message: fmt.Sprintf("Invalid %s parameter: must be %q or %q", ParamTradeStore, TradeStoreShared, TradeStoreIsolated),
+		}
+	}
+	return mode, nil
+}
+
+// Dead-man switch parameters: a strategy started with stop_on_disconnect
+// is stopped when the WebSocket session named by session_id disconnects
+const (
+	ParamStopOnDisconnect = "stop_on_disconnect"
+	ParamSessionID        = "session_id"
+)
+
+// DisconnectSession returns the session whose disconnect should stop the
+// strategy, or "" when stop_on_disconnect is not set
+func DisconnectSession(params map[string]interface{}) (string, error) {
+	raw, ok := params[ParamStopOnDisconnect]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	enabled, ok := raw.(bool)
+	if !ok {
+		return "", &StrategyError{
+			Code:    ErrInvalidStrategy,
+			Message: fmt.Sprintf("Invalid %s parameter: must be a boolean", ParamStopOnDisconnect),
+		}
+	}
+	if !enabled {
+		return "", nil
+	}
+
+	sessionID, ok := params[ParamSessionID].(string)
+	if !ok || sessionID == "" {
+		return "", &StrategyError{
+			Code:    ErrInvalidStrategy,
+			Message: fmt.Sprintf("%s requires a %s parameter", ParamStopOnDisconnect, ParamSessionID),
+		}
+	}
+	return sessionID, nil
+}
+
+// ParamCapitalAllocation caps the capital a strategy may have tied up in
+// open trades (entry price x quantity): up to 1 it is a fraction of the
+// account balance, above 1 an amount in dollars
+const ParamCapitalAllocation = "capital_allocation"
+
+// CapitalAllocation returns the strategy's capital allocation as given (a
+// fraction up to 1, dollars above), or 0 when capital_allocation is not set
+// (unlimited). AllocatedCapital converts it into dollars
+func CapitalAllocation(params map[string]interface{}) (float64, error) {
+	raw, ok := params[ParamCapitalAllocation]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	allocation, ok := raw.(float64)
+	if !ok || allocation <= 0 {
+		return 0, &StrategyError{
+			Code:    ErrInvalidStrategy,
+			Message: fmt.Sprintf("Invalid %s parameter: must be a fraction of the account balance (up to 1) or a dollar amount", ParamCapitalAllocation),
+		}
+	}
+	return allocation, nil
+}
+
+// AllocatedCapital converts a capital allocation into dollars, taking a
+// fraction of the account balance; a fraction needs a positive balance
+func AllocatedCapital(allocation, accountBalance float64) (float64, error) {
+	if allocation <= 0 || allocation > 1 {
+		return allocation, nil
+	}
+	if accountBalance <= 0 {
+		return 0, &StrategyError{
+			Code:    ErrInvalidStrategy,
+			Message: fmt.Sprintf("Invalid %s parameter: a fraction needs an account balance (trading.accountBalance)", ParamCapitalAllocation),
+		}
+	}
+	return allocation * accountBalance, nil
+}
+
 // StrategyError represents strategy-related errors
 type StrategyError struct {
 	Code    string `json:"code"`
@@ -98,6 +264,8 @@ const (
 	ErrStrategyNotFound = "STRATEGY_NOT_FOUND"
 	ErrAlreadyStopped  = "ALREADY_STOPPED"
 	ErrInvalidStrategy = "INVALID_STRATEGY"
+	ErrAlreadyPaused   = "ALREADY_PAUSED"
+	ErrNotPaused       = "NOT_PAUSED"
 )
 
 // Request/Response types
@@ -113,12 +281,49 @@ type StartStrategyResponse struct {
 }
 
 type StopStrategyRequest struct {
+	ID      string `json:"id"`
+	Flatten bool   `json:"flatten,omitempty"` // Close the strategy's open trades too
+}
+
+// PauseStrategyRequest is the body of pause and resume requests
+type PauseStrategyRequest struct {
 	ID string `json:"id"`
 }
 
 type StopStrategyResponse struct {
-	ID        string     `json:"id"`
-	StartTime time.Time  `json:"start_time"`
-	StopTime  time.Time  `json:"stop_time"`
-	Status    string     `json:"status"`
+	ID           string     `json:"id"`
+	StartTime    time.Time  `json:"start_time"`
+	StopTime     time.Time  `json:"stop_time"`
+	Status       string     `json:"status"`
+	ClosedTrades []string   `json:"closed_trades,omitempty"` // Trades closed by flatten
+}
+
+// StopAllResponse summarizes a stop-all (kill switch) request
+type StopAllResponse struct {
+	StoppedStrategies []string `json:"stopped_strategies"` // IDs stopped by this request
+	ClosedTrades      []string `json:"closed_trades"`      // IDs closed by flatten
+	Errors            []string `json:"errors,omitempty"`   // Strategies and trades left as they were
+}
+
+// StrategyErrorEntry is one recorded strategy executor error
+type StrategyErrorEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// StrategyErrorStats reports a strategy's retained errors and how many
+// older errors were dropped because the buffer was full
+type StrategyErrorStats struct {
+	StrategyID string               `json:"strategy_id"`
+	Capacity   int                  `json:"capacity"`
+	Overflow   int                  `json:"overflow"`
+	Errors     []StrategyErrorEntry `json:"errors"`
+}
+
+// StrategyErrorEvent is one strategy executor error as streamed on the
+// strategy_errors feed
+type StrategyErrorEvent struct {
+	StrategyID string    `json:"strategy_id"`
+	Error      string    `json:"error"`
+	Timestamp  time.Time `json:"timestamp"`
 }
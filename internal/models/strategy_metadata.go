@@ -1,5 +1,28 @@
 package models
 
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+Parameter Validation:
+
+   ValidateParameters(metadata, params), before a strategy is stored:
+   ├── required parameter missing (or null) → INVALID_STRATEGY
+   ├── declared parameter of the wrong type → INVALID_STRATEGY
+   │   "string"  → string
+   │   "number"  → float64 (encoding/json decodes every JSON number, whole
+   │               or not, into float64; executors read numbers as float64)
+   │   "boolean" → bool
+   │   other     → not checked, left to the strategy factory
+   ├── shared runner options (SharedParameters) → accepted, checked by
+   │   their own parsers (TradeStoreMode, CapitalAllocation, ...), since
+   │   the runner reads them rather than each strategy declaring them
+   └── any other parameter → INVALID_STRATEGY, so a typo such as "dryrun"
+       or "flaten_on_stop" is rejected instead of silently ignored
+*/
+
 // StrategyMetadata represents available strategy information
 type StrategyMetadata struct {
 	Name       string          `json:"name"`
@@ -14,3 +37,78 @@ type ParameterInfo struct {
 	Required    bool   `json:"required"`
 	Description string `json:"description"`
 }
+
+// SharedParameters are the options read by the runner or strategy handler
+// for every strategy, accepted whether or not a strategy declares them
+var SharedParameters = []string{
+	ParamTradeStore,
+	ParamFlattenOnStop,
+	ParamDryRun,
+	ParamStopOnDisconnect,
+	ParamSessionID,
+	ParamCapitalAllocation,
+}
+
+// ValidateParameters checks decoded JSON params against a strategy's
+// metadata: every required parameter must be present, every declared
+// parameter that is present must have the declared type, and every other
+// parameter must be one of SharedParameters
+// Returns an INVALID_STRATEGY StrategyError naming the first bad parameter
+func ValidateParameters(metadata StrategyMetadata, params map[string]interface{}) error {
+	known := make(map[string]bool, len(metadata.Parameters)+len(SharedParameters))
+	for _, name := range SharedParameters {
+		known[name] = true
+	}
+	for _, param := range metadata.Parameters {
+		known[param.Name] = true
+		value, present := params[param.Name]
+		if !present || value == nil {
+			if param.Required {
+				return &StrategyError{
+					Code:    ErrInvalidStrategy,
+					Message: fmt.Sprintf("Missing required parameter: %s", param.Name),
+				}
+			}
+			continue
+		}
+		if !matchesParameterType(param.Type, value) {
+			return &StrategyError{
+				Code:    ErrInvalidStrategy,
+				Message: fmt.Sprintf("Invalid parameter %s: must be a %s", param.Name, param.Type),
+			}
+		}
+	}
+
+	// Report unknown parameters in a stable order
+	var unknown []string
+	for name := range params {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return &StrategyError{
+			Code:    ErrInvalidStrategy,
+			Message: fmt.Sprintf("Unknown parameter: %s", unknown[0]),
+		}
+	}
+	return nil
+}
+
+// matchesParameterType reports whether a decoded JSON value has the metadata type
+func matchesParameterType(paramType string, value interface{}) bool {
+	switch paramType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default:
+		return true // Unknown types are left to the factory
+	}
+}
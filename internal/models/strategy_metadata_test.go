@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateParameters(t *testing.T) {
+	metadata := StrategyMetadata{
+		Name: "test",
+		Parameters: []ParameterInfo{
+			{Name: "symbol", Type: "string", Required: true},
+			{Name: "window", Type: "number", Required: true},
+			{Name: "preload", Type: "boolean"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:   "declared parameters",
+			params: map[string]interface{}{"symbol": "AAPL", "window": 14.0, "preload": true},
+		},
+		{
+			name: "shared runner options",
+			params: map[string]interface{}{
+				"symbol": "AAPL", "window": 14.0,
+				"trade_store": "isolated", "flatten_on_stop": true, "dry_run": false,
+				"stop_on_disconnect": true, "session_id": "abc", "capital_allocation": 5000.0,
+			},
+		},
+		{
+			name:    "missing required",
+			params:  map[string]interface{}{"window": 14.0},
+			wantErr: "Missing required parameter: symbol",
+		},
+		{
+			name:    "null required",
+			params:  map[string]interface{}{"symbol": nil, "window": 14.0},
+			wantErr: "Missing required parameter: symbol",
+		},
+		{
+			name:    "wrong type",
+			params:  map[string]interface{}{"symbol": "AAPL", "window": "14"},
+			wantErr: "Invalid parameter window: must be a number",
+		},
+		{
+			name:    "typo of a shared option",
+			params:  map[string]interface{}{"symbol": "AAPL", "window": 14.0, "dryrun": true},
+			wantErr: "Unknown parameter: dryrun",
+		},
+		{
+			name:    "typos reported in name order",
+			params:  map[string]interface{}{"symbol": "AAPL", "window": 14.0, "flaten_on_stop": true, "capital": 1.0},
+			wantErr: "Unknown parameter: capital",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParameters(metadata, tt.params)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateParameters: %v", err)
+				}
+				return
+			}
+			strategyErr, ok := err.(*StrategyError)
+			if !ok || strategyErr.Code != ErrInvalidStrategy {
+				t.Fatalf("error %v, want an INVALID_STRATEGY StrategyError", err)
+			}
+			if !strings.Contains(strategyErr.Message, tt.wantErr) {
+				t.Errorf("message %q, want %q", strategyErr.Message, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"time"
 )
 
@@ -11,11 +12,19 @@ Trade Model Flow and Memory Structure:
 1. Memory Structure:
    Trade
    ├── ID: string        // Format: "trade-{uuid}"
+   ├── StrategyID: string // Opening strategy (empty for manual trades)
    ├── Symbol: string    // Trading symbol (e.g., "AAPL")
+   ├── Side: Side        // "buy" (long, default) or "sell" (short)
+   ├── Quantity: float64 // Position size in units (default 1)
    ├── EntryPrice: float64
    ├── ExitPrice: float64 (optional)
    ├── EntryTime: time.Time
-   └── ExitTime: time.Time (optional)
+   ├── ExitTime: time.Time (optional)
+   ├── TakeProfit: float64 // Optional exit level closing the trade in profit
+   ├── StopLoss: float64   // Optional exit level closing the trade at a loss
+   ├── CloseReason: string // Why the trade was closed, set on close
+   ├── PnL: float64      // Realized P&L, set on close
+   └── PnLPercent: float64 // PnL as a percentage of entry notional, set on close
 
 2. Data Flow:
    a. Buy Trade:
@@ -25,28 +34,228 @@ Trade Model Flow and Memory Structure:
       Internal: Trade{ID: "trade-abc", Symbol: "AAPL", ...}
       Response: {"trade_id": "trade-abc", ...}
 
+      With exits: {"symbol": "AAPL", "entry_price": 150.25,
+                   "take_profit": 155.00, "stop_loss": 148.00}
+      The trade is closed at the take-profit or stop-loss level by the
+      first tick that crosses it (see ExitCrossed)
+
    b. Sell Trade:
       Request → Update Trade (add exit details) → Response
       Example:
-      Request: {"trade_id": "trade-abc"}
+      Request: {"trade_id": "trade-abc", "exit_price": 151.00}
       Internal: Trade{ExitPrice: 151.00, ExitTime: now}
       Response: Complete trade details
 
 3. Error Handling:
    - Invalid symbol
    - Invalid price
+   - Invalid quantity
    - Trade not found
    - Trade already closed
 */
 
 // Trade represents a trading position
 type Trade struct {
-	ID         string     `json:"trade_id"`
-	Symbol     string     `json:"symbol"`
-	EntryPrice float64    `json:"entry_price"`
-	ExitPrice  float64    `json:"exit_price,omitempty"`
-	EntryTime  time.Time  `json:"entry_time"`
-	ExitTime   time.Time  `json:"exit_time,omitempty"`
+	ID          string    `json:"trade_id"`
+	StrategyID  string    `json:"strategy_id,omitempty"`
+	Symbol      string    `json:"symbol"`
+	Side        Side      `json:"side"`
+	Quantity    float64   `json:"quantity"`
+	EntryPrice  float64   `json:"entry_price"`
+	ExitPrice   float64   `json:"exit_price,omitempty"`
+	EntryTime   time.Time `json:"entry_time"`
+	ExitTime    time.Time `json:"exit_time,omitempty"`
+	TakeProfit  float64   `json:"take_profit,omitempty"` // 0 = none
+	StopLoss    float64   `json:"stop_loss,omitempty"`   // 0 = none
+	CloseReason string    `json:"close_reason,omitempty"`
+	PnL         float64   `json:"pnl"`
+	PnLPercent  float64   `json:"pnl_percent"`
+}
+
+// RealizedPnL returns the P&L of a closed trade in its quote currency
+// Long trades gain when price rises, short trades when it falls; open
+// trades have no realized P&L
+func (t *Trade) RealizedPnL() float64 {
+	if t.ExitTime.IsZero() {
+		return 0
+	}
+	if t.Side == SideSell {
+		return (t.EntryPrice - t.ExitPrice) * t.Quantity
+	}
+	return (t.ExitPrice - t.EntryPrice) * t.Quantity
+}
+
+// UnrealizedPnL returns the P&L an open trade would realize if closed at mark
+func (t *Trade) UnrealizedPnL(mark float64) float64 {
+	if t.Side == SideSell {
+		return (t.EntryPrice - mark) * t.Quantity
+	}
+	return (mark - t.EntryPrice) * t.Quantity
+}
+
+// ComputePnL sets PnL and PnLPercent from the entry/exit prices, quantity and side
+// PnLPercent is relative to the entry notional and 0 when that is 0
+func (t *Trade) ComputePnL() {
+	t.PnL = t.RealizedPnL()
+	t.PnLPercent = 0
+	if notional := t.EntryPrice * t.Quantity; notional != 0 {
+		t.PnLPercent = t.PnL / notional * 100
+	}
+}
+
+// ExitCrossed reports whether price crosses the trade's take-profit or
+// stop-loss, and returns the level to close at and the close reason
+// A long trade takes profit at or above TakeProfit and stops out at or
+// below StopLoss; a short trade the other way round
+func (t *Trade) ExitCrossed(price float64) (float64, string, bool) {
+	if t.Side == SideSell {
+		if t.StopLoss > 0 && price >= t.StopLoss {
+			return t.StopLoss, CloseReasonStopLoss, true
+		}
+		if t.TakeProfit > 0 && price <= t.TakeProfit {
+			return t.TakeProfit, CloseReasonTakeProfit, true
+		}
+		return 0, "", false
+	}
+	if t.StopLoss > 0 && price <= t.StopLoss {
+		return t.StopLoss, CloseReasonStopLoss, true
+	}
+	if t.TakeProfit > 0 && price >= t.TakeProfit {
+		return t.TakeProfit, CloseReasonTakeProfit, true
+	}
+	return 0, "", false
+}
+
+// HasExits reports whether a take-profit or stop-loss is attached
+func (t *Trade) HasExits() bool {
+	return t.TakeProfit > 0 || t.StopLoss > 0
+}
+
+// ValidateNewTrade runs the checks every store applies before opening a
+// trade: a known side, a positive quantity, a positive entry price and exit
+// levels on the right side of the entry
+func ValidateNewTrade(side Side, entryPrice, quantity, takeProfit, stopLoss float64) error {
+	if !side.Valid() {
+		return &TradeError{
+			Code:    ErrInvalidSide,
+			Message: fmt.Sprintf("Invalid side: %q", side),
+		}
+	}
+	if quantity <= 0 || math.IsInf(quantity, 0) || math.IsNaN(quantity) {
+		return &TradeError{
+			Code:    ErrInvalidQuantity,
+			Message: fmt.Sprintf("Invalid quantity: %v", quantity),
+		}
+	}
+	if err := ValidateEntryPrice(entryPrice); err != nil {
+		return err
+	}
+	return ValidateExits(side, entryPrice, takeProfit, stopLoss)
+}
+
+// ValidateEntryPrice rejects non-positive or non-finite entry prices
+func ValidateEntryPrice(entryPrice float64) error {
+	if entryPrice <= 0 || math.IsInf(entryPrice, 0) || math.IsNaN(entryPrice) {
+		return &TradeError{
+			Code:    ErrInvalidEntryPrice,
+			Message: fmt.Sprintf("Invalid entry price: %v", entryPrice),
+		}
+	}
+	return nil
+}
+
+// ValidateExits checks take-profit and stop-loss levels (0 = none) against
+// the entry price: a long trade needs takeProfit above and stopLoss below
+// the entry, a short trade the reverse
+func ValidateExits(side Side, entryPrice, takeProfit, stopLoss float64) error {
+	if takeProfit < 0 || math.IsInf(takeProfit, 0) || math.IsNaN(takeProfit) {
+		return &TradeError{
+			Code:    ErrInvalidTakeProfit,
+			Message: fmt.Sprintf("Invalid take profit: %v", takeProfit),
+		}
+	}
+	if stopLoss < 0 || math.IsInf(stopLoss, 0) || math.IsNaN(stopLoss) {
+		return &TradeError{
+			Code:    ErrInvalidStopLoss,
+			Message: fmt.Sprintf("Invalid stop loss: %v", stopLoss),
+		}
+	}
+
+	above, below := "above", "below"
+	long := side != SideSell
+	if !long {
+		above, below = below, above
+	}
+	if takeProfit > 0 && (takeProfit > entryPrice) != long {
+		return &TradeError{
+			Code:    ErrInvalidTakeProfit,
+			Message: fmt.Sprintf("Take profit %v must be %s the entry price %v", takeProfit, above, entryPrice),
+		}
+	}
+	if stopLoss > 0 && (stopLoss < entryPrice) != long {
+		return &TradeError{
+			Code:    ErrInvalidStopLoss,
+			Message: fmt.Sprintf("Stop loss %v must be %s the entry price %v", stopLoss, below, entryPrice),
+		}
+	}
+	return nil
+}
+
+// ValidateExitPrice rejects non-positive or non-finite exit prices
+func ValidateExitPrice(exitPrice float64) error {
+	if exitPrice <= 0 || math.IsInf(exitPrice, 0) || math.IsNaN(exitPrice) {
+		return &TradeError{
+			Code:    ErrInvalidExitPrice,
+			Message: fmt.Sprintf("Invalid exit price: %v", exitPrice),
+		}
+	}
+	return nil
+}
+
+// Close reasons recorded on closed trades
+const (
+	// CloseReasonManual is a close requested through the REST API
+	CloseReasonManual = "manual"
+
+	// CloseReasonStrategy is a close made by a strategy's own logic
+	CloseReasonStrategy = "strategy"
+
+	// CloseReasonStrategyStopped is a close made when a strategy with
+	// flatten_on_stop was stopped
+	CloseReasonStrategyStopped = "strategy_stopped"
+
+	// CloseReasonTakeProfit is a close at the trade's take-profit level
+	CloseReasonTakeProfit = "take_profit"
+
+	// CloseReasonStopLoss is a close at the trade's stop-loss level
+	CloseReasonStopLoss = "stop_loss"
+
+	// CloseReasonStopAll is a close made by a stop-all request with flatten
+	CloseReasonStopAll = "stop_all"
+)
+
+// Side is the direction of a position
+type Side string
+
+const (
+	// SideBuy is a long position
+	SideBuy Side = "buy"
+
+	// SideSell is a short position
+	SideSell Side = "sell"
+)
+
+// Valid reports whether the side is a known direction
+func (s Side) Valid() bool {
+	return s == SideBuy || s == SideSell
+}
+
+// Opposite returns the opposite direction
+func (s Side) Opposite() Side {
+	if s == SideSell {
+		return SideBuy
+	}
+	return SideSell
 }
 
 // TradeError represents trading-related errors
@@ -64,11 +273,22 @@ func (e *TradeError) Error() string {
 const (
 	// Buy/Sell errors
 	ErrInvalidSymbol      = "INVALID_SYMBOL"
+	ErrInvalidSide        = "INVALID_SIDE"
 	ErrInvalidEntryPrice  = "INVALID_ENTRY_PRICE"
+	ErrInvalidQuantity    = "INVALID_QUANTITY"
+	ErrNoOpenLots         = "NO_OPEN_LOTS"
+	ErrInvalidExitPrice   = "INVALID_EXIT_PRICE"
 	ErrTradeCreation      = "TRADE_CREATION_FAILED"
 	ErrTradeNotFound      = "TRADE_NOT_FOUND"
 	ErrTradeAlreadyClosed = "TRADE_ALREADY_CLOSED"
 	ErrTradeClosing       = "TRADE_CLOSING_FAILED"
+	ErrStalePrice         = "STALE_PRICE"
+	ErrSymbolDisabled     = "SYMBOL_DISABLED"
+	ErrAllocationExceeded = "ALLOCATION_EXCEEDED"
+	ErrInvalidTakeProfit  = "INVALID_TAKE_PROFIT"
+	ErrInvalidStopLoss    = "INVALID_STOP_LOSS"
+	ErrPositionLimit      = "POSITION_LIMIT"
+	ErrRiskLimit          = "RISK_LIMIT"
 
 	// Open Positions errors
 	ErrOpenPositionsFetch    = "OPEN_POSITIONS_FETCH_FAILED"
@@ -84,10 +304,89 @@ const (
 // CreateTradeRequest represents the request body for creating a trade
 type CreateTradeRequest struct {
 	Symbol     string  `json:"symbol"`
+	Side       Side    `json:"side,omitempty"` // Defaults to SideBuy
 	EntryPrice float64 `json:"entry_price"`
+	Quantity   float64 `json:"quantity,omitempty"`    // Defaults to DefaultQuantity
+	TakeProfit float64 `json:"take_profit,omitempty"` // Optional take-profit exit level
+	StopLoss   float64 `json:"stop_loss,omitempty"`   // Optional stop-loss exit level
 }
 
+// DefaultQuantity is the position size used when a request omits quantity
+const DefaultQuantity = 1.0
+
 // CloseTradeRequest represents the request body for closing a trade
 type CloseTradeRequest struct {
-	TradeID string `json:"trade_id"`
+	TradeID   string  `json:"trade_id"`
+	ExitPrice float64 `json:"exit_price,omitempty"` // Defaults to the latest tick price
+}
+
+// CloseSymbolRequest represents the request body for closing lots by symbol
+type CloseSymbolRequest struct {
+	Symbol    string  `json:"symbol"`
+	Quantity  float64 `json:"quantity,omitempty"`   // 0 closes every open lot
+	ExitPrice float64 `json:"exit_price,omitempty"` // Defaults to the latest tick price
+}
+
+// OpenPosition is an open trade valued at the latest cached price
+// CurrentPrice, MarketValue and UnrealizedPnL are omitted when the symbol
+// has no recent price; PriceStale marks such positions
+type OpenPosition struct {
+	*Trade
+	CurrentPrice  *float64 `json:"current_price,omitempty"`
+	MarketValue   *float64 `json:"market_value,omitempty"`
+	UnrealizedPnL *float64 `json:"unrealized_pnl,omitempty"`
+	PriceStale    bool     `json:"price_stale,omitempty"`
+}
+
+// TradeHistoryDelta is a trade_history update for delta-mode subscribers:
+// the trades added to and IDs removed from the history since the last update
+type TradeHistoryDelta struct {
+	Added   []*Trade `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// OpenPositionsDelta is an open_positions update for diff-mode subscribers:
+// the positions opened, the trade IDs closed and the positions changed
+// since the last update
+// Seq numbers the diffs since the subscription's last full snapshot (1, 2,
+// ...); a gap means an update was lost and the client should resubscribe
+type OpenPositionsDelta struct {
+	Seq     uint64          `json:"seq"`
+	Added   []*OpenPosition `json:"added"`
+	Removed []string        `json:"removed"`
+	Updated []*OpenPosition `json:"updated"`
+}
+
+// Paper trade events sent on the paper_trades feed
+const (
+	PaperTradeOpened = "opened"
+	PaperTradeClosed = "closed"
+)
+
+// PaperLedger is the paper_trades snapshot: open paper trades (oldest entry
+// first) and closed ones (newest exit first)
+type PaperLedger struct {
+	Open   []*Trade `json:"open"`
+	Closed []*Trade `json:"closed"`
+}
+
+// PaperTradeEvent is a paper_trades update: a paper trade opened or closed
+type PaperTradeEvent struct {
+	Event string `json:"event"` // PaperTradeOpened or PaperTradeClosed
+	Trade *Trade `json:"trade"`
+}
+
+// FlatEvent signals that the last open trade was closed and no positions remain
+type FlatEvent struct {
+	LastTradeID string    `json:"last_trade_id"`
+	Symbol      string    `json:"symbol"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// SymbolExposure aggregates the open trades on a single symbol
+type SymbolExposure struct {
+	Symbol     string  `json:"symbol"`
+	OpenTrades int     `json:"open_trades"`
+	Quantity   float64 `json:"quantity"` // Net quantity (long minus short)
+	NetSide    Side    `json:"net_side"` // Side of the net quantity, empty when flat
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputePnL(t *testing.T) {
+	closed := time.Now()
+	tests := []struct {
+		name        string
+		side        Side
+		quantity    float64
+		entry, exit float64
+		pnl         float64
+		pnlPercent  float64
+	}{
+		{name: "long gain", side: SideBuy, quantity: 2, entry: 100, exit: 110, pnl: 20, pnlPercent: 10},
+		{name: "long loss", side: SideBuy, quantity: 2, entry: 100, exit: 95, pnl: -10, pnlPercent: -5},
+		{name: "short gain", side: SideSell, quantity: 4, entry: 50, exit: 45, pnl: 20, pnlPercent: 10},
+		{name: "short loss", side: SideSell, quantity: 4, entry: 50, exit: 55, pnl: -20, pnlPercent: -10},
+		{name: "break-even", side: SideBuy, quantity: 3, entry: 100, exit: 100},
+		{name: "zero quantity", side: SideBuy, quantity: 0, entry: 100, exit: 110},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trade := &Trade{
+				Side:       tt.side,
+				Quantity:   tt.quantity,
+				EntryPrice: tt.entry,
+				ExitPrice:  tt.exit,
+				ExitTime:   closed,
+			}
+			trade.ComputePnL()
+			if math.Abs(trade.PnL-tt.pnl) > 1e-9 || math.Abs(trade.PnLPercent-tt.pnlPercent) > 1e-9 {
+				t.Errorf("P&L %v (%v%%), want %v (%v%%)", trade.PnL, trade.PnLPercent, tt.pnl, tt.pnlPercent)
+			}
+		})
+	}
+
+	// An open trade has nothing realized yet
+	open := &Trade{Side: SideBuy, Quantity: 1, EntryPrice: 100, ExitPrice: 110}
+	open.ComputePnL()
+	if open.PnL != 0 || open.PnLPercent != 0 {
+		t.Errorf("open trade P&L %v (%v%%), want 0", open.PnL, open.PnLPercent)
+	}
+}
+
+func TestValidateNewTrade(t *testing.T) {
+	tests := []struct {
+		name       string
+		side       Side
+		entry, qty float64
+		tp, sl     float64
+		code       string
+	}{
+		{name: "valid long", side: SideBuy, entry: 100, qty: 1, tp: 110, sl: 90},
+		{name: "valid short", side: SideSell, entry: 100, qty: 1, tp: 90, sl: 110},
+		{name: "unknown side", side: Side("hold"), entry: 100, qty: 1, code: ErrInvalidSide},
+		{name: "zero quantity", side: SideBuy, entry: 100, qty: 0, code: ErrInvalidQuantity},
+		{name: "zero entry", side: SideBuy, entry: 0, qty: 1, code: ErrInvalidEntryPrice},
+		{name: "NaN entry", side: SideBuy, entry: math.NaN(), qty: 1, code: ErrInvalidEntryPrice},
+		{name: "take profit below long entry", side: SideBuy, entry: 100, qty: 1, tp: 95, code: ErrInvalidTakeProfit},
+		{name: "stop loss below short entry", side: SideSell, entry: 100, qty: 1, sl: 95, code: ErrInvalidStopLoss},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNewTrade(tt.side, tt.entry, tt.qty, tt.tp, tt.sl)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			tradeErr, ok := err.(*TradeError)
+			if !ok || tradeErr.Code != tt.code {
+				t.Fatalf("error %v, want code %s", err, tt.code)
+			}
+		})
+	}
+}
@@ -1,24 +1,66 @@
 package service
 
 import (
+	"time"
+
 	"github.com/aumbhatt/auto_trade/internal/config"
+	"github.com/aumbhatt/auto_trade/internal/store"
 	"github.com/aumbhatt/auto_trade/internal/websocket"
 )
 
 // Service represents the main business logic of the application
 type Service struct {
-	config *config.Config
-	hub    *websocket.Hub
+	config    *config.Config
+	hub       *websocket.Hub
+	startTime time.Time
+
+	// Dependencies checked by Health (optional)
+	trades     store.BasicTradeStore
+	strategies store.StrategyStore
+	feed       FeedStatus
+}
+
+// FeedStatus reports whether the tick feed is healthy
+type FeedStatus interface {
+	// SourceError returns the tick source's latest error, nil if healthy
+	SourceError() error
+}
+
+// Health is the service status reported by GET /healthz
+type Health struct {
+	Status           string   `json:"status"` // "ok" or "degraded"
+	StrategiesActive int      `json:"strategies_active"`
+	OpenTrades       int      `json:"open_trades"`
+	UptimeSeconds    float64  `json:"uptime_seconds"`
+	Errors           []string `json:"errors,omitempty"` // Why the service is degraded
 }
 
+// Health statuses
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
 // NewService creates a new instance of Service
 func NewService(cfg *config.Config, hub *websocket.Hub) *Service {
 	return &Service{
-		config: cfg,
-		hub:    hub,
+		config:    cfg,
+		hub:       hub,
+		startTime: time.Now(),
 	}
 }
 
+// SetStores sets the stores whose counts and errors Health reports
+func (s *Service) SetStores(trades store.BasicTradeStore, strategies store.StrategyStore) {
+	s.trades = trades
+	s.strategies = strategies
+}
+
+// SetFeed sets the tick feed whose errors degrade Health
+func (s *Service) SetFeed(feed FeedStatus) {
+	s.feed = feed
+}
+
 // Run starts the service
 func (s *Service) Run() error {
 	// Service is now ready to use the hub for broadcasting messages
@@ -30,3 +72,38 @@ func (s *Service) Run() error {
 func (s *Service) BroadcastMessage(msg websocket.Message) {
 	s.hub.Broadcast(msg)
 }
+
+// Health checks the stores and tick feed
+// The service is degraded when a store query fails or the tick source's
+// latest GetTick returned an error
+func (s *Service) Health() Health {
+	health := Health{
+		Status:        HealthOK,
+		UptimeSeconds: time.Since(s.startTime).Seconds(),
+	}
+
+	if s.trades != nil {
+		if trades, err := s.trades.GetOpenTrades(); err != nil {
+			health.Errors = append(health.Errors, "trade store: "+err.Error())
+		} else {
+			health.OpenTrades = len(trades)
+		}
+	}
+	if s.strategies != nil {
+		if strategies, err := s.strategies.GetActiveStrategies(); err != nil {
+			health.Errors = append(health.Errors, "strategy store: "+err.Error())
+		} else {
+			health.StrategiesActive = len(strategies)
+		}
+	}
+	if s.feed != nil {
+		if err := s.feed.SourceError(); err != nil {
+			health.Errors = append(health.Errors, "tick source: "+err.Error())
+		}
+	}
+
+	if len(health.Errors) > 0 {
+		health.Status = HealthDegraded
+	}
+	return health
+}
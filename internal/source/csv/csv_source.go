@@ -0,0 +1,168 @@
+package csv
+
+import (
+	stdcsv "encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+CSV Tick Source Flow:
+
+1. File Format:
+   One tick per row, in replay order; an optional header row is skipped
+   symbol,price,volume,timestamp
+   AAPL,150.25,1000,2025-01-23T14:23:38Z
+   AAPL,150.40,500,2025-01-23T14:23:39Z
+   Timestamps are RFC 3339 (fractional seconds allowed)
+
+2. Replay Flow:
+   a. NewCSVTickSource reads and validates the whole file up front, so a
+      bad row fails at startup rather than mid-replay
+   b. Each GetTick returns the next row, in file order
+   c. After the last row:
+      ├── loop = false → io.EOF on every further call
+      └── loop = true  → start over from the first row; each pass is
+          shifted forward by the file's time span plus one average tick
+          interval, so timestamps keep increasing across passes
+
+3. Replay Resumption:
+   Ticks carry their historical timestamps, so the source is a
+   source.ReplayableSource and reconnecting clients can resume from a
+   timestamp cursor.
+
+4. Usage Example:
+   src, err := csv.NewCSVTickSource("testdata/aapl.csv", false)
+   tickHandler := handler.NewTickHandler(hub, src, priceCache)
+*/
+
+// CSVTickSource implements source.TickSource by replaying ticks from a CSV file
+type CSVTickSource struct {
+	ticks []*models.Tick
+	loop  bool
+	next  int           // Index of the next tick to return
+	pass  int           // Completed passes over the file (loop mode)
+	shift time.Duration // Timestamp offset added per completed pass
+	mu    sync.Mutex
+}
+
+// NewCSVTickSource loads the ticks in the CSV file at path
+// With loop, replay restarts from the first row after the last one
+func NewCSVTickSource(path string, loop bool) (*CSVTickSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open tick file: %w", err)
+	}
+	defer file.Close()
+
+	ticks, err := readTicks(file)
+	if err != nil {
+		return nil, fmt.Errorf("read tick file %s: %w", path, err)
+	}
+	if len(ticks) == 0 {
+		return nil, fmt.Errorf("read tick file %s: no ticks", path)
+	}
+
+	return &CSVTickSource{
+		ticks: ticks,
+		loop:  loop,
+		shift: passShift(ticks),
+	}, nil
+}
+
+// GetTick implements source.TickSource
+// Returns io.EOF once every tick has been returned (unless looping)
+func (s *CSVTickSource) GetTick() (*models.Tick, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next == len(s.ticks) {
+		if !s.loop {
+			return nil, io.EOF
+		}
+		s.next = 0
+		s.pass++
+	}
+
+	tick := *s.ticks[s.next]
+	tick.Timestamp = tick.Timestamp.Add(time.Duration(s.pass) * s.shift)
+	s.next++
+	return &tick, nil
+}
+
+// Replayable implements source.ReplayableSource
+func (s *CSVTickSource) Replayable() bool {
+	return true
+}
+
+// readTicks parses symbol,price,volume,timestamp rows
+func readTicks(r io.Reader) ([]*models.Tick, error) {
+	reader := stdcsv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	ticks := make([]*models.Tick, 0)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return ticks, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "symbol") {
+			continue // Header row
+		}
+
+		tick, err := parseTick(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ticks = append(ticks, tick)
+	}
+}
+
+// parseTick converts one CSV record into a tick
+func parseTick(record []string) (*models.Tick, error) {
+	symbol := strings.TrimSpace(record[0])
+	if symbol == "" {
+		return nil, fmt.Errorf("missing symbol")
+	}
+	price, err := strconv.ParseFloat(record[1], 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("invalid price %q", record[1])
+	}
+	volume, err := strconv.ParseInt(record[2], 10, 64)
+	if err != nil || volume < 0 {
+		return nil, fmt.Errorf("invalid volume %q", record[2])
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, record[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q: must be RFC 3339", record[3])
+	}
+
+	return &models.Tick{
+		Symbol:    symbol,
+		Price:     price,
+		Volume:    volume,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// passShift is how far each loop pass is moved forward in time: the span
+// from first to last tick plus one average tick interval (one second for
+// files with a single timestamp)
+func passShift(ticks []*models.Tick) time.Duration {
+	span := ticks[len(ticks)-1].Timestamp.Sub(ticks[0].Timestamp)
+	if span <= 0 {
+		return time.Second
+	}
+	return span + span/time.Duration(len(ticks)-1)
+}
@@ -0,0 +1,82 @@
+package csv
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVTickSourceReplaysInOrder(t *testing.T) {
+	start := time.Date(2025, 1, 23, 14, 23, 38, 0, time.UTC)
+	want := []struct {
+		symbol string
+		price  float64
+		volume int64
+		at     time.Time
+	}{
+		{"AAPL", 150.25, 1000, start},
+		{"MSFT", 410.10, 200, start.Add(time.Second)},
+		{"AAPL", 150.40, 500, start.Add(2500 * time.Millisecond)},
+	}
+
+	for _, loop := range []bool{false, true} {
+		src, err := NewCSVTickSource("testdata/ticks.csv", loop)
+		if err != nil {
+			t.Fatalf("NewCSVTickSource: %v", err)
+		}
+		for i, w := range want {
+			tick, err := src.GetTick()
+			if err != nil {
+				t.Fatalf("loop=%v tick %d: %v", loop, i, err)
+			}
+			if tick.Symbol != w.symbol || tick.Price != w.price || tick.Volume != w.volume || !tick.Timestamp.Equal(w.at) {
+				t.Errorf("loop=%v tick %d is %+v, want %+v", loop, i, tick, w)
+			}
+		}
+
+		if !loop {
+			// EOF is sticky
+			for i := 0; i < 2; i++ {
+				if _, err := src.GetTick(); err != io.EOF {
+					t.Errorf("GetTick after the last row: %v, want io.EOF", err)
+				}
+			}
+			continue
+		}
+
+		// The second pass starts over, shifted by the 2.5s span plus one
+		// average 1.25s interval
+		tick, err := src.GetTick()
+		if err != nil {
+			t.Fatalf("GetTick on the second pass: %v", err)
+		}
+		if tick.Symbol != "AAPL" || tick.Price != 150.25 || !tick.Timestamp.Equal(start.Add(3750*time.Millisecond)) {
+			t.Errorf("second pass starts with %+v, want the first row at %v", tick, start.Add(3750*time.Millisecond))
+		}
+	}
+}
+
+func TestCSVTickSourceRejectsBadRows(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rows string
+		err  string
+	}{
+		{"bad price", "AAPL,abc,1,2025-01-23T14:23:38Z\n", `line 1: invalid price "abc"`},
+		{"bad timestamp", "symbol,price,volume,timestamp\nAAPL,1,1,yesterday\n", "line 2: invalid timestamp"},
+		{"header only", "symbol,price,volume,timestamp\n", "no ticks"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ticks.csv")
+			if err := os.WriteFile(path, []byte(tc.rows), 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if _, err := NewCSVTickSource(path, false); err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("NewCSVTickSource: %v, want an error containing %q", err, tc.err)
+			}
+		})
+	}
+}
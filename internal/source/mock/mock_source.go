@@ -2,31 +2,82 @@ package mock
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/aumbhatt/auto_trade/internal/models"
 )
 
+// Defaults used by NewMockTickSource
+var defaultSymbols = []string{"AAPL", "GOOGL", "MSFT", "AMZN"}
+
+const (
+	defaultMinPrice = 100.0
+	defaultMaxPrice = 1000.0
+)
+
 // MockTickSource implements TickSource interface with mock data
+// Each source draws from its own random generator, so sources created with
+// the same seed produce the same symbols, prices and volumes
 type MockTickSource struct {
-	symbols []string
+	symbols  []string
+	minPrice float64
+	maxPrice float64
+	rng      *rand.Rand
+	mu       sync.Mutex // rand.Rand is not safe for concurrent use
 }
 
-// NewMockTickSource creates a new instance of MockTickSource
+// NewMockTickSource creates a new instance of MockTickSource seeded from
+// the current time
 func NewMockTickSource() *MockTickSource {
+	return NewMockTickSourceWithConfig(defaultSymbols, defaultMinPrice, defaultMaxPrice)
+}
+
+// NewMockTickSourceWithSeed creates a MockTickSource whose tick sequence is
+// determined by seed
+func NewMockTickSourceWithSeed(seed int64) *MockTickSource {
+	s := NewMockTickSource()
+	s.SetSeed(seed)
+	return s
+}
+
+// NewMockTickSourceWithConfig creates a MockTickSource that draws symbols
+// from symbols and prices uniformly from [minPrice, maxPrice)
+// An empty symbol list or an invalid band (minPrice <= 0 or maxPrice <
+// minPrice) falls back to the defaults
+func NewMockTickSourceWithConfig(symbols []string, minPrice, maxPrice float64) *MockTickSource {
+	if len(symbols) == 0 {
+		symbols = defaultSymbols
+	}
+	if minPrice <= 0 || maxPrice < minPrice {
+		minPrice, maxPrice = defaultMinPrice, defaultMaxPrice
+	}
 	return &MockTickSource{
-		symbols: []string{"AAPL", "GOOGL", "MSFT", "AMZN"},
+		symbols:  append([]string(nil), symbols...),
+		minPrice: minPrice,
+		maxPrice: maxPrice,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// SetSeed restarts the tick sequence from seed
+func (s *MockTickSource) SetSeed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rng = rand.New(rand.NewSource(seed))
+}
+
 // GetTick generates and returns mock tick data
 func (s *MockTickSource) GetTick() (*models.Tick, error) {
-	symbol := s.symbols[rand.Intn(len(s.symbols))]
-	
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	symbol := s.symbols[s.rng.Intn(len(s.symbols))]
+
 	return &models.Tick{
 		Symbol:    symbol,
-		Price:     100 + rand.Float64()*900, // Random price between 100 and 1000
-		Volume:    rand.Int63n(10000),       // Random volume between 0 and 9999
+		Price:     s.minPrice + s.rng.Float64()*(s.maxPrice-s.minPrice),
+		Volume:    s.rng.Int63n(10000), // Random volume between 0 and 9999
 		Timestamp: time.Now(),
 	}, nil
 }
@@ -0,0 +1,80 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+// draw returns the next n ticks of src
+func draw(t *testing.T, src *MockTickSource, n int) []*models.Tick {
+	t.Helper()
+	ticks := make([]*models.Tick, n)
+	for i := range ticks {
+		tick, err := src.GetTick()
+		if err != nil {
+			t.Fatalf("GetTick: %v", err)
+		}
+		ticks[i] = tick
+	}
+	return ticks
+}
+
+// sameSequence reports whether two tick sequences match, ignoring timestamps
+func sameSequence(a, b []*models.Tick) bool {
+	for i := range a {
+		if a[i].Symbol != b[i].Symbol || a[i].Price != b[i].Price || a[i].Volume != b[i].Volume {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSeededSourcesAreReproducible(t *testing.T) {
+	first := draw(t, NewMockTickSourceWithSeed(42), 100)
+	if !sameSequence(first, draw(t, NewMockTickSourceWithSeed(42), 100)) {
+		t.Error("sources seeded with 42 produced different sequences")
+	}
+	if sameSequence(first, draw(t, NewMockTickSourceWithSeed(43), 100)) {
+		t.Error("sources seeded with 42 and 43 produced the same sequence")
+	}
+
+	// Reseeding restarts the sequence
+	src := NewMockTickSourceWithSeed(42)
+	draw(t, src, 10)
+	src.SetSeed(42)
+	if !sameSequence(first, draw(t, src, 100)) {
+		t.Error("reseeding with 42 did not restart the sequence")
+	}
+}
+
+func TestConfiguredSymbolsAndPriceBand(t *testing.T) {
+	src := NewMockTickSourceWithConfig([]string{"BTCUSD", "ETHUSD"}, 2000, 2500)
+	src.SetSeed(1)
+	seen := make(map[string]bool)
+	for _, tick := range draw(t, src, 1000) {
+		if tick.Symbol != "BTCUSD" && tick.Symbol != "ETHUSD" {
+			t.Fatalf("tick for %s, want only the configured symbols", tick.Symbol)
+		}
+		if tick.Price < 2000 || tick.Price >= 2500 {
+			t.Fatalf("price %v outside the band [2000, 2500)", tick.Price)
+		}
+		seen[tick.Symbol] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("ticks for %v, want both configured symbols", seen)
+	}
+
+	// Missing or invalid settings fall back to the defaults
+	for _, src := range []*MockTickSource{
+		NewMockTickSource(),
+		NewMockTickSourceWithConfig(nil, 0, 0),
+		NewMockTickSourceWithConfig([]string{"AAPL"}, 500, 100),
+	} {
+		for _, tick := range draw(t, src, 100) {
+			if tick.Price < defaultMinPrice || tick.Price >= defaultMaxPrice {
+				t.Fatalf("price %v outside the default band", tick.Price)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package mock
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Random Walk Tick Source Flow:
+
+1. Memory Structure:
+   RandomWalkTickSource
+   ├── symbols: []string          // Symbols ticks are drawn from
+   ├── prices: map[string]float64 // Last price per symbol
+   ├── volatility: float64        // Largest move per tick, as a fraction
+   ├── rng: *rand.Rand            // Own generator (see SetSeed)
+   └── mu: sync.Mutex             // Protects prices and rng
+
+2. Tick Flow:
+   a. Pick a symbol at random
+   b. Move its last price by a uniform step in [-volatility, +volatility]
+      of that price: next = last * (1 + volatility*(2u-1))
+   c. Remember and return the new price
+   Consecutive ticks of a symbol therefore differ by at most
+   volatility * last, and prices stay positive, so trend-following
+   strategies (moving averages, RSI) see realistic paths.
+
+3. Usage Example:
+   src := mock.NewRandomWalkTickSource([]string{"AAPL"}, map[string]float64{"AAPL": 150}, 0.002)
+*/
+
+// defaultVolatility is used when a random walk is given an invalid volatility
+const defaultVolatility = 0.01
+
+// RandomWalkTickSource implements TickSource with prices that random-walk
+// from their previous value
+type RandomWalkTickSource struct {
+	symbols    []string
+	prices     map[string]float64
+	volatility float64
+	rng        *rand.Rand
+	mu         sync.Mutex
+}
+
+// NewRandomWalkTickSource creates a random walk over symbols starting at
+// start prices (symbols without a positive start price begin at 100)
+// volatility is the largest fractional move per tick, e.g. 0.01 for 1%;
+// values outside (0, 1) fall back to 1%. An empty symbol list falls back
+// to the mock source's defaults
+func NewRandomWalkTickSource(symbols []string, start map[string]float64, volatility float64) *RandomWalkTickSource {
+	if len(symbols) == 0 {
+		symbols = defaultSymbols
+	}
+	if volatility <= 0 || volatility >= 1 {
+		volatility = defaultVolatility
+	}
+
+	prices := make(map[string]float64, len(symbols))
+	for _, symbol := range symbols {
+		price := start[symbol]
+		if price <= 0 {
+			price = defaultMinPrice
+		}
+		prices[symbol] = price
+	}
+
+	return &RandomWalkTickSource{
+		symbols:    append([]string(nil), symbols...),
+		prices:     prices,
+		volatility: volatility,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetSeed restarts the random sequence from seed
+func (s *RandomWalkTickSource) SetSeed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rng = rand.New(rand.NewSource(seed))
+}
+
+// GetTick generates the next step of a random symbol's walk
+func (s *RandomWalkTickSource) GetTick() (*models.Tick, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	symbol := s.symbols[s.rng.Intn(len(s.symbols))]
+	step := s.volatility * (2*s.rng.Float64() - 1)
+	price := s.prices[symbol] * (1 + step)
+	s.prices[symbol] = price
+
+	return &models.Tick{
+		Symbol:    symbol,
+		Price:     price,
+		Volume:    s.rng.Int63n(10000), // Random volume between 0 and 9999
+		Timestamp: time.Now(),
+	}, nil
+}
@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandomWalkStepsStayWithinVolatility(t *testing.T) {
+	const volatility = 0.002
+	start := map[string]float64{"AAPL": 150, "MSFT": 400}
+	src := NewRandomWalkTickSource([]string{"AAPL", "MSFT"}, start, volatility)
+	src.SetSeed(7)
+
+	last := map[string]float64{"AAPL": 150, "MSFT": 400}
+	moved := false
+	for i := 0; i < 10000; i++ {
+		tick, err := src.GetTick()
+		if err != nil {
+			t.Fatalf("GetTick: %v", err)
+		}
+		prev, ok := last[tick.Symbol]
+		if !ok {
+			t.Fatalf("tick for %s, want only AAPL or MSFT", tick.Symbol)
+		}
+		if step := math.Abs(tick.Price - prev); step > volatility*prev+1e-9 {
+			t.Fatalf("tick %d moved %s by %v from %v, more than %v of the last price", i, tick.Symbol, step, prev, volatility)
+		}
+		if tick.Price <= 0 {
+			t.Fatalf("tick %d priced %s at %v, want positive", i, tick.Symbol, tick.Price)
+		}
+		moved = moved || tick.Price != prev
+		last[tick.Symbol] = tick.Price
+	}
+	if !moved {
+		t.Error("prices never moved")
+	}
+}
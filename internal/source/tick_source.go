@@ -6,3 +6,13 @@ import "github.com/aumbhatt/auto_trade/internal/models"
 type TickSource interface {
 	GetTick() (*models.Tick, error)
 }
+
+// ReplayableSource is implemented by sources whose ticks carry meaningful
+// historical timestamps (e.g. CSV replay), so reconnecting clients can resume
+// a feed from a timestamp cursor instead of restarting it
+type ReplayableSource interface {
+	TickSource
+
+	// Replayable reports whether recent ticks should be retained for resumption
+	Replayable() bool
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Store Consistency Check:
+
+1. Counts:
+   ├── open trades    (GetOpenTrades)
+   └── closed trades  (GetTradeHistory)
+
+2. Inconsistencies Flagged:
+   ├── open trade with an exit time
+   ├── closed trade without an exit time
+   ├── closed trade exiting before it entered
+   ├── trade ID present in both the open set and history
+   └── trade with an empty ID or symbol, or non-positive entry price
+*/
+
+// CheckReport summarizes the contents and consistency of a trade store
+type CheckReport struct {
+	OpenTrades      int      `json:"open_trades"`
+	ClosedTrades    int      `json:"closed_trades"`
+	Inconsistencies []string `json:"inconsistencies"`
+}
+
+// OK reports whether no inconsistencies were found
+func (r *CheckReport) OK() bool {
+	return len(r.Inconsistencies) == 0
+}
+
+// CheckTradeStore loads all trades from a store and validates their consistency
+func CheckTradeStore(s BasicTradeStore) (*CheckReport, error) {
+	open, err := s.GetOpenTrades()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load open trades: %w", err)
+	}
+	history, err := s.GetTradeHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trade history: %w", err)
+	}
+
+	report := &CheckReport{
+		OpenTrades:      len(open),
+		ClosedTrades:    len(history),
+		Inconsistencies: make([]string, 0),
+	}
+	flag := func(format string, args ...interface{}) {
+		report.Inconsistencies = append(report.Inconsistencies, fmt.Sprintf(format, args...))
+	}
+
+	openIDs := make(map[string]struct{}, len(open))
+	for _, trade := range open {
+		checkTradeFields(trade, flag)
+		openIDs[trade.ID] = struct{}{}
+		if !trade.ExitTime.IsZero() {
+			flag("open trade %s has an exit time (%s)", trade.ID, trade.ExitTime)
+		}
+	}
+
+	for _, trade := range history {
+		checkTradeFields(trade, flag)
+		if _, dup := openIDs[trade.ID]; dup {
+			flag("trade %s is both open and in history", trade.ID)
+		}
+		if trade.ExitTime.IsZero() {
+			flag("closed trade %s has no exit time", trade.ID)
+		} else if trade.ExitTime.Before(trade.EntryTime) {
+			flag("closed trade %s exits before it enters", trade.ID)
+		}
+	}
+	return report, nil
+}
+
+// checkTradeFields flags missing or invalid required trade fields
+func checkTradeFields(trade *models.Trade, flag func(format string, args ...interface{})) {
+	if trade.ID == "" {
+		flag("trade with symbol %q has an empty ID", trade.Symbol)
+	}
+	if trade.Symbol == "" {
+		flag("trade %s has an empty symbol", trade.ID)
+	}
+	if trade.EntryPrice <= 0 {
+		flag("trade %s has a non-positive entry price (%.2f)", trade.ID, trade.EntryPrice)
+	}
+	if trade.Quantity <= 0 {
+		flag("trade %s has a non-positive quantity (%v)", trade.ID, trade.Quantity)
+	}
+	if !trade.Side.Valid() {
+		flag("trade %s has an unknown side (%q)", trade.ID, trade.Side)
+	}
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Close Policy:
+
+   Decides which open lots of a symbol are closed first when netting by
+   symbol (close-by-symbol and partial closes), tax-lot style.
+
+   Lots by entry time: L1 (09:00)  L2 (10:00)  L3 (11:00)
+   fifo → L1, L2, L3    // Oldest first (default)
+   lifo → L3, L2, L1    // Newest first
+*/
+
+// ClosePolicy orders open lots for closing
+type ClosePolicy string
+
+const (
+	// ClosePolicyFIFO closes the oldest lots first
+	ClosePolicyFIFO ClosePolicy = "fifo"
+
+	// ClosePolicyLIFO closes the newest lots first
+	ClosePolicyLIFO ClosePolicy = "lifo"
+)
+
+// ParseClosePolicy validates a close policy name, defaulting to FIFO when empty
+func ParseClosePolicy(name string) (ClosePolicy, error) {
+	switch ClosePolicy(name) {
+	case "", ClosePolicyFIFO:
+		return ClosePolicyFIFO, nil
+	case ClosePolicyLIFO:
+		return ClosePolicyLIFO, nil
+	}
+	return "", fmt.Errorf("invalid close policy %q: must be %q or %q", name, ClosePolicyFIFO, ClosePolicyLIFO)
+}
+
+// SortLots orders lots in the sequence the policy closes them
+// Lots with equal entry times are ordered by ID so the result is deterministic
+func SortLots(lots []*models.Trade, policy ClosePolicy) {
+	sort.Slice(lots, func(i, j int) bool {
+		a, b := lots[i], lots[j]
+		if policy == ClosePolicyLIFO {
+			a, b = b, a
+		}
+		if !a.EntryTime.Equal(b.EntryTime) {
+			return a.EntryTime.Before(b.EntryTime)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// SymbolCloser is implemented by trade stores that can net positions by symbol
+type SymbolCloser interface {
+	// CloseSymbol closes open lots of a symbol in close-policy order until
+	// quantity units are closed at exitPrice; quantity 0 closes every lot.
+	// A lot that is only partly needed is split: the closed part keeps the
+	// trade ID and the remainder stays open under a new ID with the original
+	// entry details.
+	CloseSymbol(symbol string, quantity, exitPrice float64, reason string) ([]*models.Trade, error)
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Exit Monitor Flow:
+
+1. Purpose:
+   Closes open trades whose take-profit or stop-loss (set with
+   ExitTradeCreator.CreateTradeWithExits) is crossed by a tick.
+
+2. Wiring:
+   ExitMonitor is a PriceCache decorator, like OrderMatcher:
+   TickSource → TickHandler → ExitMonitor.UpdatePrice
+                              ├── inner PriceCache.UpdatePrice
+                              └── for each open trade of the symbol with
+                                  a crossed exit: CloseTrade at the level
+                                  → TradeClosed → listeners
+
+3. Fills:
+   The trade closes at its take-profit or stop-loss level, not the tick
+   price, with CloseReason "take_profit" or "stop_loss". A trade closed
+   in the meantime (e.g. manually) is skipped; a close the store refuses
+   (e.g. halted symbol) is logged and retried on the next tick.
+*/
+
+// ExitMonitor closes trades at their take-profit and stop-loss levels as
+// ticks are recorded
+type ExitMonitor struct {
+	cache  PriceCache
+	trades BasicTradeStore
+}
+
+// NewExitMonitor creates a monitor that records ticks in cache and closes
+// the trades of trades whose exits they cross
+func NewExitMonitor(cache PriceCache, trades BasicTradeStore) *ExitMonitor {
+	return &ExitMonitor{
+		cache:  cache,
+		trades: trades,
+	}
+}
+
+// GetLatestTick implements PriceCache
+func (m *ExitMonitor) GetLatestTick(symbol string) (*models.Tick, bool) {
+	return m.cache.GetLatestTick(symbol)
+}
+
+// UpdatePrice implements PriceCache; it records the tick and closes the
+// trades whose exits it crosses
+func (m *ExitMonitor) UpdatePrice(tick *models.Tick) {
+	if tick == nil {
+		return
+	}
+	m.cache.UpdatePrice(tick)
+
+	open, err := m.trades.GetOpenTrades()
+	if err != nil {
+		logging.Warnf("Error checking trade exits for %s: %v", tick.Symbol, err)
+		return
+	}
+
+	for _, trade := range open {
+		if trade.Symbol != tick.Symbol || !trade.HasExits() {
+			continue
+		}
+		level, reason, crossed := trade.ExitCrossed(tick.Price)
+		if !crossed {
+			continue
+		}
+		if _, err := m.trades.CloseTrade(trade.ID, level, reason); err != nil {
+			if e, ok := err.(*models.TradeError); ok && (e.Code == models.ErrTradeNotFound || e.Code == models.ErrTradeAlreadyClosed) {
+				continue // Closed since GetOpenTrades
+			}
+			logging.Warnf("Error closing trade %s at %s: %v", trade.ID, reason, err)
+		}
+	}
+}
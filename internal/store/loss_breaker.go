@@ -0,0 +1,120 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Loss Breaker Flow:
+
+1. Purpose:
+   Halts new trades for the rest of the trading day once the day's
+   realized losses reach a limit.
+
+2. Tracking:
+   Trade stores → TradeClosed → LossBreaker.OnTradeEvent
+   └── realized += trade P&L (trades closed in the current day only)
+
+   The trading day starts at the rollover offset after midnight UTC
+   (e.g. 21h = 21:00 UTC); the first check or close after the next
+   rollover resets realized P&L to 0, which re-enables trading.
+
+3. Check Flow (CreateTrade):
+   ├── limit == 0             → allowed (breaker disabled)
+   ├── realized <= -limit     → RISK_LIMIT
+   └── otherwise              → allowed
+   Closes are never blocked, so positions can still be reduced.
+
+4. Sharing:
+   One LossBreaker listens to every trade store and is checked by all of
+   them, so losses anywhere halt opens everywhere. A nil *LossBreaker
+   allows everything.
+*/
+
+// LossBreaker rejects new trades once the day's realized losses reach a limit
+type LossBreaker struct {
+	limit    float64
+	rollover time.Duration
+	clock    clock.Clock
+
+	mu       sync.Mutex
+	dayStart time.Time
+	realized float64
+}
+
+// NewLossBreaker creates a breaker allowing up to limit in realized losses
+// per trading day, which starts rollover after midnight UTC; a zero limit
+// disables the breaker
+func NewLossBreaker(limit float64, rollover time.Duration, clk clock.Clock) *LossBreaker {
+	return &LossBreaker{
+		limit:    limit,
+		rollover: rollover,
+		clock:    clk,
+	}
+}
+
+// tradingDay returns the start of the trading day containing t
+func (b *LossBreaker) tradingDay(t time.Time) time.Time {
+	start := t.UTC().Truncate(24 * time.Hour).Add(b.rollover)
+	if start.After(t) {
+		start = start.Add(-24 * time.Hour)
+	}
+	return start
+}
+
+// roll resets realized P&L when a new trading day has started
+// Caller must hold b.mu
+func (b *LossBreaker) roll() {
+	if day := b.tradingDay(b.clock.Now()); day.After(b.dayStart) {
+		b.dayStart = day
+		b.realized = 0
+	}
+}
+
+// OnTradeEvent implements TradeEventListener; closes in the current trading
+// day add to its realized P&L
+func (b *LossBreaker) OnTradeEvent(event TradeEvent) {
+	if event.Type != TradeClosed || event.Trade == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	if event.Trade.ExitTime.Before(b.dayStart) {
+		return // Closed before the rollover
+	}
+	b.realized += event.Trade.PnL
+}
+
+// RealizedPnL returns the realized P&L of the current trading day
+func (b *LossBreaker) RealizedPnL() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	return b.realized
+}
+
+// Check returns a RISK_LIMIT TradeError if the day's realized losses have
+// reached the limit
+func (b *LossBreaker) Check() error {
+	if b == nil || b.limit <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll()
+	if b.realized <= -b.limit {
+		return &models.TradeError{
+			Code:    models.ErrRiskLimit,
+			Message: fmt.Sprintf("Daily loss limit %v reached (realized %v); trading resumes at %s", b.limit, b.realized, b.dayStart.Add(24*time.Hour).Format(time.RFC3339)),
+		}
+	}
+	return nil
+}
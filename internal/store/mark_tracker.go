@@ -0,0 +1,138 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Mark Tracker Flow:
+
+1. Purpose:
+   Marks open trades to market as ticks arrive and emits TradeUpdated
+   events carrying each trade's mark price and unrealized P&L, so open
+   positions can stream live P&L between opens and closes.
+
+2. Wiring:
+   MarkTracker is a PriceCache decorator: the TickHandler records ticks
+   through it, so every tick that reaches the price cache is also marked.
+   TickSource → TickHandler → MarkTracker.UpdatePrice
+                              ├── inner PriceCache.UpdatePrice
+                              └── mark the symbol's open trades
+                                  → TradeUpdated → listeners
+
+3. Mark Flow (per tick):
+   a. Record the tick in the inner cache first, so listeners reading the
+      cache see the new price
+   b. Load the open trades of the tick's symbol from the trade store
+   c. Emit TradeUpdated for each trade whose mark changed (or that has
+      not been marked yet); trades with an unchanged mark are skipped
+   d. Forget marks of trades that are no longer open
+
+4. Usage Example:
+   marks := store.NewMarkTracker(priceCache, tradeStore)
+   tickHandler := handler.NewTickHandler(hub, source, marks)
+   marks.AddListener(openPositionsHandler)
+*/
+
+// MarkTracker emits TradeUpdated events as ticks move open trades' marks
+type MarkTracker struct {
+	cache     PriceCache
+	trades    BasicTradeStore
+	listeners []TradeEventListener
+	marks     map[string]map[string]float64 // symbol -> trade ID -> last emitted mark
+	mu        sync.Mutex
+	emitMu    sync.Mutex
+}
+
+// NewMarkTracker creates a tracker that records ticks in cache and marks
+// the open trades of trades
+func NewMarkTracker(cache PriceCache, trades BasicTradeStore) *MarkTracker {
+	return &MarkTracker{
+		cache:  cache,
+		trades: trades,
+		marks:  make(map[string]map[string]float64),
+	}
+}
+
+// AddListener implements TradeEventEmitter
+func (m *MarkTracker) AddListener(listener TradeEventListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// RemoveListener implements TradeEventEmitter
+func (m *MarkTracker) RemoveListener(listener TradeEventListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, l := range m.listeners {
+		if l == listener {
+			m.listeners = append(m.listeners[:i], m.listeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// GetLatestTick implements PriceCache
+func (m *MarkTracker) GetLatestTick(symbol string) (*models.Tick, bool) {
+	return m.cache.GetLatestTick(symbol)
+}
+
+// UpdatePrice implements PriceCache; it records the tick and marks the
+// symbol's open trades
+func (m *MarkTracker) UpdatePrice(tick *models.Tick) {
+	if tick == nil {
+		return
+	}
+	m.cache.UpdatePrice(tick)
+
+	openTrades, err := m.trades.GetOpenTrades()
+	if err != nil {
+		logging.Errorf("Error getting open trades to mark: %v", err)
+		return
+	}
+
+	// Serialize marking so events for a symbol follow tick order
+	m.emitMu.Lock()
+	defer m.emitMu.Unlock()
+
+	m.mu.Lock()
+	previous := m.marks[tick.Symbol]
+	current := make(map[string]float64)
+	events := make([]TradeEvent, 0)
+	for _, trade := range openTrades {
+		if trade.Symbol != tick.Symbol {
+			continue
+		}
+		current[trade.ID] = tick.Price
+		if mark, ok := previous[trade.ID]; ok && mark == tick.Price {
+			continue
+		}
+
+		tradeCopy := *trade
+		events = append(events, TradeEvent{
+			Type:          TradeUpdated,
+			Trade:         &tradeCopy,
+			MarkPrice:     tick.Price,
+			UnrealizedPnL: tradeCopy.UnrealizedPnL(tick.Price),
+		})
+	}
+	if len(current) == 0 {
+		delete(m.marks, tick.Symbol)
+	} else {
+		m.marks[tick.Symbol] = current
+	}
+	listeners := make([]TradeEventListener, len(m.listeners))
+	copy(listeners, m.listeners)
+	m.mu.Unlock()
+
+	// Notify listeners outside the lock
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener.OnTradeEvent(event)
+		}
+	}
+}
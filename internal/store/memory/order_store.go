@@ -0,0 +1,303 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/google/uuid"
+)
+
+/*
+In-Memory Order Store Flow and Structure:
+
+1. Memory Structure:
+   InMemoryOrderStore
+   ├── pending: map[string]*PendingOrder // Resting orders by ID
+   ├── trades: BasicTradeStore          // Opens filled orders as trades
+   ├── symbols: map[string]string       // Optional allowlist (upper-case -> canonical)
+   ├── maxPending: int                  // Pending order cap (0 = unlimited)
+   ├── clock: Clock                     // Placement timestamps
+   ├── listeners: []OrderEventListener  // Event observers
+   ├── mu: sync.Mutex                   // Protects pending, settings and listeners
+   └── emitMu: sync.Mutex               // Serializes writes and their events
+                                        // (taken before mu)
+
+2. Operation Flow:
+   a. Place Order:
+      0. Reject unknown sides, non-positive quantities and limit prices
+      0. Reject symbols outside the allowlist (case-insensitive, when set);
+         the order records the allowlist's spelling of the symbol
+      0. Reject when maxPending orders are already pending
+      1. Generate UUID and store the order
+      2. Emit OrderPlaced
+
+   b. Cancel Order:
+      1. Remove from pending; unknown (or already filled) IDs are
+         ORDER_NOT_FOUND
+      2. Emit OrderCancelled
+
+   c. Fill Crossed Orders (per tick):
+      1. Under the lock, take the symbol's orders crossed by the tick price
+         out of pending, so a concurrent cancel cannot also fill them
+      2. Outside the lock, open each as a trade at the tick price, oldest
+         order first; the trade store emits TradeCreated
+      3. Put back orders whose trade was rejected, to retry on later ticks
+      4. Emit OrderFilled for each filled order
+
+3. Event Handling:
+   Events are emitted after the pending set changes, outside the data lock
+   and in state-change order, like the trade store's events
+*/
+
+// InMemoryOrderStore implements store.OrderStore with in-memory storage
+type InMemoryOrderStore struct {
+	pending    map[string]*models.PendingOrder
+	trades     store.BasicTradeStore
+	symbols    map[string]string
+	maxPending int
+	clock      clock.Clock
+	listeners  []store.OrderEventListener
+	mu         sync.Mutex
+	emitMu     sync.Mutex
+}
+
+// NewInMemoryOrderStore creates an order store that opens filled orders in trades
+func NewInMemoryOrderStore(trades store.BasicTradeStore) *InMemoryOrderStore {
+	return &InMemoryOrderStore{
+		pending: make(map[string]*models.PendingOrder),
+		trades:  trades,
+		clock:   clock.Real{},
+	}
+}
+
+// SetClock sets the time source for placement timestamps
+func (s *InMemoryOrderStore) SetClock(clk clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clk
+}
+
+// SetSymbols restricts PlacePendingOrder to the given symbols, matched
+// case-insensitively; an empty list accepts any symbol
+func (s *InMemoryOrderStore) SetSymbols(symbols []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(symbols) == 0 {
+		s.symbols = nil
+		return
+	}
+	s.symbols = make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		s.symbols[strings.ToUpper(symbol)] = symbol
+	}
+}
+
+// SetMaxPendingOrders caps the number of pending orders; 0 is unlimited
+func (s *InMemoryOrderStore) SetMaxPendingOrders(max int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPending = max
+}
+
+// AddListener implements store.OrderEventEmitter
+func (s *InMemoryOrderStore) AddListener(listener store.OrderEventListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// RemoveListener implements store.OrderEventEmitter
+func (s *InMemoryOrderStore) RemoveListener(listener store.OrderEventListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, l := range s.listeners {
+		if l == listener {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// lockWrite takes the locks for a change that emits events
+// s.emitMu is taken before s.mu, so a writer waiting its turn to emit never
+// holds the data lock that listeners read through
+func (s *InMemoryOrderStore) lockWrite() {
+	s.emitMu.Lock()
+	s.mu.Lock()
+}
+
+// unlockWrite releases the locks of a change that emits no events
+func (s *InMemoryOrderStore) unlockWrite() {
+	s.mu.Unlock()
+	s.emitMu.Unlock()
+}
+
+// emitEvent notifies all listeners of order events, in order
+// Caller must hold the write locks (lockWrite); s.mu is released before
+// listeners are notified, so they can read the store, and s.emitMu after,
+// so events are emitted in state-change order
+func (s *InMemoryOrderStore) emitEvent(events ...store.OrderEvent) {
+	listeners := make([]store.OrderEventListener, len(s.listeners))
+	copy(listeners, s.listeners)
+
+	defer s.emitMu.Unlock()
+	s.mu.Unlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener.OnOrderEvent(event)
+		}
+	}
+}
+
+// PlacePendingOrder implements store.OrderStore
+func (s *InMemoryOrderStore) PlacePendingOrder(symbol string, side models.Side, quantity, limitPrice float64) (*models.PendingOrder, error) {
+	if !side.Valid() {
+		return nil, &models.TradeError{
+			Code:    models.ErrInvalidSide,
+			Message: fmt.Sprintf("Invalid side: %q", side),
+		}
+	}
+	if quantity <= 0 || math.IsInf(quantity, 0) || math.IsNaN(quantity) {
+		return nil, &models.TradeError{
+			Code:    models.ErrInvalidQuantity,
+			Message: fmt.Sprintf("Invalid quantity: %v", quantity),
+		}
+	}
+	if limitPrice <= 0 || math.IsInf(limitPrice, 0) || math.IsNaN(limitPrice) {
+		return nil, &models.TradeError{
+			Code:    models.ErrInvalidLimitPrice,
+			Message: fmt.Sprintf("Invalid limit price: %v", limitPrice),
+		}
+	}
+
+	s.lockWrite()
+
+	if s.symbols != nil {
+		canonical, ok := s.symbols[strings.ToUpper(symbol)]
+		if !ok {
+			s.unlockWrite()
+			return nil, &models.TradeError{
+				Code:    models.ErrInvalidSymbol,
+				Message: fmt.Sprintf("Invalid trading symbol: %s", symbol),
+			}
+		}
+		symbol = canonical
+	}
+
+	if s.maxPending > 0 && len(s.pending) >= s.maxPending {
+		s.unlockWrite()
+		return nil, &models.TradeError{
+			Code:    models.ErrPendingOrderLimit,
+			Message: fmt.Sprintf("Pending order limit reached: %d", s.maxPending),
+		}
+	}
+
+	order := &models.PendingOrder{
+		ID:         "order-" + uuid.New().String(),
+		Symbol:     symbol,
+		Side:       side,
+		Quantity:   quantity,
+		LimitPrice: limitPrice,
+		PlacedTime: s.clock.Now(),
+	}
+	s.pending[order.ID] = order
+
+	logging.Debugf("Limit order placed: %s %s %s %.2f @ %.2f", order.ID, order.Side, order.Symbol, order.Quantity, order.LimitPrice)
+	orderCopy := *order
+	s.emitEvent(store.OrderEvent{Type: store.OrderPlaced, Order: &orderCopy})
+	return &orderCopy, nil
+}
+
+// CancelPendingOrder implements store.OrderStore
+func (s *InMemoryOrderStore) CancelPendingOrder(id string) (*models.PendingOrder, error) {
+	s.lockWrite()
+
+	order, exists := s.pending[id]
+	if !exists {
+		s.unlockWrite()
+		return nil, &models.TradeError{
+			Code:    models.ErrOrderNotFound,
+			Message: fmt.Sprintf("Pending order not found: %s", id),
+		}
+	}
+	delete(s.pending, id)
+
+	logging.Debugf("Limit order cancelled: %s", id)
+	s.emitEvent(store.OrderEvent{Type: store.OrderCancelled, Order: order})
+	return order, nil
+}
+
+// GetPendingOrders implements store.OrderStore
+func (s *InMemoryOrderStore) GetPendingOrders() ([]*models.PendingOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]*models.PendingOrder, 0, len(s.pending))
+	for _, order := range s.pending {
+		orderCopy := *order
+		orders = append(orders, &orderCopy)
+	}
+	sortOrders(orders)
+	return orders, nil
+}
+
+// FillCrossedOrders implements store.OrderStore
+func (s *InMemoryOrderStore) FillCrossedOrders(tick *models.Tick) ([]*models.Trade, error) {
+	if tick == nil || tick.Price <= 0 {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	crossed := make([]*models.PendingOrder, 0)
+	for id, order := range s.pending {
+		if order.Symbol == tick.Symbol && order.CrossedBy(tick.Price) {
+			crossed = append(crossed, order)
+			delete(s.pending, id)
+		}
+	}
+	s.mu.Unlock()
+	sortOrders(crossed)
+
+	// Open trades outside the lock; the trade store notifies its listeners
+	trades := make([]*models.Trade, 0, len(crossed))
+	events := make([]store.OrderEvent, 0, len(crossed))
+	var errs []error
+	for _, order := range crossed {
+		trade, err := s.trades.CreateTrade(order.Symbol, tick.Price, order.Quantity, order.Side, "")
+		if err != nil {
+			s.mu.Lock()
+			s.pending[order.ID] = order
+			s.mu.Unlock()
+			errs = append(errs, fmt.Errorf("fill order %s: %w", order.ID, err))
+			continue
+		}
+		logging.Debugf("Limit order filled: %s as %s at %.2f", order.ID, trade.ID, trade.EntryPrice)
+		trades = append(trades, trade)
+		events = append(events, store.OrderEvent{Type: store.OrderFilled, Order: order, Trade: trade})
+	}
+
+	if len(events) > 0 {
+		s.lockWrite()
+		s.emitEvent(events...)
+	}
+	return trades, errors.Join(errs...)
+}
+
+// sortOrders orders pending orders oldest first, ties by ID
+func sortOrders(orders []*models.PendingOrder) {
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].PlacedTime.Equal(orders[j].PlacedTime) {
+			return orders[i].PlacedTime.Before(orders[j].PlacedTime)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+)
+
+// pendingReadingListener reads the pending set on every event, like
+// PendingOrdersHandler
+type pendingReadingListener struct {
+	orders *InMemoryOrderStore
+}
+
+func (l *pendingReadingListener) OnOrderEvent(event store.OrderEvent) {
+	l.orders.GetPendingOrders()
+}
+
+func TestOrderStoreConcurrentWritesWithReadingListener(t *testing.T) {
+	orders := NewInMemoryOrderStore(NewInMemoryTradeStore())
+	orders.AddListener(&pendingReadingListener{orders: orders})
+
+	waitFor(t, 10*time.Second, func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					order, err := orders.PlacePendingOrder("AAPL", models.SideBuy, 1, 100)
+					if err != nil {
+						t.Errorf("PlacePendingOrder: %v", err)
+						return
+					}
+					if _, err := orders.CancelPendingOrder(order.ID); err != nil {
+						t.Errorf("CancelPendingOrder: %v", err)
+						return
+					}
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				orders.FillCrossedOrders(&models.Tick{Symbol: "AAPL", Price: 99})
+			}
+		}()
+		wg.Wait()
+	})
+}
+
+func TestBuyLimitFillsWhenPriceDropsToIt(t *testing.T) {
+	trades := NewInMemoryTradeStore()
+	orders := NewInMemoryOrderStore(trades)
+	matcher := store.NewOrderMatcher(NewInMemoryPriceCache(), orders)
+
+	order, err := orders.PlacePendingOrder("AAPL", models.SideBuy, 10, 95)
+	if err != nil {
+		t.Fatalf("PlacePendingOrder: %v", err)
+	}
+
+	// Above the limit, or on another symbol: the order rests
+	for _, tick := range []*models.Tick{
+		{Symbol: "AAPL", Price: 100},
+		{Symbol: "AAPL", Price: 95.01},
+		{Symbol: "MSFT", Price: 90},
+	} {
+		matcher.UpdatePrice(tick)
+	}
+	if pending, _ := orders.GetPendingOrders(); len(pending) != 1 || pending[0].ID != order.ID {
+		t.Fatalf("pending orders %v above the limit, want %s still pending", pending, order.ID)
+	}
+	if open, _ := trades.GetOpenTrades(); len(open) != 0 {
+		t.Fatalf("%d trades opened above the limit, want 0", len(open))
+	}
+
+	// A tick through the limit fills at the tick price
+	matcher.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 94.5})
+	if pending, _ := orders.GetPendingOrders(); len(pending) != 0 {
+		t.Errorf("%d orders pending after the limit was crossed, want 0", len(pending))
+	}
+	open, _ := trades.GetOpenTrades()
+	if len(open) != 1 || open[0].EntryPrice != 94.5 || open[0].Quantity != 10 {
+		t.Fatalf("open trades %v, want 10 bought at 94.5", open)
+	}
+
+	// A filled order can no longer be cancelled
+	_, err = orders.CancelPendingOrder(order.ID)
+	if e, ok := err.(*models.TradeError); !ok || e.Code != models.ErrOrderNotFound {
+		t.Errorf("cancelling a filled order: got %v, want %s", err, models.ErrOrderNotFound)
+	}
+}
+
+// orderRecorder records order events
+type orderRecorder struct {
+	events []store.OrderEvent
+}
+
+func (r *orderRecorder) OnOrderEvent(event store.OrderEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestOrderStoreEmitsOrderEvents(t *testing.T) {
+	trades := NewInMemoryTradeStore()
+	tradeEvents := &recordingListener{}
+	trades.AddListener(tradeEvents)
+	orders := NewInMemoryOrderStore(trades)
+	recorder := &orderRecorder{}
+	orders.AddListener(recorder)
+
+	cancelled, _ := orders.PlacePendingOrder("AAPL", models.SideBuy, 1, 95)
+	filled, _ := orders.PlacePendingOrder("AAPL", models.SideSell, 1, 105)
+	if _, err := orders.CancelPendingOrder(cancelled.ID); err != nil {
+		t.Fatalf("CancelPendingOrder: %v", err)
+	}
+	opened, err := orders.FillCrossedOrders(&models.Tick{Symbol: "AAPL", Price: 106})
+	if err != nil || len(opened) != 1 {
+		t.Fatalf("FillCrossedOrders: %d trades, %v; want the sell limit filled", len(opened), err)
+	}
+
+	want := []struct {
+		event store.OrderEventType
+		order string
+	}{
+		{store.OrderPlaced, cancelled.ID},
+		{store.OrderPlaced, filled.ID},
+		{store.OrderCancelled, cancelled.ID},
+		{store.OrderFilled, filled.ID},
+	}
+	if len(recorder.events) != len(want) {
+		t.Fatalf("%d order events, want %d", len(recorder.events), len(want))
+	}
+	for i, w := range want {
+		if got := recorder.events[i]; got.Type != w.event || got.Order.ID != w.order {
+			t.Errorf("event %d: %s for %s, want %s for %s", i, got.Type, got.Order.ID, w.event, w.order)
+		}
+	}
+	if fill := recorder.events[3]; fill.Trade == nil || fill.Trade.ID != opened[0].ID {
+		t.Errorf("fill event carries trade %v, want %s", fill.Trade, opened[0].ID)
+	}
+
+	// The trade store reports the fill's trade as any other open
+	if events := tradeEvents.snapshot(); len(events) != 1 || events[0].Type != store.TradeCreated || events[0].Trade.ID != opened[0].ID {
+		t.Errorf("trade events %v, want TradeCreated for the fill", events)
+	}
+}
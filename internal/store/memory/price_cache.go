@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+// InMemoryPriceCache implements store.PriceCache with an in-memory map
+type InMemoryPriceCache struct {
+	latest map[string]*models.Tick // symbol -> most recent tick
+	mu     sync.RWMutex
+}
+
+// NewInMemoryPriceCache creates a new instance of InMemoryPriceCache
+func NewInMemoryPriceCache() *InMemoryPriceCache {
+	return &InMemoryPriceCache{
+		latest: make(map[string]*models.Tick),
+	}
+}
+
+// UpdatePrice implements store.PriceCache
+func (c *InMemoryPriceCache) UpdatePrice(tick *models.Tick) {
+	if tick == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Ignore out-of-order ticks so the cache never moves backwards in time
+	if current, exists := c.latest[tick.Symbol]; exists && tick.Timestamp.Before(current.Timestamp) {
+		return
+	}
+	tickCopy := *tick
+	c.latest[tick.Symbol] = &tickCopy
+}
+
+// GetLatestTick implements store.PriceCache
+func (c *InMemoryPriceCache) GetLatestTick(symbol string) (*models.Tick, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tick, exists := c.latest[symbol]
+	if !exists {
+		return nil, false
+	}
+	tickCopy := *tick
+	return &tickCopy, true
+}
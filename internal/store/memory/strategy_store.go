@@ -2,9 +2,9 @@ package memory
 
 import (
 	"fmt"
-	"log"
 	"sync"
 
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/aumbhatt/auto_trade/internal/models"
 )
 
@@ -24,7 +24,7 @@ In-Memory Strategy Store Flow and Structure:
       3. Release read lock (RUnlock)
       4. Return copied data
 
-   b. Write Operations (Create, Stop):
+   b. Write Operations (Create, Stop, Pause, Resume):
       1. Acquire write lock (Lock)
       2. Modify maps
       3. Release write lock (Unlock)
@@ -46,9 +46,17 @@ In-Memory Strategy Store Flow and Structure:
       5. Release lock
       6. Return updated strategy
 
+   c. Pausing/Resuming Strategy:
+      1. Acquire write lock
+      2. Find in activeStrategies
+      3. Mark as paused/active (it stays in activeStrategies)
+      4. Release lock
+      5. Return updated strategy
+
 4. Error Handling:
    - Not found errors when stopping/getting strategy
    - Already stopped errors
+   - Already paused / not paused errors
    - Thread-safe error returns
 */
 
@@ -74,7 +82,7 @@ func (s *InMemoryStrategyStore) CreateStrategy(name string, params map[string]in
 
 	strategy := models.NewStrategy(name, params)
 	s.activeStrategies[strategy.ID] = strategy
-	log.Printf("Strategy created: %s", strategy.ID)
+	logging.Infof("Strategy created: %s", strategy.ID)
 	return strategy, nil
 }
 
@@ -105,7 +113,57 @@ func (s *InMemoryStrategyStore) StopStrategy(id string) (*models.Strategy, error
 	delete(s.activeStrategies, id)
 	s.strategyHistory[id] = strategy
 
-	log.Printf("Strategy stopped: %s", id)
+	logging.Infof("Strategy stopped: %s", id)
+	return strategy, nil
+}
+
+// PauseStrategy marks an active strategy as paused
+func (s *InMemoryStrategyStore) PauseStrategy(id string) (*models.Strategy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	strategy, exists := s.activeStrategies[id]
+	if !exists {
+		return nil, &models.StrategyError{
+			Code:    models.ErrStrategyNotFound,
+			Message: fmt.Sprintf("Strategy not found: %s", id),
+		}
+	}
+
+	if strategy.Status == "paused" {
+		return nil, &models.StrategyError{
+			Code:    models.ErrAlreadyPaused,
+			Message: fmt.Sprintf("Strategy already paused: %s", id),
+		}
+	}
+
+	strategy.Pause()
+	logging.Infof("Strategy paused: %s", id)
+	return strategy, nil
+}
+
+// ResumeStrategy marks a paused strategy as active again
+func (s *InMemoryStrategyStore) ResumeStrategy(id string) (*models.Strategy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	strategy, exists := s.activeStrategies[id]
+	if !exists {
+		return nil, &models.StrategyError{
+			Code:    models.ErrStrategyNotFound,
+			Message: fmt.Sprintf("Strategy not found: %s", id),
+		}
+	}
+
+	if strategy.Status != "paused" {
+		return nil, &models.StrategyError{
+			Code:    models.ErrNotPaused,
+			Message: fmt.Sprintf("Strategy not paused: %s", id),
+		}
+	}
+
+	strategy.Resume()
+	logging.Infof("Strategy resumed: %s", id)
 	return strategy, nil
 }
 
@@ -114,9 +172,12 @@ func (s *InMemoryStrategyStore) GetActiveStrategies() ([]*models.Strategy, error
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	// Copies, since active strategies are updated in place when they
+	// pause, resume or stop
 	strategies := make([]*models.Strategy, 0, len(s.activeStrategies))
 	for _, strategy := range s.activeStrategies {
-		strategies = append(strategies, strategy)
+		strategyCopy := *strategy
+		strategies = append(strategies, &strategyCopy)
 	}
 	return strategies, nil
 }
@@ -133,13 +194,36 @@ func (s *InMemoryStrategyStore) GetStrategyHistory() ([]*models.Strategy, error)
 	return strategies, nil
 }
 
+// GetStrategiesBySymbol implements store.StrategyStore
+func (s *InMemoryStrategyStore) GetStrategiesBySymbol(symbol string) ([]*models.Strategy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	strategies := make([]*models.Strategy, 0)
+	for _, strategy := range s.activeStrategies {
+		if models.TradesSymbol(strategy.Parameters, symbol) {
+			strategyCopy := *strategy
+			strategies = append(strategies, &strategyCopy)
+		}
+	}
+	return strategies, nil
+}
+
+// CountActive implements store.StrategyStore
+func (s *InMemoryStrategyStore) CountActive() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.activeStrategies)
+}
+
 // GetStrategyByID returns a strategy by its ID
 func (s *InMemoryStrategyStore) GetStrategyByID(id string) (*models.Strategy, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	if strategy, exists := s.activeStrategies[id]; exists {
-		return strategy, nil
+		strategyCopy := *strategy
+		return &strategyCopy, nil
 	}
 
 	if strategy, exists := s.strategyHistory[id]; exists {
@@ -2,10 +2,13 @@ package memory
 
 import (
 	"fmt"
-	"log"
+	"math"
+	"sort"
+	"strings"
 	"sync"
-	"time"
 
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/aumbhatt/auto_trade/internal/models"
 	"github.com/aumbhatt/auto_trade/internal/store"
 	"github.com/google/uuid"
@@ -19,7 +22,17 @@ In-Memory Trade Store Flow and Structure:
    ├── openTrades: map[string]*Trade    // Active trades
    ├── tradeHistory: map[string]*Trade  // Closed trades
    ├── listeners: []TradeEventListener  // Event observers
-   └── mu: sync.RWMutex                // Protects maps and listeners
+   ├── listenerQueueSize: int          // >0 delivers events asynchronously
+   ├── priceGuard: *PriceGuard         // Optional stale-price rejection
+   ├── halts: *SymbolHalts             // Optional per-symbol trading halts
+   ├── closePolicy: ClosePolicy        // Lot order for CloseSymbol (fifo/lifo)
+   ├── symbols: map[string]string      // Optional allowlist (upper-case -> canonical)
+   ├── limits: PositionLimits          // Optional caps on open trades
+   ├── lossBreaker: *LossBreaker       // Optional daily loss circuit breaker
+   ├── clock: Clock                    // Entry/exit timestamps (simulated in backtests)
+   ├── mu: sync.RWMutex                // Protects maps and listeners
+   └── emitMu: sync.Mutex              // Serializes writes and their events
+                                       // (taken before mu)
 
 2. Data Organization:
    openTrades = {
@@ -32,6 +45,16 @@ In-Memory Trade Store Flow and Structure:
 
 3. Operation Flow:
    a. Create Trade:
+      0. Reject unknown sides, non-positive quantities and non-positive
+         entry prices (INVALID_ENTRY_PRICE)
+      0. Reject symbols outside the allowlist (case-insensitive, when set);
+         the trade records the allowlist's spelling of the symbol
+      0. Reject if trading on the symbol is halted (SYMBOL_DISABLED)
+      0. Reject if the symbol's cached price is stale (when guarded)
+      0. Reject if the day's realized losses reached the loss limit
+         (RISK_LIMIT, when a breaker is set)
+      0. Reject if the symbol or store is at its open trade limit
+         (POSITION_LIMIT, when limited)
       1. Generate UUID
       2. Create trade object
       3. Store in openTrades
@@ -39,18 +62,48 @@ In-Memory Trade Store Flow and Structure:
       5. Return trade
 
    b. Close Trade:
+      0. Reject if trading on the symbol is halted (SYMBOL_DISABLED)
+      The price guard only applies to opens: closing (here and by symbol)
+      is always allowed on a stale price, so positions can still be exited
+      when the feed stalls
       1. Find in openTrades
-      2. Add exit details
+      2. Add exit details (caller-supplied exit price) and realized P&L
       3. Move to tradeHistory
       4. Emit TradeClosed event
       5. Return updated trade
 
+   c. Close Symbol (netting):
+      1. Order the symbol's open lots by close policy (FIFO/LIFO entry time)
+      2. Close whole lots until the requested quantity is reached
+      3. Split a partly needed lot: the closed part keeps its ID, the
+         remainder reopens under a new ID with the same entry details
+      4. Emit TradeClosed (and TradeCreated for a remainder) in order
+
+   d. Positions:
+      Open and closed trades are aggregated under the read lock, so the
+      positions match a single state of the store
+
+   e. Get Trade:
+      Look up the ID in openTrades, then tradeHistory (TRADE_NOT_FOUND if
+      in neither)
+
+   f. Query History:
+      1. Filter tradeHistory by symbol and exit-time range
+      2. Sort by exit time, newest first (ties by trade ID)
+      3. Apply offset and limit
+
 4. Event Handling:
    - AddListener registers new observers
    - RemoveListener unregisters observers
    - emitEvent notifies all observers
    - Events emitted after state changes
-   - Listeners notified outside locks
+   - Listeners notified outside the data lock, so they can read the store
+   - Writers take emitMu before mu: a writer waiting for an earlier
+     writer's listeners holds no lock those listeners need
+   - Events are emitted in the order the state changes happened
+   - With a listener queue size, each listener is wrapped in its own
+     ordered queue (store.OrderedListener): delivery is asynchronous,
+     but every listener still sees events in emission order
 
 5. Concurrency:
    - RWMutex for map access
@@ -64,7 +117,17 @@ type InMemoryTradeStore struct {
 	openTrades   map[string]*models.Trade
 	tradeHistory map[string]*models.Trade
 	listeners    []store.TradeEventListener
+	priceGuard   *store.PriceGuard
+	halts        *store.SymbolHalts
+	closePolicy  store.ClosePolicy
+	symbols      map[string]string
+	limits       store.PositionLimits
+	lossBreaker  *store.LossBreaker
+	clock        clock.Clock
 	mu           sync.RWMutex
+
+	listenerQueueSize int
+	emitMu            sync.Mutex
 }
 
 // NewInMemoryTradeStore creates a new instance of InMemoryTradeStore
@@ -73,13 +136,86 @@ func NewInMemoryTradeStore() *InMemoryTradeStore {
 		openTrades:   make(map[string]*models.Trade),
 		tradeHistory: make(map[string]*models.Trade),
 		listeners:    make([]store.TradeEventListener, 0),
+		closePolicy:  store.ClosePolicyFIFO,
+		clock:        clock.Real{},
+	}
+}
+
+// SetClock sets the time source for entry and exit timestamps
+func (s *InMemoryTradeStore) SetClock(clk clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clk
+}
+
+// SetClosePolicy sets the lot order used by CloseSymbol
+func (s *InMemoryTradeStore) SetClosePolicy(policy store.ClosePolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closePolicy = policy
+}
+
+// SetSymbols restricts CreateTrade to the given symbols, matched
+// case-insensitively; an empty list accepts any symbol
+func (s *InMemoryTradeStore) SetSymbols(symbols []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(symbols) == 0 {
+		s.symbols = nil
+		return
 	}
+	s.symbols = make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		s.symbols[strings.ToUpper(symbol)] = symbol
+	}
+}
+
+// SetSymbolHalts rejects opens and closes on symbols halted in the set
+func (s *InMemoryTradeStore) SetSymbolHalts(halts *store.SymbolHalts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.halts = halts
+}
+
+// SetPositionLimits caps the trades CreateTrade may hold open, per symbol
+// and in total; zero limits are unlimited
+func (s *InMemoryTradeStore) SetPositionLimits(limits store.PositionLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+}
+
+// SetLossBreaker rejects opens while the breaker's daily loss limit is
+// reached; the breaker must also be added as a listener to see closes
+func (s *InMemoryTradeStore) SetLossBreaker(breaker *store.LossBreaker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lossBreaker = breaker
+}
+
+// SetPriceGuard enables rejection of trades on stale prices
+func (s *InMemoryTradeStore) SetPriceGuard(guard *store.PriceGuard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priceGuard = guard
+}
+
+// SetListenerQueueSize makes listeners added afterwards receive events
+// asynchronously through per-listener ordered queues of this size; 0 keeps
+// synchronous delivery
+func (s *InMemoryTradeStore) SetListenerQueueSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listenerQueueSize = size
 }
 
 // AddListener implements store.TradeEventEmitter
 func (s *InMemoryTradeStore) AddListener(listener store.TradeEventListener) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.listenerQueueSize > 0 {
+		listener = store.NewOrderedListener(listener, s.listenerQueueSize)
+	}
 	s.listeners = append(s.listeners, listener)
 }
 
@@ -90,6 +226,11 @@ func (s *InMemoryTradeStore) RemoveListener(listener store.TradeEventListener) {
 	
 	// Find and remove the listener
 	for i, l := range s.listeners {
+		if ordered, ok := l.(*store.OrderedListener); ok && ordered.Listener() == listener {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			go ordered.Close() // Drain without holding the lock
+			break
+		}
 		if l == listener {
 			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
 			break
@@ -97,40 +238,103 @@ func (s *InMemoryTradeStore) RemoveListener(listener store.TradeEventListener) {
 	}
 }
 
-// emitEvent notifies all listeners of a trade event
-func (s *InMemoryTradeStore) emitEvent(event store.TradeEvent) {
-	s.mu.RLock()
+// lockWrite takes the locks for a change that emits events
+// s.emitMu is taken before s.mu, so a writer waiting its turn to emit never
+// holds the data lock that listeners read through
+func (s *InMemoryTradeStore) lockWrite() {
+	s.emitMu.Lock()
+	s.mu.Lock()
+}
+
+// unlockWrite releases the locks of a change that emits no events
+func (s *InMemoryTradeStore) unlockWrite() {
+	s.mu.Unlock()
+	s.emitMu.Unlock()
+}
+
+// emitEvent notifies all listeners of trade events, in order
+// Caller must hold the write locks (lockWrite); s.mu is released before
+// listeners are notified, so they can read the store, and s.emitMu after,
+// so events are emitted in state-change order
+func (s *InMemoryTradeStore) emitEvent(events ...store.TradeEvent) {
 	listeners := make([]store.TradeEventListener, len(s.listeners))
 	copy(listeners, s.listeners)
-	s.mu.RUnlock()
+
+	defer s.emitMu.Unlock()
+	s.mu.Unlock()
 
 	// Notify listeners outside the lock to prevent deadlocks
-	for _, listener := range listeners {
-		listener.OnTradeEvent(event)
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener.OnTradeEvent(event)
+		}
 	}
 }
 
 // CreateTrade implements store.BasicTradeStore
-func (s *InMemoryTradeStore) CreateTrade(symbol string, entryPrice float64) (*models.Trade, error) {
-	s.mu.Lock()
+func (s *InMemoryTradeStore) CreateTrade(symbol string, entryPrice, quantity float64, side models.Side, strategyID string) (*models.Trade, error) {
+	return s.CreateTradeWithExits(symbol, entryPrice, quantity, side, strategyID, 0, 0)
+}
+
+// CreateTradeWithExits implements store.ExitTradeCreator
+func (s *InMemoryTradeStore) CreateTradeWithExits(symbol string, entryPrice, quantity float64, side models.Side, strategyID string, takeProfit, stopLoss float64) (*models.Trade, error) {
+	if err := models.ValidateNewTrade(side, entryPrice, quantity, takeProfit, stopLoss); err != nil {
+		return nil, err
+	}
+
+	s.lockWrite()
+
+	if s.symbols != nil {
+		canonical, ok := s.symbols[strings.ToUpper(symbol)]
+		if !ok {
+			s.unlockWrite()
+			return nil, &models.TradeError{
+				Code:    models.ErrInvalidSymbol,
+				Message: fmt.Sprintf("Invalid trading symbol: %s", symbol),
+			}
+		}
+		symbol = canonical
+	}
+
+	if err := s.halts.Check(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := s.priceGuard.Check(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := s.lossBreaker.Check(); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := s.checkLimits(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
 
 	trade := &models.Trade{
 		ID:         fmt.Sprintf("trade-%s", uuid.New().String()),
+		StrategyID: strategyID,
 		Symbol:     symbol,
+		Side:       side,
+		Quantity:   quantity,
 		EntryPrice: entryPrice,
-		EntryTime:  time.Now(),
+		EntryTime:  s.clock.Now(),
+		TakeProfit: takeProfit,
+		StopLoss:   stopLoss,
 	}
 
 	s.openTrades[trade.ID] = trade
-	log.Printf("Trade opened: %s", trade.ID)
+	logging.Debugf("Trade opened: %s", trade.ID)
 	
 	// Make a copy of trade data for the event
 	tradeCopy := *trade
 	
-	// Release lock before emitting event
-	s.mu.Unlock()
-	
-	// Notify listeners with copied data
+	// Notify listeners with copied data; releases the lock
 	s.emitEvent(store.TradeEvent{
 		Type:  store.TradeCreated,
 		Trade: &tradeCopy,
@@ -139,13 +343,28 @@ func (s *InMemoryTradeStore) CreateTrade(symbol string, entryPrice float64) (*mo
 	return trade, nil
 }
 
+// checkLimits enforces the open trade limits for a new trade on symbol
+// Caller must hold s.mu
+func (s *InMemoryTradeStore) checkLimits(symbol string) error {
+	if !s.limits.Enabled() {
+		return nil
+	}
+	symbolOpen := 0
+	for _, trade := range s.openTrades {
+		if strings.EqualFold(trade.Symbol, symbol) {
+			symbolOpen++
+		}
+	}
+	return s.limits.Check(symbol, symbolOpen, len(s.openTrades))
+}
+
 // CloseTrade implements store.BasicTradeStore
-func (s *InMemoryTradeStore) CloseTrade(id string) (*models.Trade, error) {
-	s.mu.Lock()
+func (s *InMemoryTradeStore) CloseTrade(id string, exitPrice float64, reason string) (*models.Trade, error) {
+	s.lockWrite()
 
 	trade, exists := s.openTrades[id]
 	if !exists {
-		s.mu.Unlock()
+		s.unlockWrite()
 		return nil, &models.TradeError{
 			Code:    models.ErrTradeNotFound,
 			Message: fmt.Sprintf("Trade not found: %s", id),
@@ -154,30 +373,39 @@ func (s *InMemoryTradeStore) CloseTrade(id string) (*models.Trade, error) {
 
 	// Check if already closed
 	if !trade.ExitTime.IsZero() {
-		s.mu.Unlock()
+		s.unlockWrite()
 		return nil, &models.TradeError{
 			Code:    models.ErrTradeAlreadyClosed,
 			Message: fmt.Sprintf("Trade already closed: %s", id),
 		}
 	}
 
+	if err := s.halts.Check(trade.Symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := models.ValidateExitPrice(exitPrice); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
 	// Close the trade
-	trade.ExitTime = time.Now()
-	trade.ExitPrice = trade.EntryPrice + 1 // Mock exit price for demo
+	trade.ExitTime = s.clock.Now()
+	trade.ExitPrice = exitPrice
+	trade.CloseReason = reason
+	trade.ComputePnL()
 
 	// Move to history
 	delete(s.openTrades, id)
 	s.tradeHistory[id] = trade
 
-	log.Printf("Trade closed: %s", trade.ID)
+	logging.Debugf("Trade closed: %s", trade.ID)
 	
 	// Make a copy of trade data for the event
 	tradeCopy := *trade
 	
-	// Release lock before emitting event
-	s.mu.Unlock()
-	
-	// Notify listeners with copied data
+	// Notify listeners with copied data; releases the lock
 	s.emitEvent(store.TradeEvent{
 		Type:  store.TradeClosed,
 		Trade: &tradeCopy,
@@ -186,14 +414,96 @@ func (s *InMemoryTradeStore) CloseTrade(id string) (*models.Trade, error) {
 	return trade, nil
 }
 
+// CloseSymbol implements store.SymbolCloser
+func (s *InMemoryTradeStore) CloseSymbol(symbol string, quantity, exitPrice float64, reason string) ([]*models.Trade, error) {
+	if quantity < 0 || math.IsInf(quantity, 0) || math.IsNaN(quantity) {
+		return nil, &models.TradeError{
+			Code:    models.ErrInvalidQuantity,
+			Message: fmt.Sprintf("Invalid quantity: %v", quantity),
+		}
+	}
+	if err := models.ValidateExitPrice(exitPrice); err != nil {
+		return nil, err
+	}
+
+	s.lockWrite()
+
+	lots := make([]*models.Trade, 0)
+	for _, trade := range s.openTrades {
+		if trade.Symbol == symbol {
+			lots = append(lots, trade)
+		}
+	}
+	if len(lots) == 0 {
+		s.unlockWrite()
+		return nil, &models.TradeError{
+			Code:    models.ErrNoOpenLots,
+			Message: fmt.Sprintf("No open lots for symbol: %s", symbol),
+		}
+	}
+
+	if err := s.halts.Check(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	store.SortLots(lots, s.closePolicy)
+
+	closed := make([]*models.Trade, 0, len(lots))
+	events := make([]store.TradeEvent, 0, len(lots)+1)
+	var reopened *models.Trade
+	remaining := quantity
+	for _, lot := range lots {
+		if quantity > 0 && remaining <= 0 {
+			break
+		}
+
+		// Split off the part of the lot that stays open
+		if quantity > 0 && remaining < lot.Quantity {
+			remainder := *lot
+			remainder.ID = fmt.Sprintf("trade-%s", uuid.New().String())
+			remainder.Quantity = lot.Quantity - remaining
+			lot.Quantity = remaining
+			s.openTrades[remainder.ID] = &remainder
+			reopened = &remainder
+		}
+		remaining -= lot.Quantity
+
+		lot.ExitTime = s.clock.Now()
+		lot.ExitPrice = exitPrice
+		lot.CloseReason = reason
+		lot.ComputePnL()
+		delete(s.openTrades, lot.ID)
+		s.tradeHistory[lot.ID] = lot
+		logging.Debugf("Trade closed: %s", lot.ID)
+
+		closed = append(closed, lot)
+		lotCopy := *lot
+		events = append(events, store.TradeEvent{Type: store.TradeClosed, Trade: &lotCopy})
+	}
+
+	// The remainder of a split lot opens after the closed part
+	if reopened != nil {
+		reopenedCopy := *reopened
+		events = append(events, store.TradeEvent{Type: store.TradeCreated, Trade: &reopenedCopy})
+	}
+
+	// Notify listeners with copied data; releases the lock
+	s.emitEvent(events...)
+
+	return closed, nil
+}
+
 // GetOpenTrades implements store.BasicTradeStore
 func (s *InMemoryTradeStore) GetOpenTrades() ([]*models.Trade, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	// Copies, since open trades are updated in place when they close
 	trades := make([]*models.Trade, 0, len(s.openTrades))
 	for _, trade := range s.openTrades {
-		trades = append(trades, trade)
+		tradeCopy := *trade
+		trades = append(trades, &tradeCopy)
 	}
 
 	return trades, nil
@@ -211,3 +521,58 @@ func (s *InMemoryTradeStore) GetTradeHistory() ([]*models.Trade, error) {
 
 	return trades, nil
 }
+
+// GetTradeByID implements store.BasicTradeStore
+func (s *InMemoryTradeStore) GetTradeByID(id string) (*models.Trade, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if trade, ok := s.openTrades[id]; ok {
+		tradeCopy := *trade
+		return &tradeCopy, nil
+	}
+	if trade, ok := s.tradeHistory[id]; ok {
+		return trade, nil
+	}
+	return nil, &models.TradeError{
+		Code:    models.ErrTradeNotFound,
+		Message: fmt.Sprintf("Trade not found: %s", id),
+	}
+}
+
+// GetPositions implements store.BasicTradeStore
+func (s *InMemoryTradeStore) GetPositions() ([]*models.Position, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	open := make([]*models.Trade, 0, len(s.openTrades))
+	for _, trade := range s.openTrades {
+		open = append(open, trade)
+	}
+	closed := make([]*models.Trade, 0, len(s.tradeHistory))
+	for _, trade := range s.tradeHistory {
+		closed = append(closed, trade)
+	}
+	return store.AggregatePositions(open, closed), nil
+}
+
+// GetTradeHistoryFiltered implements store.BasicTradeStore
+// Ties on exit time are broken by trade ID so pages are stable
+func (s *InMemoryTradeStore) GetTradeHistoryFiltered(opts store.TradeHistoryQuery) ([]*models.Trade, error) {
+	s.mu.RLock()
+	trades := make([]*models.Trade, 0)
+	for _, trade := range s.tradeHistory {
+		if opts.Matches(trade) {
+			trades = append(trades, trade)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].ExitTime.Equal(trades[j].ExitTime) {
+			return trades[i].ExitTime.After(trades[j].ExitTime)
+		}
+		return trades[i].ID < trades[j].ID
+	})
+	return opts.Page(trades), nil
+}
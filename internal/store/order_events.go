@@ -0,0 +1,57 @@
+package store
+
+import "github.com/aumbhatt/auto_trade/internal/models"
+
+/*
+Order Events Flow:
+
+1. Event Types:
+   - OrderPlaced: New limit order is pending
+   - OrderCancelled: Pending order was cancelled
+   - OrderFilled: Pending order was crossed and opened as a trade; the
+     trade store emits TradeCreated for the trade itself
+
+2. Event Flow:
+   Order store changes the pending set → emits OrderEvent →
+   listeners (e.g. the pending_orders feed) re-broadcast pending orders
+
+3. Usage Example:
+   orders.AddListener(pendingOrdersHandler)
+   orders.PlacePendingOrder(...) // Triggers OrderPlaced
+*/
+
+// OrderEventType defines the type of pending order event
+type OrderEventType string
+
+const (
+	// OrderPlaced indicates a new limit order is pending
+	OrderPlaced OrderEventType = "placed"
+
+	// OrderCancelled indicates a pending order was cancelled
+	OrderCancelled OrderEventType = "cancelled"
+
+	// OrderFilled indicates a pending order was opened as a trade
+	OrderFilled OrderEventType = "filled"
+)
+
+// OrderEvent represents a pending order event
+type OrderEvent struct {
+	Type  OrderEventType       // Type of event
+	Order *models.PendingOrder // Associated order
+	Trade *models.Trade        // Trade opened by the fill (OrderFilled only)
+}
+
+// OrderEventListener defines interface for objects that want to receive order events
+type OrderEventListener interface {
+	// OnOrderEvent is called when an order event occurs
+	OnOrderEvent(event OrderEvent)
+}
+
+// OrderEventEmitter defines interface for objects that emit order events
+type OrderEventEmitter interface {
+	// AddListener registers a new listener
+	AddListener(listener OrderEventListener)
+
+	// RemoveListener unregisters an order event listener
+	RemoveListener(listener OrderEventListener)
+}
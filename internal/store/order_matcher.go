@@ -0,0 +1,58 @@
+package store
+
+import (
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Order Matcher Flow:
+
+1. Purpose:
+   Feeds ticks to the order store so resting limit orders fill as soon as
+   the market crosses them.
+
+2. Wiring:
+   OrderMatcher is a PriceCache decorator, like MarkTracker:
+   TickSource → TickHandler → OrderMatcher.UpdatePrice
+                              ├── inner PriceCache.UpdatePrice
+                              └── OrderStore.FillCrossedOrders
+                                  → TradeCreated → listeners
+
+3. Ordering:
+   The tick is recorded in the inner cache before orders are filled, so
+   the trade store's price guard sees the price the order fills at.
+*/
+
+// OrderMatcher fills pending orders as ticks are recorded
+type OrderMatcher struct {
+	cache  PriceCache
+	orders BasicOrderStore
+}
+
+// NewOrderMatcher creates a matcher that records ticks in cache and fills
+// the crossed orders of orders
+func NewOrderMatcher(cache PriceCache, orders BasicOrderStore) *OrderMatcher {
+	return &OrderMatcher{
+		cache:  cache,
+		orders: orders,
+	}
+}
+
+// GetLatestTick implements PriceCache
+func (m *OrderMatcher) GetLatestTick(symbol string) (*models.Tick, bool) {
+	return m.cache.GetLatestTick(symbol)
+}
+
+// UpdatePrice implements PriceCache; it records the tick and fills the
+// pending orders it crosses
+func (m *OrderMatcher) UpdatePrice(tick *models.Tick) {
+	if tick == nil {
+		return
+	}
+	m.cache.UpdatePrice(tick)
+
+	if _, err := m.orders.FillCrossedOrders(tick); err != nil {
+		logging.Warnf("Error filling pending orders for %s: %v", tick.Symbol, err)
+	}
+}
@@ -0,0 +1,59 @@
+package store
+
+import "github.com/aumbhatt/auto_trade/internal/models"
+
+/*
+Order Store Interface and Flow:
+
+1. Purpose:
+   Holds resting limit orders until a tick crosses their price, then
+   opens them as trades in the trade store.
+
+2. Usage Flow:
+   a. Place Order:
+      symbol, side, quantity, limitPrice → PlacePendingOrder() → PendingOrder
+      1. Validate side, quantity, limit price and symbol
+      2. Reject when the pending order cap is reached (PENDING_ORDER_LIMIT)
+      3. Store the order as pending
+      4. Emit OrderPlaced
+
+   b. Cancel Order:
+      id → CancelPendingOrder() → PendingOrder
+      1. Remove the order from the pending set (ORDER_NOT_FOUND if absent,
+         including orders that already filled)
+      2. Emit OrderCancelled
+
+   c. Fill Orders:
+      tick → FillCrossedOrders() → []*Trade
+      1. Take the tick symbol's pending orders crossed by its price
+      2. Open a trade for each at the tick price (the trade store emits
+         TradeCreated), then emit OrderFilled
+      3. Orders whose trade is rejected (e.g. SYMBOL_DISABLED) stay pending
+
+3. Usage Example:
+   orders.PlacePendingOrder("AAPL", models.SideBuy, 10, 149.00)
+   matcher := store.NewOrderMatcher(priceCache, orders)
+   tickHandler := handler.NewTickHandler(hub, source, matcher)
+*/
+
+// BasicOrderStore defines the pending limit order operations
+type BasicOrderStore interface {
+	// PlacePendingOrder stores a limit order until a tick crosses limitPrice
+	PlacePendingOrder(symbol string, side models.Side, quantity, limitPrice float64) (*models.PendingOrder, error)
+
+	// CancelPendingOrder removes a pending order and returns it
+	CancelPendingOrder(id string) (*models.PendingOrder, error)
+
+	// GetPendingOrders returns all pending orders, oldest first
+	GetPendingOrders() ([]*models.PendingOrder, error)
+
+	// FillCrossedOrders opens trades for the pending orders the tick crosses
+	// and returns them
+	FillCrossedOrders(tick *models.Tick) ([]*models.Trade, error)
+}
+
+// OrderStore combines pending order operations with event emission
+type OrderStore interface {
+	BasicOrderStore
+	OrderEventEmitter
+}
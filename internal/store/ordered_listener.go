@@ -0,0 +1,72 @@
+package store
+
+import "sync"
+
+/*
+Ordered Listener Flow:
+
+   emitter ──OnTradeEvent──► queue (buffered chan) ──worker goroutine──► listener
+
+   Each wrapped listener gets its own queue and worker, so a slow listener
+   never delays the others, while every listener still observes events in
+   exactly the order they were emitted (e.g. created before closed).
+   A full queue blocks the emitter rather than dropping or reordering events.
+*/
+
+// OrderedListener delivers trade events to a listener asynchronously, in emission order
+type OrderedListener struct {
+	listener TradeEventListener
+	queue    chan TradeEvent
+	done     chan struct{}
+	closed   bool
+	mu       sync.Mutex // Guards closed and sends on queue
+}
+
+// NewOrderedListener wraps a listener with its own ordered queue of the given size
+func NewOrderedListener(listener TradeEventListener, queueSize int) *OrderedListener {
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	l := &OrderedListener{
+		listener: listener,
+		queue:    make(chan TradeEvent, queueSize),
+		done:     make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Listener returns the wrapped listener
+func (l *OrderedListener) Listener() TradeEventListener {
+	return l.listener
+}
+
+// OnTradeEvent implements TradeEventListener by queueing the event
+// Events arriving after Close are dropped
+func (l *OrderedListener) OnTradeEvent(event TradeEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.queue <- event
+}
+
+// Close stops accepting events and returns once queued events are delivered
+func (l *OrderedListener) Close() {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+	<-l.done
+}
+
+// run delivers queued events one at a time
+func (l *OrderedListener) run() {
+	defer close(l.done)
+	for event := range l.queue {
+		l.listener.OnTradeEvent(event)
+	}
+}
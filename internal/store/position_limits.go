@@ -0,0 +1,53 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Position Limits Flow:
+
+1. Purpose:
+   Caps how many trades a trade store holds open, so a misbehaving
+   strategy cannot open thousands of positions.
+
+2. Check Flow (CreateTrade, under the store's lock):
+   open trades on the symbol >= MaxOpenPerSymbol → POSITION_LIMIT
+   open trades in the store  >= MaxOpenTotal     → POSITION_LIMIT
+   otherwise                                     → allowed
+
+   Symbols are counted case-insensitively. Closing a trade frees its slot;
+   splitting a lot on CloseSymbol keeps the open count and is not checked.
+   A limit of 0 is unlimited.
+*/
+
+// PositionLimits caps the open trades of a trade store
+type PositionLimits struct {
+	MaxOpenPerSymbol int // Open trades per symbol (0 = unlimited)
+	MaxOpenTotal     int // Open trades across symbols (0 = unlimited)
+}
+
+// Enabled reports whether any limit is set
+func (l PositionLimits) Enabled() bool {
+	return l.MaxOpenPerSymbol > 0 || l.MaxOpenTotal > 0
+}
+
+// Check returns a POSITION_LIMIT error if opening another trade on symbol,
+// which has symbolOpen of the store's totalOpen open trades, exceeds a limit
+func (l PositionLimits) Check(symbol string, symbolOpen, totalOpen int) error {
+	if l.MaxOpenPerSymbol > 0 && symbolOpen >= l.MaxOpenPerSymbol {
+		return &models.TradeError{
+			Code:    models.ErrPositionLimit,
+			Message: fmt.Sprintf("Open position limit reached for %s: %d", symbol, l.MaxOpenPerSymbol),
+		}
+	}
+	if l.MaxOpenTotal > 0 && totalOpen >= l.MaxOpenTotal {
+		return &models.TradeError{
+			Code:    models.ErrPositionLimit,
+			Message: fmt.Sprintf("Open position limit reached: %d", l.MaxOpenTotal),
+		}
+	}
+	return nil
+}
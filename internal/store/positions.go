@@ -0,0 +1,63 @@
+package store
+
+import (
+	"sort"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+// positionKey identifies a position: one strategy, symbol and side
+type positionKey struct {
+	strategyID string
+	symbol     string
+	side       models.Side
+}
+
+// AggregatePositions combines open trades into positions per strategy,
+// symbol and side, with the realized P&L of the matching closed trades
+// Only positions with open trades are returned, sorted by strategy ID,
+// symbol and side
+func AggregatePositions(open, closed []*models.Trade) []*models.Position {
+	byKey := make(map[positionKey]*models.Position)
+	notional := make(map[positionKey]float64) // Sum of entry price x quantity
+	for _, trade := range open {
+		key := positionKey{trade.StrategyID, trade.Symbol, trade.Side}
+		position, exists := byKey[key]
+		if !exists {
+			position = &models.Position{
+				StrategyID: trade.StrategyID,
+				Symbol:     trade.Symbol,
+				Side:       trade.Side,
+			}
+			byKey[key] = position
+		}
+		notional[key] += trade.EntryPrice * trade.Quantity
+		position.Quantity += trade.Quantity
+		position.OpenTrades++
+	}
+
+	for _, trade := range closed {
+		if position, exists := byKey[positionKey{trade.StrategyID, trade.Symbol, trade.Side}]; exists {
+			position.RealizedPnL += trade.PnL
+		}
+	}
+
+	positions := make([]*models.Position, 0, len(byKey))
+	for key, position := range byKey {
+		if position.Quantity > 0 {
+			position.AvgEntryPrice = notional[key] / position.Quantity
+		}
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if a.StrategyID != b.StrategyID {
+			return a.StrategyID < b.StrategyID
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Side < b.Side
+	})
+	return positions
+}
@@ -0,0 +1,29 @@
+package store
+
+import "github.com/aumbhatt/auto_trade/internal/models"
+
+/*
+Price Cache Interface and Flow:
+
+1. Purpose:
+   Keeps the most recent tick per symbol so request handlers can look up
+   the current market price without waiting for the next tick.
+
+2. Data Flow:
+   TickSource → TickHandler → PriceCache.UpdatePrice
+   REST handler → PriceCache.GetLatestTick(symbol) → current price
+
+3. Usage Example:
+   cache.UpdatePrice(tick)
+   tick, ok := cache.GetLatestTick("AAPL")
+*/
+
+// PriceCache defines the interface for latest-price lookups
+type PriceCache interface {
+	// UpdatePrice records a tick as the latest known price for its symbol
+	UpdatePrice(tick *models.Tick)
+
+	// GetLatestTick returns the most recent tick for a symbol
+	// Returns false if no tick has been seen for the symbol
+	GetLatestTick(symbol string) (*models.Tick, bool)
+}
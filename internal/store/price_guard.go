@@ -0,0 +1,65 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Price Guard Flow:
+
+1. Purpose:
+   Rejects opens when the latest cached price for a symbol is older than
+   maxAge, so trades are never entered on outdated market data. Trade
+   stores do not check it on closes: exiting a position must stay
+   possible when the feed stalls.
+
+2. Check Flow:
+   symbol → PriceCache.GetLatestTick → age = clock.Now() - tick.Timestamp
+   ├── maxAge == 0       → allowed (guard disabled)
+   ├── no cached price   → STALE_PRICE
+   ├── age > maxAge      → STALE_PRICE
+   └── otherwise         → allowed
+*/
+
+// PriceGuard rejects opens whose symbol has no sufficiently fresh price
+type PriceGuard struct {
+	cache  PriceCache
+	maxAge time.Duration
+	clock  clock.Clock
+}
+
+// NewPriceGuard creates a guard; a zero maxAge disables the check
+func NewPriceGuard(cache PriceCache, maxAge time.Duration, clk clock.Clock) *PriceGuard {
+	return &PriceGuard{
+		cache:  cache,
+		maxAge: maxAge,
+		clock:  clk,
+	}
+}
+
+// Check returns a STALE_PRICE TradeError if the symbol's price is missing or too old
+func (g *PriceGuard) Check(symbol string) error {
+	if g == nil || g.maxAge <= 0 {
+		return nil
+	}
+
+	tick, ok := g.cache.GetLatestTick(symbol)
+	if !ok {
+		return &models.TradeError{
+			Code:    models.ErrStalePrice,
+			Message: fmt.Sprintf("No price available for symbol: %s", symbol),
+		}
+	}
+
+	if age := g.clock.Now().Sub(tick.Timestamp); age > g.maxAge {
+		return &models.TradeError{
+			Code:    models.ErrStalePrice,
+			Message: fmt.Sprintf("Price for %s is %s old (max %s)", symbol, age.Round(time.Millisecond), g.maxAge),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+/*
+SQLite Persistence Flow and Structure:
+
+1. Schema:
+   trades
+   ├── id TEXT PRIMARY KEY
+   ├── strategy_id, symbol, side TEXT
+   ├── quantity, entry_price, exit_price REAL
+   ├── entry_time, exit_time INTEGER   // Unix nanoseconds; exit_time 0 = open
+   ├── close_reason TEXT
+   ├── pnl, pnl_percent REAL
+   └── take_profit, stop_loss REAL      // Exit levels; 0 = none
+
+   strategies
+   ├── id TEXT PRIMARY KEY
+   ├── name TEXT
+   ├── parameters TEXT                  // JSON object
+   ├── start_time INTEGER               // Unix nanoseconds
+   ├── stop_time INTEGER                // NULL while active
+   └── status TEXT                      // "active", "paused" or "stopped"
+
+2. Lifecycle:
+   a. Open creates the tables if missing, so a new file starts empty and
+      an existing file is picked up as-is; columns added since a file was
+      created (see migrations) are added with their defaults
+   b. Stores read open trades and active strategies straight from the
+      tables, so state left by a previous process reappears on startup
+   c. The caller owns the *sql.DB and closes it on shutdown
+
+3. Usage Example:
+   db, err := sqlite.Open("auto_trade.db")
+   tradeStore := sqlite.NewTradeStore(db)
+   strategyStore := sqlite.NewStrategyStore(db)
+*/
+
+const schema = `
+CREATE TABLE IF NOT EXISTS trades (
+	id           TEXT PRIMARY KEY,
+	strategy_id  TEXT NOT NULL DEFAULT '',
+	symbol       TEXT NOT NULL,
+	side         TEXT NOT NULL,
+	quantity     REAL NOT NULL,
+	entry_price  REAL NOT NULL,
+	exit_price   REAL NOT NULL DEFAULT 0,
+	entry_time   INTEGER NOT NULL,
+	exit_time    INTEGER NOT NULL DEFAULT 0,
+	close_reason TEXT NOT NULL DEFAULT '',
+	pnl          REAL NOT NULL DEFAULT 0,
+	pnl_percent  REAL NOT NULL DEFAULT 0,
+	take_profit  REAL NOT NULL DEFAULT 0,
+	stop_loss    REAL NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS trades_exit_time ON trades (exit_time);
+
+CREATE TABLE IF NOT EXISTS strategies (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	parameters TEXT NOT NULL,
+	start_time INTEGER NOT NULL,
+	stop_time  INTEGER,
+	status     TEXT NOT NULL
+);
+`
+
+// migrations are columns added to tables after their first release, so
+// files created by older versions gain them on Open
+var migrations = []struct {
+	table, column, definition string
+}{
+	{"trades", "take_profit", "REAL NOT NULL DEFAULT 0"},
+	{"trades", "stop_loss", "REAL NOT NULL DEFAULT 0"},
+}
+
+// Open opens the SQLite database at path and creates the schema if needed
+// Use ":memory:" for a throwaway database
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database %s: %w", path, err)
+	}
+
+	// SQLite allows a single writer; one connection also keeps ":memory:"
+	// databases from being split across connections
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create sqlite schema: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate sqlite schema: %w", err)
+	}
+	return db, nil
+}
+
+// migrate adds the migrations' columns that are missing
+func migrate(db *sql.DB) error {
+	for _, m := range migrations {
+		exists, err := hasColumn(db, m.table, m.column)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+			return fmt.Errorf("add %s.%s: %w", m.table, m.column, err)
+		}
+	}
+	return nil
+}
+
+// hasColumn reports whether a table has a column
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, colType    string
+			defaultValue     sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// toUnixNano stores a time as Unix nanoseconds; the zero time is stored as 0
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano is the inverse of toUnixNano
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
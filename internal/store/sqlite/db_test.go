@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+)
+
+// committedListener records, for each event, whether its trade could
+// already be read back from the database
+type committedListener struct {
+	store     *TradeStore
+	committed []bool
+}
+
+func (l *committedListener) OnTradeEvent(event store.TradeEvent) {
+	trade, err := l.store.GetTradeByID(event.Trade.ID)
+	l.committed = append(l.committed, err == nil && trade.ExitTime.IsZero() == (event.Type == store.TradeCreated))
+}
+
+func TestStateSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auto_trade.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	trades, strategies := NewTradeStore(db), NewStrategyStore(db)
+	listener := &committedListener{store: trades}
+	trades.AddListener(listener)
+
+	kept, _ := trades.CreateTrade("AAPL", 100, 2, models.SideBuy, "")
+	closed, _ := trades.CreateTrade("MSFT", 200, 1, models.SideBuy, "")
+	if _, err := trades.CloseTrade(closed.ID, 210, models.CloseReasonManual); err != nil {
+		t.Fatalf("CloseTrade: %v", err)
+	}
+	running, _ := strategies.CreateStrategy("repeat", map[string]interface{}{"symbol": "AAPL", "exit_price": 110.0})
+	stopped, _ := strategies.CreateStrategy("repeat", map[string]interface{}{"symbol": "MSFT", "exit_price": 220.0})
+	if _, err := strategies.StopStrategy(stopped.ID); err != nil {
+		t.Fatalf("StopStrategy: %v", err)
+	}
+
+	// Events are emitted once their change is readable
+	if len(listener.committed) != 3 {
+		t.Fatalf("%d events emitted, want 3", len(listener.committed))
+	}
+	for i, committed := range listener.committed {
+		if !committed {
+			t.Errorf("event %d emitted before its change was committed", i)
+		}
+	}
+
+	// Restart: a new process opens the same file
+	db.Close()
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer db.Close()
+	trades, strategies = NewTradeStore(db), NewStrategyStore(db)
+
+	open, _ := trades.GetOpenTrades()
+	if len(open) != 1 || open[0].ID != kept.ID || open[0].Quantity != 2 {
+		t.Errorf("open trades %v after reopening, want only %s", open, kept.ID)
+	}
+	history, _ := trades.GetTradeHistory()
+	if len(history) != 1 || history[0].ID != closed.ID || history[0].ExitPrice != 210 {
+		t.Errorf("trade history %v after reopening, want %s closed at 210", history, closed.ID)
+	}
+
+	active, _ := strategies.GetActiveStrategies()
+	if len(active) != 1 || active[0].ID != running.ID || active[0].Parameters["symbol"] != "AAPL" {
+		t.Errorf("active strategies %v after reopening, want only %s", active, running.ID)
+	}
+	inactive, _ := strategies.GetStrategyHistory()
+	if len(inactive) != 1 || inactive[0].ID != stopped.ID {
+		t.Errorf("strategy history %v after reopening, want only %s", inactive, stopped.ID)
+	}
+
+	// The reopened store keeps trading the reloaded trade
+	if _, err := trades.CloseTrade(kept.ID, 105, models.CloseReasonManual); err != nil {
+		t.Errorf("closing a reloaded trade: %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+SQLite Strategy Store Flow and Structure:
+
+1. Memory Structure:
+   StrategyStore
+   ├── db: *sql.DB      // strategies table (see db.go)
+   └── mu: sync.Mutex   // Serializes stop/pause/resume read-modify-write
+
+2. Data Flow:
+   a. Creating Strategy:
+      1. Build the strategy (models.NewStrategy)
+      2. INSERT with status "active" and parameters as JSON
+      3. Return strategy
+
+   b. Stopping Strategy:
+      1. Load the strategy; unknown IDs are STRATEGY_NOT_FOUND and
+         stopped ones ALREADY_STOPPED
+      2. Mark as stopped and UPDATE stop_time and status
+      3. Return updated strategy
+
+   c. Pausing/Resuming Strategy:
+      1. Load the strategy; it must be active (to pause) or paused (to
+         resume), otherwise STRATEGY_NOT_FOUND, ALREADY_PAUSED or NOT_PAUSED
+      2. UPDATE status
+
+   d. Queries:
+      Active and history are selected by status (active includes paused);
+      each call returns fresh copies decoded from the table.
+      GetStrategiesBySymbol filters the active rows by their decoded
+      parameters; CountActive counts them in SQL.
+
+3. Restart:
+   Strategies left active by a previous process (e.g. one that crashed)
+   are returned by GetActiveStrategies, so the caller can resume them;
+   paused ones come back paused.
+   Parameters round-trip through JSON, so numbers come back as float64
+   exactly as they arrive from the REST API.
+*/
+
+// StrategyStore implements store.StrategyStore on a SQLite database
+type StrategyStore struct {
+	db *sql.DB
+	mu sync.Mutex
+}
+
+// NewStrategyStore creates a strategy store on a database opened with Open
+func NewStrategyStore(db *sql.DB) *StrategyStore {
+	return &StrategyStore{db: db}
+}
+
+// CreateStrategy implements store.StrategyStore
+func (s *StrategyStore) CreateStrategy(name string, params map[string]interface{}) (*models.Strategy, error) {
+	strategy := models.NewStrategy(name, params)
+
+	encoded, err := json.Marshal(strategy.Parameters)
+	if err != nil {
+		return nil, &models.StrategyError{
+			Code:    models.ErrInvalidStrategy,
+			Message: fmt.Sprintf("Invalid strategy parameters: %v", err),
+		}
+	}
+
+	_, err = s.db.Exec("INSERT INTO strategies (id, name, parameters, start_time, stop_time, status) VALUES (?, ?, ?, ?, NULL, ?)",
+		strategy.ID, strategy.Name, string(encoded), toUnixNano(strategy.StartTime), strategy.Status)
+	if err != nil {
+		return nil, fmt.Errorf("save strategy %s: %w", strategy.ID, err)
+	}
+
+	logging.Infof("Strategy created: %s", strategy.ID)
+	return strategy, nil
+}
+
+// StopStrategy implements store.StrategyStore
+func (s *StrategyStore) StopStrategy(id string) (*models.Strategy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	strategy, err := s.GetStrategyByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategy.Status == "stopped" {
+		return nil, &models.StrategyError{
+			Code:    models.ErrAlreadyStopped,
+			Message: fmt.Sprintf("Strategy already stopped: %s", id),
+		}
+	}
+
+	strategy.Stop()
+
+	_, err = s.db.Exec("UPDATE strategies SET stop_time = ?, status = ? WHERE id = ?",
+		toUnixNano(*strategy.StopTime), strategy.Status, id)
+	if err != nil {
+		return nil, fmt.Errorf("save strategy %s: %w", id, err)
+	}
+
+	logging.Infof("Strategy stopped: %s", id)
+	return strategy, nil
+}
+
+// PauseStrategy implements store.StrategyStore
+func (s *StrategyStore) PauseStrategy(id string) (*models.Strategy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	strategy, err := s.getLiveStrategy(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategy.Status == "paused" {
+		return nil, &models.StrategyError{
+			Code:    models.ErrAlreadyPaused,
+			Message: fmt.Sprintf("Strategy already paused: %s", id),
+		}
+	}
+
+	strategy.Pause()
+	if err := s.saveStatus(strategy); err != nil {
+		return nil, err
+	}
+
+	logging.Infof("Strategy paused: %s", id)
+	return strategy, nil
+}
+
+// ResumeStrategy implements store.StrategyStore
+func (s *StrategyStore) ResumeStrategy(id string) (*models.Strategy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	strategy, err := s.getLiveStrategy(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategy.Status != "paused" {
+		return nil, &models.StrategyError{
+			Code:    models.ErrNotPaused,
+			Message: fmt.Sprintf("Strategy not paused: %s", id),
+		}
+	}
+
+	strategy.Resume()
+	if err := s.saveStatus(strategy); err != nil {
+		return nil, err
+	}
+
+	logging.Infof("Strategy resumed: %s", id)
+	return strategy, nil
+}
+
+// getLiveStrategy loads a strategy that has not been stopped
+// Stopped strategies are STRATEGY_NOT_FOUND, as for the in-memory store
+func (s *StrategyStore) getLiveStrategy(id string) (*models.Strategy, error) {
+	strategy, err := s.GetStrategyByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if strategy.Status == "stopped" {
+		return nil, &models.StrategyError{
+			Code:    models.ErrStrategyNotFound,
+			Message: fmt.Sprintf("Strategy not found: %s", id),
+		}
+	}
+	return strategy, nil
+}
+
+// saveStatus writes a strategy's status
+func (s *StrategyStore) saveStatus(strategy *models.Strategy) error {
+	if _, err := s.db.Exec("UPDATE strategies SET status = ? WHERE id = ?", strategy.Status, strategy.ID); err != nil {
+		return fmt.Errorf("save strategy %s: %w", strategy.ID, err)
+	}
+	return nil
+}
+
+// GetActiveStrategies implements store.StrategyStore
+// Paused strategies are included
+func (s *StrategyStore) GetActiveStrategies() ([]*models.Strategy, error) {
+	return s.queryStrategies("WHERE status IN ('active', 'paused')")
+}
+
+// GetStrategyHistory implements store.StrategyStore
+func (s *StrategyStore) GetStrategyHistory() ([]*models.Strategy, error) {
+	return s.queryStrategies("WHERE status = 'stopped'")
+}
+
+// GetStrategiesBySymbol implements store.StrategyStore
+func (s *StrategyStore) GetStrategiesBySymbol(symbol string) ([]*models.Strategy, error) {
+	active, err := s.GetActiveStrategies()
+	if err != nil {
+		return nil, err
+	}
+
+	strategies := make([]*models.Strategy, 0)
+	for _, strategy := range active {
+		if models.TradesSymbol(strategy.Parameters, symbol) {
+			strategies = append(strategies, strategy)
+		}
+	}
+	return strategies, nil
+}
+
+// CountActive implements store.StrategyStore
+// Returns 0 (and logs) if the count cannot be read
+func (s *StrategyStore) CountActive() int {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM strategies WHERE status IN ('active', 'paused')").Scan(&count); err != nil {
+		logging.Errorf("Error counting active strategies: %v", err)
+		return 0
+	}
+	return count
+}
+
+// GetStrategyByID implements store.StrategyStore
+func (s *StrategyStore) GetStrategyByID(id string) (*models.Strategy, error) {
+	strategies, err := s.queryStrategies("WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(strategies) == 0 {
+		return nil, &models.StrategyError{
+			Code:    models.ErrStrategyNotFound,
+			Message: fmt.Sprintf("Strategy not found: %s", id),
+		}
+	}
+	return strategies[0], nil
+}
+
+// queryStrategies loads the strategies selected by a WHERE clause
+func (s *StrategyStore) queryStrategies(clause string, args ...interface{}) ([]*models.Strategy, error) {
+	rows, err := s.db.Query("SELECT id, name, parameters, start_time, stop_time, status FROM strategies "+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	strategies := make([]*models.Strategy, 0)
+	for rows.Next() {
+		var strategy models.Strategy
+		var params string
+		var startTime int64
+		var stopTime sql.NullInt64
+		if err := rows.Scan(&strategy.ID, &strategy.Name, &params, &startTime, &stopTime, &strategy.Status); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(params), &strategy.Parameters); err != nil {
+			return nil, fmt.Errorf("decode parameters of strategy %s: %w", strategy.ID, err)
+		}
+		strategy.StartTime = fromUnixNano(startTime)
+		if stopTime.Valid {
+			stopped := fromUnixNano(stopTime.Int64)
+			strategy.StopTime = &stopped
+		}
+		strategies = append(strategies, &strategy)
+	}
+	return strategies, rows.Err()
+}
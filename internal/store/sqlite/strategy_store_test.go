@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// openTestStrategyDB opens a throwaway strategy store closed when the test
+// ends
+func openTestStrategyDB(t *testing.T) *StrategyStore {
+	t.Helper()
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewStrategyStore(db)
+}
+
+func TestStrategiesBySymbolAndActiveCount(t *testing.T) {
+	s := openTestStrategyDB(t)
+	create := func(name string, params map[string]interface{}) string {
+		t.Helper()
+		strategy, err := s.CreateStrategy(name, params)
+		if err != nil {
+			t.Fatalf("CreateStrategy: %v", err)
+		}
+		return strategy.ID
+	}
+
+	martingale := create("martingale", map[string]interface{}{"symbol": "AAPL"})
+	repeat := create("repeat", map[string]interface{}{"symbol": "aapl"})
+	pairs := create("pairs", map[string]interface{}{"symbol_a": "MSFT", "symbol_b": "AAPL"})
+	msft := create("repeat", map[string]interface{}{"symbol": "MSFT"})
+	paused := create("rsi", map[string]interface{}{"symbol": "AAPL"})
+	stopped := create("rsi", map[string]interface{}{"symbol": "AAPL"})
+	if _, err := s.PauseStrategy(paused); err != nil {
+		t.Fatalf("PauseStrategy: %v", err)
+	}
+	if _, err := s.StopStrategy(stopped); err != nil {
+		t.Fatalf("StopStrategy: %v", err)
+	}
+
+	// ids returns the sorted IDs of the active strategies trading symbol
+	ids := func(symbol string) string {
+		t.Helper()
+		strategies, err := s.GetStrategiesBySymbol(symbol)
+		if err != nil {
+			t.Fatalf("GetStrategiesBySymbol(%s): %v", symbol, err)
+		}
+		if strategies == nil {
+			t.Errorf("GetStrategiesBySymbol(%s) returned nil, want an empty list", symbol)
+		}
+		found := make([]string, len(strategies))
+		for i, strategy := range strategies {
+			found[i] = strategy.ID
+		}
+		sort.Strings(found)
+		return strings.Join(found, ",")
+	}
+	sorted := func(ids ...string) string {
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+
+	// Matching ignores case, takes either leg of a pair and counts paused
+	// strategies but not stopped ones
+	if got, want := ids("AAPL"), sorted(martingale, repeat, pairs, paused); got != want {
+		t.Errorf("AAPL strategies %s, want %s", got, want)
+	}
+	if got, want := ids("msft"), sorted(pairs, msft); got != want {
+		t.Errorf("MSFT strategies %s, want %s", got, want)
+	}
+	if got := ids("TSLA"); got != "" {
+		t.Errorf("TSLA strategies %s, want none", got)
+	}
+	if n := s.CountActive(); n != 5 {
+		t.Errorf("%d active strategies, want 5 with the paused one and without the stopped one", n)
+	}
+}
@@ -0,0 +1,603 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/google/uuid"
+)
+
+/*
+SQLite Trade Store Flow and Structure:
+
+1. Memory Structure:
+   TradeStore
+   ├── db: *sql.DB                     // trades table (see db.go)
+   ├── listeners: []TradeEventListener  // Event observers
+   ├── listenerQueueSize: int          // >0 delivers events asynchronously
+   ├── priceGuard: *PriceGuard         // Optional stale-price rejection
+   ├── halts: *SymbolHalts             // Optional per-symbol trading halts
+   ├── closePolicy: ClosePolicy        // Lot order for CloseSymbol (fifo/lifo)
+   ├── symbols: map[string]string      // Optional allowlist (upper-case -> canonical)
+   ├── limits: PositionLimits          // Optional caps on open trades
+   ├── lossBreaker: *LossBreaker       // Optional daily loss circuit breaker
+   ├── clock: Clock                    // Entry/exit timestamps
+   ├── mu: sync.Mutex                  // Serializes writes; protects settings
+   └── emitMu: sync.Mutex              // Serializes writes and their events
+                                       // (taken before mu)
+
+2. Operation Flow:
+   Validation matches the in-memory store (side, quantity, allowlist,
+   SYMBOL_DISABLED, STALE_PRICE on opens only, RISK_LIMIT, POSITION_LIMIT,
+   exit price); only storage differs.
+
+   a. Create Trade:
+      1. Validate
+      2. INSERT the open trade (exit_time 0)
+      3. Emit TradeCreated once the insert has committed
+
+   b. Close Trade:
+      1. Load the open trade, validate
+      2. UPDATE exit details and realized P&L
+      3. Emit TradeClosed once the update has committed
+
+   c. Close Symbol (netting):
+      1. Load the symbol's open lots and order them by close policy
+      2. In one transaction close whole lots and split a partly needed
+         lot (the remainder is inserted under a new ID)
+      3. After commit emit TradeClosed (and TradeCreated for a remainder)
+
+   d. Query:
+      Open trades have exit_time 0; history is exit_time > 0, filtered
+      and paged in SQL, newest exit first (ties by trade ID)
+
+3. Restart:
+   Open trades and history live in the database, so a store reopened on
+   the same file returns the trades left by the previous process.
+   No events are emitted for reloaded trades.
+*/
+
+// TradeStore implements store.TradeStore on a SQLite database
+type TradeStore struct {
+	db          *sql.DB
+	listeners   []store.TradeEventListener
+	priceGuard  *store.PriceGuard
+	halts       *store.SymbolHalts
+	closePolicy store.ClosePolicy
+	symbols     map[string]string
+	limits      store.PositionLimits
+	lossBreaker *store.LossBreaker
+	clock       clock.Clock
+	mu          sync.Mutex
+
+	listenerQueueSize int
+	emitMu            sync.Mutex
+}
+
+// NewTradeStore creates a trade store on a database opened with Open
+func NewTradeStore(db *sql.DB) *TradeStore {
+	return &TradeStore{
+		db:          db,
+		listeners:   make([]store.TradeEventListener, 0),
+		closePolicy: store.ClosePolicyFIFO,
+		clock:       clock.Real{},
+	}
+}
+
+// SetClock sets the time source for entry and exit timestamps
+func (s *TradeStore) SetClock(clk clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clk
+}
+
+// SetClosePolicy sets the lot order used by CloseSymbol
+func (s *TradeStore) SetClosePolicy(policy store.ClosePolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closePolicy = policy
+}
+
+// SetSymbols restricts CreateTrade to the given symbols, matched
+// case-insensitively; an empty list accepts any symbol
+func (s *TradeStore) SetSymbols(symbols []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(symbols) == 0 {
+		s.symbols = nil
+		return
+	}
+	s.symbols = make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		s.symbols[strings.ToUpper(symbol)] = symbol
+	}
+}
+
+// SetSymbolHalts rejects opens and closes on symbols halted in the set
+func (s *TradeStore) SetSymbolHalts(halts *store.SymbolHalts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.halts = halts
+}
+
+// SetPositionLimits caps the trades CreateTrade may hold open, per symbol
+// and in total; zero limits are unlimited
+func (s *TradeStore) SetPositionLimits(limits store.PositionLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+}
+
+// SetLossBreaker rejects opens while the breaker's daily loss limit is
+// reached; the breaker must also be added as a listener to see closes
+func (s *TradeStore) SetLossBreaker(breaker *store.LossBreaker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lossBreaker = breaker
+}
+
+// SetPriceGuard enables rejection of trades on stale prices
+func (s *TradeStore) SetPriceGuard(guard *store.PriceGuard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priceGuard = guard
+}
+
+// SetListenerQueueSize makes listeners added afterwards receive events
+// asynchronously through per-listener ordered queues of this size; 0 keeps
+// synchronous delivery
+func (s *TradeStore) SetListenerQueueSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listenerQueueSize = size
+}
+
+// AddListener implements store.TradeEventEmitter
+func (s *TradeStore) AddListener(listener store.TradeEventListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listenerQueueSize > 0 {
+		listener = store.NewOrderedListener(listener, s.listenerQueueSize)
+	}
+	s.listeners = append(s.listeners, listener)
+}
+
+// RemoveListener implements store.TradeEventEmitter
+func (s *TradeStore) RemoveListener(listener store.TradeEventListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, l := range s.listeners {
+		if ordered, ok := l.(*store.OrderedListener); ok && ordered.Listener() == listener {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			go ordered.Close() // Drain without holding the lock
+			break
+		}
+		if l == listener {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// lockWrite takes the locks for a change that emits events
+// s.emitMu is taken before s.mu, so a writer waiting its turn to emit never
+// holds the lock listeners may need
+func (s *TradeStore) lockWrite() {
+	s.emitMu.Lock()
+	s.mu.Lock()
+}
+
+// unlockWrite releases the locks of a change that emits no events
+func (s *TradeStore) unlockWrite() {
+	s.mu.Unlock()
+	s.emitMu.Unlock()
+}
+
+// emitEvent notifies all listeners of committed trade events, in order
+// Caller must hold the write locks (lockWrite); s.mu is released before
+// listeners are notified and s.emitMu after, so events are emitted in
+// commit order
+func (s *TradeStore) emitEvent(events ...store.TradeEvent) {
+	listeners := make([]store.TradeEventListener, len(s.listeners))
+	copy(listeners, s.listeners)
+
+	defer s.emitMu.Unlock()
+	s.mu.Unlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener.OnTradeEvent(event)
+		}
+	}
+}
+
+// CreateTrade implements store.BasicTradeStore
+func (s *TradeStore) CreateTrade(symbol string, entryPrice, quantity float64, side models.Side, strategyID string) (*models.Trade, error) {
+	return s.CreateTradeWithExits(symbol, entryPrice, quantity, side, strategyID, 0, 0)
+}
+
+// CreateTradeWithExits implements store.ExitTradeCreator
+func (s *TradeStore) CreateTradeWithExits(symbol string, entryPrice, quantity float64, side models.Side, strategyID string, takeProfit, stopLoss float64) (*models.Trade, error) {
+	if err := models.ValidateNewTrade(side, entryPrice, quantity, takeProfit, stopLoss); err != nil {
+		return nil, err
+	}
+
+	s.lockWrite()
+
+	if s.symbols != nil {
+		canonical, ok := s.symbols[strings.ToUpper(symbol)]
+		if !ok {
+			s.unlockWrite()
+			return nil, &models.TradeError{
+				Code:    models.ErrInvalidSymbol,
+				Message: fmt.Sprintf("Invalid trading symbol: %s", symbol),
+			}
+		}
+		symbol = canonical
+	}
+
+	if err := s.halts.Check(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := s.priceGuard.Check(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := s.lossBreaker.Check(); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := s.checkLimits(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	trade := &models.Trade{
+		ID:         fmt.Sprintf("trade-%s", uuid.New().String()),
+		StrategyID: strategyID,
+		Symbol:     symbol,
+		Side:       side,
+		Quantity:   quantity,
+		EntryPrice: entryPrice,
+		EntryTime:  s.clock.Now(),
+		TakeProfit: takeProfit,
+		StopLoss:   stopLoss,
+	}
+
+	if err := insertTrade(s.db, trade); err != nil {
+		s.unlockWrite()
+		return nil, &models.TradeError{
+			Code:    models.ErrTradeCreation,
+			Message: fmt.Sprintf("Failed to save trade: %v", err),
+		}
+	}
+	logging.Debugf("Trade opened: %s", trade.ID)
+
+	tradeCopy := *trade
+
+	// Notify listeners with copied data; releases the lock
+	s.emitEvent(store.TradeEvent{
+		Type:  store.TradeCreated,
+		Trade: &tradeCopy,
+	})
+
+	return trade, nil
+}
+
+// checkLimits enforces the open trade limits for a new trade on symbol,
+// counting open trades in the database
+// Caller must hold s.mu, so no insert races the count
+func (s *TradeStore) checkLimits(symbol string) error {
+	if !s.limits.Enabled() {
+		return nil
+	}
+	var symbolOpen, totalOpen int
+	err := s.db.QueryRow("SELECT COALESCE(SUM(UPPER(symbol) = UPPER(?)), 0), COUNT(*) FROM trades WHERE exit_time = 0", symbol).
+		Scan(&symbolOpen, &totalOpen)
+	if err != nil {
+		return &models.TradeError{
+			Code:    models.ErrTradeCreation,
+			Message: fmt.Sprintf("Failed to count open trades: %v", err),
+		}
+	}
+	return s.limits.Check(symbol, symbolOpen, totalOpen)
+}
+
+// CloseTrade implements store.BasicTradeStore
+func (s *TradeStore) CloseTrade(id string, exitPrice float64, reason string) (*models.Trade, error) {
+	s.lockWrite()
+
+	trade, err := s.getTrade(id)
+	if err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if !trade.ExitTime.IsZero() {
+		s.unlockWrite()
+		return nil, &models.TradeError{
+			Code:    models.ErrTradeAlreadyClosed,
+			Message: fmt.Sprintf("Trade already closed: %s", id),
+		}
+	}
+
+	if err := s.halts.Check(trade.Symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	if err := models.ValidateExitPrice(exitPrice); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	trade.ExitTime = s.clock.Now()
+	trade.ExitPrice = exitPrice
+	trade.CloseReason = reason
+	trade.ComputePnL()
+
+	if err := updateTrade(s.db, trade); err != nil {
+		s.unlockWrite()
+		return nil, &models.TradeError{
+			Code:    models.ErrTradeClosing,
+			Message: fmt.Sprintf("Failed to save trade: %v", err),
+		}
+	}
+	logging.Debugf("Trade closed: %s", trade.ID)
+
+	tradeCopy := *trade
+
+	// Notify listeners with copied data; releases the lock
+	s.emitEvent(store.TradeEvent{
+		Type:  store.TradeClosed,
+		Trade: &tradeCopy,
+	})
+
+	return trade, nil
+}
+
+// CloseSymbol implements store.SymbolCloser
+func (s *TradeStore) CloseSymbol(symbol string, quantity, exitPrice float64, reason string) ([]*models.Trade, error) {
+	if quantity < 0 || math.IsInf(quantity, 0) || math.IsNaN(quantity) {
+		return nil, &models.TradeError{
+			Code:    models.ErrInvalidQuantity,
+			Message: fmt.Sprintf("Invalid quantity: %v", quantity),
+		}
+	}
+	if err := models.ValidateExitPrice(exitPrice); err != nil {
+		return nil, err
+	}
+
+	s.lockWrite()
+
+	lots, err := queryTrades(s.db, "WHERE exit_time = 0 AND symbol = ?", symbol)
+	if err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+	if len(lots) == 0 {
+		s.unlockWrite()
+		return nil, &models.TradeError{
+			Code:    models.ErrNoOpenLots,
+			Message: fmt.Sprintf("No open lots for symbol: %s", symbol),
+		}
+	}
+
+	if err := s.halts.Check(symbol); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	store.SortLots(lots, s.closePolicy)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+
+	closed := make([]*models.Trade, 0, len(lots))
+	events := make([]store.TradeEvent, 0, len(lots)+1)
+	var reopened *models.Trade
+	remaining := quantity
+	for _, lot := range lots {
+		if quantity > 0 && remaining <= 0 {
+			break
+		}
+
+		// Split off the part of the lot that stays open
+		if quantity > 0 && remaining < lot.Quantity {
+			remainder := *lot
+			remainder.ID = fmt.Sprintf("trade-%s", uuid.New().String())
+			remainder.Quantity = lot.Quantity - remaining
+			lot.Quantity = remaining
+			if err := insertTrade(tx, &remainder); err != nil {
+				tx.Rollback()
+				s.unlockWrite()
+				return nil, err
+			}
+			reopened = &remainder
+		}
+		remaining -= lot.Quantity
+
+		lot.ExitTime = s.clock.Now()
+		lot.ExitPrice = exitPrice
+		lot.CloseReason = reason
+		lot.ComputePnL()
+		if err := updateTrade(tx, lot); err != nil {
+			tx.Rollback()
+			s.unlockWrite()
+			return nil, err
+		}
+
+		closed = append(closed, lot)
+		lotCopy := *lot
+		events = append(events, store.TradeEvent{Type: store.TradeClosed, Trade: &lotCopy})
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.unlockWrite()
+		return nil, err
+	}
+	for _, lot := range closed {
+		logging.Debugf("Trade closed: %s", lot.ID)
+	}
+
+	// The remainder of a split lot opens after the closed part
+	if reopened != nil {
+		reopenedCopy := *reopened
+		events = append(events, store.TradeEvent{Type: store.TradeCreated, Trade: &reopenedCopy})
+	}
+
+	// Notify listeners with copied data; releases the lock
+	s.emitEvent(events...)
+
+	return closed, nil
+}
+
+// GetOpenTrades implements store.BasicTradeStore
+func (s *TradeStore) GetOpenTrades() ([]*models.Trade, error) {
+	return queryTrades(s.db, "WHERE exit_time = 0")
+}
+
+// GetTradeHistory implements store.BasicTradeStore
+func (s *TradeStore) GetTradeHistory() ([]*models.Trade, error) {
+	return queryTrades(s.db, "WHERE exit_time > 0")
+}
+
+// GetPositions implements store.BasicTradeStore
+// Open and closed trades are read in one query, so they are consistent
+func (s *TradeStore) GetPositions() ([]*models.Position, error) {
+	trades, err := queryTrades(s.db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	open := make([]*models.Trade, 0)
+	closed := make([]*models.Trade, 0, len(trades))
+	for _, trade := range trades {
+		if trade.ExitTime.IsZero() {
+			open = append(open, trade)
+		} else {
+			closed = append(closed, trade)
+		}
+	}
+	return store.AggregatePositions(open, closed), nil
+}
+
+// GetTradeHistoryFiltered implements store.BasicTradeStore
+// Ties on exit time are broken by trade ID so pages are stable
+func (s *TradeStore) GetTradeHistoryFiltered(opts store.TradeHistoryQuery) ([]*models.Trade, error) {
+	where := "WHERE exit_time > 0"
+	args := make([]interface{}, 0, 5)
+	if opts.Symbol != "" {
+		where += " AND symbol = ?"
+		args = append(args, opts.Symbol)
+	}
+	if !opts.From.IsZero() {
+		where += " AND exit_time >= ?"
+		args = append(args, opts.From.UnixNano())
+	}
+	if !opts.To.IsZero() {
+		where += " AND exit_time < ?"
+		args = append(args, opts.To.UnixNano())
+	}
+	where += " ORDER BY exit_time DESC, id ASC"
+
+	// SQLite needs a LIMIT to take an OFFSET; -1 means no limit
+	if opts.Limit > 0 || opts.Offset > 0 {
+		limit := opts.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		where += " LIMIT ? OFFSET ?"
+		args = append(args, limit, opts.Offset)
+	}
+	return queryTrades(s.db, where, args...)
+}
+
+// GetTradeByID implements store.BasicTradeStore
+func (s *TradeStore) GetTradeByID(id string) (*models.Trade, error) {
+	return s.getTrade(id)
+}
+
+// getTrade loads a trade by ID, open or closed
+func (s *TradeStore) getTrade(id string) (*models.Trade, error) {
+	trades, err := queryTrades(s.db, "WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(trades) == 0 {
+		return nil, &models.TradeError{
+			Code:    models.ErrTradeNotFound,
+			Message: fmt.Sprintf("Trade not found: %s", id),
+		}
+	}
+	return trades[0], nil
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+const tradeColumns = "id, strategy_id, symbol, side, quantity, entry_price, exit_price, entry_time, exit_time, close_reason, pnl, pnl_percent, take_profit, stop_loss"
+
+// insertTrade stores a new trade row
+func insertTrade(db execer, t *models.Trade) error {
+	_, err := db.Exec("INSERT INTO trades ("+tradeColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		t.ID, t.StrategyID, t.Symbol, string(t.Side), t.Quantity, t.EntryPrice, t.ExitPrice,
+		toUnixNano(t.EntryTime), toUnixNano(t.ExitTime), t.CloseReason, t.PnL, t.PnLPercent, t.TakeProfit, t.StopLoss)
+	return err
+}
+
+// updateTrade saves a trade's quantity and exit details
+func updateTrade(db execer, t *models.Trade) error {
+	result, err := db.Exec("UPDATE trades SET quantity = ?, exit_price = ?, exit_time = ?, close_reason = ?, pnl = ?, pnl_percent = ? WHERE id = ?",
+		t.Quantity, t.ExitPrice, toUnixNano(t.ExitTime), t.CloseReason, t.PnL, t.PnLPercent, t.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return errors.New("trade row missing: " + t.ID)
+	}
+	return nil
+}
+
+// queryTrades loads the trades selected by a WHERE/ORDER clause
+func queryTrades(db *sql.DB, clause string, args ...interface{}) ([]*models.Trade, error) {
+	rows, err := db.Query("SELECT "+tradeColumns+" FROM trades "+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trades := make([]*models.Trade, 0)
+	for rows.Next() {
+		var t models.Trade
+		var side string
+		var entryTime, exitTime int64
+		if err := rows.Scan(&t.ID, &t.StrategyID, &t.Symbol, &side, &t.Quantity, &t.EntryPrice, &t.ExitPrice,
+			&entryTime, &exitTime, &t.CloseReason, &t.PnL, &t.PnLPercent, &t.TakeProfit, &t.StopLoss); err != nil {
+			return nil, err
+		}
+		t.Side = models.Side(side)
+		t.EntryTime = fromUnixNano(entryTime)
+		t.ExitTime = fromUnixNano(exitTime)
+		trades = append(trades, &t)
+	}
+	return trades, rows.Err()
+}
@@ -0,0 +1,305 @@
+package sqlite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+// openTestDB opens a throwaway database closed when the test ends
+func openTestDB(t *testing.T) *TradeStore {
+	t.Helper()
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewTradeStore(db)
+}
+
+// readingListener reads the store on every event, like FlatHandler
+type readingListener struct {
+	store *TradeStore
+}
+
+func (l *readingListener) OnTradeEvent(event store.TradeEvent) {
+	l.store.GetOpenTrades()
+	l.store.GetPositions()
+}
+
+func TestTradeStoreConcurrentWritesWithReadingListener(t *testing.T) {
+	s := openTestDB(t)
+	s.AddListener(&readingListener{store: s})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 25; j++ {
+					trade, err := s.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+					if err != nil {
+						t.Errorf("CreateTrade: %v", err)
+						return
+					}
+					if _, err := s.CloseTrade(trade.ID, 101, "test"); err != nil {
+						t.Errorf("CloseTrade: %v", err)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("concurrent writes deadlocked")
+	}
+}
+
+func TestPriceGuardBlocksOnlyOpens(t *testing.T) {
+	start := time.Date(2025, 1, 23, 11, 0, 0, 0, time.UTC)
+	clk := clock.NewSimulated(start)
+	prices := memory.NewInMemoryPriceCache()
+	prices.UpdatePrice(&models.Tick{Symbol: "AAPL", Price: 100, Timestamp: start})
+
+	s := openTestDB(t)
+	s.SetClock(clk)
+	s.SetPriceGuard(store.NewPriceGuard(prices, time.Second, clk))
+
+	first, err := s.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	if err != nil {
+		t.Fatalf("CreateTrade on a fresh price: %v", err)
+	}
+	if _, err := s.CreateTrade("AAPL", 100, 1, models.SideBuy, ""); err != nil {
+		t.Fatalf("CreateTrade on a fresh price: %v", err)
+	}
+
+	// The feed stalls: opens are refused, closes still go through
+	clk.Advance(start.Add(time.Minute))
+	_, err = s.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	if tradeErr, ok := err.(*models.TradeError); !ok || tradeErr.Code != models.ErrStalePrice {
+		t.Fatalf("CreateTrade on a stale price returned %v, want STALE_PRICE", err)
+	}
+	if _, err := s.CloseTrade(first.ID, 101, models.CloseReasonManual); err != nil {
+		t.Errorf("CloseTrade on a stale price: %v", err)
+	}
+	if closed, err := s.CloseSymbol("AAPL", 0, 101, models.CloseReasonManual); err != nil || len(closed) != 1 {
+		t.Errorf("CloseSymbol on a stale price closed %d trades, err %v; want 1", len(closed), err)
+	}
+}
+
+func TestClosePolicyOrdersLots(t *testing.T) {
+	for _, tc := range []struct {
+		policy store.ClosePolicy
+		closed []float64 // Entry prices of the closed lots, in close order
+		open   []float64 // Entry prices of the lots left open, ascending
+	}{
+		{store.ClosePolicyFIFO, []float64{100, 101}, []float64{101, 102}},
+		{store.ClosePolicyLIFO, []float64{102, 101}, []float64{100, 101}},
+	} {
+		start := time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC)
+		clk := clock.NewSimulated(start)
+		s := openTestDB(t)
+		s.SetClock(clk)
+		s.SetClosePolicy(tc.policy)
+
+		// Three lots an hour apart, told apart by entry price
+		for i, price := range []float64{100, 101, 102} {
+			clk.Advance(start.Add(time.Duration(i) * time.Hour))
+			if _, err := s.CreateTrade("AAPL", price, 1, models.SideBuy, ""); err != nil {
+				t.Fatalf("CreateTrade: %v", err)
+			}
+		}
+
+		// One and a half lots: one closes whole, the next is split
+		closed, err := s.CloseSymbol("AAPL", 1.5, 110, models.CloseReasonManual)
+		if err != nil {
+			t.Fatalf("%s: CloseSymbol: %v", tc.policy, err)
+		}
+		if len(closed) != 2 || closed[0].EntryPrice != tc.closed[0] || closed[0].Quantity != 1 ||
+			closed[1].EntryPrice != tc.closed[1] || closed[1].Quantity != 0.5 {
+			t.Errorf("%s closed %s, want all of the %v lot then half of the %v lot", tc.policy, describeLots(closed), tc.closed[0], tc.closed[1])
+		}
+
+		open, _ := s.GetOpenTrades()
+		sort.Slice(open, func(i, j int) bool { return open[i].EntryPrice < open[j].EntryPrice })
+		if len(open) != 2 || open[0].EntryPrice != tc.open[0] || open[1].EntryPrice != tc.open[1] || open[0].Quantity+open[1].Quantity != 1.5 {
+			t.Errorf("%s left %s open, want lots entered at %v with 1.5 in total", tc.policy, describeLots(open), tc.open)
+		}
+	}
+}
+
+// describeLots lists lots as quantity@entry price
+func describeLots(lots []*models.Trade) string {
+	parts := make([]string, len(lots))
+	for i, lot := range lots {
+		parts[i] = fmt.Sprintf("%v@%v", lot.Quantity, lot.EntryPrice)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+func TestGetTradeByIDFindsOpenAndClosed(t *testing.T) {
+	s := openTestDB(t)
+	open, err := s.CreateTrade("AAPL", 100, 1, models.SideBuy, "")
+	if err != nil {
+		t.Fatalf("CreateTrade: %v", err)
+	}
+	closed, _ := s.CreateTrade("MSFT", 200, 1, models.SideBuy, "")
+	if _, err := s.CloseTrade(closed.ID, 210, models.CloseReasonManual); err != nil {
+		t.Fatalf("CloseTrade: %v", err)
+	}
+
+	if got, err := s.GetTradeByID(open.ID); err != nil || got.Symbol != "AAPL" || !got.ExitTime.IsZero() {
+		t.Errorf("open trade: got %v, %v; want the open AAPL trade", got, err)
+	}
+	if got, err := s.GetTradeByID(closed.ID); err != nil || got.Symbol != "MSFT" || got.ExitPrice != 210 {
+		t.Errorf("closed trade: got %v, %v; want the MSFT trade closed at 210", got, err)
+	}
+	_, err = s.GetTradeByID("missing")
+	if e, ok := err.(*models.TradeError); !ok || e.Code != models.ErrTradeNotFound {
+		t.Errorf("missing ID: got %v, want %s", err, models.ErrTradeNotFound)
+	}
+}
+
+func TestCreateTradeRejectsNonPositiveEntryPrice(t *testing.T) {
+	s := openTestDB(t)
+	for _, price := range []float64{0, -100} {
+		_, err := s.CreateTrade("AAPL", price, 1, models.SideBuy, "")
+		if e, ok := err.(*models.TradeError); !ok || e.Code != models.ErrInvalidEntryPrice {
+			t.Errorf("entry price %v: got %v, want %s", price, err, models.ErrInvalidEntryPrice)
+		}
+	}
+	if open, _ := s.GetOpenTrades(); len(open) != 0 {
+		t.Fatalf("%d trades opened at invalid prices, want 0", len(open))
+	}
+
+	if trade, err := s.CreateTrade("AAPL", 100, 1, models.SideBuy, ""); err != nil || trade.EntryPrice != 100 {
+		t.Errorf("valid entry price: got %v, %v; want a trade at 100", trade, err)
+	}
+}
+
+func TestCreateTradeChecksSymbolAllowlist(t *testing.T) {
+	s := openTestDB(t)
+	s.SetSymbols([]string{"AAPL", "BTC/USD"})
+
+	if _, err := s.CreateTrade("AAPL", 100, 1, models.SideBuy, ""); err != nil {
+		t.Errorf("allowed symbol rejected: %v", err)
+	}
+
+	_, err := s.CreateTrade("APPL", 100, 1, models.SideBuy, "")
+	if e, ok := err.(*models.TradeError); !ok || e.Code != models.ErrInvalidSymbol {
+		t.Errorf("unlisted symbol: got %v, want %s", err, models.ErrInvalidSymbol)
+	}
+
+	// Matching ignores case and stores the configured spelling
+	trade, err := s.CreateTrade("btc/usd", 30000, 1, models.SideBuy, "")
+	if err != nil {
+		t.Fatalf("lower-case symbol rejected: %v", err)
+	}
+	if trade.Symbol != "BTC/USD" {
+		t.Errorf("trade symbol %q, want BTC/USD", trade.Symbol)
+	}
+}
+
+func TestTradeHistoryFiltered(t *testing.T) {
+	start := time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC)
+	clk := clock.NewSimulated(start)
+	s := openTestDB(t)
+	s.SetClock(clk)
+
+	// Five trades closed a minute apart, told apart by exit price 101-105
+	for i, symbol := range []string{"AAPL", "MSFT", "AAPL", "MSFT", "AAPL"} {
+		trade, err := s.CreateTrade(symbol, 100, 1, models.SideBuy, "")
+		if err != nil {
+			t.Fatalf("CreateTrade: %v", err)
+		}
+		clk.Advance(start.Add(time.Duration(i+1) * time.Minute))
+		if _, err := s.CloseTrade(trade.ID, float64(101+i), models.CloseReasonManual); err != nil {
+			t.Fatalf("CloseTrade: %v", err)
+		}
+	}
+
+	minute := func(n int) time.Time { return start.Add(time.Duration(n) * time.Minute) }
+	for _, tc := range []struct {
+		name  string
+		query store.TradeHistoryQuery
+		want  []float64 // Exit prices, newest first
+	}{
+		{"everything", store.TradeHistoryQuery{}, []float64{105, 104, 103, 102, 101}},
+		{"first page", store.TradeHistoryQuery{Limit: 2}, []float64{105, 104}},
+		{"partial last page", store.TradeHistoryQuery{Limit: 2, Offset: 4}, []float64{101}},
+		{"offset at the end", store.TradeHistoryQuery{Limit: 2, Offset: 5}, nil},
+		{"offset past the end", store.TradeHistoryQuery{Offset: 10}, nil},
+		{"symbol", store.TradeHistoryQuery{Symbol: "AAPL"}, []float64{105, 103, 101}},
+		{"symbol page", store.TradeHistoryQuery{Symbol: "AAPL", Limit: 1, Offset: 1}, []float64{103}},
+		{"range excludes To", store.TradeHistoryQuery{From: minute(2), To: minute(4)}, []float64{103, 102}},
+		{"range after the last trade", store.TradeHistoryQuery{From: minute(10)}, nil},
+		{"empty range", store.TradeHistoryQuery{From: minute(3), To: minute(3)}, nil},
+		{"unknown symbol", store.TradeHistoryQuery{Symbol: "GOOG"}, nil},
+	} {
+		trades, err := s.GetTradeHistoryFiltered(tc.query)
+		if err != nil {
+			t.Fatalf("%s: GetTradeHistoryFiltered: %v", tc.name, err)
+		}
+		got := make([]float64, 0, len(trades))
+		for _, trade := range trades {
+			got = append(got, trade.ExitPrice)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(append([]float64{}, tc.want...)) {
+			t.Errorf("%s: exit prices %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPositionLimitsCapOpenTrades(t *testing.T) {
+	trades := openTestDB(t)
+	trades.SetPositionLimits(store.PositionLimits{MaxOpenPerSymbol: 2, MaxOpenTotal: 3})
+
+	open := func(symbol string) (*models.Trade, error) {
+		return trades.CreateTrade(symbol, 100, 1, models.SideBuy, "")
+	}
+	isLimit := func(err error) bool {
+		e, ok := err.(*models.TradeError)
+		return ok && e.Code == models.ErrPositionLimit
+	}
+
+	// Up to the limits
+	first, err := open("AAPL")
+	if err != nil {
+		t.Fatalf("first AAPL trade: %v", err)
+	}
+	if _, err := open("AAPL"); err != nil {
+		t.Fatalf("second AAPL trade: %v", err)
+	}
+	if _, err := open("AAPL"); !isLimit(err) {
+		t.Errorf("third AAPL trade: got %v, want %s", err, models.ErrPositionLimit)
+	}
+	if _, err := open("MSFT"); err != nil {
+		t.Fatalf("MSFT trade under the total limit: %v", err)
+	}
+	if _, err := open("GOOG"); !isLimit(err) {
+		t.Errorf("fourth trade in total: got %v, want %s", err, models.ErrPositionLimit)
+	}
+
+	// Closing a trade frees its slot
+	if _, err := trades.CloseTrade(first.ID, 101, models.CloseReasonManual); err != nil {
+		t.Fatalf("CloseTrade: %v", err)
+	}
+	if _, err := open("AAPL"); err != nil {
+		t.Errorf("AAPL trade after a close: %v", err)
+	}
+}
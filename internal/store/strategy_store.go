@@ -9,9 +9,13 @@ Strategy Store Interface and Flow:
    StrategyStore
    ├── CreateStrategy        // Creates and stores new strategy
    ├── StopStrategy         // Stops a running strategy
+   ├── PauseStrategy        // Pauses an active strategy
+   ├── ResumeStrategy       // Resumes a paused strategy
    ├── GetActiveStrategies  // Lists all active strategies
    ├── GetStrategyHistory   // Lists all stopped strategies
-   └── GetStrategyByID      // Retrieves specific strategy
+   ├── GetStrategyByID      // Retrieves specific strategy
+   ├── GetStrategiesBySymbol // Lists active strategies trading a symbol
+   └── CountActive          // Counts active strategies
 
 2. Operation Flow:
    a. Creating Strategy:
@@ -27,15 +31,27 @@ Strategy Store Interface and Flow:
       4. Add to strategy history map
       5. Return updated strategy
 
-   c. Querying:
-      - GetActiveStrategies returns strategies from active map
+   c. Pausing/Resuming Strategy:
+      1. Find strategy by ID in active strategies
+      2. Mark as paused (strategy.Pause()) or active (strategy.Resume())
+      3. Keep it in the active strategies map
+      Pausing a paused strategy fails with ALREADY_PAUSED, resuming an
+      active one with NOT_PAUSED
+
+   d. Querying:
+      - GetActiveStrategies returns strategies from active map, paused
+        ones included
       - GetStrategyHistory returns strategies from history map
       - GetStrategyByID checks both maps
+      - GetStrategiesBySymbol filters the active map by the strategies'
+        symbol parameters (models.TradesSymbol: "symbol", or the
+        "symbol_a"/"symbol_b" legs of pairs), case-insensitively
+      - CountActive counts the active map, paused strategies included
 
 3. Data Organization:
    activeStrategies map[string]*Strategy
    ├── "moving_average-abc123" → Strategy{Status: "active"}
-   └── "rsi-def456" → Strategy{Status: "active"}
+   └── "rsi-def456" → Strategy{Status: "paused"}
 
    strategyHistory map[string]*Strategy
    └── "sma-xyz789" → Strategy{Status: "stopped"}
@@ -73,8 +89,15 @@ type StrategyStore interface {
 	// 3. Moves it from active to history map
 	StopStrategy(id string) (*models.Strategy, error)
 
+	// PauseStrategy marks an active strategy as paused
+	// The strategy stays in the active strategies map
+	PauseStrategy(id string) (*models.Strategy, error)
+
+	// ResumeStrategy marks a paused strategy as active again
+	ResumeStrategy(id string) (*models.Strategy, error)
+
 	// GetActiveStrategies returns all currently active strategies
-	// Returns strategies from the active strategies map
+	// Returns strategies from the active strategies map, including paused ones
 	GetActiveStrategies() ([]*models.Strategy, error)
 
 	// GetStrategyHistory returns all stopped strategies
@@ -84,4 +107,12 @@ type StrategyStore interface {
 	// GetStrategyByID returns a strategy by its ID
 	// Checks both active and history maps
 	GetStrategyByID(id string) (*models.Strategy, error)
+
+	// GetStrategiesBySymbol returns the active strategies, paused ones
+	// included, whose parameters name symbol (case-insensitive)
+	GetStrategiesBySymbol(symbol string) ([]*models.Strategy, error)
+
+	// CountActive returns the number of active strategies, paused ones
+	// included
+	CountActive() int
 }
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Symbol Halts Flow:
+
+1. Purpose:
+   Lets operators halt trading on individual symbols (e.g. during an
+   exchange halt) without affecting the others.
+
+2. Check Flow:
+   symbol → upper-case → halted?
+   ├── yes → SYMBOL_DISABLED (trade stores reject opens and closes;
+   │         the strategy runner holds back the symbol's ticks, pausing
+   │         strategies on it)
+   └── no  → allowed
+
+3. Sharing:
+   One SymbolHalts is shared by every trade store and the runner, so a
+   toggle takes effect everywhere at once. A nil *SymbolHalts halts nothing.
+*/
+
+// SymbolHalts is a thread-safe set of symbols with trading disabled
+type SymbolHalts struct {
+	disabled map[string]struct{}
+	mu       sync.RWMutex
+}
+
+// NewSymbolHalts creates a set with the given symbols disabled
+func NewSymbolHalts(symbols []string) *SymbolHalts {
+	h := &SymbolHalts{disabled: make(map[string]struct{})}
+	for _, symbol := range symbols {
+		h.Disable(symbol)
+	}
+	return h
+}
+
+// Disable halts trading on a symbol (case-insensitive)
+func (h *SymbolHalts) Disable(symbol string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.disabled[strings.ToUpper(symbol)] = struct{}{}
+}
+
+// Enable resumes trading on a symbol (case-insensitive)
+func (h *SymbolHalts) Enable(symbol string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.disabled, strings.ToUpper(symbol))
+}
+
+// IsDisabled reports whether trading on a symbol is halted
+func (h *SymbolHalts) IsDisabled(symbol string) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, disabled := h.disabled[strings.ToUpper(symbol)]
+	return disabled
+}
+
+// Disabled returns the halted symbols, sorted
+func (h *SymbolHalts) Disabled() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	symbols := make([]string, 0, len(h.disabled))
+	for symbol := range h.disabled {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Check returns a SYMBOL_DISABLED TradeError if trading on the symbol is halted
+func (h *SymbolHalts) Check(symbol string) error {
+	if h.IsDisabled(symbol) {
+		return &models.TradeError{
+			Code:    models.ErrSymbolDisabled,
+			Message: fmt.Sprintf("Trading is disabled for symbol: %s", symbol),
+		}
+	}
+	return nil
+}
@@ -22,6 +22,8 @@ Trade Events Flow and Structure:
 3. Event Types:
    - TradeCreated: New trade opened
    - TradeClosed: Existing trade closed
+   - TradeUpdated: Open trade's mark price moved (emitted by MarkTracker,
+     not the trade store); carries MarkPrice and UnrealizedPnL
 
 4. Usage Example:
    store.AddListener(openPositionsHandler)
@@ -38,12 +40,19 @@ const (
 	
 	// TradeClosed indicates an existing trade was closed
 	TradeClosed TradeEventType = "closed"
+
+	// TradeUpdated indicates an open trade was marked at a new price
+	TradeUpdated TradeEventType = "updated"
 )
 
 // TradeEvent represents a trade-related event
 type TradeEvent struct {
 	Type  TradeEventType // Type of event
 	Trade *models.Trade  // Associated trade
+
+	// Set on TradeUpdated events only
+	MarkPrice     float64 // Price the trade was marked at
+	UnrealizedPnL float64 // P&L of the open trade at MarkPrice
 }
 
 // TradeEventListener defines interface for objects that want to receive trade events
@@ -1,6 +1,10 @@
 package store
 
-import "github.com/aumbhatt/auto_trade/internal/models"
+import (
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
 
 /*
 Trade Store Interface and Flow:
@@ -11,14 +15,15 @@ Trade Store Interface and Flow:
    │   ├── CreateTrade
    │   ├── CloseTrade
    │   ├── GetOpenTrades
-   │   └── GetTradeHistory
+   │   ├── GetTradeHistory
+   │   └── GetPositions
    └── Event emission (from TradeEventEmitter)
        ├── AddListener
        └── RemoveListener
 
 2. Usage Flow:
    a. Create Trade:
-      symbol, price → CreateTrade() → Trade
+      symbol, price, quantity, side, strategyID → CreateTrade() → Trade
       1. Generate trade ID
       2. Create trade object
       3. Store in open trades
@@ -26,7 +31,7 @@ Trade Store Interface and Flow:
       5. Return trade
 
    b. Close Trade:
-      id → CloseTrade() → Trade
+      id, exitPrice, reason → CloseTrade() → Trade
       1. Find trade in open trades
       2. Add exit details
       3. Move to trade history
@@ -41,26 +46,108 @@ Trade Store Interface and Flow:
       GetTradeHistory() → []*Trade
       1. Return all closed trades
 
-3. Future Extensions:
-   - Add database persistence
-   - Add filtering/pagination
+   e. Get Positions:
+      GetPositions() → []*Position
+      1. Group open trades by strategy, symbol and side
+      2. Quantity-weighted average entry price per group
+      3. Add the realized P&L of the group's closed trades
+      (see AggregatePositions)
+
+   f. Create Trade With Exits (ExitTradeCreator, optional):
+      Like CreateTrade, with take-profit and stop-loss levels stored on
+      the trade; ExitMonitor closes it when a tick crosses either level
+
+   g. Query Trade History:
+      TradeHistoryQuery → GetTradeHistoryFiltered() → []*Trade
+      1. Keep closed trades matching Symbol and From <= ExitTime < To
+      2. Sort by ExitTime, newest first
+      3. Skip Offset trades, return at most Limit
+
+3. Implementations:
+   - memory.InMemoryTradeStore keeps trades in maps; state is lost on
+     restart
+   - sqlite.TradeStore persists trades in a SQLite database, so open
+     trades and history survive a restart; events are emitted after
+     each write commits
+   Both apply the same validation (see models.ValidateNewTrade) and
+   emit the same events; config storage.type picks one at startup
+
+4. Future Extensions:
    - Add trade updates
    - Add batch operations
 */
 
 // BasicTradeStore defines the core trade operations
 type BasicTradeStore interface {
-	// CreateTrade creates a new trade with given symbol and entry price
-	CreateTrade(symbol string, entryPrice float64) (*models.Trade, error)
+	// CreateTrade creates a new trade with given symbol, entry price, quantity and side
+	// strategyID links the trade to the strategy that opened it ("" for manual trades)
+	CreateTrade(symbol string, entryPrice, quantity float64, side models.Side, strategyID string) (*models.Trade, error)
 
-	// CloseTrade closes an existing trade
-	CloseTrade(id string) (*models.Trade, error)
+	// CloseTrade closes an existing trade at the given exit price
+	// reason records why it was closed (see models.CloseReason*)
+	CloseTrade(id string, exitPrice float64, reason string) (*models.Trade, error)
 
 	// GetOpenTrades returns all open trades
 	GetOpenTrades() ([]*models.Trade, error)
 
 	// GetTradeHistory returns all closed trades
 	GetTradeHistory() ([]*models.Trade, error)
+
+	// GetTradeByID returns a trade by ID, open or closed
+	// Returns a TRADE_NOT_FOUND TradeError for unknown IDs
+	GetTradeByID(id string) (*models.Trade, error)
+
+	// GetTradeHistoryFiltered returns the closed trades matching the query,
+	// newest exit first
+	GetTradeHistoryFiltered(opts TradeHistoryQuery) ([]*models.Trade, error)
+
+	// GetPositions returns the open positions per strategy, symbol and side
+	// (see AggregatePositions); prices are not marked
+	GetPositions() ([]*models.Position, error)
+}
+
+// ExitTradeCreator is implemented by trade stores that can open trades with
+// take-profit and stop-loss exits attached
+type ExitTradeCreator interface {
+	// CreateTradeWithExits is CreateTrade with take-profit and stop-loss
+	// levels (0 = none), checked with models.ValidateExits
+	CreateTradeWithExits(symbol string, entryPrice, quantity float64, side models.Side, strategyID string, takeProfit, stopLoss float64) (*models.Trade, error)
+}
+
+// TradeHistoryQuery selects a page of closed trades
+// Zero values mean "no constraint"
+type TradeHistoryQuery struct {
+	Symbol string    // Only trades for this symbol
+	From   time.Time // Exit time at or after From
+	To     time.Time // Exit time before To
+	Limit  int       // At most Limit trades (0 = all)
+	Offset int       // Skip the first Offset matching trades
+}
+
+// Matches reports whether a closed trade passes the query's filters
+func (q TradeHistoryQuery) Matches(trade *models.Trade) bool {
+	if q.Symbol != "" && trade.Symbol != q.Symbol {
+		return false
+	}
+	if !q.From.IsZero() && trade.ExitTime.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !trade.ExitTime.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// Page applies Offset and Limit to trades already filtered and sorted
+func (q TradeHistoryQuery) Page(trades []*models.Trade) []*models.Trade {
+	if q.Offset >= len(trades) {
+		return []*models.Trade{}
+	}
+	trades = trades[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(trades) {
+		trades = trades[:q.Limit]
+	}
+	return trades
 }
 
 // TradeStore combines basic trade operations with event emission capabilities
@@ -0,0 +1,185 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Candle Breakout Strategy Flow and Structure:
+
+1. Memory Structure:
+   CandleBreakoutStrategy
+   ├── runner: *DefaultRunner        // For executing trades
+   ├── symbol: string               // Trading symbol
+   ├── interval: time.Duration      // Candle length
+   ├── quantity: float64            // Units per trade
+   ├── previous: *models.Candle     // Last completed candle
+   ├── currentTrade: *models.Trade  // Open long (nil when flat)
+   ├── cooldown: *ReversalCooldown  // Optional whipsaw guard (see cooldown.go)
+   └── mu: sync.Mutex              // Protects shared state
+
+2. Operation Flow:
+   The runner aggregates ticks into candles (see CandleStrategyExecutor)
+   and hands over each completed candle:
+
+   a. Flat:
+      close > previous high → buy at the candle close
+
+   b. In Position:
+      close < previous low  → sell at the candle close
+
+3. Parameters:
+   {
+       "symbol": "AAPL",
+       "interval_seconds": 60,   // optional, candle length (default 60)
+       "quantity": 10,           // optional, defaults to 1
+       "reversal_cooldown_seconds": 60  // optional, timed by candle end
+   }
+*/
+
+// defaultCandleInterval is the candle length when interval_seconds is omitted
+const defaultCandleInterval = time.Minute
+
+// CandleBreakoutStrategy buys a close above the previous candle's high and
+// exits on a close below the previous candle's low
+type CandleBreakoutStrategy struct {
+	runner       *DefaultRunner
+	symbol       string
+	interval     time.Duration
+	quantity     float64
+	previous     *models.Candle
+	currentTrade *models.Trade
+	cooldown     *ReversalCooldown
+	mu           sync.Mutex
+}
+
+// NewCandleBreakoutStrategy creates a new candle breakout strategy instance
+func NewCandleBreakoutStrategy(runner *DefaultRunner, params map[string]interface{}) (StrategyExecutor, error) {
+	// Extract and validate symbol
+	symbol, ok := params["symbol"].(string)
+	if !ok || symbol == "" {
+		return nil, fmt.Errorf("invalid or missing symbol parameter")
+	}
+
+	// Extract and validate candle interval
+	interval := defaultCandleInterval
+	if raw, ok := params["interval_seconds"]; ok && raw != nil {
+		seconds, ok := raw.(float64)
+		if !ok || seconds < 1 || seconds != math.Trunc(seconds) {
+			return nil, fmt.Errorf("invalid interval_seconds parameter: must be a whole number >= 1")
+		}
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	quantity, err := quantityFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cooldown, err := NewReversalCooldownFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CandleBreakoutStrategy{
+		runner:   runner,
+		symbol:   symbol,
+		interval: interval,
+		quantity: quantity,
+		cooldown: cooldown,
+	}, nil
+}
+
+// ProcessTick implements the StrategyExecutor interface
+// The runner delivers candles to this strategy, so ticks are rejected
+func (s *CandleBreakoutStrategy) ProcessTick(tick *models.Tick) error {
+	return fmt.Errorf("candle_breakout consumes candles, not ticks")
+}
+
+// CandleInterval implements CandleStrategyExecutor
+func (s *CandleBreakoutStrategy) CandleInterval() time.Duration {
+	return s.interval
+}
+
+// ProcessCandle implements CandleStrategyExecutor
+func (s *CandleBreakoutStrategy) ProcessCandle(candle *models.Candle) error {
+	if candle == nil {
+		return fmt.Errorf("received nil candle")
+	}
+	if candle.Symbol != s.symbol {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.previous
+	s.previous = candle
+	if previous == nil {
+		return nil // Need a reference candle
+	}
+
+	// Flat: enter on a breakout above the previous high
+	if s.currentTrade == nil {
+		if candle.Close > previous.High && s.cooldown.Allows(s.symbol, models.SideBuy, candle.End) {
+			trade, err := s.runner.executeBuy(s.symbol, candle.Close, s.quantity)
+			if err != nil {
+				return fmt.Errorf("failed to execute buy: %w", err)
+			}
+			s.currentTrade = trade
+			logging.Debugf("Candle breakout entry: %s close %.2f > previous high %.2f", s.symbol, candle.Close, previous.High)
+		}
+		return nil
+	}
+
+	// In position: exit on a breakdown below the previous low
+	if candle.Close < previous.Low {
+		if _, err := s.runner.executeSell(s.currentTrade.ID, candle.Close); err != nil {
+			return fmt.Errorf("failed to execute sell: %w", err)
+		}
+		s.cooldown.RecordClose(s.symbol, models.SideSell, candle.End) // Long-only: the exit is the reversal
+		s.currentTrade = nil
+		logging.Debugf("Candle breakout exit: %s close %.2f < previous low %.2f", s.symbol, candle.Close, previous.Low)
+	}
+	return nil
+}
+
+// Metadata for the candle breakout strategy
+var candleBreakoutMetadata = models.StrategyMetadata{
+	Name: "candle_breakout",
+	Parameters: []models.ParameterInfo{
+		{
+			Name:        "symbol",
+			Type:        "string",
+			Required:    true,
+			Description: "Trading symbol (e.g. AAPL)",
+		},
+		{
+			Name:        "interval_seconds",
+			Type:        "number",
+			Required:    false,
+			Description: "Candle length in seconds (whole number, default 60)",
+		},
+		quantityParameter,
+		reversalCooldownParameter,
+		flattenOnStopParameter,
+		dryRunParameter,
+	},
+	Flow: []string{
+		"1. Aggregate ticks into candles of interval_seconds",
+		"2. When flat and a candle closes above the previous candle's high: Buy at the close",
+		"3. When long and a candle closes below the previous candle's low: Sell at the close",
+		"4. Repeat from step 2",
+	},
+}
+
+// init registers the candle breakout strategy with the registry
+func init() {
+	defaultRegistry.Register("candle_breakout", NewCandleBreakoutStrategy, candleBreakoutMetadata)
+}
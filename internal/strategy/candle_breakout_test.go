@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+// minuteCandle returns the nth one-minute AAPL candle of the test
+func minuteCandle(n int, low, high, close float64) *models.Candle {
+	start := time.Date(2025, 1, 23, 9, n, 0, 0, time.UTC)
+	return &models.Candle{Symbol: "AAPL", Open: close, High: high, Low: low, Close: close, Start: start, End: start.Add(time.Minute)}
+}
+
+func TestCandleBreakoutEntersOnBreakout(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	executor, err := NewCandleBreakoutStrategy(runner, map[string]interface{}{"symbol": "AAPL"})
+	if err != nil {
+		t.Fatalf("NewCandleBreakoutStrategy: %v", err)
+	}
+	strategy := executor.(*CandleBreakoutStrategy)
+
+	open := func() []*models.Trade {
+		t.Helper()
+		open, err := trades.GetOpenTrades()
+		if err != nil {
+			t.Fatalf("GetOpenTrades: %v", err)
+		}
+		return open
+	}
+
+	for _, candle := range []*models.Candle{
+		minuteCandle(0, 99, 101, 100),   // Reference
+		minuteCandle(1, 99.5, 101, 101), // Closes at the high, not above
+	} {
+		if err := strategy.ProcessCandle(candle); err != nil {
+			t.Fatalf("ProcessCandle: %v", err)
+		}
+	}
+	if n := len(open()); n != 0 {
+		t.Fatalf("%d trades opened without a breakout, want 0", n)
+	}
+
+	// Close above the previous high: buy at the close
+	if err := strategy.ProcessCandle(minuteCandle(2, 100, 103, 102.5)); err != nil {
+		t.Fatalf("ProcessCandle: %v", err)
+	}
+	if got := open(); len(got) != 1 || got[0].EntryPrice != 102.5 {
+		t.Fatalf("open trades %v after a breakout, want one bought at 102.5", got)
+	}
+
+	// Close below the previous low: exit
+	if err := strategy.ProcessCandle(minuteCandle(3, 98, 103, 99)); err != nil {
+		t.Fatalf("ProcessCandle: %v", err)
+	}
+	if n := len(open()); n != 0 {
+		t.Errorf("%d trades open after a breakdown, want 0", n)
+	}
+
+	if err := strategy.ProcessTick(&models.Tick{Symbol: "AAPL", Price: 100}); err == nil {
+		t.Error("candle strategy accepted a raw tick")
+	}
+}
+
+func TestRunnerFeedsCandlesFromTicks(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+
+	// Three one-minute candles closing at 100, 101 and 103, and a tick
+	// opening a fourth to complete the third
+	start := time.Date(2025, 1, 23, 9, 0, 0, 0, time.UTC)
+	var ticks []*models.Tick
+	for minute, prices := range [][]float64{{100, 101, 99, 100}, {100, 101.5, 101}, {102, 103}, {103}} {
+		for i, price := range prices {
+			at := start.Add(time.Duration(minute)*time.Minute + time.Duration(i)*time.Second)
+			ticks = append(ticks, &models.Tick{Symbol: "AAPL", Price: price, Timestamp: at})
+		}
+	}
+
+	instance := models.NewStrategy("candle_breakout", map[string]interface{}{"symbol": "AAPL"})
+	if err := runner.Replay(instance, ticks, func(err error) { t.Errorf("executor error: %v", err) }); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	// Only the third candle closes above its predecessor's high
+	open, _ := trades.GetOpenTrades()
+	if len(open) != 1 || open[0].EntryPrice != 103 {
+		t.Errorf("open trades %v, want one bought at the third candle's close of 103", open)
+	}
+}
@@ -0,0 +1,77 @@
+package strategy
+
+import (
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Candle Aggregation Flow and Structure:
+
+1. Memory Structure:
+   CandleAggregator
+   ├── interval: time.Duration        // Candle length
+   └── open: map[string]*Candle       // symbol -> candle being built
+
+2. Operation Flow:
+   a. Tick arrives for a symbol
+   b. Bucket = tick timestamp truncated to the interval
+   c. Same bucket as the open candle → update high/low/close/volume
+   d. Later bucket → the open candle is complete: return it and start a
+      new candle from this tick
+
+3. Notes:
+   - A candle is only emitted when the first tick of a later interval
+     arrives, so the last candle of a quiet symbol waits for its next tick
+   - Ticks older than the open candle's bucket are ignored
+   - Not thread-safe; each runner goroutine owns its aggregator
+*/
+
+// CandleAggregator builds per-symbol candles of a fixed interval from ticks
+type CandleAggregator struct {
+	interval time.Duration
+	open     map[string]*models.Candle
+}
+
+// NewCandleAggregator creates an aggregator emitting candles of the given interval
+func NewCandleAggregator(interval time.Duration) *CandleAggregator {
+	return &CandleAggregator{
+		interval: interval,
+		open:     make(map[string]*models.Candle),
+	}
+}
+
+// Add folds a tick into its symbol's candle
+// Returns the completed candle when the tick starts a new interval, else nil
+func (a *CandleAggregator) Add(tick *models.Tick) *models.Candle {
+	start := tick.Timestamp.Truncate(a.interval)
+
+	current, ok := a.open[tick.Symbol]
+	if ok && start.Before(current.Start) {
+		return nil // Late tick for an already completed interval
+	}
+	if ok && start.Equal(current.Start) {
+		if tick.Price > current.High {
+			current.High = tick.Price
+		}
+		if tick.Price < current.Low {
+			current.Low = tick.Price
+		}
+		current.Close = tick.Price
+		current.Volume += tick.Volume
+		return nil
+	}
+
+	a.open[tick.Symbol] = &models.Candle{
+		Symbol: tick.Symbol,
+		Open:   tick.Price,
+		High:   tick.Price,
+		Low:    tick.Price,
+		Close:  tick.Price,
+		Volume: tick.Volume,
+		Start:  start,
+		End:    start.Add(a.interval),
+	}
+	return current // nil for a symbol's first tick
+}
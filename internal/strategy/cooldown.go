@@ -0,0 +1,107 @@
+package strategy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Reversal Cooldown Flow and Structure:
+
+1. Purpose:
+   Prevents whipsaw in direction-capable strategies: after a position is
+   closed, an opposite-direction position on the same symbol may not be
+   opened until reversal_cooldown_seconds have passed. Same-direction
+   re-entries are not affected.
+   Used by pairs, which can go either way, and by the long-only
+   strategies (repeat, rsi, martingale, candle_breakout). These never open
+   a short, so the exit sell is their reversal: they record the close as
+   a sell, and a buy back within the cooldown is suppressed.
+
+2. Memory Structure:
+   ReversalCooldown
+   ├── cooldown: time.Duration          // 0 disables the guard
+   ├── lastClose: map[string]closeInfo  // symbol -> side and time of last close
+   └── mu: sync.Mutex                  // Protects lastClose
+
+3. Time Source:
+   Uses tick timestamps rather than wall-clock time, so the guard behaves
+   the same in live trading and replays.
+
+4. Example Usage:
+   cooldown, err := NewReversalCooldownFromParams(params)
+   if cooldown.Allows(tick.Symbol, models.SideSell, tick.Timestamp) {
+       // open short
+   }
+   cooldown.RecordClose(tick.Symbol, models.SideBuy, tick.Timestamp)
+*/
+
+// reversalCooldownParameter describes the shared cooldown parameter for strategy metadata
+var reversalCooldownParameter = models.ParameterInfo{
+	Name:        "reversal_cooldown_seconds",
+	Type:        "number",
+	Required:    false,
+	Description: "Seconds after closing a position before an opposite-direction position on the same symbol may open (default 0 = no cooldown)",
+}
+
+// closeInfo records the last closed position on a symbol
+type closeInfo struct {
+	side models.Side
+	at   time.Time
+}
+
+// ReversalCooldown suppresses opposite-direction entries shortly after a close
+type ReversalCooldown struct {
+	cooldown  time.Duration
+	lastClose map[string]closeInfo
+	mu        sync.Mutex
+}
+
+// NewReversalCooldown creates a cooldown guard; a zero duration disables it
+func NewReversalCooldown(cooldown time.Duration) *ReversalCooldown {
+	return &ReversalCooldown{
+		cooldown:  cooldown,
+		lastClose: make(map[string]closeInfo),
+	}
+}
+
+// NewReversalCooldownFromParams creates a cooldown guard from the optional
+// reversal_cooldown_seconds strategy parameter
+func NewReversalCooldownFromParams(params map[string]interface{}) (*ReversalCooldown, error) {
+	raw, ok := params[reversalCooldownParameter.Name]
+	if !ok || raw == nil {
+		return NewReversalCooldown(0), nil
+	}
+
+	seconds, ok := raw.(float64)
+	if !ok || seconds < 0 {
+		return nil, fmt.Errorf("invalid %s parameter: must be a non-negative number", reversalCooldownParameter.Name)
+	}
+	return NewReversalCooldown(time.Duration(seconds * float64(time.Second))), nil
+}
+
+// RecordClose records that a position with the given side was closed at the given time
+func (c *ReversalCooldown) RecordClose(symbol string, side models.Side, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastClose[symbol] = closeInfo{side: side, at: at}
+}
+
+// Allows reports whether a position with the given side may be opened at the given time
+func (c *ReversalCooldown) Allows(symbol string, side models.Side, at time.Time) bool {
+	if c.cooldown <= 0 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, exists := c.lastClose[symbol]
+	if !exists || last.side == side {
+		return true
+	}
+	return at.Sub(last.at) >= c.cooldown
+}
@@ -0,0 +1,71 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestReversalCooldownSuppressesImmediateReversal(t *testing.T) {
+	cooldown, err := NewReversalCooldownFromParams(map[string]interface{}{"reversal_cooldown_seconds": 60.0})
+	if err != nil {
+		t.Fatalf("NewReversalCooldownFromParams: %v", err)
+	}
+
+	closed := time.Now()
+	cooldown.RecordClose("AAPL", models.SideBuy, closed)
+	if cooldown.Allows("AAPL", models.SideSell, closed.Add(time.Second)) {
+		t.Error("short allowed one second after closing a long")
+	}
+	if !cooldown.Allows("AAPL", models.SideBuy, closed.Add(time.Second)) {
+		t.Error("same-direction re-entry suppressed")
+	}
+	if !cooldown.Allows("MSFT", models.SideSell, closed.Add(time.Second)) {
+		t.Error("other symbol suppressed")
+	}
+	if !cooldown.Allows("AAPL", models.SideSell, closed.Add(time.Minute)) {
+		t.Error("short suppressed once the cooldown passed")
+	}
+}
+
+func TestLongOnlyStrategyWaitsOutCooldownBeforeBuyingBack(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	executor, err := NewRepeatStrategy(runner, map[string]interface{}{
+		"symbol":                    "AAPL",
+		"exit_price":                110.0,
+		"reversal_cooldown_seconds": 60.0,
+	})
+	if err != nil {
+		t.Fatalf("NewRepeatStrategy: %v", err)
+	}
+
+	start := time.Now()
+	tick := func(price float64, after time.Duration) {
+		t.Helper()
+		if err := executor.ProcessTick(&models.Tick{Symbol: "AAPL", Price: price, Timestamp: start.Add(after)}); err != nil {
+			t.Fatalf("ProcessTick: %v", err)
+		}
+	}
+	openTrades := func() int {
+		t.Helper()
+		open, err := trades.GetOpenTrades()
+		if err != nil {
+			t.Fatalf("GetOpenTrades: %v", err)
+		}
+		return len(open)
+	}
+
+	tick(100, 0)             // Buy
+	tick(110, time.Second)   // Exit
+	tick(100, 2*time.Second) // Buy back suppressed
+	if n := openTrades(); n != 0 {
+		t.Fatalf("%d open trades within the cooldown, want 0", n)
+	}
+	tick(100, 61*time.Second) // Cooldown passed
+	if n := openTrades(); n != 1 {
+		t.Errorf("%d open trades after the cooldown, want 1", n)
+	}
+}
@@ -0,0 +1,75 @@
+package strategy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Strategy Error Log:
+
+   Fixed-capacity ring buffer of a strategy's most recent executor errors.
+   When full, the oldest error is overwritten and the overflow counter is
+   incremented, so readers know diagnostic info was lost during an error storm.
+
+   capacity 3, errors e1..e5 → entries [e3 e4 e5], overflow 2
+*/
+
+// DefaultErrorBufferSize is the number of errors retained per strategy
+const DefaultErrorBufferSize = 100
+
+// DefaultErrorRetention is how long a stopped strategy's errors are kept
+const DefaultErrorRetention = 24 * time.Hour
+
+// ErrorLog retains the most recent errors of a strategy
+type ErrorLog struct {
+	entries  []models.StrategyErrorEntry // Ring buffer storage
+	next     int                         // Index of the next write
+	count    int                         // Number of retained entries
+	overflow int                         // Errors dropped to make room
+	mu       sync.Mutex
+}
+
+// NewErrorLog creates an error log retaining up to capacity errors
+func NewErrorLog(capacity int) *ErrorLog {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &ErrorLog{
+		entries: make([]models.StrategyErrorEntry, capacity),
+	}
+}
+
+// Add records an error, dropping the oldest one when the log is full
+func (l *ErrorLog) Add(err error, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[l.next] = models.StrategyErrorEntry{Time: at, Message: err.Error()}
+	l.next = (l.next + 1) % len(l.entries)
+	if l.count < len(l.entries) {
+		l.count++
+	} else {
+		l.overflow++
+	}
+}
+
+// Stats returns the retained errors, oldest first, and the overflow count
+func (l *ErrorLog) Stats(strategyID string) models.StrategyErrorStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	errs := make([]models.StrategyErrorEntry, 0, l.count)
+	start := (l.next - l.count + len(l.entries)) % len(l.entries)
+	for i := 0; i < l.count; i++ {
+		errs = append(errs, l.entries[(start+i)%len(l.entries)])
+	}
+	return models.StrategyErrorStats{
+		StrategyID: strategyID,
+		Capacity:   len(l.entries),
+		Overflow:   l.overflow,
+		Errors:     errs,
+	}
+}
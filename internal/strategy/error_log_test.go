@@ -0,0 +1,32 @@
+package strategy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrorLogKeepsMostRecentAndCountsOverflow(t *testing.T) {
+	log := NewErrorLog(3)
+	start := time.Now()
+	for i := 1; i <= 5; i++ {
+		log.Add(fmt.Errorf("e%d", i), start.Add(time.Duration(i)*time.Second))
+
+		stats := log.Stats("strat-1")
+		if wantOverflow := i - 3; wantOverflow > 0 && stats.Overflow != wantOverflow {
+			t.Errorf("after %d errors overflow is %d, want %d", i, stats.Overflow, wantOverflow)
+		}
+	}
+
+	stats := log.Stats("strat-1")
+	if stats.StrategyID != "strat-1" || stats.Capacity != 3 || stats.Overflow != 2 {
+		t.Errorf("stats %+v, want strat-1 with capacity 3 and overflow 2", stats)
+	}
+	var messages []string
+	for _, entry := range stats.Errors {
+		messages = append(messages, entry.Message)
+	}
+	if fmt.Sprint(messages) != "[e3 e4 e5]" {
+		t.Errorf("retained %v, want the three most recent, oldest first", messages)
+	}
+}
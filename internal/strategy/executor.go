@@ -1,6 +1,11 @@
 package strategy
 
-import "github.com/aumbhatt/auto_trade/internal/models"
+import (
+	"fmt"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
 
 /*
 Strategy Executor Flow and Structure:
@@ -23,6 +28,26 @@ Strategy Executor Flow and Structure:
 4. Example Usage:
    executor := NewRepeatStrategy(runner, params)
    err := executor.ProcessTick(tick)
+
+5. Symbol Declaration (optional):
+   Executors implementing SymbolDeclarer only receive ticks for the
+   symbols they declare; multi-symbol strategies list every symbol.
+
+6. Warmup (optional):
+   Executors implementing Warmable can have their indicator state
+   preloaded from retained tick history when started with "preload": true.
+   Warmup must update state only; it never trades.
+
+7. Candles (optional):
+   Executors implementing CandleStrategyExecutor receive completed candles
+   instead of ticks: the runner aggregates the strategy's tick feed into
+   candles of CandleInterval and calls ProcessCandle; ProcessTick is not
+   called for them.
+
+8. Runtime State (optional):
+   Executors implementing Snapshotter report their live state (e.g.
+   martingale's position count and size); the runner exposes it per
+   running strategy and the active_strategies feed includes it.
 */
 
 // StrategyExecutor defines the interface for strategy implementations
@@ -31,3 +56,83 @@ type StrategyExecutor interface {
 	// Returns error if the tick processing fails
 	ProcessTick(tick *models.Tick) error
 }
+
+// SymbolDeclarer is implemented by executors that trade a fixed set of symbols
+// The runner only forwards ticks for the declared symbols
+type SymbolDeclarer interface {
+	// Symbols returns the symbols the executor needs ticks for
+	Symbols() []string
+}
+
+// CandleStrategyExecutor is implemented by executors that trade on candles
+type CandleStrategyExecutor interface {
+	StrategyExecutor
+	// CandleInterval returns the length of the candles the executor consumes
+	CandleInterval() time.Duration
+	// ProcessCandle processes a completed candle
+	ProcessCandle(candle *models.Candle) error
+}
+
+// Warmable is implemented by executors whose indicators can be preloaded
+type Warmable interface {
+	// Warmup feeds a historical tick into the executor's state without trading
+	Warmup(tick *models.Tick)
+}
+
+// Snapshotter is implemented by executors that report their runtime state
+type Snapshotter interface {
+	// Snapshot returns a copy of the executor's current state; it must be
+	// safe to call while ticks are being processed
+	Snapshot() map[string]interface{}
+}
+
+// TickHistory provides retained recent ticks for warmup preloads
+type TickHistory interface {
+	// RecentTicks returns retained ticks for the given symbols, oldest first
+	RecentTicks(symbols []string) []*models.Tick
+}
+
+// preloadParameter describes the shared warmup parameter for strategy metadata
+var preloadParameter = models.ParameterInfo{
+	Name:        "preload",
+	Type:        "boolean",
+	Required:    false,
+	Description: "Preload indicators from recent tick history on start so trading can begin immediately (default false)",
+}
+
+// flattenOnStopParameter describes the shared stop behaviour parameter for strategy metadata
+var flattenOnStopParameter = models.ParameterInfo{
+	Name:        models.ParamFlattenOnStop,
+	Type:        "boolean",
+	Required:    false,
+	Description: "Close the strategy's open trades at the current price when it is stopped (default false)",
+}
+
+// dryRunParameter describes the shared paper trading parameter for strategy metadata
+var dryRunParameter = models.ParameterInfo{
+	Name:        models.ParamDryRun,
+	Type:        "boolean",
+	Required:    false,
+	Description: "Paper trade: record trades on the paper_trades feed instead of the real trade store (default false)",
+}
+
+// quantityParameter describes the shared position size parameter for strategy metadata
+var quantityParameter = models.ParameterInfo{
+	Name:        "quantity",
+	Type:        "number",
+	Required:    false,
+	Description: "Units per trade (default 1)",
+}
+
+// quantityFromParams returns the optional "quantity" parameter, defaulting to models.DefaultQuantity
+func quantityFromParams(params map[string]interface{}) (float64, error) {
+	raw, ok := params[quantityParameter.Name]
+	if !ok || raw == nil {
+		return models.DefaultQuantity, nil
+	}
+	quantity, ok := raw.(float64)
+	if !ok || quantity <= 0 {
+		return 0, fmt.Errorf("invalid quantity parameter: must be a positive number")
+	}
+	return quantity, nil
+}
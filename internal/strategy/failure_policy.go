@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+Strategy Failure Policy:
+
+   A strategy is only auto-stopped when its errors exceed a rate, so a
+   single transient error is logged and tolerated.
+
+   maxErrors 5, window 1m:
+   errors at 0s, 40s, 90s, 150s  → never more than 5 within 1m → keeps running
+   6 errors between 10s and 12s  → 6th error exceeds the rate → stopped
+
+   maxErrors 0 disables rate-based failure (only critical errors stop).
+*/
+
+// ErrorRateTracker counts a strategy's errors over a sliding window
+type ErrorRateTracker struct {
+	maxErrors int
+	window    time.Duration
+	times     []time.Time // Error times within the window, oldest first
+	mu        sync.Mutex
+}
+
+// NewErrorRateTracker creates a tracker failing after more than maxErrors within window
+func NewErrorRateTracker(maxErrors int, window time.Duration) *ErrorRateTracker {
+	return &ErrorRateTracker{
+		maxErrors: maxErrors,
+		window:    window,
+	}
+}
+
+// Record notes an error at the given time and reports whether the error
+// rate is now exceeded
+func (t *ErrorRateTracker) Record(at time.Time) bool {
+	if t == nil || t.maxErrors <= 0 || t.window <= 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Drop errors that fell out of the window
+	cutoff := at.Add(-t.window)
+	keep := 0
+	for keep < len(t.times) && !t.times[keep].After(cutoff) {
+		keep++
+	}
+	t.times = append(t.times[keep:], at)
+
+	return len(t.times) > t.maxErrors
+}
@@ -2,9 +2,10 @@ package strategy
 
 import (
 	"fmt"
-	"log"
+	"math"
 	"sync"
 
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/aumbhatt/auto_trade/internal/models"
 )
 
@@ -17,10 +18,12 @@ Martingale Strategy Flow and Structure:
    ├── symbol: string             // Trading symbol
    ├── basePosition: float64      // Initial position size
    ├── takeProfit: float64        // Profit target percentage
+   ├── stopLoss: float64          // Loss exit percentage
    ├── maxPositions: int          // Max position increases
    ├── currentTrade: *models.Trade // Current position
    ├── positionCount: int         // Number of positions taken
    ├── currentSize: float64       // Current position size
+   ├── cooldown: *ReversalCooldown // Optional whipsaw guard (see cooldown.go)
    └── mu: sync.Mutex            // Protects shared state
 
 2. Operation Flow:
@@ -33,11 +36,14 @@ Martingale Strategy Flow and Structure:
 
    b. Has Position:
       - Validate current trade state
-      - Calculate take profit target
+      - Calculate take profit and stop loss targets
       - Handle position exit:
-        * Take profit
-        * Stop loss
+        * Take profit: price >= entry * (1 + take_profit/100)
+        * Stop loss:   price <= entry * (1 - stop_loss/100)
         * Position sizing for next trade
+      - Prices between the two targets hold the position
+      - With reversal_cooldown_seconds, the next entry waits until the
+        cooldown has passed since the exit
 
 3. Error Handling:
    - Invalid tick data
@@ -45,18 +51,33 @@ Martingale Strategy Flow and Structure:
    - Trade execution failures
    - Invalid trade state
    - Position size limits
+
+4. Runtime State (Snapshot, shown in the active_strategies feed):
+   position_count, current_size, in_position and, while in a position,
+   trade_id
+   The state lives in the executor, which the runner keeps for the whole
+   running job: a doubled size survives pauses and resumes, but a stopped
+   strategy, or one restored after a server restart, starts over from
+   base_position with no current trade.
 */
 
+// DefaultMaxMartingalePositions caps the max_positions parameter unless the
+// runner sets another cap (SetMaxMartingalePositions); position size doubles
+// on every loss, so the largest position is base_position * 2^max_positions
+const DefaultMaxMartingalePositions = 20
+
 // MartingaleStrategy implements the Martingale trading strategy
 type MartingaleStrategy struct {
 	runner       *DefaultRunner
 	symbol       string
 	basePosition float64
 	takeProfit   float64
+	stopLoss     float64
 	maxPositions int
 	currentTrade *models.Trade
 	positionCount int
 	currentSize  float64
+	cooldown     *ReversalCooldown
 	mu           sync.Mutex
 }
 
@@ -80,23 +101,67 @@ func NewMartingaleStrategy(runner *DefaultRunner, params map[string]interface{})
 		return nil, fmt.Errorf("invalid or missing take_profit parameter")
 	}
 
+	// Extract and validate stop_loss
+	stopLoss, ok := params["stop_loss"].(float64)
+	if !ok || stopLoss <= 0 || stopLoss >= 100 {
+		return nil, fmt.Errorf("invalid or missing stop_loss parameter: must be between 0 and 100")
+	}
+
 	// Extract and validate max_positions
 	maxPositions, ok := params["max_positions"].(float64)
 	if !ok || maxPositions < 1 {
 		return nil, fmt.Errorf("invalid or missing max_positions parameter")
 	}
+	if maxPositions != math.Trunc(maxPositions) {
+		return nil, fmt.Errorf("invalid max_positions parameter: must be a whole number, got %v", maxPositions)
+	}
+	limit := runner.maxMartingalePositions
+	if limit <= 0 {
+		limit = DefaultMaxMartingalePositions
+	}
+	if maxPositions > float64(limit) {
+		return nil, fmt.Errorf("invalid max_positions parameter: %v exceeds the maximum of %d", maxPositions, limit)
+	}
+
+	// Reject combinations whose largest position cannot be represented
+	if math.IsInf(basePosition*math.Pow(2, maxPositions), 0) {
+		return nil, fmt.Errorf("invalid base_position parameter: %v doubled %v times overflows", basePosition, maxPositions)
+	}
+
+	cooldown, err := NewReversalCooldownFromParams(params)
+	if err != nil {
+		return nil, err
+	}
 
 	return &MartingaleStrategy{
 		runner:       runner,
 		symbol:       symbol,
 		basePosition: basePosition,
 		takeProfit:   takeProfit,
+		stopLoss:     stopLoss,
 		maxPositions: int(maxPositions),
 		currentSize:  basePosition,
 		positionCount: 0,
+		cooldown:     cooldown,
 	}, nil
 }
 
+// Snapshot implements Snapshotter
+func (s *MartingaleStrategy) Snapshot() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := map[string]interface{}{
+		"position_count": s.positionCount,
+		"current_size":   s.currentSize,
+		"in_position":    s.currentTrade != nil,
+	}
+	if s.currentTrade != nil {
+		state["trade_id"] = s.currentTrade.ID
+	}
+	return state
+}
+
 // validateTick checks if the tick data is valid
 func (s *MartingaleStrategy) validateTick(tick *models.Tick) error {
 	if tick == nil {
@@ -125,6 +190,11 @@ func (s *MartingaleStrategy) validateCurrentTrade() error {
 	return nil
 }
 
+// maxPositionSize returns the largest position size the strategy may reach
+func (s *MartingaleStrategy) maxPositionSize() float64 {
+	return s.basePosition * math.Pow(2, float64(s.maxPositions))
+}
+
 // resetPosition resets the strategy state
 func (s *MartingaleStrategy) resetPosition() {
 	s.currentTrade = nil
@@ -135,11 +205,8 @@ func (s *MartingaleStrategy) resetPosition() {
 // enterPosition attempts to enter a new position
 func (s *MartingaleStrategy) enterPosition(tick *models.Tick) error {
 	// Safety check for position size
-	maxSize := s.basePosition
-	for i := 0; i < s.maxPositions; i++ {
-		maxSize *= 2
-	}
-	if s.currentSize > maxSize {
+	maxSize := s.maxPositionSize()
+	if math.IsInf(s.currentSize, 0) || math.IsNaN(s.currentSize) || s.currentSize > maxSize {
 		return fmt.Errorf("position size %.2f exceeds maximum allowed (max: %.2f)", s.currentSize, maxSize)
 	}
 
@@ -150,52 +217,52 @@ func (s *MartingaleStrategy) enterPosition(tick *models.Tick) error {
 	}
 
 	// Execute buy
-	trade, err := s.runner.executeBuy(s.symbol, tick.Price)
+	trade, err := s.runner.executeBuy(s.symbol, tick.Price, quantity)
 	if err != nil {
 		return fmt.Errorf("failed to execute buy: %w", err)
 	}
 
 	s.currentTrade = trade
 	s.positionCount++
-	log.Printf("Opened position %d: Size=%.2f, Quantity=%.4f, Price=%.2f", 
+	logging.Debugf("Opened position %d: Size=%.2f, Quantity=%.4f, Price=%.2f", 
 		s.positionCount, s.currentSize, quantity, tick.Price)
 	return nil
 }
 
 // handleTakeProfit handles take profit exit
 func (s *MartingaleStrategy) handleTakeProfit(tick *models.Tick) error {
-	if _, err := s.runner.executeSell(s.currentTrade.ID); err != nil {
+	if _, err := s.runner.executeSell(s.currentTrade.ID, tick.Price); err != nil {
 		return fmt.Errorf("failed to execute take profit sell: %w", err)
 	}
+	s.cooldown.RecordClose(s.symbol, models.SideSell, tick.Timestamp) // Long-only: the exit is the reversal
 
 	// Calculate profit
-	quantity := s.currentSize / s.currentTrade.EntryPrice
-	profit := (tick.Price - s.currentTrade.EntryPrice) * quantity
+	profit := (tick.Price - s.currentTrade.EntryPrice) * s.currentTrade.Quantity
 	
 	// Reset for next cycle
 	s.resetPosition()
-	log.Printf("Take profit: Profit=%.2f", profit)
+	logging.Debugf("Take profit: Profit=%.2f", profit)
 	return nil
 }
 
 // handleLoss handles loss exit
 func (s *MartingaleStrategy) handleLoss(tick *models.Tick) error {
-	if _, err := s.runner.executeSell(s.currentTrade.ID); err != nil {
+	if _, err := s.runner.executeSell(s.currentTrade.ID, tick.Price); err != nil {
 		return fmt.Errorf("failed to execute loss sell: %w", err)
 	}
+	s.cooldown.RecordClose(s.symbol, models.SideSell, tick.Timestamp) // Long-only: the exit is the reversal
 
 	// Calculate loss
-	quantity := s.currentSize / s.currentTrade.EntryPrice
-	loss := (tick.Price - s.currentTrade.EntryPrice) * quantity
+	loss := (tick.Price - s.currentTrade.EntryPrice) * s.currentTrade.Quantity
 
 	// Prepare next position size
-	if s.positionCount < s.maxPositions {
+	if s.positionCount < s.maxPositions && s.currentSize*2 <= s.maxPositionSize() {
 		s.currentSize *= 2
-		log.Printf("Loss=%.2f, Doubling position size to %.2f", loss, s.currentSize)
+		logging.Debugf("Loss=%.2f, Doubling position size to %.2f", loss, s.currentSize)
 	} else {
 		s.currentSize = s.basePosition
 		s.positionCount = 0
-		log.Printf("Loss=%.2f, Max positions reached, resetting to base position %.2f", 
+		logging.Debugf("Loss=%.2f, Max positions reached, resetting to base position %.2f", 
 			loss, s.basePosition)
 	}
 
@@ -215,27 +282,31 @@ func (s *MartingaleStrategy) ProcessTick(tick *models.Tick) error {
 
 	// Enter new position if none exists
 	if s.currentTrade == nil {
+		if !s.cooldown.Allows(s.symbol, models.SideBuy, tick.Timestamp) {
+			return nil
+		}
 		return s.enterPosition(tick)
 	}
 
 	// Validate current trade
 	if err := s.validateCurrentTrade(); err != nil {
-		log.Printf("Invalid trade state, resetting: %v", err)
+		logging.Warnf("Invalid trade state, resetting: %v", err)
 		s.resetPosition()
 		return nil
 	}
 
-	// Calculate take profit target
+	// Calculate take profit and stop loss targets
 	entryPrice := s.currentTrade.EntryPrice
 	targetPrice := entryPrice * (1 + s.takeProfit/100)
+	stopPrice := entryPrice * (1 - s.stopLoss/100)
 
 	// Check for take profit
 	if tick.Price >= targetPrice {
 		return s.handleTakeProfit(tick)
 	}
 
-	// Check for loss exit
-	if tick.Price < entryPrice {
+	// Check for stop loss
+	if tick.Price <= stopPrice {
 		return s.handleLoss(tick)
 	}
 
@@ -264,19 +335,28 @@ var martingaleMetadata = models.StrategyMetadata{
 			Required:    true,
 			Description: "Price increase percentage for taking profit (e.g. 1.0 for 1%)",
 		},
+		{
+			Name:        "stop_loss",
+			Type:        "number",
+			Required:    true,
+			Description: "Price decrease percentage for the loss exit (e.g. 1.0 for 1%, below 100)",
+		},
 		{
 			Name:        "max_positions",
 			Type:        "number",
 			Required:    true,
-			Description: "Maximum number of increasing positions allowed",
+			Description: "Maximum number of increasing positions allowed (whole number, capped by server config)",
 		},
+		reversalCooldownParameter,
+		flattenOnStopParameter,
+		dryRunParameter,
 	},
 	Flow: []string{
 		"1. Start with base_position size",
 		"2. Enter long position at market price",
 		"3. Set take profit target at entry_price * (1 + take_profit/100)",
 		"4. If target hit: Take profit and reset position size to base_position",
-		"5. If price drops: Exit at loss",
+		"5. If price falls to entry_price * (1 - stop_loss/100): Exit at loss",
 		"6. If under max_positions: Double position size and enter new position",
 		"7. If at max_positions: Reset position size to base_position",
 		"8. Repeat from step 1",
@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestMartingalePositionCapComesFromRunner(t *testing.T) {
+	params := martingaleParams()
+	params["max_positions"] = 10.0
+	instance := models.NewStrategy("martingale", params)
+
+	// create builds the executor the way Start and Replay do
+	create := func(runner *DefaultRunner) error {
+		_, err := GetDefaultRegistry().Create("martingale", runner.scopedRunner(instance, runner.tradeStore), params)
+		return err
+	}
+
+	defaults := NewDefaultRunner(memory.NewInMemoryStrategyStore(), memory.NewInMemoryTradeStore())
+	if err := create(defaults); err != nil {
+		t.Fatalf("10 positions under the default cap of %d: %v", DefaultMaxMartingalePositions, err)
+	}
+
+	capped := NewDefaultRunner(memory.NewInMemoryStrategyStore(), memory.NewInMemoryTradeStore())
+	capped.SetMaxMartingalePositions(5)
+	if err := create(capped); err == nil || !strings.Contains(err.Error(), "exceeds the maximum of 5") {
+		t.Errorf("10 positions under a cap of 5 returned %v, want an error naming the cap", err)
+	}
+
+	// Other runners keep their own cap
+	if err := create(defaults); err != nil {
+		t.Errorf("default runner affected by another runner's cap: %v", err)
+	}
+}
+
+func TestMartingaleStopLoss(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	executor, err := NewMartingaleStrategy(runner, martingaleParams()) // 5% take profit and stop loss
+	if err != nil {
+		t.Fatalf("NewMartingaleStrategy: %v", err)
+	}
+	martingale := executor.(*MartingaleStrategy)
+
+	// tick processes a price and returns the position state afterwards
+	tick := func(price float64) (inPosition bool, size float64, closed int) {
+		t.Helper()
+		if err := martingale.ProcessTick(&models.Tick{Symbol: "AAPL", Price: price, Timestamp: time.Now()}); err != nil {
+			t.Fatalf("ProcessTick(%v): %v", price, err)
+		}
+		state := martingale.Snapshot()
+		history, _ := trades.GetTradeHistory()
+		return state["in_position"].(bool), state["current_size"].(float64), len(history)
+	}
+
+	tick(100) // Enter at 100: stop at 95, target at 105
+	if in, size, closed := tick(97); !in || size != 1 || closed != 0 {
+		t.Fatalf("between entry and stop: in position %v, size %v, %d closed; want no action", in, size, closed)
+	}
+	if in, size, closed := tick(95); in || size != 2 || closed != 1 {
+		t.Fatalf("at the stop: in position %v, size %v, %d closed; want a sell and size doubled to 2", in, size, closed)
+	}
+
+	tick(100) // Re-enter at double size
+	if in, size, closed := tick(106); in || size != 1 || closed != 2 {
+		t.Errorf("above take profit: in position %v, size %v, %d closed; want a sell and size reset to 1", in, size, closed)
+	}
+
+	for _, stopLoss := range []interface{}{nil, 0.0, -5.0, 100.0} {
+		params := martingaleParams()
+		if stopLoss == nil {
+			delete(params, "stop_loss")
+		} else {
+			params["stop_loss"] = stopLoss
+		}
+		if _, err := NewMartingaleStrategy(runner, params); err == nil {
+			t.Errorf("stop_loss %v accepted", stopLoss)
+		}
+	}
+}
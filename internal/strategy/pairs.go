@@ -0,0 +1,374 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+Pairs Strategy Flow and Structure:
+
+1. Memory Structure:
+   PairsStrategy
+   ├── runner: *DefaultRunner        // For executing trades
+   ├── symbolA, symbolB: string      // The two legs of the pair
+   ├── window: int                  // Rolling window length (spread samples)
+   ├── entryZ: float64              // |z| at which to open a spread position
+   ├── exitZ: float64               // |z| at which to close it
+   ├── quantity: float64            // Units per leg
+   ├── priceA, priceB: float64      // Latest price of each leg
+   ├── spreads: []float64           // Rolling ln(priceA / priceB) samples
+   ├── legA, legB: *models.Trade    // Open legs (nil when flat; one set
+   │                                // while a failed close is retried)
+   ├── cooldown: *ReversalCooldown  // Optional whipsaw guard
+   └── mu: sync.Mutex              // Protects shared state
+
+2. Spread and Z-Score:
+   spread = ln(priceA / priceB)     // sampled whenever either leg ticks
+   z      = (spread - mean) / stddev over the rolling window
+
+3. Operation Flow:
+   a. Warmup:
+      Wait until both legs have a price and the window is full
+      With "preload": true, the window is filled from retained tick
+      history on start, so the strategy can trade on the first live tick
+
+   b. Flat:
+      z <= -entry_z → spread unusually low:  long A / short B
+      z >=  entry_z → spread unusually high: short A / long B
+
+   c. In Position:
+      |z| <= exit_z             → close both legs
+      z crossed to the opposite
+      entry threshold            → close both legs and reverse
+                                   (unless within reversal cooldown)
+      A leg whose close failed stays open and is closed again on the
+      next tick, before any new entry
+
+4. Parameters:
+   {
+       "symbol_a": "AAPL",
+       "symbol_b": "MSFT",
+       "window": 30,
+       "entry_z": 2.0,
+       "exit_z": 0.5,
+       "quantity": 10,                   // optional, units per leg (default 1)
+       "reversal_cooldown_seconds": 60,  // optional
+       "preload": true                   // optional
+   }
+*/
+
+// PairsStrategy trades the spread between two symbols using z-score thresholds
+type PairsStrategy struct {
+	runner   *DefaultRunner
+	symbolA  string
+	symbolB  string
+	window   int
+	entryZ   float64
+	exitZ    float64
+	quantity float64
+	priceA   float64
+	priceB   float64
+	spreads  []float64
+	legA     *models.Trade
+	legB     *models.Trade
+	cooldown *ReversalCooldown
+	mu       sync.Mutex
+}
+
+// NewPairsStrategy creates a new pairs strategy instance
+func NewPairsStrategy(runner *DefaultRunner, params map[string]interface{}) (StrategyExecutor, error) {
+	// Extract and validate symbols
+	symbolA, ok := params["symbol_a"].(string)
+	if !ok || symbolA == "" {
+		return nil, fmt.Errorf("invalid or missing symbol_a parameter")
+	}
+	symbolB, ok := params["symbol_b"].(string)
+	if !ok || symbolB == "" {
+		return nil, fmt.Errorf("invalid or missing symbol_b parameter")
+	}
+	if symbolA == symbolB {
+		return nil, fmt.Errorf("symbol_a and symbol_b must differ")
+	}
+
+	// Extract and validate window
+	window, ok := params["window"].(float64)
+	if !ok || window < 2 || window != math.Trunc(window) {
+		return nil, fmt.Errorf("invalid or missing window parameter: must be a whole number >= 2")
+	}
+
+	// Extract and validate thresholds
+	entryZ, ok := params["entry_z"].(float64)
+	if !ok || entryZ <= 0 {
+		return nil, fmt.Errorf("invalid or missing entry_z parameter")
+	}
+	exitZ, ok := params["exit_z"].(float64)
+	if !ok || exitZ < 0 || exitZ >= entryZ {
+		return nil, fmt.Errorf("invalid or missing exit_z parameter: must be >= 0 and below entry_z")
+	}
+
+	quantity, err := quantityFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cooldown, err := NewReversalCooldownFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PairsStrategy{
+		runner:   runner,
+		symbolA:  symbolA,
+		symbolB:  symbolB,
+		window:   int(window),
+		entryZ:   entryZ,
+		exitZ:    exitZ,
+		quantity: quantity,
+		spreads:  make([]float64, 0, int(window)),
+		cooldown: cooldown,
+	}, nil
+}
+
+// Symbols implements SymbolDeclarer; the strategy needs ticks for both legs
+func (s *PairsStrategy) Symbols() []string {
+	return []string{s.symbolA, s.symbolB}
+}
+
+// Warmup implements Warmable; it fills the spread window without trading
+func (s *PairsStrategy) Warmup(tick *models.Tick) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observe(tick)
+}
+
+// ProcessTick implements the StrategyExecutor interface
+func (s *PairsStrategy) ProcessTick(tick *models.Tick) error {
+	if tick == nil {
+		return fmt.Errorf("received nil tick")
+	}
+	if tick.Price <= 0 && (tick.Symbol == s.symbolA || tick.Symbol == s.symbolB) {
+		return fmt.Errorf("invalid tick price: %.2f", tick.Price)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.observe(tick)
+	if !ok {
+		return nil // Other symbol, missing leg or still warming up
+	}
+
+	// A leg left open by a failed close is closed before anything else
+	if (s.legA == nil) != (s.legB == nil) {
+		return s.close(tick, z)
+	}
+
+	// Flat: open when the spread is stretched
+	if s.legA == nil {
+		switch {
+		case z <= -s.entryZ:
+			return s.open(models.SideBuy, tick, z)
+		case z >= s.entryZ:
+			return s.open(models.SideSell, tick, z)
+		}
+		return nil
+	}
+
+	// In position: exit on reversion, reverse on an opposite stretch
+	sideA := s.legA.Side
+	if math.Abs(z) <= s.exitZ {
+		return s.close(tick, z)
+	}
+	if (sideA == models.SideBuy && z >= s.entryZ) || (sideA == models.SideSell && z <= -s.entryZ) {
+		if err := s.close(tick, z); err != nil {
+			return err
+		}
+		return s.open(sideA.Opposite(), tick, z)
+	}
+	return nil
+}
+
+// observe records a leg price and spread sample, returning the current z-score
+// Returns false until both legs are priced and the window is full
+// Caller must hold s.mu
+func (s *PairsStrategy) observe(tick *models.Tick) (float64, bool) {
+	if tick == nil || tick.Price <= 0 {
+		return 0, false
+	}
+	switch tick.Symbol {
+	case s.symbolA:
+		s.priceA = tick.Price
+	case s.symbolB:
+		s.priceB = tick.Price
+	default:
+		return 0, false
+	}
+	if s.priceA == 0 || s.priceB == 0 {
+		return 0, false // Wait for both legs
+	}
+
+	s.addSpread(math.Log(s.priceA / s.priceB))
+	return s.zScore()
+}
+
+// addSpread appends a spread sample, keeping only the rolling window
+func (s *PairsStrategy) addSpread(spread float64) {
+	if len(s.spreads) == s.window {
+		copy(s.spreads, s.spreads[1:])
+		s.spreads = s.spreads[:len(s.spreads)-1]
+	}
+	s.spreads = append(s.spreads, spread)
+}
+
+// zScore returns the z-score of the latest spread over a full window
+func (s *PairsStrategy) zScore() (float64, bool) {
+	if len(s.spreads) < s.window {
+		return 0, false
+	}
+
+	mean := 0.0
+	for _, v := range s.spreads {
+		mean += v
+	}
+	mean /= float64(len(s.spreads))
+
+	variance := 0.0
+	for _, v := range s.spreads {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / float64(len(s.spreads)))
+	if std == 0 {
+		return 0, false
+	}
+	return (s.spreads[len(s.spreads)-1] - mean) / std, true
+}
+
+// open enters the spread position with leg A on sideA and leg B opposite
+func (s *PairsStrategy) open(sideA models.Side, tick *models.Tick, z float64) error {
+	if !s.cooldown.Allows(s.pairKey(), sideA, tick.Timestamp) {
+		return nil // Suppress whipsaw reversal
+	}
+
+	legA, err := s.runner.executeOpen(s.symbolA, sideA, s.priceA, s.quantity)
+	if err != nil {
+		return fmt.Errorf("failed to open %s leg: %w", s.symbolA, err)
+	}
+	legB, err := s.runner.executeOpen(s.symbolB, sideA.Opposite(), s.priceB, s.quantity)
+	if err != nil {
+		// Unwind the first leg so the strategy is never left half-hedged
+		if _, closeErr := s.runner.executeSell(legA.ID, s.priceA); closeErr != nil {
+			logging.Errorf("Pairs: failed to unwind %s leg %s: %v", s.symbolA, legA.ID, closeErr)
+		}
+		return fmt.Errorf("failed to open %s leg: %w", s.symbolB, err)
+	}
+
+	s.legA, s.legB = legA, legB
+	logging.Debugf("Pairs opened: %s %s / %s %s, z=%.2f", sideA, s.symbolA, sideA.Opposite(), s.symbolB, z)
+	return nil
+}
+
+// close exits both legs of the spread position
+// Each leg is cleared only once its close succeeds; a leg that fails to
+// close stays set and is retried on the next tick, so the strategy never
+// reads as flat while a leg is still open
+func (s *PairsStrategy) close(tick *models.Tick, z float64) error {
+	sideA := s.sideA()
+	var errs []error
+	if s.legA != nil {
+		if _, err := s.runner.executeSell(s.legA.ID, s.priceA); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s leg: %w", s.symbolA, err))
+		} else {
+			s.legA = nil
+		}
+	}
+	if s.legB != nil {
+		if _, err := s.runner.executeSell(s.legB.ID, s.priceB); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s leg: %w", s.symbolB, err))
+		} else {
+			s.legB = nil
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	s.cooldown.RecordClose(s.pairKey(), sideA, tick.Timestamp)
+	logging.Debugf("Pairs closed: %s/%s, z=%.2f", s.symbolA, s.symbolB, z)
+	return nil
+}
+
+// sideA returns the side of leg A of the open position, derived from leg B
+// when only leg B is left
+func (s *PairsStrategy) sideA() models.Side {
+	if s.legA != nil {
+		return s.legA.Side
+	}
+	return s.legB.Side.Opposite()
+}
+
+// pairKey identifies the pair for the reversal cooldown
+func (s *PairsStrategy) pairKey() string {
+	return s.symbolA + "/" + s.symbolB
+}
+
+// Metadata for the pairs strategy
+var pairsMetadata = models.StrategyMetadata{
+	Name: "pairs",
+	Parameters: []models.ParameterInfo{
+		{
+			Name:        "symbol_a",
+			Type:        "string",
+			Required:    true,
+			Description: "First leg of the pair (e.g. AAPL)",
+		},
+		{
+			Name:        "symbol_b",
+			Type:        "string",
+			Required:    true,
+			Description: "Second leg of the pair (e.g. MSFT)",
+		},
+		{
+			Name:        "window",
+			Type:        "number",
+			Required:    true,
+			Description: "Number of spread samples in the rolling window (whole number >= 2)",
+		},
+		{
+			Name:        "entry_z",
+			Type:        "number",
+			Required:    true,
+			Description: "Z-score magnitude at which to open a spread position (e.g. 2.0)",
+		},
+		{
+			Name:        "exit_z",
+			Type:        "number",
+			Required:    true,
+			Description: "Z-score magnitude at which to close the spread position (below entry_z)",
+		},
+		quantityParameter,
+		reversalCooldownParameter,
+		preloadParameter,
+		flattenOnStopParameter,
+		dryRunParameter,
+	},
+	Flow: []string{
+		"1. Track spread = ln(price_a / price_b) over a rolling window",
+		"2. Wait until both symbols have prices and the window is full",
+		"3. If z-score <= -entry_z: Long symbol_a, short symbol_b",
+		"4. If z-score >= entry_z: Short symbol_a, long symbol_b",
+		"5. If |z-score| <= exit_z: Close both legs",
+		"6. If z-score crosses the opposite entry threshold: Close and reverse (after reversal cooldown)",
+		"7. Repeat from step 1",
+	},
+}
+
+// init registers the pairs strategy with the registry
+func init() {
+	defaultRegistry.Register("pairs", NewPairsStrategy, pairsMetadata)
+}
@@ -0,0 +1,81 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+// failingCloseStore fails closes of trades in failSymbol
+type failingCloseStore struct {
+	*memory.InMemoryTradeStore
+	failSymbol string
+}
+
+func (s *failingCloseStore) CloseTrade(id string, exitPrice float64, reason string) (*models.Trade, error) {
+	trade, err := s.GetTradeByID(id)
+	if err == nil && trade.Symbol == s.failSymbol {
+		return nil, errors.New("close rejected")
+	}
+	return s.InMemoryTradeStore.CloseTrade(id, exitPrice, reason)
+}
+
+func TestPairsRetriesLegThatFailedToClose(t *testing.T) {
+	trades := &failingCloseStore{InMemoryTradeStore: memory.NewInMemoryTradeStore()}
+	runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	executor, err := NewPairsStrategy(runner, map[string]interface{}{
+		"symbol_a": "AAPL",
+		"symbol_b": "MSFT",
+		"window":   2.0,
+		"entry_z":  0.9,
+		"exit_z":   0.5,
+	})
+	if err != nil {
+		t.Fatalf("NewPairsStrategy: %v", err)
+	}
+	s := executor.(*PairsStrategy)
+
+	at := time.Now()
+	tick := func(symbol string, price float64) error {
+		at = at.Add(time.Second)
+		return s.ProcessTick(&models.Tick{Symbol: symbol, Price: price, Timestamp: at})
+	}
+
+	// With a two-sample window z is always +-1: open short A / long B
+	for _, step := range []struct {
+		symbol string
+		price  float64
+	}{{"AAPL", 100}, {"MSFT", 100}, {"AAPL", 110}} {
+		if err := tick(step.symbol, step.price); err != nil {
+			t.Fatalf("ProcessTick: %v", err)
+		}
+	}
+	if s.legA == nil || s.legB == nil {
+		t.Fatal("pair did not open")
+	}
+
+	// The opposite stretch reverses, but leg B fails to close
+	trades.failSymbol = "MSFT"
+	if err := tick("MSFT", 110); err == nil {
+		t.Fatal("reverse with a failing leg B close returned no error")
+	}
+	if s.legA != nil || s.legB == nil {
+		t.Fatalf("after the failed close legA=%v legB=%v, want only leg B left", s.legA, s.legB)
+	}
+
+	// The next tick is a fresh entry signal, but the leftover leg is closed
+	// first and nothing new is opened
+	trades.failSymbol = ""
+	if err := tick("AAPL", 121); err != nil {
+		t.Fatalf("ProcessTick retrying the close: %v", err)
+	}
+	if s.legA != nil || s.legB != nil {
+		t.Errorf("after the retry legA=%v legB=%v, want flat", s.legA, s.legB)
+	}
+	if open, _ := trades.GetOpenTrades(); len(open) != 0 {
+		t.Errorf("%d trades left open, want 0", len(open))
+	}
+}
@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/aumbhatt/auto_trade/internal/models"
@@ -21,7 +22,12 @@ Strategy Registry Flow and Structure:
       2. Add to factories map
       3. Available for creation
 
-   b. Creation:
+   b. Validation (before a strategy is stored):
+      1. Look up metadata by name
+      2. models.ValidateParameters checks the params against it
+      3. Failures are INVALID_STRATEGY errors
+
+   c. Creation:
       1. Look up factory by name
       2. Create executor instance
       3. Return for use
@@ -63,7 +69,7 @@ func (r *Registry) Register(name string, factory StrategyFactory, metadata model
 	r.metadata[name] = metadata
 }
 
-// GetStrategyMetadata returns metadata for all registered strategies
+// GetStrategyMetadata returns metadata for all registered strategies, sorted by name
 func (r *Registry) GetStrategyMetadata() []models.StrategyMetadata {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -72,9 +78,30 @@ func (r *Registry) GetStrategyMetadata() []models.StrategyMetadata {
 	for _, m := range r.metadata {
 		metadata = append(metadata, m)
 	}
+	sort.Slice(metadata, func(i, j int) bool {
+		return metadata[i].Name < metadata[j].Name
+	})
 	return metadata
 }
 
+// ValidateParameters checks params against the strategy's metadata (see
+// models.ValidateParameters)
+// Returns an INVALID_STRATEGY StrategyError for unknown strategies
+func (r *Registry) ValidateParameters(name string, params map[string]interface{}) error {
+	r.mu.RLock()
+	metadata, exists := r.metadata[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return &models.StrategyError{
+			Code:    models.ErrInvalidStrategy,
+			Message: fmt.Sprintf("Unknown strategy type: %s", name),
+		}
+	}
+
+	return models.ValidateParameters(metadata, params)
+}
+
 // Create creates a new strategy executor instance
 func (r *Registry) Create(name string, runner *DefaultRunner, params map[string]interface{}) (StrategyExecutor, error) {
 	r.mu.RLock()
@@ -0,0 +1,39 @@
+package strategy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultRegistryMetadata(t *testing.T) {
+	// Declared parameters of each strategy, shared ones included
+	want := map[string][]string{
+		"martingale": {"symbol", "base_position", "take_profit", "stop_loss", "max_positions", "reversal_cooldown_seconds", "flatten_on_stop", "dry_run"},
+		"repeat":     {"symbol", "exit_price", "trailing_stop", "quantity", "reversal_cooldown_seconds", "flatten_on_stop", "dry_run"},
+	}
+
+	found := make(map[string]bool)
+	for _, metadata := range GetDefaultRegistry().GetStrategyMetadata() {
+		params, ok := want[metadata.Name]
+		if !ok {
+			continue
+		}
+		found[metadata.Name] = true
+
+		var names []string
+		for _, param := range metadata.Parameters {
+			names = append(names, param.Name)
+		}
+		if !reflect.DeepEqual(names, params) {
+			t.Errorf("%s parameters %v, want %v", metadata.Name, names, params)
+		}
+		if len(metadata.Flow) == 0 {
+			t.Errorf("%s has no flow description", metadata.Name)
+		}
+	}
+	for name := range want {
+		if !found[name] {
+			t.Errorf("%s missing from GetStrategyMetadata", name)
+		}
+	}
+}
@@ -14,8 +14,12 @@ Repeat Strategy Flow and Structure:
    RepeatStrategy
    ├── runner: *DefaultRunner       // For executing trades
    ├── symbol: string              // Trading symbol
-   ├── exitPrice: float64         // Sell when price >= this
+   ├── exitPrice: float64         // Sell when price >= this (0 = none)
+   ├── trailingStop: float64      // Sell this % below the high (0 = none)
+   ├── quantity: float64          // Units per trade
    ├── currentTrade: *models.Trade // Track current position
+   ├── highPrice: float64         // Highest price since entry
+   ├── cooldown: *ReversalCooldown // Optional whipsaw guard (see cooldown.go)
    └── mu: sync.Mutex             // Protects currentTrade
 
 2. Operation Flow:
@@ -24,16 +28,28 @@ Repeat Strategy Flow and Structure:
       Store trade ID
 
    b. Has Position:
+      Track the highest price since entry (starting at the entry price)
       IF price >= exitPrice
+         OR price <= high * (1 - trailingStop/100)
          Execute sell
          Clear trade ID
          Ready for next cycle
 
+   Example with trailing_stop 5, bought at 100:
+      110 → high 110, stop at 104.5
+      120 → high 120, stop at 114
+      113 → 113 <= 114, sell
+
 3. Parameters:
    {
        "symbol": "AAPL",
-       "exit_price": 155.0
+       "exit_price": 155.0,  // optional if trailing_stop is set
+       "trailing_stop": 5,   // optional, percent below the high
+       "quantity": 10,       // optional, defaults to 1
+       "reversal_cooldown_seconds": 60  // optional
    }
+   At least one of exit_price and trailing_stop is required; with both,
+   whichever triggers first exits
 
 4. Error Handling:
    - Invalid parameters
@@ -46,7 +62,11 @@ type RepeatStrategy struct {
 	runner       *DefaultRunner
 	symbol       string
 	exitPrice    float64
+	trailingStop float64
+	quantity     float64
 	currentTrade *models.Trade
+	highPrice    float64
+	cooldown     *ReversalCooldown
 	mu           sync.Mutex
 }
 
@@ -59,15 +79,44 @@ func NewRepeatStrategy(runner *DefaultRunner, params map[string]interface{}) (St
 	}
 
 	// Extract and validate exit price
-	exitPrice, ok := params["exit_price"].(float64)
-	if !ok || exitPrice <= 0 {
-		return nil, fmt.Errorf("invalid or missing exit_price parameter")
+	var exitPrice float64
+	if raw, ok := params["exit_price"]; ok && raw != nil {
+		exitPrice, ok = raw.(float64)
+		if !ok || exitPrice <= 0 {
+			return nil, fmt.Errorf("invalid exit_price parameter")
+		}
+	}
+
+	// Extract and validate trailing stop
+	var trailingStop float64
+	if raw, ok := params["trailing_stop"]; ok && raw != nil {
+		trailingStop, ok = raw.(float64)
+		if !ok || trailingStop <= 0 || trailingStop >= 100 {
+			return nil, fmt.Errorf("invalid trailing_stop parameter: must be between 0 and 100")
+		}
+	}
+
+	if exitPrice == 0 && trailingStop == 0 {
+		return nil, fmt.Errorf("missing exit_price or trailing_stop parameter")
+	}
+
+	quantity, err := quantityFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cooldown, err := NewReversalCooldownFromParams(params)
+	if err != nil {
+		return nil, err
 	}
 
 	return &RepeatStrategy{
-		runner:    runner,
-		symbol:    symbol,
-		exitPrice: exitPrice,
+		runner:       runner,
+		symbol:       symbol,
+		exitPrice:    exitPrice,
+		trailingStop: trailingStop,
+		quantity:     quantity,
+		cooldown:     cooldown,
 	}, nil
 }
 
@@ -83,20 +132,30 @@ func (s *RepeatStrategy) ProcessTick(tick *models.Tick) error {
 
 	// Enter trade immediately if no position
 	if s.currentTrade == nil {
-		trade, err := s.runner.executeBuy(s.symbol, tick.Price)
+		if !s.cooldown.Allows(s.symbol, models.SideBuy, tick.Timestamp) {
+			return nil
+		}
+		trade, err := s.runner.executeBuy(s.symbol, tick.Price, s.quantity)
 		if err != nil {
 			return fmt.Errorf("failed to execute buy: %w", err)
 		}
 		s.currentTrade = trade
+		s.highPrice = trade.EntryPrice
 		return nil
 	}
 
+	if tick.Price > s.highPrice {
+		s.highPrice = tick.Price
+	}
+
 	// Check for sell condition
-	if s.currentTrade != nil && tick.Price >= s.exitPrice {
-		_, err := s.runner.executeSell(s.currentTrade.ID)
+	if s.exitReached(tick.Price) || s.trailingStopReached(tick.Price) {
+		_, err := s.runner.executeSell(s.currentTrade.ID, tick.Price)
 		if err != nil {
 			return fmt.Errorf("failed to execute sell: %w", err)
 		}
+		// Long-only: the exit is the reversal
+		s.cooldown.RecordClose(s.symbol, models.SideSell, tick.Timestamp)
 		s.currentTrade = nil // Ready for next cycle
 		return nil
 	}
@@ -104,6 +163,17 @@ func (s *RepeatStrategy) ProcessTick(tick *models.Tick) error {
 	return nil
 }
 
+// exitReached reports whether price reached the fixed exit price
+func (s *RepeatStrategy) exitReached(price float64) bool {
+	return s.exitPrice > 0 && price >= s.exitPrice
+}
+
+// trailingStopReached reports whether price fell trailingStop percent below
+// the high since entry
+func (s *RepeatStrategy) trailingStopReached(price float64) bool {
+	return s.trailingStop > 0 && price <= s.highPrice*(1-s.trailingStop/100)
+}
+
 // Metadata for the repeat strategy
 var repeatMetadata = models.StrategyMetadata{
 	Name: "repeat",
@@ -117,15 +187,25 @@ var repeatMetadata = models.StrategyMetadata{
 		{
 			Name:        "exit_price",
 			Type:        "number",
-			Required:    true,
-			Description: "Price at which to sell and restart cycle",
+			Required:    false,
+			Description: "Price at which to sell and restart cycle (required unless trailing_stop is set)",
+		},
+		{
+			Name:        "trailing_stop",
+			Type:        "number",
+			Required:    false,
+			Description: "Sell when price falls this percentage below its high since entry (e.g. 5 = 5%)",
 		},
+		quantityParameter,
+		reversalCooldownParameter,
+		flattenOnStopParameter,
+		dryRunParameter,
 	},
 	Flow: []string{
 		"1. Wait for no active position",
 		"2. Enter trade immediately at market price",
-		"3. Hold position until price reaches exit_price",
-		"4. Sell position when price >= exit_price",
+		"3. Hold position, tracking the highest price since entry",
+		"4. Sell position when price >= exit_price or price falls trailing_stop% below the high",
 		"5. Return to step 1",
 	},
 }
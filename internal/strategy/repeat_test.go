@@ -0,0 +1,62 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+func TestRepeatTrailingStopExitsBelowHigh(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]interface{}
+		prices   []float64
+		wantExit float64 // 0 = still open
+	}{
+		{
+			// Stop trails the high: 104.5 at 110, 114 at 120
+			name:     "trailing stop",
+			params:   map[string]interface{}{"symbol": "AAPL", "trailing_stop": 5.0},
+			prices:   []float64{100, 110, 120, 115, 113},
+			wantExit: 113,
+		},
+		{
+			name:     "fixed exit first",
+			params:   map[string]interface{}{"symbol": "AAPL", "trailing_stop": 5.0, "exit_price": 118.0},
+			prices:   []float64{100, 110, 119},
+			wantExit: 119,
+		},
+		{
+			name:   "no trailing stop",
+			params: map[string]interface{}{"symbol": "AAPL", "exit_price": 130.0},
+			prices: []float64{100, 120, 80},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades := memory.NewInMemoryTradeStore()
+			runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+			ticks := make([]*models.Tick, len(tt.prices))
+			for i, price := range tt.prices {
+				ticks[i] = &models.Tick{Symbol: "AAPL", Price: price, Timestamp: time.Now()}
+			}
+			instance := models.NewStrategy("repeat", tt.params)
+			if err := runner.Replay(instance, ticks, func(err error) { t.Errorf("executor error: %v", err) }); err != nil {
+				t.Fatalf("Replay: %v", err)
+			}
+
+			history, _ := trades.GetTradeHistory()
+			if tt.wantExit == 0 {
+				if len(history) != 0 {
+					t.Errorf("%d trades closed, want the position held", len(history))
+				}
+				return
+			}
+			if len(history) != 1 || history[0].EntryPrice != 100 || history[0].ExitPrice != tt.wantExit {
+				t.Errorf("closed trades %v, want one bought at 100 and sold at %v", history, tt.wantExit)
+			}
+		})
+	}
+}
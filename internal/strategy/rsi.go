@@ -0,0 +1,268 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/aumbhatt/auto_trade/internal/models"
+)
+
+/*
+RSI Strategy Flow and Structure:
+
+1. Memory Structure:
+   RSIStrategy
+   ├── runner: *DefaultRunner       // For executing trades
+   ├── symbol: string              // Trading symbol
+   ├── period: int                 // Number of price changes in the RSI window
+   ├── oversold: float64           // RSI level marking oversold (e.g. 30)
+   ├── overbought: float64         // RSI level marking overbought (e.g. 70)
+   ├── quantity: float64           // Units per trade
+   ├── prices: []float64           // Rolling window of the last period+1 prices
+   ├── lastRSI: float64            // RSI after the previous tick
+   ├── hasRSI: bool                // Whether lastRSI is set
+   ├── currentTrade: *models.Trade  // Open position (nil when flat)
+   ├── cooldown: *ReversalCooldown  // Optional whipsaw guard (see cooldown.go)
+   └── mu: sync.Mutex              // Protects shared state
+
+2. RSI:
+   Over the last period price changes:
+   avgGain = sum(gains) / period, avgLoss = sum(losses) / period
+   RSI     = 100 - 100 / (1 + avgGain/avgLoss)
+   A window with no losses is RSI 100; a flat window is RSI 50
+
+3. Operation Flow:
+   a. Warmup:
+      Wait until the window holds period+1 prices and one RSI is known
+      With "preload": true, the window is filled from retained tick
+      history on start
+
+   b. Flat:
+      RSI crosses up out of oversold
+      (previous < oversold, current >= oversold) → buy
+
+   c. In Position:
+      RSI crosses down from overbought
+      (previous > overbought, current <= overbought) → sell
+
+4. Parameters:
+   {
+       "symbol": "AAPL",
+       "period": 14,
+       "oversold": 30,
+       "overbought": 70,
+       "quantity": 10,       // optional, defaults to 1
+       "reversal_cooldown_seconds": 60  // optional
+   }
+*/
+
+// RSIStrategy is a mean-reversion strategy trading RSI crossings
+type RSIStrategy struct {
+	runner       *DefaultRunner
+	symbol       string
+	period       int
+	oversold     float64
+	overbought   float64
+	quantity     float64
+	prices       []float64
+	lastRSI      float64
+	hasRSI       bool
+	currentTrade *models.Trade
+	cooldown     *ReversalCooldown
+	mu           sync.Mutex
+}
+
+// NewRSIStrategy creates a new RSI strategy instance
+func NewRSIStrategy(runner *DefaultRunner, params map[string]interface{}) (StrategyExecutor, error) {
+	// Extract and validate symbol
+	symbol, ok := params["symbol"].(string)
+	if !ok || symbol == "" {
+		return nil, fmt.Errorf("invalid or missing symbol parameter")
+	}
+
+	// Extract and validate period
+	period, ok := params["period"].(float64)
+	if !ok || period < 2 || period != math.Trunc(period) {
+		return nil, fmt.Errorf("invalid or missing period parameter: must be a whole number >= 2")
+	}
+
+	// Extract and validate levels
+	oversold, ok := params["oversold"].(float64)
+	if !ok || oversold <= 0 || oversold >= 100 {
+		return nil, fmt.Errorf("invalid or missing oversold parameter: must be between 0 and 100")
+	}
+	overbought, ok := params["overbought"].(float64)
+	if !ok || overbought <= oversold || overbought >= 100 {
+		return nil, fmt.Errorf("invalid or missing overbought parameter: must be above oversold and below 100")
+	}
+
+	quantity, err := quantityFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cooldown, err := NewReversalCooldownFromParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RSIStrategy{
+		runner:     runner,
+		symbol:     symbol,
+		period:     int(period),
+		oversold:   oversold,
+		overbought: overbought,
+		quantity:   quantity,
+		prices:     make([]float64, 0, int(period)+1),
+		cooldown:   cooldown,
+	}, nil
+}
+
+// Warmup implements Warmable; it fills the price window without trading
+func (s *RSIStrategy) Warmup(tick *models.Tick) {
+	if tick == nil || tick.Symbol != s.symbol || tick.Price <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observe(tick.Price)
+}
+
+// ProcessTick implements the StrategyExecutor interface
+func (s *RSIStrategy) ProcessTick(tick *models.Tick) error {
+	if tick == nil {
+		return fmt.Errorf("received nil tick")
+	}
+
+	// Ignore ticks for other symbols
+	if tick.Symbol != s.symbol {
+		return nil
+	}
+	if tick.Price <= 0 {
+		return fmt.Errorf("invalid tick price: %.2f", tick.Price)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, hadPrev := s.lastRSI, s.hasRSI
+	rsi, ok := s.observe(tick.Price)
+	if !ok || !hadPrev {
+		return nil // Still warming up
+	}
+
+	// Flat: buy when RSI recovers out of oversold
+	if s.currentTrade == nil {
+		if prev < s.oversold && rsi >= s.oversold && s.cooldown.Allows(s.symbol, models.SideBuy, tick.Timestamp) {
+			trade, err := s.runner.executeBuy(s.symbol, tick.Price, s.quantity)
+			if err != nil {
+				return fmt.Errorf("failed to execute buy: %w", err)
+			}
+			s.currentTrade = trade
+			logging.Debugf("RSI buy: %s at %.2f, RSI %.2f -> %.2f", s.symbol, tick.Price, prev, rsi)
+		}
+		return nil
+	}
+
+	// In position: sell when RSI falls back from overbought
+	if prev > s.overbought && rsi <= s.overbought {
+		if _, err := s.runner.executeSell(s.currentTrade.ID, tick.Price); err != nil {
+			return fmt.Errorf("failed to execute sell: %w", err)
+		}
+		s.cooldown.RecordClose(s.symbol, models.SideSell, tick.Timestamp) // Long-only: the exit is the reversal
+		s.currentTrade = nil
+		logging.Debugf("RSI sell: %s at %.2f, RSI %.2f -> %.2f", s.symbol, tick.Price, prev, rsi)
+	}
+	return nil
+}
+
+// observe adds a price to the rolling window and returns the current RSI
+// Returns false until the window holds period+1 prices
+// Caller must hold s.mu
+func (s *RSIStrategy) observe(price float64) (float64, bool) {
+	if len(s.prices) == s.period+1 {
+		copy(s.prices, s.prices[1:])
+		s.prices = s.prices[:len(s.prices)-1]
+	}
+	s.prices = append(s.prices, price)
+	if len(s.prices) < s.period+1 {
+		return 0, false
+	}
+
+	rsi := computeRSI(s.prices)
+	s.lastRSI, s.hasRSI = rsi, true
+	return rsi, true
+}
+
+// computeRSI returns the RSI of the price changes across prices
+func computeRSI(prices []float64) float64 {
+	var gains, losses float64
+	for i := 1; i < len(prices); i++ {
+		change := prices[i] - prices[i-1]
+		if change > 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+
+	switch {
+	case gains == 0 && losses == 0:
+		return 50
+	case losses == 0:
+		return 100
+	}
+	// Averages share the same divisor, so their ratio is gains/losses
+	return 100 - 100/(1+gains/losses)
+}
+
+// Metadata for the RSI strategy
+var rsiMetadata = models.StrategyMetadata{
+	Name: "rsi",
+	Parameters: []models.ParameterInfo{
+		{
+			Name:        "symbol",
+			Type:        "string",
+			Required:    true,
+			Description: "Trading symbol (e.g. AAPL)",
+		},
+		{
+			Name:        "period",
+			Type:        "number",
+			Required:    true,
+			Description: "Number of price changes in the RSI window (whole number >= 2, e.g. 14)",
+		},
+		{
+			Name:        "oversold",
+			Type:        "number",
+			Required:    true,
+			Description: "RSI level below which the symbol is oversold (e.g. 30)",
+		},
+		{
+			Name:        "overbought",
+			Type:        "number",
+			Required:    true,
+			Description: "RSI level above which the symbol is overbought (e.g. 70, above oversold)",
+		},
+		quantityParameter,
+		preloadParameter,
+		reversalCooldownParameter,
+		flattenOnStopParameter,
+		dryRunParameter,
+	},
+	Flow: []string{
+		"1. Track RSI over the last period price changes",
+		"2. Wait until the window is full",
+		"3. If flat and RSI crosses up through oversold: Buy",
+		"4. If in position and RSI crosses down through overbought: Sell",
+		"5. Repeat from step 1",
+	},
+}
+
+// init registers the RSI strategy with the registry
+func init() {
+	defaultRegistry.Register("rsi", NewRSIStrategy, rsiMetadata)
+}
@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+// fixedHistory is a TickHistory holding a fixed series of ticks
+type fixedHistory []*models.Tick
+
+func (h fixedHistory) RecentTicks(symbols []string) []*models.Tick {
+	return h
+}
+
+// rsiTicks returns ticks for AAPL at the given prices, a second apart
+func rsiTicks(start time.Time, prices ...float64) []*models.Tick {
+	ticks := make([]*models.Tick, len(prices))
+	for i, price := range prices {
+		ticks[i] = &models.Tick{Symbol: "AAPL", Price: price, Timestamp: start.Add(time.Duration(i) * time.Second)}
+	}
+	return ticks
+}
+
+func TestPreloadedRSIIsWarmOnFirstLiveTick(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(strategies, trades)
+
+	// Three falling prices after the first put RSI(3) at 0, deep in oversold
+	start := time.Now()
+	runner.SetTickHistory(fixedHistory(rsiTicks(start, 100, 99, 98, 97)))
+
+	started := make(map[bool]*models.Strategy)
+	for _, preload := range []bool{true, false} {
+		strategy, err := strategies.CreateStrategy("rsi", map[string]interface{}{
+			"symbol":     "AAPL",
+			"period":     3.0,
+			"oversold":   30.0,
+			"overbought": 70.0,
+			"preload":    preload,
+		})
+		if err != nil {
+			t.Fatalf("CreateStrategy: %v", err)
+		}
+		ticks := make(chan *models.Tick)
+		if err := runner.Start(strategy, ticks); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+
+		// The first live tick lifts RSI out of oversold
+		ticks <- &models.Tick{Symbol: "AAPL", Price: 110, Timestamp: start.Add(time.Minute)}
+		if err := runner.Stop(strategy); err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+		started[preload] = strategy
+	}
+
+	bought := make(map[string]bool)
+	open, _ := trades.GetOpenTrades()
+	for _, trade := range open {
+		bought[trade.StrategyID] = true
+	}
+	if !bought[started[true].ID] {
+		t.Error("preloaded strategy did not buy on the first live tick")
+	}
+	if bought[started[false].ID] {
+		t.Error("strategy without preload traded while still warming up")
+	}
+}
+
+func TestRSIEntersAndExitsOnCrossings(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	instance := models.NewStrategy("rsi", map[string]interface{}{
+		"symbol":     "AAPL",
+		"period":     3.0,
+		"oversold":   30.0,
+		"overbought": 70.0,
+	})
+
+	// RSI(3) after the first four prices: 0, 33.3, 66.7, 100, 100, 66.7
+	ticks := rsiTicks(time.Now(), 100, 99, 98, 97, 98, 99, 100, 101, 100)
+	if err := runner.Replay(instance, ticks, func(err error) { t.Errorf("executor error: %v", err) }); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if open, _ := trades.GetOpenTrades(); len(open) != 0 {
+		t.Errorf("%d trades still open, want the position exited", len(open))
+	}
+	history, _ := trades.GetTradeHistory()
+	if len(history) != 1 {
+		t.Fatalf("%d closed trades, want 1", len(history))
+	}
+	// Bought crossing up through 30, sold crossing down through 70
+	if trade := history[0]; trade.EntryPrice != 98 || trade.ExitPrice != 100 {
+		t.Errorf("trade from %.2f to %.2f, want bought at 98 and sold at 100", trade.EntryPrice, trade.ExitPrice)
+	}
+}
@@ -3,11 +3,15 @@ package strategy
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/aumbhatt/auto_trade/internal/models"
 	"github.com/aumbhatt/auto_trade/internal/store"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
 )
 
 /*
@@ -18,7 +22,28 @@ Strategy Runner Flow and Structure:
    ├── store: StrategyStore          // Strategy storage
    ├── tradeStore: TradeStore        // For executing trades
    ├── runningJobs: map[string]chan struct{}  // Strategy ID -> done channel
-   └── mu: sync.RWMutex             // Protects runningJobs map
+   ├── errorLogs: map[string]*ErrorLog // Strategy ID -> recent errors (kept after stop)
+   ├── errorLogsStopped: map[string]time.Time // Strategy ID -> when its job stopped
+   ├── errorHooks: []func(StrategyErrorEvent) // Called with every executor error
+   ├── accountBalance: float64      // Fractional capital allocations are taken of this
+   ├── maxMartingalePositions: int  // Cap on martingale max_positions, passed to scoped runners
+   └── mu: sync.RWMutex             // Protects runningJobs and the error log maps
+
+   Each running job trades against either the shared tradeStore or, when
+   the strategy's "trade_store" parameter is "isolated", its own in-memory
+   trade store so its positions and P&L stay separate from everything else.
+   With "dry_run": true it trades against the paper trade store
+   (SetPaperTradeStore) instead, whatever its "trade_store", so its trades
+   only show up on the paper_trades feed.
+   Ticks always come from the shared tick stream.
+
+   A strategy started with "capital_allocation" cannot open a trade that
+   would take the notional of its open trades (entry price x quantity)
+   past the allocation; the open fails with ALLOCATION_EXCEEDED and is
+   recorded as a strategy error. Other strategies are unaffected. An
+   allocation above 1 is in dollars; up to 1 it is a fraction of the
+   account balance (SetAccountBalance), e.g. 0.25 of 100000 allows 25000.
+   A fraction without an account balance is refused at start.
 
 2. Operation Flow:
    a. Starting Strategy:
@@ -28,16 +53,55 @@ Strategy Runner Flow and Structure:
       4. Return success/error
 
    b. Running Strategy:
-      1. Receive ticks from tickChan
+      1. Receive ticks from tickChan (fed by the TickHandler from the live
+         tick source), keeping only the executor's declared symbols or, if
+         it declares none, its "symbol" parameter; a closed tickChan ends
+         the strategy loop
       2. Process according to strategy logic
       3. Execute trades via tradeStore
       4. Continue until done channel closed
 
+      Executor state: the executor is created once per running job, when
+      its goroutine starts, and kept on the job (runningJob.executor) until
+      the job ends. Every tick of the job, including those after a
+      pause/resume, goes to that same instance, so in-memory progression
+      (martingale's size and position count, indicator windows) carries
+      over. Stopping discards it; a strategy restored from the store after
+      a server restart gets a fresh executor (warmed up with "preload",
+      but with trading progression reset).
+
    c. Stopping Strategy:
-      1. Close done channel
+      1. Close done channel (exactly once, whether stopped by the user or
+         by a critical error; errChan is never closed, senders and the
+         error handler exit on done instead)
+         Stop and StopAndFlatten then wait for the strategy loop to exit
+         (finished channel), so a tick in flight cannot open a trade after
+         they return or while the strategy's trades are being flattened
+         With "flatten_on_stop": true (or via StopAndFlatten), close the
+         strategy's open trades at the latest cached price (CloseReason
+         "strategy_stopped")
       2. Remove from runningJobs
       3. Update strategy status
       4. Return success/error
+      Stopping an already-stopped strategy succeeds; unknown IDs fail.
+      StopAndFlatten on a stopped strategy still closes any trades it left
+      open in the shared trade store
+
+   d. Pausing/Resuming Strategy:
+      1. Update the status in the store ("paused" / "active")
+      2. Flip the job's paused flag; the strategy loop drops ticks while
+         it is set, so the executor keeps its in-memory state (positions,
+         martingale size, indicator windows) and open trades stay open
+      3. A strategy started with status "paused" (e.g. resumed after a
+         restart) starts with the flag set
+      Stopping a paused strategy works as for an active one
+
+   e. Replaying Strategy (backtests):
+      Replay runs a new executor synchronously over a slice of ticks with
+      the same tick handling as a started strategy (clock, symbols, halts,
+      candles), against the runner's trade store. There is no goroutine,
+      job or error policy: executor errors go to a callback and the replay
+      continues
 
 3. Concurrency:
    - Each strategy runs in separate goroutine
@@ -49,14 +113,35 @@ Strategy Runner Flow and Structure:
    - Strategy-specific errors
    - Runner operation errors
    - Trade execution errors
-
-5. Example Usage:
+   - Executor errors are logged and kept in a per-strategy ErrorLog capped
+     at errorBufferSize; ErrorStats reports them with the overflow count
+   - A stopped strategy's ErrorLog stays readable for errorRetention
+     (SetErrorRetention), then is evicted the next time a strategy starts
+     or stops; 0 evicts it on stop. Restarting the strategy replaces it
+   - OnError hooks receive every executor error as it happens (the
+     strategy_errors feed streams them to clients)
+   - A strategy is auto-stopped on a critical error, or when more than
+     maxErrors errors occur within errorWindow; isolated errors are tolerated
+
+5. Time:
+   The runner reads time from its clock (wall clock by default). In
+   backtests a clock.Advancer (e.g. clock.Simulated) is advanced to each
+   tick's timestamp before the strategy sees it, so error windows and
+   strategy time checks (runner.Now) follow the data deterministically.
+
+6. Symbol Halts:
+   Ticks for symbols halted in the runner's SymbolHalts are not passed to
+   strategies, pausing them on that symbol until it is enabled again.
+
+7. Example Usage:
    runner := NewDefaultRunner(strategyStore, tradeStore)
 
    // Start strategy
    err := runner.Start(strategy, tickChan)
 
    // Later...
+   err = runner.Pause(strategy)
+   err = runner.Resume(strategy)
    err = runner.Stop(strategy)
 */
 
@@ -67,6 +152,36 @@ type Runner interface {
 
 	// Stop gracefully stops a running strategy
 	Stop(strategy *models.Strategy) error
+
+	// Pause makes a running strategy ignore ticks until it is resumed
+	Pause(strategy *models.Strategy) error
+
+	// Resume makes a paused strategy act on ticks again
+	Resume(strategy *models.Strategy) error
+}
+
+// Flattener is implemented by runners that can close a strategy's open
+// trades as part of stopping it
+type Flattener interface {
+	// StopAndFlatten stops the strategy like Stop, then closes its open
+	// trades at the latest cached price; returns the IDs of the trades
+	// closed
+	StopAndFlatten(strategy *models.Strategy) ([]string, error)
+}
+
+// StateReporter is implemented by runners that expose the runtime state of
+// running strategies' executors
+type StateReporter interface {
+	// RuntimeState returns a running strategy's executor state
+	// Returns false if it is not running or its executor reports none
+	RuntimeState(strategyID string) (map[string]interface{}, bool)
+}
+
+// ErrorReporter is implemented by runners that retain strategy errors
+type ErrorReporter interface {
+	// ErrorStats returns the retained errors of a strategy
+	// Returns false if the strategy was never started by this runner
+	ErrorStats(strategyID string) (models.StrategyErrorStats, bool)
 }
 
 // DefaultRunner implements the Runner interface
@@ -75,13 +190,84 @@ type DefaultRunner struct {
 	tradeStore  store.TradeStore
 	runningJobs map[string]*runningJob // strategy ID -> running job info
 	mu          sync.RWMutex
+	strategyID  string  // Set on runners scoped to a single strategy's executor
+	allocation  float64 // Capital cap of the scoped strategy's open trades (0 = unlimited)
+
+	// accountBalance is what fractional capital allocations are taken of
+	// (0 = none; fractions are refused)
+	accountBalance float64
+
+	// maxMartingalePositions caps the martingale max_positions parameter;
+	// shared with scoped runners (0 = DefaultMaxMartingalePositions)
+	maxMartingalePositions int
+
+	// newIsolatedStore creates the trade store for "isolated" strategies
+	newIsolatedStore func() store.TradeStore
+	// paperStore is the trade store of "dry_run" strategies (nil = none)
+	paperStore store.TradeStore
+
+	// tickHistory supplies warmup ticks for strategies started with "preload"
+	tickHistory TickHistory
+
+	// prices supplies exit prices for strategies stopped with "flatten_on_stop"
+	prices store.PriceCache
+
+	// clock is the runner's time source; shared with scoped runners
+	clock clock.Clock
+
+	// halts pauses strategies on halted symbols by holding back their ticks
+	halts *store.SymbolHalts
+
+	// errorLogs retains each strategy's recent errors, capped at errorBufferSize
+	errorLogs       map[string]*ErrorLog
+	errorBufferSize int
+
+	// errorLogsStopped holds when each stopped strategy's job ended; its
+	// error log is evicted errorRetention later
+	errorLogsStopped map[string]time.Time
+	errorRetention   time.Duration
+
+	// maxErrors within errorWindow auto-stops a strategy; 0 disables
+	maxErrors   int
+	errorWindow time.Duration
+
+	// errorHooks are called with every executor error (see OnError)
+	errorHooks []func(models.StrategyErrorEvent)
 }
 
 // runningJob holds information about a running strategy
 type runningJob struct {
-	done    chan struct{}    // Signal to stop the strategy
-	errChan chan error       // Channel for executor errors
-	cancel  func()          // Cancel function for the context
+	done       chan struct{}    // Signal to stop the strategy
+	finished   chan struct{}    // Closed when runStrategy returns
+	errChan    chan error       // Channel for executor errors
+	cancel     func()           // Cancel function for the context
+	tradeStore store.TradeStore // Trade store used by this strategy's executor
+	stopOnce   sync.Once        // Guards shutdown
+	paused     atomic.Bool      // Ticks are dropped while set
+	executor   atomic.Value     // StrategyExecutor, once created
+}
+
+// shutdown cancels the job and closes its done channel exactly once
+// Returns false if the job was already shut down
+func (j *runningJob) shutdown() bool {
+	stopped := false
+	j.stopOnce.Do(func() {
+		j.cancel()
+		close(j.done)
+		stopped = true
+	})
+	return stopped
+}
+
+// report hands an executor error to the error handler
+// Returns false if the job was shut down instead
+func (j *runningJob) report(err error) bool {
+	select {
+	case j.errChan <- err:
+		return true
+	case <-j.done:
+		return false
+	}
 }
 
 // NewDefaultRunner creates a new DefaultRunner instance
@@ -90,9 +276,121 @@ func NewDefaultRunner(strategyStore store.StrategyStore, tradeStore store.TradeS
 		store:       strategyStore,
 		tradeStore:  tradeStore,
 		runningJobs: make(map[string]*runningJob),
+		newIsolatedStore: func() store.TradeStore {
+			return memory.NewInMemoryTradeStore()
+		},
+		errorLogs:        make(map[string]*ErrorLog),
+		errorBufferSize:  DefaultErrorBufferSize,
+		errorLogsStopped: make(map[string]time.Time),
+		errorRetention:   DefaultErrorRetention,
+		clock:            clock.Real{},
 	}
 }
 
+// SetClock sets the runner's time source, e.g. a simulated clock for backtests
+func (r *DefaultRunner) SetClock(clk clock.Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clock = clk
+}
+
+// Now returns the current time according to the runner's clock
+func (r *DefaultRunner) Now() time.Time {
+	r.mu.RLock()
+	clk := r.clock
+	r.mu.RUnlock()
+	return clk.Now()
+}
+
+// SetErrorBufferSize caps the number of errors retained per strategy started afterwards
+func (r *DefaultRunner) SetErrorBufferSize(size int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errorBufferSize = size
+}
+
+// SetErrorRetention sets how long a stopped strategy's errors stay
+// readable through ErrorStats; 0 evicts them when the strategy stops
+func (r *DefaultRunner) SetErrorRetention(retention time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errorRetention = retention
+}
+
+// SetFailurePolicy auto-stops strategies started afterwards once they produce
+// more than maxErrors errors within window; maxErrors 0 disables it
+func (r *DefaultRunner) SetFailurePolicy(maxErrors int, window time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxErrors = maxErrors
+	r.errorWindow = window
+}
+
+// OnError registers a hook called with every strategy executor error, e.g.
+// to stream errors to clients; hooks run on the strategy's error handler
+// goroutine and must not block
+func (r *DefaultRunner) OnError(hook func(models.StrategyErrorEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errorHooks = append(r.errorHooks, hook)
+}
+
+// ErrorStats implements ErrorReporter
+func (r *DefaultRunner) ErrorStats(strategyID string) (models.StrategyErrorStats, bool) {
+	r.mu.RLock()
+	errorLog, exists := r.errorLogs[strategyID]
+	if stopped, ok := r.errorLogsStopped[strategyID]; ok && r.errorLogExpired(stopped, r.clock.Now()) {
+		exists = false // Awaiting eviction
+	}
+	r.mu.RUnlock()
+	if !exists {
+		return models.StrategyErrorStats{}, false
+	}
+	return errorLog.Stats(strategyID), true
+}
+
+// SetPaperTradeStore sets the trade store dry-run strategies trade against
+// instead of the real one; without it dry-run strategies fail to start
+func (r *DefaultRunner) SetPaperTradeStore(paper store.TradeStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paperStore = paper
+}
+
+// SetAccountBalance sets the balance that fractional capital allocations
+// are taken of; 0 refuses fractions
+func (r *DefaultRunner) SetAccountBalance(balance float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accountBalance = balance
+}
+
+// SetMaxMartingalePositions caps the max_positions parameter of martingale
+// strategies started afterwards; 0 uses DefaultMaxMartingalePositions
+func (r *DefaultRunner) SetMaxMartingalePositions(max int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxMartingalePositions = max
+}
+
+// capitalAllocation returns a strategy's capital allocation in dollars
+// (0 = unlimited)
+// Caller must hold r.mu
+func (r *DefaultRunner) capitalAllocation(strategy *models.Strategy) (float64, error) {
+	allocation, err := models.CapitalAllocation(strategy.Parameters)
+	if err != nil {
+		return 0, err
+	}
+	return models.AllocatedCapital(allocation, r.accountBalance)
+}
+
+// SetIsolatedStoreFactory overrides how trade stores for isolated strategies are created
+func (r *DefaultRunner) SetIsolatedStoreFactory(factory func() store.TradeStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.newIsolatedStore = factory
+}
+
 // Start begins executing a strategy
 func (r *DefaultRunner) Start(strategy *models.Strategy, tickChan <-chan *models.Tick) error {
 	r.mu.Lock()
@@ -103,75 +401,347 @@ func (r *DefaultRunner) Start(strategy *models.Strategy, tickChan <-chan *models
 		return fmt.Errorf("strategy already running: %s", strategy.ID)
 	}
 
+	// Select the trade store for this strategy
+	mode, err := models.TradeStoreMode(strategy.Parameters)
+	if err != nil {
+		return err
+	}
+	if _, err := r.capitalAllocation(strategy); err != nil {
+		return err
+	}
+	tradeStore := r.tradeStore
+	switch {
+	case models.DryRun(strategy.Parameters):
+		if r.paperStore == nil {
+			return &models.StrategyError{
+				Code:    models.ErrInvalidStrategy,
+				Message: fmt.Sprintf("Paper trading is not available: %s", strategy.ID),
+			}
+		}
+		tradeStore = r.paperStore
+	case mode == models.TradeStoreIsolated:
+		tradeStore = r.newIsolatedStore()
+	}
+
 	// Create running job with error channel
 	job := &runningJob{
-		done:    make(chan struct{}),
-		errChan: make(chan error, 1), // Buffered to prevent blocking
+		done:       make(chan struct{}),
+		finished:   make(chan struct{}),
+		errChan:    make(chan error, 1), // Buffered to prevent blocking
+		tradeStore: tradeStore,
 	}
+	job.paused.Store(strategy.Status == "paused")
 
 	// Create context with cancel
 	ctx, cancel := context.WithCancel(context.Background())
 	job.cancel = cancel
 
 	r.runningJobs[strategy.ID] = job
+	errorLog := NewErrorLog(r.errorBufferSize)
+	r.errorLogs[strategy.ID] = errorLog
+	delete(r.errorLogsStopped, strategy.ID)
+	r.pruneErrorLogs()
 
 	// Start strategy in goroutine
 	go func() {
+		defer close(job.finished)
 		r.runStrategy(ctx, strategy, tickChan, job)
 	}()
 
 	// Start error handler
+	errorRate := NewErrorRateTracker(r.maxErrors, r.errorWindow)
 	go func() {
-		r.handleErrors(strategy.ID, job)
+		r.handleErrors(strategy.ID, job, errorLog, errorRate)
 	}()
 
 	return nil
 }
 
 // Stop gracefully stops a running strategy
+// Stop is idempotent: stopping a strategy that is no longer running succeeds
+// as long as the store knows it; only unknown IDs are an error
 func (r *DefaultRunner) Stop(strategy *models.Strategy) error {
+	_, err := r.stop(strategy, models.FlattenOnStop(strategy.Parameters))
+	return err
+}
+
+// StopAndFlatten implements Flattener
+func (r *DefaultRunner) StopAndFlatten(strategy *models.Strategy) ([]string, error) {
+	return r.stop(strategy, true)
+}
+
+// stop stops a strategy, closing its open trades when flatten is set
+func (r *DefaultRunner) stop(strategy *models.Strategy, flatten bool) ([]string, error) {
+	// Shut the job down under the lock, like the critical-error path, so a
+	// concurrent self-stop and user stop cannot both tear it down
 	r.mu.Lock()
 	job, exists := r.runningJobs[strategy.ID]
+	stopped := exists && job.shutdown()
+	if stopped {
+		delete(r.runningJobs, strategy.ID)
+		r.retireErrorLog(strategy.ID)
+	}
 	r.mu.Unlock()
 
-	if !exists {
-		return fmt.Errorf("strategy not running: %s", strategy.ID)
+	if !stopped {
+		if err := r.stopNotRunning(strategy.ID); err != nil {
+			return nil, err
+		}
+		if !flatten {
+			return nil, nil
+		}
+		// Isolated stores are gone with their job; only shared trades remain
+		return r.flatten(strategy.ID, r.tradeStore), nil
 	}
 
-	// Cancel context and signal strategy to stop
-	job.cancel()
-	close(job.done)
-
-	// Wait for error handler to finish
-	close(job.errChan)
+	// Wait out a tick still being processed, so no trade opens after stop
+	// returns or while flattening
+	<-job.finished
 
-	r.mu.Lock()
-	delete(r.runningJobs, strategy.ID)
-	r.mu.Unlock()
+	var closed []string
+	if flatten {
+		closed = r.flatten(strategy.ID, job.tradeStore)
+	}
 
 	// Update strategy status
 	_, err := r.store.StopStrategy(strategy.ID)
+	return closed, err
+}
+
+// Pause makes a running strategy ignore ticks until it is resumed
+// The strategy stays active in the store with status "paused"
+func (r *DefaultRunner) Pause(strategy *models.Strategy) error {
+	job, err := r.job(strategy.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.store.PauseStrategy(strategy.ID); err != nil {
+		return err
+	}
+	job.paused.Store(true)
+	return nil
+}
+
+// Resume makes a paused strategy act on ticks again
+func (r *DefaultRunner) Resume(strategy *models.Strategy) error {
+	job, err := r.job(strategy.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.store.ResumeStrategy(strategy.ID); err != nil {
+		return err
+	}
+	job.paused.Store(false)
+	return nil
+}
+
+// job returns a strategy's running job
+func (r *DefaultRunner) job(strategyID string) (*runningJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.runningJobs[strategyID]
+	if !exists {
+		return nil, &models.StrategyError{
+			Code:    models.ErrStrategyNotFound,
+			Message: fmt.Sprintf("Strategy not running: %s", strategyID),
+		}
+	}
+	return job, nil
+}
+
+// stopNotRunning reconciles the store for a strategy with no running job
+func (r *DefaultRunner) stopNotRunning(strategyID string) error {
+	stored, err := r.store.GetStrategyByID(strategyID)
+	if err != nil {
+		return err
+	}
+	if stored.Status == "stopped" {
+		return nil
+	}
+
+	// Active in the store but not running (e.g. its executor failed to start)
+	_, err = r.store.StopStrategy(strategyID)
 	return err
 }
 
+// SetSymbolHalts pauses strategies on symbols halted in the set
+func (r *DefaultRunner) SetSymbolHalts(halts *store.SymbolHalts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.halts = halts
+}
+
+// SetPriceCache sets the source of current prices used to flatten on stop
+func (r *DefaultRunner) SetPriceCache(prices store.PriceCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prices = prices
+}
+
+// flatten closes a stopped strategy's open trades at the latest cached price
+// and returns their IDs
+// Trades whose symbol has no cached price are left open and logged
+func (r *DefaultRunner) flatten(strategyID string, tradeStore store.TradeStore) []string {
+	r.mu.RLock()
+	prices := r.prices
+	r.mu.RUnlock()
+
+	trades, err := tradeStore.GetOpenTrades()
+	if err != nil {
+		logging.Errorf("Strategy %s: failed to list open trades to flatten: %v", strategyID, err)
+		return nil
+	}
+
+	var closed []string
+	for _, trade := range trades {
+		if trade.StrategyID != strategyID {
+			continue
+		}
+		var tick *models.Tick
+		if prices != nil {
+			tick, _ = prices.GetLatestTick(trade.Symbol)
+		}
+		if tick == nil {
+			logging.Warnf("Strategy %s: no price for %s, leaving trade %s open", strategyID, trade.Symbol, trade.ID)
+			continue
+		}
+		if _, err := tradeStore.CloseTrade(trade.ID, tick.Price, models.CloseReasonStrategyStopped); err != nil {
+			logging.Errorf("Strategy %s: failed to flatten trade %s: %v", strategyID, trade.ID, err)
+			continue
+		}
+		closed = append(closed, trade.ID)
+	}
+	return closed
+}
+
+// SetTickHistory sets the source of retained ticks used for warmup preloads
+func (r *DefaultRunner) SetTickHistory(history TickHistory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tickHistory = history
+}
+
+// preload feeds retained history into a warmable executor before live ticks
+func (r *DefaultRunner) preload(strategy *models.Strategy, executor StrategyExecutor) {
+	if enabled, _ := strategy.Parameters[preloadParameter.Name].(bool); !enabled {
+		return
+	}
+	warmable, ok := executor.(Warmable)
+	if !ok {
+		return
+	}
+
+	r.mu.RLock()
+	history := r.tickHistory
+	r.mu.RUnlock()
+	if history == nil {
+		return
+	}
+
+	var symbols []string
+	if declarer, ok := executor.(SymbolDeclarer); ok {
+		symbols = declarer.Symbols()
+	} else if symbol, ok := strategy.Parameters["symbol"].(string); ok {
+		symbols = []string{symbol}
+	}
+
+	ticks := history.RecentTicks(symbols)
+	for _, tick := range ticks {
+		warmable.Warmup(tick)
+	}
+	logging.Infof("Strategy %s preloaded with %d historical ticks", strategy.ID, len(ticks))
+}
+
+// RuntimeState implements StateReporter
+func (r *DefaultRunner) RuntimeState(strategyID string) (map[string]interface{}, bool) {
+	r.mu.RLock()
+	job, exists := r.runningJobs[strategyID]
+	r.mu.RUnlock()
+	if !exists {
+		return nil, false
+	}
+
+	snapshotter, ok := job.executor.Load().(Snapshotter)
+	if !ok {
+		return nil, false
+	}
+	return snapshotter.Snapshot(), true
+}
+
+// TradeStore returns the trade store a running strategy trades against
+// Returns false if the strategy is not running
+func (r *DefaultRunner) TradeStore(strategyID string) (store.TradeStore, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.runningJobs[strategyID]
+	if !exists {
+		return nil, false
+	}
+	return job.tradeStore, true
+}
+
+// scopedRunner returns a runner for a single strategy's executor whose trade
+// helpers use the given trade store, tag trades with the strategy ID and
+// hold opens to the strategy's capital allocation
+func (r *DefaultRunner) scopedRunner(strategy *models.Strategy, tradeStore store.TradeStore) *DefaultRunner {
+	r.mu.RLock()
+	clk := r.clock
+	maxMartingalePositions := r.maxMartingalePositions
+	// Validated in Start
+	allocation, _ := r.capitalAllocation(strategy)
+	r.mu.RUnlock()
+
+	scoped := NewDefaultRunner(r.store, tradeStore)
+	scoped.strategyID = strategy.ID
+	scoped.clock = clk
+	scoped.allocation = allocation
+	scoped.maxMartingalePositions = maxMartingalePositions
+	return scoped
+}
+
 // handleErrors handles errors from the strategy executor
-func (r *DefaultRunner) handleErrors(strategyID string, job *runningJob) {
-	for err := range job.errChan {
+func (r *DefaultRunner) handleErrors(strategyID string, job *runningJob, errorLog *ErrorLog, errorRate *ErrorRateTracker) {
+	for {
+		var err error
+		select {
+		case err = <-job.errChan:
+		case <-job.done:
+			return
+		}
 		if err != nil {
 			// Log error
-			log.Printf("Strategy %s error: %v", strategyID, err)
+			logging.Warnf("Strategy %s error: %v", strategyID, err)
+			now := r.Now()
+			errorLog.Add(err, now)
+
+			// Notify hooks
+			r.mu.RLock()
+			hooks := r.errorHooks
+			r.mu.RUnlock()
+			event := models.StrategyErrorEvent{StrategyID: strategyID, Error: err.Error(), Timestamp: now}
+			for _, hook := range hooks {
+				hook(event)
+			}
 
-			// Stop strategy on critical errors
-			if isCriticalError(err) {
-				log.Printf("Stopping strategy %s due to critical error", strategyID)
+			// Stop strategy on critical errors or a sustained error rate
+			critical := isCriticalError(err)
+			if critical || errorRate.Record(now) {
+				if critical {
+					logging.Errorf("Stopping strategy %s due to critical error", strategyID)
+				} else {
+					logging.Errorf("Stopping strategy %s: more than %d errors within %v", strategyID, errorRate.maxErrors, errorRate.window)
+				}
 				r.mu.Lock()
-				if _, exists := r.runningJobs[strategyID]; exists {
-					job.cancel()
-					close(job.done)
+				if r.runningJobs[strategyID] == job && job.shutdown() {
 					delete(r.runningJobs, strategyID)
+					r.retireErrorLog(strategyID)
 					// Update strategy status
 					if _, err := r.store.StopStrategy(strategyID); err != nil {
-						log.Printf("Error stopping strategy %s: %v", strategyID, err)
+						logging.Errorf("Error stopping strategy %s: %v", strategyID, err)
 					}
 				}
 				r.mu.Unlock()
@@ -181,6 +751,36 @@ func (r *DefaultRunner) handleErrors(strategyID string, job *runningJob) {
 	}
 }
 
+// retireErrorLog starts the retention period of a stopped strategy's
+// error log and evicts expired ones
+// Caller must hold r.mu
+func (r *DefaultRunner) retireErrorLog(strategyID string) {
+	if _, exists := r.errorLogs[strategyID]; exists {
+		r.errorLogsStopped[strategyID] = r.clock.Now()
+	}
+	r.pruneErrorLogs()
+}
+
+// pruneErrorLogs evicts the error logs of strategies stopped longer than
+// errorRetention ago
+// Caller must hold r.mu
+func (r *DefaultRunner) pruneErrorLogs() {
+	now := r.clock.Now()
+	for strategyID, stopped := range r.errorLogsStopped {
+		if r.errorLogExpired(stopped, now) {
+			delete(r.errorLogs, strategyID)
+			delete(r.errorLogsStopped, strategyID)
+		}
+	}
+}
+
+// errorLogExpired reports whether an error log of a strategy stopped at
+// the given time is past its retention
+// Caller must hold r.mu
+func (r *DefaultRunner) errorLogExpired(stopped, now time.Time) bool {
+	return now.Sub(stopped) >= r.errorRetention
+}
+
 // isCriticalError determines if an error should stop the strategy
 func isCriticalError(err error) bool {
 	// Add logic to determine critical errors
@@ -191,18 +791,31 @@ func isCriticalError(err error) bool {
 // runStrategy executes the strategy logic
 func (r *DefaultRunner) runStrategy(ctx context.Context, strategy *models.Strategy, tickChan <-chan *models.Tick, job *runningJob) {
 	// Create strategy executor
-	executor, err := GetDefaultRegistry().Create(strategy.Name, r, strategy.Parameters)
+	executor, err := GetDefaultRegistry().Create(strategy.Name, r.scopedRunner(strategy, job.tradeStore), strategy.Parameters)
 	if err != nil {
-		job.errChan <- fmt.Errorf("failed to create strategy executor: %w", err)
+		job.report(fmt.Errorf("failed to create strategy executor: %w", err))
 		return
 	}
 
+	job.executor.Store(executor)
+
+	// Warm indicators from history before the first live tick
+	r.preload(strategy, executor)
+
+	pipeline := r.newTickPipeline(strategy, executor)
+	pipeline.paused = &job.paused
+
 	// Strategy runs until done channel is closed
 	for {
 		select {
-		case tick := <-tickChan:
-			if err := executor.ProcessTick(tick); err != nil {
-				job.errChan <- err
+		case tick, ok := <-tickChan:
+			if !ok {
+				return // Tick feed removed
+			}
+			if err := pipeline.process(tick); err != nil {
+				if !job.report(err) {
+					return
+				}
 			}
 		case <-ctx.Done():
 			return
@@ -212,13 +825,151 @@ func (r *DefaultRunner) runStrategy(ctx context.Context, strategy *models.Strate
 	}
 }
 
+// Replay runs a strategy synchronously over ticks, in order, against the
+// runner's trade store, e.g. for backtests. Ticks go through the same
+// handling as a started strategy's feed (clock, symbols, halts, candles);
+// executor errors are passed to onError (if set) and do not end the replay
+func (r *DefaultRunner) Replay(strategy *models.Strategy, ticks []*models.Tick, onError func(error)) error {
+	r.mu.RLock()
+	_, err := r.capitalAllocation(strategy)
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	executor, err := GetDefaultRegistry().Create(strategy.Name, r.scopedRunner(strategy, r.tradeStore), strategy.Parameters)
+	if err != nil {
+		return fmt.Errorf("failed to create strategy executor: %w", err)
+	}
+
+	pipeline := r.newTickPipeline(strategy, executor)
+	for _, tick := range ticks {
+		if err := pipeline.process(tick); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	return nil
+}
+
+// tickPipeline hands ticks to a strategy executor
+type tickPipeline struct {
+	executor       StrategyExecutor
+	symbols        map[string]struct{}    // nil = all symbols
+	candleExecutor CandleStrategyExecutor // Set for candle strategies
+	candles        *CandleAggregator      // Aggregates ticks for candleExecutor
+	advancer       clock.Advancer         // Simulated clock following ticks (optional)
+	halts          *store.SymbolHalts     // Halted symbols are held back
+	paused         *atomic.Bool           // Ticks are dropped while set (optional)
+}
+
+// newTickPipeline prepares the tick handling of a strategy's executor
+func (r *DefaultRunner) newTickPipeline(strategy *models.Strategy, executor StrategyExecutor) *tickPipeline {
+	pipeline := &tickPipeline{
+		executor: executor,
+		symbols:  tickSymbols(strategy, executor),
+	}
+
+	// Candle strategies get the tick feed aggregated into candles
+	if candleExecutor, ok := executor.(CandleStrategyExecutor); ok {
+		pipeline.candleExecutor = candleExecutor
+		pipeline.candles = NewCandleAggregator(candleExecutor.CandleInterval())
+	}
+
+	// Simulated clocks follow tick timestamps
+	r.mu.RLock()
+	pipeline.advancer, _ = r.clock.(clock.Advancer)
+	pipeline.halts = r.halts
+	r.mu.RUnlock()
+
+	return pipeline
+}
+
+// process passes one tick to the executor, returning the executor's error
+func (p *tickPipeline) process(tick *models.Tick) error {
+	if tick == nil {
+		return nil
+	}
+	if p.advancer != nil {
+		p.advancer.Advance(tick.Timestamp)
+	}
+	if p.paused != nil && p.paused.Load() {
+		return nil // Paused by the user
+	}
+	if p.symbols != nil {
+		if _, wanted := p.symbols[tick.Symbol]; !wanted {
+			return nil
+		}
+	}
+	if p.halts.IsDisabled(tick.Symbol) {
+		return nil // Paused while the symbol is halted
+	}
+	if p.candles != nil {
+		if candle := p.candles.Add(tick); candle != nil {
+			return p.candleExecutor.ProcessCandle(candle)
+		}
+		return nil
+	}
+	return p.executor.ProcessTick(tick)
+}
+
+// tickSymbols returns the symbols a strategy should receive ticks for, or nil
+// for all symbols. Executors declaring symbols win over the "symbol" parameter
+func tickSymbols(strategy *models.Strategy, executor StrategyExecutor) map[string]struct{} {
+	if declarer, ok := executor.(SymbolDeclarer); ok {
+		symbols := make(map[string]struct{})
+		for _, symbol := range declarer.Symbols() {
+			symbols[symbol] = struct{}{}
+		}
+		return symbols
+	}
+	if symbol, ok := strategy.Parameters["symbol"].(string); ok && symbol != "" {
+		return map[string]struct{}{symbol: {}}
+	}
+	return nil
+}
+
 // Helper methods for strategy implementations to use
-func (r *DefaultRunner) executeBuy(symbol string, price float64) (*models.Trade, error) {
+func (r *DefaultRunner) executeBuy(symbol string, price, quantity float64) (*models.Trade, error) {
+	return r.executeOpen(symbol, models.SideBuy, price, quantity)
+}
+
+func (r *DefaultRunner) executeOpen(symbol string, side models.Side, price, quantity float64) (*models.Trade, error) {
+	if err := r.checkAllocation(price * quantity); err != nil {
+		return nil, err
+	}
+
 	// Use trade store to create trade
-	return r.tradeStore.CreateTrade(symbol, price)
+	return r.tradeStore.CreateTrade(symbol, price, quantity, side, r.strategyID)
+}
+
+// checkAllocation rejects an open of the given notional that would take the
+// strategy's open capital past its allocation
+func (r *DefaultRunner) checkAllocation(notional float64) error {
+	if r.allocation <= 0 {
+		return nil
+	}
+
+	openTrades, err := r.tradeStore.GetOpenTrades()
+	if err != nil {
+		return err
+	}
+	used := 0.0
+	for _, trade := range openTrades {
+		if trade.StrategyID == r.strategyID {
+			used += trade.EntryPrice * trade.Quantity
+		}
+	}
+
+	if used+notional > r.allocation {
+		return &models.TradeError{
+			Code:    models.ErrAllocationExceeded,
+			Message: fmt.Sprintf("Capital allocation exceeded: %.2f in use, %.2f requested, %.2f allocated", used, notional, r.allocation),
+		}
+	}
+	return nil
 }
 
-func (r *DefaultRunner) executeSell(tradeID string) (*models.Trade, error) {
+func (r *DefaultRunner) executeSell(tradeID string, price float64) (*models.Trade, error) {
 	// Use trade store to close trade
-	return r.tradeStore.CloseTrade(tradeID)
+	return r.tradeStore.CloseTrade(tradeID, price, models.CloseReasonStrategy)
 }
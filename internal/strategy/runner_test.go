@@ -0,0 +1,526 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/clock"
+	"github.com/aumbhatt/auto_trade/internal/models"
+	"github.com/aumbhatt/auto_trade/internal/store/memory"
+)
+
+// gatedTradeStore holds every CreateTrade until the gate is opened, like a
+// tick still being processed when the strategy is stopped
+type gatedTradeStore struct {
+	*memory.InMemoryTradeStore
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func newGatedTradeStore() *gatedTradeStore {
+	return &gatedTradeStore{
+		InMemoryTradeStore: memory.NewInMemoryTradeStore(),
+		entered:            make(chan struct{}, 1),
+		gate:               make(chan struct{}),
+	}
+}
+
+func (s *gatedTradeStore) CreateTrade(symbol string, entryPrice, quantity float64, side models.Side, strategyID string) (*models.Trade, error) {
+	select {
+	case s.entered <- struct{}{}:
+	default:
+	}
+	<-s.gate
+	return s.InMemoryTradeStore.CreateTrade(symbol, entryPrice, quantity, side, strategyID)
+}
+
+// martingaleParams are valid martingale parameters trading AAPL
+func martingaleParams() map[string]interface{} {
+	return map[string]interface{}{
+		"symbol":        "AAPL",
+		"base_position": 1.0,
+		"take_profit":   5.0,
+		"stop_loss":     5.0,
+		"max_positions": 3.0,
+	}
+}
+
+func TestStopAndFlattenWaitsForTickInFlight(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	trades := newGatedTradeStore()
+	prices := memory.NewInMemoryPriceCache()
+	runner := NewDefaultRunner(strategies, trades)
+	runner.SetPriceCache(prices)
+
+	strategy, err := strategies.CreateStrategy("martingale", martingaleParams())
+	if err != nil {
+		t.Fatalf("CreateStrategy: %v", err)
+	}
+	ticks := make(chan *models.Tick, 1)
+	if err := runner.Start(strategy, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 100, Timestamp: time.Now()}
+	prices.UpdatePrice(tick)
+	ticks <- tick
+	select {
+	case <-trades.entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("strategy did not open a trade")
+	}
+
+	type result struct {
+		closed []string
+		err    error
+	}
+	stopped := make(chan result, 1)
+	go func() {
+		closed, err := runner.StopAndFlatten(strategy)
+		stopped <- result{closed, err}
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("StopAndFlatten returned while a tick was still opening a trade")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(trades.gate)
+
+	var res result
+	select {
+	case res = <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("StopAndFlatten did not return")
+	}
+	if res.err != nil {
+		t.Fatalf("StopAndFlatten: %v", res.err)
+	}
+	if len(res.closed) != 1 {
+		t.Fatalf("flattened %v, want the trade opened by the in-flight tick", res.closed)
+	}
+	if open, _ := trades.GetOpenTrades(); len(open) != 0 {
+		t.Errorf("%d trades left open after flatten", len(open))
+	}
+	history, _ := trades.GetTradeHistory()
+	if len(history) != 1 || history[0].CloseReason != models.CloseReasonStrategyStopped {
+		t.Errorf("trade history %v, want one trade closed as strategy_stopped", history)
+	}
+	if got, _ := strategies.GetStrategyByID(strategy.ID); got.Status != "stopped" {
+		t.Errorf("strategy status %q, want stopped", got.Status)
+	}
+}
+
+func TestStopWithFlattenOnStopClosesTrades(t *testing.T) {
+	for _, flatten := range []bool{true, false} {
+		strategies := memory.NewInMemoryStrategyStore()
+		trades := memory.NewInMemoryTradeStore()
+		prices := memory.NewInMemoryPriceCache()
+		runner := NewDefaultRunner(strategies, trades)
+		runner.SetPriceCache(prices)
+
+		params := martingaleParams()
+		params["flatten_on_stop"] = flatten
+		strategy, err := strategies.CreateStrategy("martingale", params)
+		if err != nil {
+			t.Fatalf("CreateStrategy: %v", err)
+		}
+		ticks := make(chan *models.Tick)
+		if err := runner.Start(strategy, ticks); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		tick := &models.Tick{Symbol: "AAPL", Price: 100, Timestamp: time.Now()}
+		prices.UpdatePrice(tick)
+		ticks <- tick
+
+		// Stop right behind the tick: the trade it opens must still be flattened
+		if err := runner.Stop(strategy); err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+		open, _ := trades.GetOpenTrades()
+		if !flatten {
+			if len(open) != 1 {
+				t.Errorf("%d trades open after stop without flatten_on_stop, want 1", len(open))
+			}
+			continue
+		}
+		if len(open) != 0 {
+			t.Errorf("%d trades left open after stop with flatten_on_stop", len(open))
+		}
+		history, _ := trades.GetTradeHistory()
+		if len(history) != 1 {
+			t.Errorf("%d closed trades after stop with flatten_on_stop, want 1", len(history))
+		}
+		for _, trade := range history {
+			if trade.CloseReason != models.CloseReasonStrategyStopped || trade.ExitPrice != 100 {
+				t.Errorf("trade closed at %v for %q, want at 100 for %s", trade.ExitPrice, trade.CloseReason, models.CloseReasonStrategyStopped)
+			}
+		}
+	}
+}
+
+func TestCapitalAllocationBlocksOnlyTheExhaustedStrategy(t *testing.T) {
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(memory.NewInMemoryStrategyStore(), trades)
+	runner.SetAccountBalance(10000)
+
+	// A fifth of the balance, and a dollar amount
+	fraction := runner.scopedRunner(&models.Strategy{ID: "fraction", Parameters: map[string]interface{}{"capital_allocation": 0.2}}, trades)
+	dollars := runner.scopedRunner(&models.Strategy{ID: "dollars", Parameters: map[string]interface{}{"capital_allocation": 5000.0}}, trades)
+
+	if _, err := fraction.executeBuy("AAPL", 100, 15); err != nil {
+		t.Fatalf("first buy within the allocation: %v", err)
+	}
+	_, err := fraction.executeBuy("AAPL", 100, 10)
+	if tradeErr, ok := err.(*models.TradeError); !ok || tradeErr.Code != models.ErrAllocationExceeded {
+		t.Fatalf("buy past 0.2 of 10000 returned %v, want ALLOCATION_EXCEEDED", err)
+	}
+	if _, err := dollars.executeBuy("AAPL", 100, 10); err != nil {
+		t.Errorf("other strategy blocked: %v", err)
+	}
+	if open, _ := trades.GetOpenTrades(); len(open) != 2 {
+		t.Errorf("%d open trades, want 2", len(open))
+	}
+}
+
+func TestFractionalAllocationNeedsAccountBalance(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+
+	params := martingaleParams()
+	params["capital_allocation"] = 0.5
+	strategy, err := strategies.CreateStrategy("martingale", params)
+	if err != nil {
+		t.Fatalf("CreateStrategy: %v", err)
+	}
+	err = runner.Start(strategy, make(chan *models.Tick))
+	if strategyErr, ok := err.(*models.StrategyError); !ok || strategyErr.Code != models.ErrInvalidStrategy {
+		t.Fatalf("Start returned %v, want an INVALID_STRATEGY error", err)
+	}
+}
+
+func TestStoppedStrategyErrorsExpireAfterRetention(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	clk := clock.NewSimulated(time.Now())
+	runner.SetClock(clk)
+	runner.SetErrorRetention(time.Hour)
+
+	start := func() *models.Strategy {
+		t.Helper()
+		strategy, err := strategies.CreateStrategy("martingale", martingaleParams())
+		if err != nil {
+			t.Fatalf("CreateStrategy: %v", err)
+		}
+		if err := runner.Start(strategy, make(chan *models.Tick)); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		return strategy
+	}
+
+	first := start()
+	if err := runner.Stop(first); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if _, exists := runner.ErrorStats(first.ID); !exists {
+		t.Fatal("errors gone right after stop, want them kept for the retention period")
+	}
+
+	clk.Advance(clk.Now().Add(time.Hour))
+	if _, exists := runner.ErrorStats(first.ID); exists {
+		t.Error("errors still reported after the retention period")
+	}
+
+	// The next start or stop evicts the expired log
+	second := start()
+	runner.mu.RLock()
+	_, retained := runner.errorLogs[first.ID]
+	runner.mu.RUnlock()
+	if retained {
+		t.Error("expired error log still held after another strategy started")
+	}
+	if _, exists := runner.ErrorStats(second.ID); !exists {
+		t.Error("running strategy's errors evicted")
+	}
+	runner.Stop(second)
+}
+
+func TestStopWhenNotRunning(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	strategy, err := strategies.CreateStrategy("martingale", martingaleParams())
+	if err != nil {
+		t.Fatalf("CreateStrategy: %v", err)
+	}
+	if err := runner.Start(strategy, make(chan *models.Tick)); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := runner.Stop(strategy); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	// Stopping again succeeds and leaves it stopped
+	if err := runner.Stop(strategy); err != nil {
+		t.Errorf("second Stop: %v", err)
+	}
+	if got, _ := strategies.GetStrategyByID(strategy.ID); got.Status != "stopped" {
+		t.Errorf("status %q after the second stop, want stopped", got.Status)
+	}
+
+	// Active in the store but never started: stopping marks it stopped
+	orphan, _ := strategies.CreateStrategy("martingale", martingaleParams())
+	if err := runner.Stop(orphan); err != nil {
+		t.Errorf("Stop of a strategy that never ran: %v", err)
+	}
+	if got, _ := strategies.GetStrategyByID(orphan.ID); got.Status != "stopped" {
+		t.Errorf("never-run strategy status %q after stop, want stopped", got.Status)
+	}
+
+	if err := runner.Stop(&models.Strategy{ID: "unknown"}); err == nil {
+		t.Error("stopping an unknown strategy succeeded")
+	}
+}
+
+func TestSelfStopRacingExternalStop(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	runner.SetFailurePolicy(1, time.Minute) // The second error stops the strategy
+
+	for i := 0; i < 50; i++ {
+		strategy, err := strategies.CreateStrategy("martingale", martingaleParams())
+		if err != nil {
+			t.Fatalf("CreateStrategy: %v", err)
+		}
+
+		// Two invalid ticks make the strategy stop itself
+		ticks := make(chan *models.Tick, 2)
+		ticks <- &models.Tick{Symbol: "AAPL", Price: 0, Timestamp: time.Now()}
+		ticks <- &models.Tick{Symbol: "AAPL", Price: 0, Timestamp: time.Now()}
+		if err := runner.Start(strategy, ticks); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+
+		// Stop after a varying delay: before, during or after the self-stop
+		time.Sleep(time.Duration(i%10) * 50 * time.Microsecond)
+		if err := runner.Stop(strategy); err != nil {
+			t.Fatalf("run %d: Stop: %v", i, err)
+		}
+
+		runner.mu.RLock()
+		_, running := runner.runningJobs[strategy.ID]
+		runner.mu.RUnlock()
+		if running {
+			t.Fatalf("run %d: strategy still running after stop", i)
+		}
+		if got, _ := strategies.GetStrategyByID(strategy.ID); got.Status != "stopped" {
+			t.Fatalf("run %d: status %q, want stopped", i, got.Status)
+		}
+	}
+}
+
+func TestErrorBurstStopsStrategy(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	runner := NewDefaultRunner(strategies, memory.NewInMemoryTradeStore())
+	clk := clock.NewSimulated(time.Now())
+	runner.SetClock(clk)
+	runner.SetFailurePolicy(2, time.Minute) // The third error within a minute stops it
+
+	strategy, err := strategies.CreateStrategy("martingale", martingaleParams())
+	if err != nil {
+		t.Fatalf("CreateStrategy: %v", err)
+	}
+	ticks := make(chan *models.Tick)
+	if err := runner.Start(strategy, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer runner.Stop(strategy)
+
+	// fail feeds an invalid tick and waits until its error is logged
+	errorCount := 0
+	fail := func() {
+		t.Helper()
+		ticks <- &models.Tick{Symbol: "AAPL", Price: 0, Timestamp: clk.Now()}
+		errorCount++
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			stats, _ := runner.ErrorStats(strategy.ID)
+			if len(stats.Errors) >= errorCount {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("error %d not logged", errorCount)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	status := func() string {
+		got, _ := strategies.GetStrategyByID(strategy.ID)
+		return got.Status
+	}
+
+	// Sporadic errors 40s apart never put three within a minute
+	for i := 0; i < 5; i++ {
+		fail()
+		clk.Advance(clk.Now().Add(40 * time.Second))
+	}
+	if s := status(); s != "active" {
+		t.Fatalf("status %q after sporadic errors, want active", s)
+	}
+
+	// A burst of three stops it, once the sporadic errors left the window
+	clk.Advance(clk.Now().Add(time.Minute))
+	for i := 0; i < 3; i++ {
+		fail()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for status() != "stopped" {
+		if time.Now().After(deadline) {
+			t.Fatalf("status %q after a burst of errors, want stopped", status())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPausedStrategyIgnoresTicksUntilResumed(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(strategies, trades)
+	strategy, _ := strategies.CreateStrategy("repeat", map[string]interface{}{"symbol": "AAPL", "exit_price": 110.0})
+	ticks := make(chan *models.Tick)
+	if err := runner.Start(strategy, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer runner.Stop(strategy)
+
+	// send delivers a tick, then another symbol's tick the loop can only
+	// take once the first is processed
+	send := func(price float64) {
+		ticks <- &models.Tick{Symbol: "AAPL", Price: price, Timestamp: time.Now()}
+		ticks <- &models.Tick{Symbol: "MSFT", Price: 1, Timestamp: time.Now()}
+	}
+	open := func() int {
+		open, _ := trades.GetOpenTrades()
+		return len(open)
+	}
+
+	send(100)
+	if open() != 1 {
+		t.Fatalf("%d trades open after the entry tick, want 1", open())
+	}
+
+	if err := runner.Pause(strategy); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if active, _ := strategies.GetActiveStrategies(); len(active) != 1 || active[0].Status != "paused" {
+		t.Fatalf("active strategies %v while paused, want it listed as paused", active)
+	}
+	if err := runner.Pause(strategy); err == nil {
+		t.Error("paused an already paused strategy")
+	}
+
+	// The exit price is ignored while paused
+	send(110)
+	if open() != 1 {
+		t.Fatalf("%d trades open after a paused exit tick, want the position kept", open())
+	}
+
+	if err := runner.Resume(strategy); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if got, _ := strategies.GetStrategyByID(strategy.ID); got.Status != "active" {
+		t.Errorf("status %q after resume, want active", got.Status)
+	}
+
+	// The strategy still knows its position and exits it
+	send(110)
+	if open() != 0 {
+		t.Errorf("%d trades open after a resumed exit tick, want 0", open())
+	}
+}
+
+func TestMartingaleProgressionSurvivesPauseAndResume(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(strategies, trades)
+	strategy, _ := strategies.CreateStrategy("martingale", martingaleParams()) // 5% stop, 3 positions
+	ticks := make(chan *models.Tick)
+	if err := runner.Start(strategy, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer runner.Stop(strategy)
+
+	// send delivers ticks; the loop only takes each once the previous one
+	// is processed, so all but the last are done when it returns
+	send := func(prices ...float64) {
+		for _, price := range prices {
+			ticks <- &models.Tick{Symbol: "AAPL", Price: price, Timestamp: time.Now()}
+		}
+	}
+	state := func() map[string]interface{} {
+		t.Helper()
+		state, ok := runner.RuntimeState(strategy.ID)
+		if !ok {
+			t.Fatal("no runtime state for the running martingale")
+		}
+		return state
+	}
+
+	// Two losses double the size twice; the third entry is at 4
+	send(100, 95, 100, 95, 100, 99)
+	if got := state(); got["current_size"] != 4.0 || got["position_count"] != 3 || got["in_position"] != true {
+		t.Fatalf("state %v after two losses, want the third position open at size 4", got)
+	}
+
+	if err := runner.Pause(strategy); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	if err := runner.Resume(strategy); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	send(99, 99)
+	if got := state(); got["current_size"] != 4.0 || got["position_count"] != 3 || got["in_position"] != true {
+		t.Errorf("state %v after pause and resume, want the progression kept", got)
+	}
+	if open, _ := trades.GetOpenTrades(); len(open) != 1 || open[0].Quantity*open[0].EntryPrice != 4 {
+		t.Errorf("open trades %v, want one sized 4 at entry", open)
+	}
+}
+
+func TestDryRunTradesOnlyOnThePaperStore(t *testing.T) {
+	strategies := memory.NewInMemoryStrategyStore()
+	trades := memory.NewInMemoryTradeStore()
+	runner := NewDefaultRunner(strategies, trades)
+	strategy, _ := strategies.CreateStrategy("repeat", map[string]interface{}{
+		"symbol": "AAPL", "exit_price": 110.0, models.ParamDryRun: true,
+	})
+
+	if err := runner.Start(strategy, make(chan *models.Tick)); err == nil {
+		runner.Stop(strategy)
+		t.Fatal("dry-run strategy started without a paper trade store")
+	}
+
+	paper := memory.NewInMemoryTradeStore()
+	runner.SetPaperTradeStore(paper)
+	ticks := make(chan *models.Tick)
+	if err := runner.Start(strategy, ticks); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer runner.Stop(strategy)
+
+	// Buy, exit and buy again; the last tick only waits for the others
+	for _, price := range []float64{100, 110, 100, 101} {
+		ticks <- &models.Tick{Symbol: "AAPL", Price: price, Timestamp: time.Now()}
+	}
+
+	if open, _ := trades.GetOpenTrades(); len(open) != 0 {
+		t.Errorf("%d real trades open, want none", len(open))
+	}
+	if history, _ := trades.GetTradeHistory(); len(history) != 0 {
+		t.Errorf("%d real trades closed, want none", len(history))
+	}
+	open, _ := paper.GetOpenTrades()
+	history, _ := paper.GetTradeHistory()
+	if len(open) != 1 || len(history) != 1 || open[0].StrategyID != strategy.ID {
+		t.Errorf("%d open and %d closed paper trades, want one of each for the strategy", len(open), len(history))
+	}
+}
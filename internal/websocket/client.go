@@ -2,11 +2,13 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"reflect"
 	"sync"
 	"time"
 
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
@@ -16,9 +18,18 @@ WebSocket Client Flow and Memory Structure:
 
 1. Memory Structure:
    Client
+   └── id: string               // Session ID, sent to the client on connect
    └── hub: *Hub                // Reference to central hub
    └── conn: *websocket.Conn    // WebSocket connection
    └── send: chan Message       // Outbound message queue
+   └── closed: bool             // Set once the hub closes send; guarded by sendMu
+   └── codec: Codec             // JSON (default) or MessagePack
+   └── maxMessageSize: int64    // Read limit; larger messages close the connection
+   └── closeNotice: *Message    // Final message sent before closing (set by hub)
+   └── closeFrame: []byte       // Close frame payload (set by hub; empty = normal close)
+   └── done: chan struct{}      // Closed when the write pump exits
+   └── resumeToken: string      // Issued for this session (see resume.go)
+   └── replaces: *Client        // Session taken over by resuming its token
 
 2. Connection Flow:
    Browser → WebSocket Server → Client Instance
@@ -36,7 +47,7 @@ WebSocket Client Flow and Memory Structure:
           "type": "subscribe",
           "payload": {
             "type": "ticks",
-            "options": {"symbols": ["AAPL", "GOOGL"]}
+            "options": {"history": 10}
           }
         }
 
@@ -45,7 +56,9 @@ WebSocket Client Flow and Memory Structure:
       2. Generates new UUID for subscription
       3. Routes to registry.HandleSubscribe
       4. Registry forwards to TickHandler
-      5. TickHandler starts sending data
+      5. The subscribe_response below is queued
+      6. registry.HandleSubscribed lets the handler send initial messages
+         (replays) after the response, then TickHandler sends live data
 
       ← Client Sends:
         {
@@ -102,6 +115,16 @@ WebSocket Client Flow and Memory Structure:
          "error": "Invalid subscribe request format"
        }
      }
+
+   ← Subscribing beyond the hub's per-client cap (SetMaxSubscriptions);
+     unsubscribing frees a slot:
+     {
+       "type": "error",
+       "payload": {
+         "code": "SUBSCRIPTION_LIMIT",
+         "error": "Subscription limit reached: 100"
+       }
+     }
 */
 
 const (
@@ -113,27 +136,71 @@ const (
 
 	// Send pings to peer with this period
 	pingPeriod = (pongWait * 9) / 10
-
-	// Maximum message size allowed from peer
-	maxMessageSize = 512
 )
 
+// ClientSendBuffer is the number of messages queued for a client before it
+// is dropped as a slow consumer
+const ClientSendBuffer = 256
+
+// DefaultMaxMessageSize is the largest message accepted from a peer when no
+// limit is configured; subscribe options such as long symbol lists need room
+const DefaultMaxMessageSize = 8192
+
 // Client represents a single WebSocket connection
 type Client struct {
-	hub          *Hub
-	conn         *websocket.Conn
-	send         chan Message
+	id    string
+	hub   *Hub
+	conn  *websocket.Conn
+	send  chan Message
+	codec Codec
+	// closed is set when the hub closes send; every send checks it under
+	// sendMu so none can hit the closed channel (see trySend)
+	sendMu sync.Mutex
+	closed bool
+	// Largest message accepted from the peer
+	maxMessageSize int64
+	// Final message and close frame written when send is closed; set by
+	// the hub before it closes send
+	closeNotice *Message
+	closeFrame  []byte
+	// Closed when the write pump exits
+	done chan struct{}
 	// Track subscriptions
-	subscriptions    sync.Map // map[string]map[string]struct{} // msgType -> subscribeIDs
-	subscriptionType sync.Map // map[string]string // subscribeID -> msgType
+	subscriptions       sync.Map // map[string]map[string]struct{} // msgType -> subscribeIDs
+	subscriptionType    sync.Map // map[string]string // subscribeID -> msgType
+	subscriptionOptions sync.Map // map[string]map[string]interface{} // subscribeID -> options
+	// Session resume: token presented on connect, token issued for this
+	// session, and the subscriptions to restore (set by the hub before
+	// registered is closed)
+	resumeFrom  string
+	resumeToken string
+	resumed     []resumedSubscription
+	registered  chan struct{}
+	// Session this client took over with its resume token; its
+	// subscriptions are restored once it has released them
+	replaces *Client
+	// Closed once the hub has released the client's subscriptions on
+	// unregister; releasedSubscriptions holds what was released
+	released              chan struct{}
+	releasedSubscriptions []resumedSubscription
 }
 
-// NewClient creates a new client instance
-func NewClient(hub *Hub, conn *websocket.Conn) *Client {
+// NewClient creates a new client instance using the given codec
+// maxMessageSize limits inbound messages; <= 0 uses DefaultMaxMessageSize
+func NewClient(hub *Hub, conn *websocket.Conn, codec Codec, maxMessageSize int64) *Client {
+	if maxMessageSize <= 0 {
+		maxMessageSize = DefaultMaxMessageSize
+	}
 	return &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan Message, 256),
+		id:             uuid.New().String(),
+		hub:            hub,
+		conn:           conn,
+		send:           make(chan Message, ClientSendBuffer),
+		codec:          codec,
+		maxMessageSize: maxMessageSize,
+		done:           make(chan struct{}),
+		registered:     make(chan struct{}),
+		released:       make(chan struct{}),
 	}
 }
 
@@ -160,8 +227,9 @@ func (c *Client) addSubscription(msgType, subscribeID string) {
 	c.subscriptions.Store(msgType, subMap)
 }
 
-// removeSubscription removes a subscription
+// removeSubscription removes a subscription and its options
 func (c *Client) removeSubscription(msgType, subscribeID string) {
+	c.subscriptionOptions.Delete(subscribeID)
 	if subs, ok := c.subscriptions.Load(msgType); ok {
 		if subMap, ok := subs.(map[string]struct{}); ok {
 			delete(subMap, subscribeID)
@@ -177,27 +245,41 @@ func (c *Client) removeSubscription(msgType, subscribeID string) {
 // readPump pumps messages from the WebSocket connection to the hub
 func (c *Client) readPump() {
 	defer func() {
-		c.hub.unregister <- c
+		c.hub.unregisterClient(c)
 		c.conn.Close()
 	}()
 
-	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadLimit(c.maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
 
+	// Restore a resumed session once its session message is queued, before
+	// the client's own requests are read
+	<-c.registered
+	subscriptions := c.resumed
+	if c.replaces != nil {
+		subscriptions = c.replaces.awaitRelease()
+	}
+	c.restoreSubscriptions(subscriptions)
+
 	for {
-		var msg Message
-		err := c.conn.ReadJSON(&msg)
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("error: %v", err)
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logging.Warnf("error: %v", err)
 			}
 			break
 		}
 
+		var msg Message
+		if err := c.codec.Decode(data, &msg); err != nil {
+			c.sendError(fmt.Sprintf("Invalid %s message: %v", c.codec.Name(), err))
+			continue
+		}
+
 		c.handleMessage(msg)
 	}
 }
@@ -208,6 +290,7 @@ func (c *Client) writePump() {
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
+		close(c.done)
 	}()
 
 	for {
@@ -216,12 +299,16 @@ func (c *Client) writePump() {
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// The hub closed the channel
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				c.writeClose()
 				return
 			}
 
-			err := c.conn.WriteJSON(message)
+			frameType, data, err := c.codec.Encode(message)
 			if err != nil {
+				logging.Errorf("error encoding %s message: %v", c.codec.Name(), err)
+				continue
+			}
+			if err := c.conn.WriteMessage(frameType, data); err != nil {
 				return
 			}
 
@@ -234,40 +321,94 @@ func (c *Client) writePump() {
 	}
 }
 
+// writeClose writes the close frame, preceded by the hub's final message
+// (reconnect notice, slow consumer error) when it set one
+func (c *Client) writeClose() {
+	if c.closeNotice != nil {
+		if frameType, data, err := c.codec.Encode(*c.closeNotice); err == nil {
+			c.conn.WriteMessage(frameType, data)
+		}
+	}
+	frame := c.closeFrame
+	if frame == nil {
+		frame = []byte{}
+	}
+	c.conn.WriteMessage(websocket.CloseMessage, frame)
+}
+
+// subscribedTypes returns the message types the client is subscribed to
+func (c *Client) subscribedTypes() []string {
+	types := make([]string, 0)
+	c.subscriptions.Range(func(key, value interface{}) bool {
+		types = append(types, key.(string))
+		return true
+	})
+	return types
+}
+
+// subscriptionCount returns the client's active subscriptions
+func (c *Client) subscriptionCount() int {
+	count := 0
+	c.subscriptionType.Range(func(key, value interface{}) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// subscribe routes a subscription to its handler and confirms it to the
+// client with a subscribe_response, or an error if the handler refuses it
+func (c *Client) subscribe(subscribeID, msgType string, options map[string]interface{}) {
+	// Track the subscription locally before routing it, so messages the
+	// handler sends while subscribing (snapshots, replays) are delivered
+	c.addSubscription(msgType, subscribeID)
+	c.subscriptionType.Store(subscribeID, msgType)
+	c.hub.trackSubscription(subscribeID, c)
+
+	if err := c.hub.registry.HandleSubscribe(msgType, subscribeID, options); err != nil {
+		c.removeSubscription(msgType, subscribeID)
+		c.subscriptionType.Delete(subscribeID)
+		c.hub.untrackSubscription(subscribeID)
+		c.sendError(fmt.Sprintf("Subscription failed: %v", err))
+		return
+	}
+	c.subscriptionOptions.Store(subscribeID, options)
+
+	response := Message{
+		Type: MessageTypeSubscribeResponse,
+		Payload: SubscribeResponse{
+			SubscribeID: subscribeID,
+			Type:        msgType,
+			Status:      StatusSuccess,
+		},
+	}
+	c.reply(response)
+
+	// Initial messages that must follow the response (e.g. tick replays)
+	c.hub.registry.HandleSubscribed(msgType, subscribeID)
+}
+
 // handleMessage processes incoming messages
 func (c *Client) handleMessage(msg Message) {
 	switch msg.Type {
 	case MessageTypeSubscribe:
 		var subReq SubscribeRequest
 		if err := convertPayload(msg.Payload, &subReq); err != nil {
-			c.sendError("Invalid subscribe request format")
+			c.sendError(fmt.Sprintf("Invalid subscribe request format: %v", err))
 			return
 		}
 
-		subscribeID := uuid.New().String()
-		if err := c.hub.registry.HandleSubscribe(subReq.Type, subscribeID, subReq.Options); err != nil {
-			c.sendError(fmt.Sprintf("Subscription failed: %v", err))
+		if limit := c.hub.subscriptionLimit(); limit > 0 && c.subscriptionCount() >= limit {
+			c.sendCodedError(ErrCodeSubscriptionLimit, fmt.Sprintf("Subscription limit reached: %d", limit))
 			return
 		}
 
-		// Track the subscription locally
-		c.addSubscription(subReq.Type, subscribeID)
-		c.subscriptionType.Store(subscribeID, subReq.Type)
-
-		response := Message{
-			Type: MessageTypeSubscribeResponse,
-			Payload: SubscribeResponse{
-				SubscribeID: subscribeID,
-				Type:        subReq.Type,
-				Status:      StatusSuccess,
-			},
-		}
-		c.send <- response
+		c.subscribe(uuid.New().String(), subReq.Type, subReq.Options)
 
 	case MessageTypeUnsubscribe:
 		var unsubReq UnsubscribeRequest
 		if err := convertPayload(msg.Payload, &unsubReq); err != nil {
-			c.sendError("Invalid unsubscribe request format")
+			c.sendError(fmt.Sprintf("Invalid unsubscribe request format: %v", err))
 			return
 		}
 
@@ -287,6 +428,7 @@ func (c *Client) handleMessage(msg Message) {
 		// Remove the subscription locally
 		c.removeSubscription(msgType, unsubReq.SubscribeID)
 		c.subscriptionType.Delete(unsubReq.SubscribeID)
+		c.hub.untrackSubscription(unsubReq.SubscribeID)
 
 		response := Message{
 			Type: MessageTypeUnsubscribeResponse,
@@ -295,7 +437,7 @@ func (c *Client) handleMessage(msg Message) {
 				Status:      StatusSuccess,
 			},
 		}
-		c.send <- response
+		c.reply(response)
 
 	default:
 		c.sendError("Unknown message type")
@@ -310,14 +452,93 @@ func (c *Client) sendError(errMsg string) {
 			"error": errMsg,
 		},
 	}
-	c.send <- msg
+	c.reply(msg)
+}
+
+// sendCodedError sends an error message with a machine-readable code
+func (c *Client) sendCodedError(code, errMsg string) {
+	msg := Message{
+		Type: MessageTypeError,
+		Payload: map[string]string{
+			"code":  code,
+			"error": errMsg,
+		},
+	}
+	c.reply(msg)
+}
+
+// trySend queues a message for the write pump without blocking; false if
+// the queue is full or the hub has closed it
+func (c *Client) trySend(message Message) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// closeSend closes the send channel once, ending the write pump after it
+// flushes the queue
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// reply queues a message answering the client's own request; a client
+// whose queue is full is dropped as a slow consumer
+func (c *Client) reply(message Message) {
+	if !c.trySend(message) {
+		c.hub.dropSlowClient(c)
+	}
 }
 
 // convertPayload converts a payload interface to a specific type
+// Unmarshal errors are rewritten to name the offending field and types
 func convertPayload(payload interface{}, target interface{}) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return fmt.Errorf("payload cannot be encoded: %v", err)
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			if typeErr.Field == "" {
+				return fmt.Errorf("payload must be %s, got %s", describeType(typeErr.Type.Kind()), typeErr.Value)
+			}
+			return fmt.Errorf("field '%s' must be %s, got %s", typeErr.Field, describeType(typeErr.Type.Kind()), typeErr.Value)
+		}
+		return fmt.Errorf("payload is not valid: %v", err)
+	}
+	return nil
+}
+
+// describeType maps Go kinds to their JSON type names
+func describeType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a number"
+	default:
+		return kind.String()
 	}
-	return json.Unmarshal(data, target)
 }
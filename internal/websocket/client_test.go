@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	gws "github.com/gorilla/websocket"
+)
+
+// newDroppedClient returns a client the hub has already dropped as a slow
+// consumer, with its send channel closed
+func newDroppedClient(t *testing.T) (*Hub, *Client) {
+	t.Helper()
+	hub := NewHub(newFakeRegistry(), 0)
+	client := NewClient(hub, nil, codecs[CodecJSON], 0)
+	hub.mu.Lock()
+	hub.clients[client] = true
+	hub.dropSlowConsumer(client)
+	hub.mu.Unlock()
+	return hub, client
+}
+
+func TestRequestsAfterDropDoNotSend(t *testing.T) {
+	hub, client := newDroppedClient(t)
+
+	// Each request would reply on the closed channel
+	requests := []Message{
+		{Type: MessageTypeSubscribe, Payload: map[string]interface{}{"type": "ticks"}},
+		{Type: MessageTypeSubscribe, Payload: "not an object"},
+		{Type: MessageTypeUnsubscribe, Payload: map[string]interface{}{"subscribe_id": "unknown"}},
+		{Type: "unknown"},
+	}
+	for _, msg := range requests {
+		client.handleMessage(msg)
+	}
+	client.subscribe("sub-1", "ticks", nil)
+	if err := hub.CancelSubscription("sub-1", "test"); err != nil {
+		t.Fatalf("CancelSubscription: %v", err)
+	}
+}
+
+func TestFullQueueOnReplyDropsClient(t *testing.T) {
+	hub := NewHub(newFakeRegistry(), 0)
+	client := NewClient(hub, nil, codecs[CodecJSON], 0)
+	hub.mu.Lock()
+	hub.clients[client] = true
+	hub.mu.Unlock()
+	for i := 0; i < ClientSendBuffer; i++ {
+		client.trySend(Message{Type: "ticks"})
+	}
+
+	client.handleMessage(Message{Type: "unknown"})
+	if hub.ClientCount() != 0 {
+		t.Fatal("client with a full queue was not dropped")
+	}
+	if !client.closed {
+		t.Error("dropped client's send channel is not closed")
+	}
+	if client.closeNotice == nil || client.closeNotice.Type != MessageTypeError {
+		t.Errorf("close notice %+v, want a SLOW_CONSUMER error", client.closeNotice)
+	}
+}
+
+func TestConvertPayloadDescribesMalformedPayload(t *testing.T) {
+	for _, tc := range []struct {
+		payload interface{}
+		want    string
+	}{
+		{"ticks", "payload must be an object, got string"},
+		{map[string]interface{}{"type": 5}, "field 'type' must be a string, got number"},
+		{map[string]interface{}{"type": "ticks", "options": []int{1}}, "field 'options' must be an object, got array"},
+	} {
+		var request SubscribeRequest
+		err := convertPayload(tc.payload, &request)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("convertPayload(%v) returned %v, want %q", tc.payload, err, tc.want)
+		}
+	}
+}
+
+func TestMalformedSubscribeGetsDescriptiveError(t *testing.T) {
+	server := newTestHub(t)
+	client := server.dial(t, "")
+	client.send(Message{Type: MessageTypeSubscribe, Payload: map[string]interface{}{"type": 5}})
+
+	msg := client.next()
+	var errPayload map[string]string
+	client.decode(msg, &errPayload)
+	want := "Invalid subscribe request format: field 'type' must be a string, got number"
+	if msg.Type != MessageTypeError || errPayload["error"] != want {
+		t.Fatalf("got %s message %s, want error %q", msg.Type, msg.Payload, want)
+	}
+
+	// The connection stays usable
+	client.subscribe("ticks")
+}
+
+func TestLargeSubscribeWithinReadLimit(t *testing.T) {
+	server := newTestHub(t)
+	client := server.dial(t, "")
+
+	// 50 symbols are well past the old 512 byte limit
+	symbols := make([]string, 50)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYMBOL%02d/USD", i)
+	}
+	request := Message{
+		Type:    MessageTypeSubscribe,
+		Payload: SubscribeRequest{Type: "ticks", Options: map[string]interface{}{"symbols": symbols}},
+	}
+	if encoded, _ := json.Marshal(request); len(encoded) <= 512 || len(encoded) >= DefaultMaxMessageSize {
+		t.Fatalf("subscribe is %d bytes, want between 512 and %d", len(encoded), DefaultMaxMessageSize)
+	}
+	client.send(request)
+	client.subscribeResponse()
+	client.subscribe("ticks") // Still connected
+
+	// Past the limit the connection is closed
+	client.send(Message{Type: MessageTypeSubscribe, Payload: SubscribeRequest{
+		Type:    "ticks",
+		Options: map[string]interface{}{"padding": strings.Repeat("x", DefaultMaxMessageSize)},
+	}})
+	client.expectClosed()
+	closeErr, ok := client.readErr.(*gws.CloseError)
+	if !ok || closeErr.Code != gws.CloseMessageTooBig {
+		t.Errorf("connection ended with %v, want a 1009 close", client.readErr)
+	}
+}
+
+func TestSubscriptionLimitPerClient(t *testing.T) {
+	server := newTestHub(t)
+	server.hub.SetMaxSubscriptions(2)
+	client := server.dial(t, "")
+
+	first := client.subscribe("ticks")
+	client.subscribe("open_positions")
+
+	client.send(Message{Type: MessageTypeSubscribe, Payload: SubscribeRequest{Type: "ticks"}})
+	msg := client.next()
+	var refused struct {
+		Code  string `json:"code"`
+		Error string `json:"error"`
+	}
+	client.decode(msg, &refused)
+	if msg.Type != MessageTypeError || refused.Code != ErrCodeSubscriptionLimit {
+		t.Fatalf("got %s message %s over the cap, want a %s error", msg.Type, msg.Payload, ErrCodeSubscriptionLimit)
+	}
+	if n := server.registry.activeCount(); n != 2 {
+		t.Errorf("%d handler subscriptions, want the refused one not registered", n)
+	}
+
+	// The cap is per client
+	server.dial(t, "").subscribe("ticks")
+
+	// Unsubscribing frees a slot
+	client.send(Message{Type: MessageTypeUnsubscribe, Payload: UnsubscribeRequest{SubscribeID: first}})
+	if msg := client.next(); msg.Type != MessageTypeUnsubscribeResponse {
+		t.Fatalf("got %s message %s, want unsubscribe_response", msg.Type, msg.Payload)
+	}
+	client.subscribe("ticks")
+}
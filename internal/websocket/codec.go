@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+/*
+Codec Negotiation:
+
+1. Selection (per connection, default JSON):
+   a. Query parameter:  ws://localhost:8080/ws?codec=msgpack
+   b. Subprotocol:      new WebSocket(url, ["msgpack"])
+   The query parameter wins when both are given.
+
+2. Framing:
+   ├── json    → text frames
+   └── msgpack → binary frames
+
+3. Field Names:
+   MessagePack uses the same field names as JSON (struct json tags),
+   so payloads look identical once decoded on the client.
+
+4. Numbers:
+   MessagePack encodes whole numbers as integers. Decoded payloads have
+   every number converted to float64, as encoding/json does, so option
+   parsing (e.g. raw.(float64)) works the same for both codecs.
+*/
+
+// Codec names
+const (
+	CodecJSON    = "json"
+	CodecMsgpack = "msgpack"
+)
+
+// Codec serializes messages for a single connection
+type Codec interface {
+	// Name returns the codec name used for negotiation
+	Name() string
+
+	// Encode serializes a message and returns the WebSocket frame type to use
+	Encode(msg Message) (frameType int, data []byte, err error)
+
+	// Decode deserializes a frame into a message
+	Decode(data []byte, msg *Message) error
+}
+
+// jsonCodec encodes messages as JSON text frames
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return CodecJSON }
+
+func (jsonCodec) Encode(msg Message) (int, []byte, error) {
+	data, err := json.Marshal(msg)
+	return websocket.TextMessage, data, err
+}
+
+func (jsonCodec) Decode(data []byte, msg *Message) error {
+	return json.Unmarshal(data, msg)
+}
+
+// msgpackCodec encodes messages as MessagePack binary frames
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string { return CodecMsgpack }
+
+func (msgpackCodec) Encode(msg Message) (int, []byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(msg); err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, buf.Bytes(), nil
+}
+
+func (msgpackCodec) Decode(data []byte, msg *Message) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	if err := dec.Decode(msg); err != nil {
+		return err
+	}
+	msg.Payload = normalizeNumbers(msg.Payload)
+	return nil
+}
+
+// normalizeNumbers converts the integers and float32s of a decoded
+// MessagePack value to float64, recursing into maps and arrays
+func normalizeNumbers(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int8:
+		return float64(n)
+	case int16:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	case uint8:
+		return float64(n)
+	case uint16:
+		return float64(n)
+	case uint32:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case uint:
+		return float64(n)
+	case float32:
+		return float64(n)
+	case map[string]interface{}:
+		for key, value := range n {
+			n[key] = normalizeNumbers(value)
+		}
+		return n
+	case map[interface{}]interface{}:
+		for key, value := range n {
+			n[key] = normalizeNumbers(value)
+		}
+		return n
+	case []interface{}:
+		for i, value := range n {
+			n[i] = normalizeNumbers(value)
+		}
+		return n
+	}
+	return v
+}
+
+// codecs lists the supported codecs by name
+var codecs = map[string]Codec{
+	CodecJSON:    jsonCodec{},
+	CodecMsgpack: msgpackCodec{},
+}
+
+// supportedSubprotocols is advertised to clients during the upgrade
+var supportedSubprotocols = []string{CodecJSON, CodecMsgpack}
+
+// negotiateCodec selects the codec requested by the client, defaulting to JSON
+// The query parameter is checked first, then the negotiated subprotocol
+func negotiateCodec(r *http.Request, conn *websocket.Conn) Codec {
+	if codec, ok := codecs[r.URL.Query().Get("codec")]; ok {
+		return codec
+	}
+	if codec, ok := codecs[conn.Subprotocol()]; ok {
+		return codec
+	}
+	return codecs[CodecJSON]
+}
@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"testing"
+)
+
+func TestCodecsDecodeNumbersAsFloat64(t *testing.T) {
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			_, data, err := codec.Encode(Message{
+				Type: MessageTypeSubscribe,
+				Payload: map[string]interface{}{
+					"type": "ticks",
+					"options": map[string]interface{}{
+						"interval_ms": 250,
+						"history":     uint8(10),
+						"limit":       int64(-1),
+						"quantity":    float32(1.5),
+						"levels":      []interface{}{1, 2.5},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			var msg Message
+			if err := codec.Decode(data, &msg); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			var req SubscribeRequest
+			if err := convertPayload(msg.Payload, &req); err != nil {
+				t.Fatalf("convertPayload: %v", err)
+			}
+
+			want := map[string]float64{"interval_ms": 250, "history": 10, "limit": -1, "quantity": 1.5}
+			for key, value := range want {
+				got, ok := req.Options[key].(float64)
+				if !ok || got != value {
+					t.Errorf("option %s decoded as %T %v, want float64 %v", key, req.Options[key], req.Options[key], value)
+				}
+			}
+			levels, _ := req.Options["levels"].([]interface{})
+			if len(levels) != 2 {
+				t.Fatalf("levels decoded as %v", req.Options["levels"])
+			}
+			for i, level := range levels {
+				if _, ok := level.(float64); !ok {
+					t.Errorf("levels[%d] decoded as %T, want float64", i, level)
+				}
+			}
+
+			// The raw payload is normalized too, for handlers that read it directly
+			payload := msg.Payload.(map[string]interface{})
+			options := payload["options"].(map[string]interface{})
+			if _, ok := options["interval_ms"].(float64); !ok {
+				t.Errorf("raw interval_ms decoded as %T, want float64", options["interval_ms"])
+			}
+		})
+	}
+}
@@ -1,16 +1,18 @@
 package websocket
 
 import (
-	"log"
 	"net/http"
 
+	"github.com/aumbhatt/auto_trade/internal/auth"
+	"github.com/aumbhatt/auto_trade/internal/logging"
 	"github.com/gorilla/websocket"
 )
 
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	// Allow all origins for development
+	Subprotocols:    supportedSubprotocols,
+	// Allow all origins unless the handler restricts them
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
@@ -18,26 +20,71 @@ var upgrader = websocket.Upgrader{
 
 // Handler represents the WebSocket handler
 type Handler struct {
-	hub *Hub
+	hub            *Hub
+	maxMessageSize int64
+	apiKey         string   // Required token; empty disables auth
+	allowedOrigins []string // Browser origins allowed to connect; empty allows all
+	compression    bool     // Negotiate permessage-deflate with clients that offer it
 }
 
 // NewHandler creates a new WebSocket handler
-func NewHandler(hub *Hub) *Handler {
+// maxMessageSize limits inbound client messages (<= 0 uses DefaultMaxMessageSize)
+func NewHandler(hub *Hub, maxMessageSize int64) *Handler {
 	return &Handler{
-		hub: hub,
+		hub:            hub,
+		maxMessageSize: maxMessageSize,
 	}
 }
 
+// SetAPIKey requires connections to present the key as a bearer token or
+// ?token= query parameter; an empty key disables the check
+func (h *Handler) SetAPIKey(apiKey string) {
+	h.apiKey = apiKey
+}
+
+// SetAllowedOrigins restricts the browser origins allowed to connect; an
+// empty list (or a "*" entry) allows any origin. Clients that send no
+// Origin header (non-browser clients) are always allowed
+func (h *Handler) SetAllowedOrigins(origins []string) {
+	h.allowedOrigins = origins
+}
+
+// SetCompression negotiates permessage-deflate with clients that offer it
+// and compresses messages written to them; clients that do not offer it
+// connect uncompressed
+func (h *Handler) SetCompression(enabled bool) {
+	h.compression = enabled
+}
+
+// checkOrigin validates the Origin header of an upgrade request
+func (h *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || auth.OriginAllowed(origin, h.allowedOrigins)
+}
+
 // ServeHTTP handles WebSocket requests
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	// Reject before upgrading so unauthenticated clients never get a connection
+	if !auth.Authorized(r, h.apiKey, true) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	u := upgrader
+	u.CheckOrigin = h.checkOrigin
+	u.EnableCompression = h.compression
+	conn, err := u.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("Error upgrading connection:", err)
+		logging.Warnf("Error upgrading connection: %v", err)
 		return
 	}
+	// No effect unless the client negotiated compression
+	conn.EnableWriteCompression(h.compression)
 
-	client := NewClient(h.hub, conn)
-	client.hub.register <- client
+	client := NewClient(h.hub, conn, negotiateCodec(r, conn), h.maxMessageSize)
+	client.resumeFrom = r.URL.Query().Get("resume")
+	client.hub.registerClient(client)
 
 	// Start the client's read and write pumps in separate goroutines
 	go client.writePump()
@@ -45,7 +92,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // HandleWebSocket returns an http.HandlerFunc for the WebSocket endpoint
-func HandleWebSocket(hub *Hub) http.HandlerFunc {
-	handler := NewHandler(hub)
+func HandleWebSocket(hub *Hub, maxMessageSize int64) http.HandlerFunc {
+	handler := NewHandler(hub, maxMessageSize)
 	return handler.ServeHTTP
 }
@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gws "github.com/gorilla/websocket"
+)
+
+func TestUpgradeChecksOrigin(t *testing.T) {
+	server := newTestHub(t)
+	dial := func(allowed []string, origin string) (int, error) {
+		t.Helper()
+		h := NewHandler(server.hub, 0)
+		h.SetAllowedOrigins(allowed)
+		endpoint := httptest.NewServer(h)
+		defer endpoint.Close()
+
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := gws.DefaultDialer.Dial("ws"+strings.TrimPrefix(endpoint.URL, "http"), header)
+		if err != nil {
+			if resp == nil {
+				return 0, err
+			}
+			return resp.StatusCode, err
+		}
+		conn.Close()
+		return resp.StatusCode, nil
+	}
+
+	allowed := []string{"https://app.example.com"}
+	for _, tc := range []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    int
+	}{
+		{"allowed origin", allowed, "https://APP.example.com", http.StatusSwitchingProtocols},
+		{"disallowed origin", allowed, "https://evil.example.com", http.StatusForbidden},
+		{"no origin header", allowed, "", http.StatusSwitchingProtocols},
+		{"empty allow list", nil, "https://evil.example.com", http.StatusSwitchingProtocols},
+		{"wildcard entry", []string{"*"}, "https://evil.example.com", http.StatusSwitchingProtocols},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if status, err := dial(tc.allowed, tc.origin); status != tc.want {
+				t.Errorf("upgrade status %d (%v), want %d", status, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompressionNegotiated(t *testing.T) {
+	server := newTestHub(t)
+	for _, tc := range []struct {
+		name    string
+		enabled bool
+		offered bool
+		want    bool
+	}{
+		{"enabled and offered", true, true, true},
+		{"client without compression", true, false, false},
+		{"disabled", false, true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHandler(server.hub, 0)
+			h.SetCompression(tc.enabled)
+			endpoint := httptest.NewServer(h)
+			defer endpoint.Close()
+
+			dialer := *gws.DefaultDialer
+			dialer.EnableCompression = tc.offered
+			conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(endpoint.URL, "http"), nil)
+			if err != nil {
+				t.Fatalf("Dial: %v", err)
+			}
+			defer conn.Close()
+
+			extension := resp.Header.Get("Sec-WebSocket-Extensions")
+			if got := strings.Contains(extension, "permessage-deflate"); got != tc.want {
+				t.Errorf("Sec-WebSocket-Extensions %q, want permessage-deflate negotiated: %v", extension, tc.want)
+			}
+
+			// Either way the session message arrives readable
+			var msg received
+			if err := conn.ReadJSON(&msg); err != nil || msg.Type != MessageTypeSession {
+				t.Errorf("first message %+v (%v), want the session", msg, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,17 @@
 package websocket
 
-import "sync"
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aumbhatt/auto_trade/internal/logging"
+	"github.com/gorilla/websocket"
+)
 
 /*
 Hub Memory Structure and Message Flow:
@@ -8,10 +19,22 @@ Hub Memory Structure and Message Flow:
 1. Memory Structure:
    Hub
    ├── clients: map[*Client]bool        // Active client connections
-   ├── broadcast: chan Message          // Channel for broadcasting messages
+   ├── broadcast: chan Message          // Buffered channel for broadcasting messages
+   ├── broadcasts: uint64               // Broadcasts queued for fan-out
+   ├── dropped: uint64                  // Broadcasts dropped because the buffer was full
    ├── register: chan *Client           // Channel for new client registration
    ├── unregister: chan *Client         // Channel for client disconnection
-   ├── mu: sync.RWMutex                // Protects clients map
+   ├── subscriptions: map[string]*Client // Subscribe ID -> owning client
+   ├── disconnectHooks: []func(string)  // Called with the session ID when a client disconnects
+   ├── reconnectBackoff: Duration      // Base backoff advertised on shutdown
+   ├── maxSubscriptions: int           // Per-client subscription cap (0 = unlimited)
+   ├── resumeSessions: map[string]*resumeSession // Resume token -> saved subscriptions
+   ├── resumeOwners: map[string]*Client // Resume token -> client whose subscriptions aren't saved yet
+   ├── resumeTTL: Duration             // How long saved subscriptions last (0 = off)
+   ├── closing: string                 // Set by Shutdown; new clients are turned away
+   ├── quit: chan struct{}             // Closed by Shutdown to stop Run
+   ├── stopped: chan struct{}          // Closed when Run returns
+   ├── mu: sync.RWMutex                // Protects clients and subscriptions maps
    └── registry: *handler.Registry      // Message type handlers
        └── handlers: map[string]MessageHandler
            ├── "ticks" → TickHandler
@@ -25,6 +48,11 @@ Hub Memory Structure and Message Flow:
       2. Client instance created
       3. Client sent to Hub's register channel
       4. Hub adds client to clients map
+      5. Hub sends the client its session ID and resume token:
+         {"type": "session", "payload": {"session_id": "uuid-abc", "resume_token": "uuid-tok"}}
+      6. A client connecting with ?resume=<token> gets the subscriptions of
+         that session back, taking over its connection if the hub has not
+         noticed the drop yet (see resume.go)
 
    b. Message Broadcasting:
       Tick Data Example:
@@ -40,11 +68,57 @@ Hub Memory Structure and Message Flow:
          }
       3. Hub sends to all relevant clients
 
-   c. Client Disconnection:
+      4. Messages for feeds disabled in the registry are dropped
+
+      5. Backpressure:
+         Broadcast never blocks the producer. Messages queue in a buffered
+         channel that Run drains; when the buffer is full (e.g. a burst of
+         10k ticks faster than Run can fan out) the message is dropped and
+         counted, and a log line is written for the first drop and every
+         1000th after. Tick, trade and strategy handlers share the hub, so
+         one bursty feed can no longer stall the others, at the cost of
+         losing messages while the buffer is full. Size the buffer for the
+         largest expected burst.
+
+   c. Slow Consumers:
+      1. A client's send queue is full when a message is fanned out
+      2. Hub logs the client's session ID and subscribed types
+      3. Hub removes the client and closes its send channel
+      4. The write pump flushes the queue, then best-effort sends
+         {"type": "error", "payload": {"code": "SLOW_CONSUMER", ...}}
+         and a 1013 (Try Again Later) close frame
+      5. Replies to the client's own requests (subscribe_response,
+         errors) use the same queue; a client whose queue is full when
+         replying is dropped the same way
+      6. Every send checks the client's closed flag first (trySend), so
+         a dropped client whose read pump is still handling a request
+         cannot send on its closed channel
+
+   d. Client Disconnection:
       1. Client connection closes
       2. Client sent to Hub's unregister channel
       3. Hub removes client from clients map
       4. Hub closes client's send channel
+      5. The client's subscriptions are unsubscribed from their handlers
+         and saved under its resume token
+      6. Disconnect hooks run with the client's session ID (not during
+         Shutdown), e.g. to stop strategies tied to the session
+
+   e. Server Shutdown (rolling restart):
+      1. Shutdown marks the hub closing
+      2. Every client gets a final "reconnect" message and a close frame
+         (1012 Service Restart) carrying a jittered backoff:
+         {
+           "type": "reconnect",
+           "payload": {"reason": "server shutting down", "backoff_ms": 1437}
+         }
+         backoff_ms is uniform in [base, 2*base), spreading reconnects out
+         so clients don't all return at once
+      3. Clients connecting after Shutdown are closed the same way
+      4. The run loop stops: queued broadcasts are drained and discarded,
+         later Broadcast calls are ignored, and Run returns
+      5. Shutdown waits for Run to return and the close frames to be
+         written (or ctx to end)
 
 3. Concurrent Operations:
    - Multiple clients can connect/disconnect simultaneously
@@ -70,69 +144,460 @@ type Hub struct {
 	// Registered clients
 	clients map[*Client]bool
 
-	// Inbound messages from the clients
+	// Outbound messages queued for fan-out
 	broadcast chan Message
 
+	// Broadcasts queued for fan-out (atomic)
+	broadcasts uint64
+
+	// Broadcasts dropped because the buffer was full (atomic)
+	dropped uint64
+
 	// Register requests from the clients
 	register chan *Client
 
 	// Unregister requests from clients
 	unregister chan *Client
 
-	// Mutex for protecting the clients map
+	// Owning client of each subscribe ID
+	subscriptions map[string]*Client
+
+	// Called with a client's session ID after it disconnects
+	disconnectHooks []func(sessionID string)
+
+	// Base reconnect backoff advertised when closing connections
+	reconnectBackoff time.Duration
+
+	// Active subscriptions allowed per client (0 = unlimited)
+	maxSubscriptions int
+
+	// Resumable sessions of disconnected clients by resume token, kept for
+	// resumeTTL (0 = no resume tokens)
+	resumeSessions map[string]*resumeSession
+	resumeTTL      time.Duration
+
+	// Clients by resume token until their subscriptions are saved, so a
+	// resume can take over a session the hub has not seen drop yet
+	resumeOwners map[string]*Client
+
+	// Reason given to clients once Shutdown has started; empty while running
+	closing string
+
+	// Closed by Shutdown to stop the run loop; stopped is closed when Run returns
+	quit     chan struct{}
+	quitOnce sync.Once
+	stopped  chan struct{}
+
+	// Mutex for protecting the clients and subscriptions maps
 	mu sync.RWMutex
 
 	// Registry for message type handlers
 	registry MessageTypeRegistry
 }
 
+// DefaultBroadcastBuffer is the broadcast queue size used when none is configured
+const DefaultBroadcastBuffer = 4096
+
 // NewHub creates a new Hub instance
-func NewHub(registry MessageTypeRegistry) *Hub {
+// broadcastBuffer sizes the fan-out queue; <= 0 uses DefaultBroadcastBuffer
+func NewHub(registry MessageTypeRegistry, broadcastBuffer int) *Hub {
+	if broadcastBuffer <= 0 {
+		broadcastBuffer = DefaultBroadcastBuffer
+	}
 	return &Hub{
-		broadcast:  make(chan Message),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
-		registry:   registry,
+		broadcast:        make(chan Message, broadcastBuffer),
+		register:         make(chan *Client),
+		unregister:       make(chan *Client),
+		clients:          make(map[*Client]bool),
+		subscriptions:    make(map[string]*Client),
+		registry:         registry,
+		reconnectBackoff: DefaultReconnectBackoff,
+		resumeSessions:   make(map[string]*resumeSession),
+		resumeOwners:     make(map[string]*Client),
+		resumeTTL:        DefaultResumeTTL,
+		quit:             make(chan struct{}),
+		stopped:          make(chan struct{}),
+	}
+}
+
+// DefaultReconnectBackoff is the base reconnect backoff advertised to clients
+const DefaultReconnectBackoff = time.Second
+
+// OnDisconnect registers a hook called with a client's session ID after the
+// client disconnects. Hooks run on their own goroutine, not during Shutdown
+func (h *Hub) OnDisconnect(hook func(sessionID string)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.disconnectHooks = append(h.disconnectHooks, hook)
+}
+
+// HasSession reports whether a client with the session ID is connected
+func (h *Hub) HasSession(sessionID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.id == sessionID {
+			return true
+		}
 	}
+	return false
+}
+
+// ClientCount returns the number of registered clients
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
 }
 
-// Run starts the hub's main loop
+// SubscriptionCount returns the number of tracked subscriptions across all clients
+func (h *Hub) SubscriptionCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscriptions)
+}
+
+// SetReconnectBackoff sets the base backoff advertised when connections are closed
+func (h *Hub) SetReconnectBackoff(base time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reconnectBackoff = base
+}
+
+// SetMaxSubscriptions caps the active subscriptions of each client; further
+// subscribes fail with SUBSCRIPTION_LIMIT. 0 is unlimited
+func (h *Hub) SetMaxSubscriptions(max int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maxSubscriptions = max
+}
+
+// subscriptionLimit returns the per-client subscription cap
+func (h *Hub) subscriptionLimit() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.maxSubscriptions
+}
+
+// reconnectNotice builds a reconnect message with a backoff jittered
+// uniformly in [base, 2*base)
+// Caller must hold h.mu
+func (h *Hub) reconnectNotice(reason string) *Message {
+	backoff := h.reconnectBackoff
+	if backoff > 0 {
+		backoff += time.Duration(rand.Int63n(int64(backoff)))
+	}
+	return &Message{
+		Type: MessageTypeReconnect,
+		Payload: ReconnectNotice{
+			Reason:    reason,
+			BackoffMs: backoff.Milliseconds(),
+		},
+	}
+}
+
+// closeWithNotice closes a client's send channel after attaching a reconnect
+// notice; the write pump sends it and a close frame before disconnecting
+// Caller must hold h.mu
+func (h *Hub) closeWithNotice(client *Client, reason string) {
+	notice := h.reconnectNotice(reason)
+	backoffMs := notice.Payload.(ReconnectNotice).BackoffMs
+	client.closeNotice = notice
+	client.closeFrame = websocket.FormatCloseMessage(websocket.CloseServiceRestart, fmt.Sprintf("reconnect after %dms", backoffMs))
+	client.closeSend()
+}
+
+// dropSlowConsumer disconnects a client whose send queue is full. The
+// client is told why with a best-effort SLOW_CONSUMER error, written once
+// the write pump reaches it, followed by a 1013 (Try Again Later) close
+// Caller must hold h.mu
+func (h *Hub) dropSlowConsumer(client *Client) {
+	logging.Warnf("Dropping slow consumer %s: send queue full (%d), subscriptions %v", client.id, cap(client.send), client.subscribedTypes())
+
+	client.closeNotice = &Message{
+		Type: MessageTypeError,
+		Payload: map[string]string{
+			"code":  ErrCodeSlowConsumer,
+			"error": "Send queue full, closing connection",
+		},
+	}
+	client.closeFrame = websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer")
+	client.closeSend()
+	delete(h.clients, client)
+}
+
+// dropSlowClient drops a client whose send queue overflowed outside the
+// fan-out, e.g. on a reply to its own request; a no-op once it is closed
+func (h *Hub) dropSlowClient(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[client]; ok {
+		h.dropSlowConsumer(client)
+	}
+}
+
+// Shutdown closes every connection with a reconnect notice, turns away new
+// ones and stops the run loop. It returns once Run has returned and the
+// notices are written, or when ctx is done
+func (h *Hub) Shutdown(ctx context.Context, reason string) error {
+	h.mu.Lock()
+	h.closing = reason
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		h.closeWithNotice(client, reason)
+		delete(h.clients, client)
+		clients = append(clients, client)
+	}
+	h.mu.Unlock()
+
+	h.quitOnce.Do(func() { close(h.quit) })
+	select {
+	case <-h.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for _, client := range clients {
+		select {
+		case <-client.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// registerClient hands a new client to the run loop, or closes it with a
+// reconnect notice once the hub has shut down
+func (h *Hub) registerClient(client *Client) {
+	select {
+	case h.register <- client:
+	case <-h.quit:
+		h.mu.Lock()
+		h.closeWithNotice(client, h.closing)
+		h.mu.Unlock()
+		close(client.registered)
+	}
+}
+
+// unregisterClient hands a disconnected client to the run loop; after
+// shutdown there is nothing left to clean up
+func (h *Hub) unregisterClient(client *Client) {
+	select {
+	case h.unregister <- client:
+	case <-h.quit:
+	}
+}
+
+// Run starts the hub's main loop; it returns after Shutdown
 func (h *Hub) Run() {
+	defer close(h.stopped)
+
 	for {
 		select {
+		case <-h.quit:
+			// Discard queued broadcasts; every client is already closed
+			for {
+				select {
+				case <-h.broadcast:
+				default:
+					return
+				}
+			}
+
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client] = true
+			if h.closing != "" {
+				h.closeWithNotice(client, h.closing)
+			} else {
+				h.clients[client] = true
+				subscriptions, resumed := h.takeResumeSession(client.resumeFrom)
+				if !resumed {
+					client.replaces, resumed = h.takeOverSession(client.resumeFrom)
+				}
+				client.resumed = subscriptions
+				client.resumeToken = h.newResumeToken()
+				if client.resumeToken != "" {
+					h.resumeOwners[client.resumeToken] = client
+				}
+				client.trySend(Message{
+					Type: MessageTypeSession,
+					Payload: SessionInfo{
+						SessionID:   client.id,
+						ResumeToken: client.resumeToken,
+						Resumed:     resumed,
+					},
+				})
+			}
 			h.mu.Unlock()
+			close(client.registered)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
-				close(client.send)
+				client.closeSend()
+			}
+			for subscribeID, owner := range h.subscriptions {
+				if owner == client {
+					delete(h.subscriptions, subscribeID)
+				}
+			}
+			var hooks []func(string)
+			if h.closing == "" {
+				hooks = append(hooks, h.disconnectHooks...)
 			}
 			h.mu.Unlock()
 
+			// Release handler subscriptions and run hooks off the hub loop;
+			// both may call back into the hub
+			go func(client *Client) {
+				client.releasedSubscriptions = h.releaseSubscriptions(client)
+				close(client.released)
+				h.saveResumeSession(client, client.releasedSubscriptions)
+				for _, hook := range hooks {
+					hook(client.id)
+				}
+			}(client)
+
 		case message := <-h.broadcast:
+			// Drop messages for feeds disabled at runtime
+			if !h.registry.IsEnabled(message.Type) {
+				continue
+			}
+
+			var slow []*Client
 			h.mu.RLock()
 			for client := range h.clients {
 				// Only send to clients subscribed to this message type
-				if client.isSubscribed(message.Type, message.SubscribeID) {
-					select {
-					case client.send <- message:
-					default:
-						close(client.send)
-						delete(h.clients, client)
-					}
+				if client.isSubscribed(message.Type, message.SubscribeID) && !client.trySend(message) {
+					slow = append(slow, client)
 				}
 			}
 			h.mu.RUnlock()
+
+			if len(slow) > 0 {
+				h.mu.Lock()
+				for _, client := range slow {
+					// Skip clients that disconnected since the send failed
+					if _, ok := h.clients[client]; ok {
+						h.dropSlowConsumer(client)
+					}
+				}
+				h.mu.Unlock()
+			}
+		}
+	}
+}
+
+// trackSubscription records the client that owns a subscribe ID
+func (h *Hub) trackSubscription(subscribeID string, client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscriptions[subscribeID] = client
+}
+
+// untrackSubscription forgets the owner of a subscribe ID
+func (h *Hub) untrackSubscription(subscribeID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscriptions, subscribeID)
+}
+
+// releaseSubscriptions unsubscribes a disconnected client's subscriptions
+// from their handlers so no per-subscription state outlives the connection,
+// returning them for the client's resume session
+func (h *Hub) releaseSubscriptions(client *Client) []resumedSubscription {
+	released := make([]resumedSubscription, 0)
+	client.subscriptionType.Range(func(key, value interface{}) bool {
+		subscribeID, msgType := key.(string), value.(string)
+		if err := h.registry.HandleUnsubscribe(msgType, subscribeID); err != nil {
+			logging.Errorf("Failed to release subscription %s (%s) of client %s: %v", subscribeID, msgType, client.id, err)
 		}
+		if options, ok := client.subscriptionOptions.Load(subscribeID); ok {
+			released = append(released, resumedSubscription{
+				SubscribeID: subscribeID,
+				Type:        msgType,
+				Options:     options.(map[string]interface{}),
+			})
+		}
+		client.removeSubscription(msgType, subscribeID)
+		client.subscriptionType.Delete(subscribeID)
+		return true
+	})
+	sort.Slice(released, func(i, j int) bool {
+		return released[i].SubscribeID < released[j].SubscribeID
+	})
+	return released
+}
+
+// CancelSubscription unsubscribes a subscription server-side and notifies
+// the owning client with an "unsubscribed" message
+func (h *Hub) CancelSubscription(subscribeID string, reason string) error {
+	h.mu.RLock()
+	client, exists := h.subscriptions[subscribeID]
+	h.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("subscription not found: %s", subscribeID)
+	}
+
+	msgTypeI, ok := client.subscriptionType.Load(subscribeID)
+	if !ok {
+		return fmt.Errorf("subscription not found: %s", subscribeID)
 	}
+	msgType := msgTypeI.(string)
+
+	if err := h.registry.HandleUnsubscribe(msgType, subscribeID); err != nil {
+		return err
+	}
+	client.removeSubscription(msgType, subscribeID)
+	client.subscriptionType.Delete(subscribeID)
+	h.untrackSubscription(subscribeID)
+
+	notice := Message{
+		Type: MessageTypeUnsubscribed,
+		Payload: UnsubscribedNotice{
+			SubscribeID: subscribeID,
+			Type:        msgType,
+			Reason:      reason,
+		},
+	}
+
+	// Best effort: dropped if the client's queue is full or already closed
+	client.trySend(notice)
+	return nil
+}
+
+// Broadcast queues a message for all subscribed clients without blocking
+// The message is dropped when the broadcast buffer is full; returns false
+// if it was dropped (or the hub is shut down)
+func (h *Hub) Broadcast(message Message) bool {
+	select {
+	case <-h.quit:
+		return false // Shut down
+	default:
+	}
+
+	select {
+	case h.broadcast <- message:
+		atomic.AddUint64(&h.broadcasts, 1)
+		return true
+	default:
+		dropped := atomic.AddUint64(&h.dropped, 1)
+		if dropped == 1 || dropped%1000 == 0 {
+			logging.Warnf("Hub broadcast buffer full (%d): dropped %s message, %d dropped in total", cap(h.broadcast), message.Type, dropped)
+		}
+		return false
+	}
+}
+
+// BroadcastCount returns the number of messages queued for fan-out
+func (h *Hub) BroadcastCount() uint64 {
+	return atomic.LoadUint64(&h.broadcasts)
 }
 
-// Broadcast sends a message to all connected clients
-func (h *Hub) Broadcast(message Message) {
-	h.broadcast <- message
+// DroppedBroadcasts returns the number of messages dropped because the
+// broadcast buffer was full
+func (h *Hub) DroppedBroadcasts() uint64 {
+	return atomic.LoadUint64(&h.dropped)
 }
@@ -0,0 +1,210 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gws "github.com/gorilla/websocket"
+)
+
+// fakeRegistry accepts subscriptions to any type and records the active ones
+type fakeRegistry struct {
+	mu     sync.Mutex
+	active map[string]string // subscribe ID -> type
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{active: make(map[string]string)}
+}
+
+func (r *fakeRegistry) HandleSubscribe(msgType string, subscribeID string, options map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.active[subscribeID]; exists {
+		return fmt.Errorf("subscription %s already active", subscribeID)
+	}
+	r.active[subscribeID] = msgType
+	return nil
+}
+
+func (r *fakeRegistry) HandleSubscribed(msgType string, subscribeID string) {}
+
+func (r *fakeRegistry) HandleUnsubscribe(msgType string, subscribeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.active[subscribeID]; !exists {
+		return fmt.Errorf("subscription not found: %s", subscribeID)
+	}
+	delete(r.active, subscribeID)
+	return nil
+}
+
+func (r *fakeRegistry) IsEnabled(msgType string) bool { return true }
+func (r *fakeRegistry) StartAll() error               { return nil }
+func (r *fakeRegistry) StopAll() error                { return nil }
+
+// activeCount returns the number of active subscriptions
+func (r *fakeRegistry) activeCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.active)
+}
+
+// testHub is a running hub served over a real WebSocket endpoint
+type testHub struct {
+	hub      *Hub
+	registry *fakeRegistry
+	url      string
+}
+
+// newTestHub starts a hub on an httptest server, torn down when the test ends
+func newTestHub(t *testing.T) *testHub {
+	t.Helper()
+	registry := newFakeRegistry()
+	hub := NewHub(registry, 0)
+	go hub.Run()
+
+	server := httptest.NewServer(HandleWebSocket(hub, 0))
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hub.Shutdown(ctx, "test done")
+		server.Close()
+	})
+	return &testHub{
+		hub:      hub,
+		registry: registry,
+		url:      "ws" + strings.TrimPrefix(server.URL, "http"),
+	}
+}
+
+// received is a message read by a test client, payload left encoded
+type received struct {
+	Type        string          `json:"type"`
+	SubscribeID string          `json:"subscribe_id"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// testClient is a WebSocket client connected to a testHub
+type testClient struct {
+	t        *testing.T
+	conn     *gws.Conn
+	session  SessionInfo
+	messages chan received // Read by a background reader; closed on error
+	readErr  error         // Error that ended the reader, set before messages is closed
+}
+
+// dial connects a client, resuming the token if one is given, and reads
+// its session message
+func (s *testHub) dial(t *testing.T, resume string) *testClient {
+	t.Helper()
+	url := s.url
+	if resume != "" {
+		url += "?resume=" + resume
+	}
+	conn, _, err := gws.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := &testClient{t: t, conn: conn, messages: make(chan received, 1024)}
+	go c.readLoop()
+
+	msg := c.next()
+	if msg.Type != MessageTypeSession {
+		t.Fatalf("first message is %q, want session", msg.Type)
+	}
+	c.decode(msg, &c.session)
+	return c
+}
+
+// readLoop reads messages until the connection fails
+func (c *testClient) readLoop() {
+	defer close(c.messages)
+	for {
+		var msg received
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			c.readErr = err
+			return
+		}
+		c.messages <- msg
+	}
+}
+
+// send writes a message to the server
+func (c *testClient) send(msg Message) {
+	c.t.Helper()
+	if err := c.conn.WriteJSON(msg); err != nil {
+		c.t.Fatalf("WriteJSON: %v", err)
+	}
+}
+
+// next returns the next message, failing the test after two seconds
+func (c *testClient) next() received {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("connection closed")
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		c.t.Fatal("timed out waiting for a message")
+	}
+	return received{}
+}
+
+// expectClosed fails the test unless the server closes the connection
+// within two seconds; messages before the close are skipped
+func (c *testClient) expectClosed() {
+	c.t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-c.messages:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			c.t.Fatal("connection not closed")
+		}
+	}
+}
+
+// subscribe subscribes to a type and returns the subscribe ID from the
+// subscribe_response, which must be the next message
+func (c *testClient) subscribe(msgType string) string {
+	c.t.Helper()
+	c.send(Message{
+		Type:    MessageTypeSubscribe,
+		Payload: SubscribeRequest{Type: msgType},
+	})
+	return c.subscribeResponse()
+}
+
+// subscribeResponse reads a subscribe_response and returns its subscribe ID
+func (c *testClient) subscribeResponse() string {
+	c.t.Helper()
+	msg := c.next()
+	if msg.Type != MessageTypeSubscribeResponse {
+		c.t.Fatalf("got %s message %s, want subscribe_response", msg.Type, msg.Payload)
+	}
+	var response SubscribeResponse
+	c.decode(msg, &response)
+	return response.SubscribeID
+}
+
+// decode unmarshals a message payload
+func (c *testClient) decode(msg received, target interface{}) {
+	c.t.Helper()
+	if err := json.Unmarshal(msg.Payload, target); err != nil {
+		c.t.Fatalf("decode %s payload %s: %v", msg.Type, msg.Payload, err)
+	}
+}
@@ -0,0 +1,252 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	gws "github.com/gorilla/websocket"
+)
+
+// waitFor fails the test unless cond holds within two seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// expectTeardown waits until the hub has dropped every client and
+// subscription and the goroutine count is back to baseline
+func expectTeardown(t *testing.T, server *testHub, baseline int) {
+	t.Helper()
+	waitFor(t, "clients to unregister", func() bool { return server.hub.ClientCount() == 0 })
+	waitFor(t, "subscriptions to be dropped", func() bool { return server.hub.SubscriptionCount() == 0 })
+	waitFor(t, "handler subscriptions to be released", func() bool { return server.registry.activeCount() == 0 })
+	waitFor(t, "goroutines to exit", func() bool { return runtime.NumGoroutine() <= baseline })
+}
+
+func TestClientLifecycleTearsDownCleanly(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		disconnect func(c *testClient)
+	}{
+		{
+			name: "normal close",
+			disconnect: func(c *testClient) {
+				message := gws.FormatCloseMessage(gws.CloseNormalClosure, "bye")
+				if err := c.conn.WriteControl(gws.CloseMessage, message, time.Now().Add(time.Second)); err != nil {
+					c.t.Fatalf("WriteControl: %v", err)
+				}
+				c.expectClosed()
+				c.conn.Close()
+			},
+		},
+		{
+			// No close frame: the server only sees the read fail
+			name:       "abrupt disconnect",
+			disconnect: func(c *testClient) { c.conn.UnderlyingConn().Close() },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestHub(t)
+			baseline := runtime.NumGoroutine()
+
+			clients := []*testClient{server.dial(t, ""), server.dial(t, "")}
+			ids := make([]string, len(clients))
+			for i, client := range clients {
+				ids[i] = client.subscribe("ticks")
+			}
+			if n := server.hub.ClientCount(); n != 2 {
+				t.Fatalf("%d clients registered, want 2", n)
+			}
+			if n := server.hub.SubscriptionCount(); n != 2 {
+				t.Fatalf("%d subscriptions tracked, want 2", n)
+			}
+
+			// Each client receives the broadcasts for its own subscription
+			for i, client := range clients {
+				if !server.hub.Broadcast(Message{Type: "ticks", SubscribeID: ids[i], Payload: i}) {
+					t.Fatal("broadcast dropped")
+				}
+				if msg := client.next(); msg.Type != "ticks" || msg.SubscribeID != ids[i] {
+					t.Fatalf("client %d got %s message for %s, want its ticks", i, msg.Type, msg.SubscribeID)
+				}
+			}
+
+			for _, client := range clients {
+				tc.disconnect(client)
+			}
+			expectTeardown(t, server, baseline)
+		})
+	}
+}
+
+func TestBroadcastAfterDisconnectIsDiscarded(t *testing.T) {
+	server := newTestHub(t)
+	baseline := runtime.NumGoroutine()
+
+	client := server.dial(t, "")
+	id := client.subscribe("ticks")
+	client.conn.UnderlyingConn().Close()
+
+	// Broadcasts racing the unregister must not reach the closed send queue
+	for i := 0; i < 100; i++ {
+		server.hub.Broadcast(Message{Type: "ticks", SubscribeID: id, Payload: i})
+	}
+	expectTeardown(t, server, baseline)
+}
+
+func TestCancelSubscriptionNotifiesClient(t *testing.T) {
+	server := newTestHub(t)
+	client := server.dial(t, "")
+	cancelled := client.subscribe("ticks")
+	kept := client.subscribe("ticks")
+
+	if err := server.hub.CancelSubscription(cancelled, "maintenance"); err != nil {
+		t.Fatalf("CancelSubscription: %v", err)
+	}
+	msg := client.next()
+	var notice UnsubscribedNotice
+	client.decode(msg, &notice)
+	if msg.Type != MessageTypeUnsubscribed || notice.SubscribeID != cancelled || notice.Type != "ticks" || notice.Reason != "maintenance" {
+		t.Fatalf("got %s message %s, want an unsubscribed notice for %s", msg.Type, msg.Payload, cancelled)
+	}
+	if n := server.registry.activeCount(); n != 1 {
+		t.Errorf("%d handler subscriptions after cancel, want 1", n)
+	}
+
+	// Only the kept subscription still receives updates
+	server.hub.Broadcast(Message{Type: "ticks", SubscribeID: cancelled, Payload: 1})
+	server.hub.Broadcast(Message{Type: "ticks", SubscribeID: kept, Payload: 2})
+	if msg := client.next(); msg.SubscribeID != kept {
+		t.Errorf("got a message for %s, want only %s", msg.SubscribeID, kept)
+	}
+
+	if err := server.hub.CancelSubscription(cancelled, "again"); err == nil {
+		t.Error("cancelled an already cancelled subscription")
+	}
+}
+
+func TestShutdownAdvertisesReconnectBackoff(t *testing.T) {
+	server := newTestHub(t)
+	server.hub.SetReconnectBackoff(200 * time.Millisecond)
+	client := server.dial(t, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.hub.Shutdown(ctx, "rolling restart"); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	msg := client.next()
+	var notice ReconnectNotice
+	client.decode(msg, &notice)
+	if msg.Type != MessageTypeReconnect || notice.Reason != "rolling restart" {
+		t.Fatalf("got %s message %s, want a reconnect notice", msg.Type, msg.Payload)
+	}
+	if notice.BackoffMs < 200 || notice.BackoffMs >= 400 {
+		t.Errorf("backoff %dms, want within [200, 400)", notice.BackoffMs)
+	}
+
+	// The close frame repeats the hint for clients that only see the close
+	client.expectClosed()
+	closeErr, ok := client.readErr.(*gws.CloseError)
+	if !ok || closeErr.Code != gws.CloseServiceRestart || closeErr.Text != fmt.Sprintf("reconnect after %dms", notice.BackoffMs) {
+		t.Errorf("connection ended with %v, want a 1012 close carrying the backoff", client.readErr)
+	}
+}
+
+func TestBroadcastBurstDropsInsteadOfBlocking(t *testing.T) {
+	// Run is not started yet, standing in for a fan-out loop stalled
+	// behind a slow client
+	hub := NewHub(newFakeRegistry(), 16)
+
+	done := make(chan int)
+	go func() {
+		queued := 0
+		for i := 0; i < 10000; i++ {
+			if hub.Broadcast(Message{Type: "ticks", SubscribeID: "sub", Payload: i}) {
+				queued++
+			}
+		}
+		done <- queued
+	}()
+	select {
+	case queued := <-done:
+		if queued != 16 {
+			t.Errorf("%d messages queued, want the buffer's 16", queued)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("burst of 10k broadcasts blocked on a full buffer")
+	}
+	if hub.BroadcastCount() != 16 || hub.DroppedBroadcasts() != 10000-16 {
+		t.Errorf("%d queued and %d dropped, want 16 and %d", hub.BroadcastCount(), hub.DroppedBroadcasts(), 10000-16)
+	}
+
+	// Once the loop catches up the buffer takes messages again
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hub.Shutdown(ctx, "test done")
+	})
+	waitFor(t, "the buffer to drain", func() bool { return len(hub.broadcast) == 0 })
+	if !hub.Broadcast(Message{Type: "ticks", SubscribeID: "sub"}) {
+		t.Error("broadcast dropped after the buffer drained")
+	}
+}
+
+func TestShutdownClosesEveryClientAndStopsRun(t *testing.T) {
+	server := newTestHub(t)
+	clients := []*testClient{server.dial(t, ""), server.dial(t, ""), server.dial(t, "")}
+	for _, client := range clients {
+		client.subscribe("ticks")
+	}
+
+	server.hub.mu.RLock()
+	registered := make([]*Client, 0, len(server.hub.clients))
+	for client := range server.hub.clients {
+		registered = append(registered, client)
+	}
+	server.hub.mu.RUnlock()
+	if len(registered) != len(clients) {
+		t.Fatalf("%d clients registered, want %d", len(registered), len(clients))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.hub.Shutdown(ctx, "shutting down"); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	select {
+	case <-server.hub.stopped:
+	default:
+		t.Error("Shutdown returned before Run")
+	}
+	for i, client := range registered {
+		client.sendMu.Lock()
+		closed := client.closed
+		client.sendMu.Unlock()
+		if !closed {
+			t.Errorf("client %d send channel still open", i)
+		}
+	}
+	for _, client := range clients {
+		if msg := client.next(); msg.Type != MessageTypeReconnect {
+			t.Errorf("got %s message, want a reconnect notice", msg.Type)
+		}
+		client.expectClosed()
+	}
+
+	if server.hub.Broadcast(Message{Type: "ticks"}) {
+		t.Error("broadcast accepted after shutdown")
+	}
+}
@@ -33,13 +33,43 @@ type UnsubscribeResponse struct {
 	Error       string `json:"error,omitempty"`
 }
 
+// UnsubscribedNotice tells a client the server cancelled one of its subscriptions
+type UnsubscribedNotice struct {
+	SubscribeID string `json:"subscribe_id"`
+	Type        string `json:"type"`
+	Reason      string `json:"reason"`
+}
+
+// SessionInfo is sent to every client when it connects
+type SessionInfo struct {
+	SessionID   string `json:"session_id"`
+	ResumeToken string `json:"resume_token,omitempty"` // Reconnect with ?resume= to restore subscriptions
+	Resumed     bool   `json:"resumed,omitempty"`      // Subscriptions of a dropped session follow
+}
+
+// ReconnectNotice is the last message sent before the server closes a
+// connection; clients should wait BackoffMs before reconnecting
+type ReconnectNotice struct {
+	Reason    string `json:"reason"`
+	BackoffMs int64  `json:"backoff_ms"`
+}
+
 // Message types
 const (
-	MessageTypeSubscribe          = "subscribe"
-	MessageTypeSubscribeResponse  = "subscribe_response"
-	MessageTypeUnsubscribe       = "unsubscribe"
+	MessageTypeSubscribe           = "subscribe"
+	MessageTypeSubscribeResponse   = "subscribe_response"
+	MessageTypeUnsubscribe         = "unsubscribe"
 	MessageTypeUnsubscribeResponse = "unsubscribe_response"
-	MessageTypeError             = "error"
+	MessageTypeUnsubscribed        = "unsubscribed"
+	MessageTypeReconnect           = "reconnect"
+	MessageTypeSession             = "session"
+	MessageTypeError               = "error"
+)
+
+// Error codes sent in error message payloads
+const (
+	ErrCodeSlowConsumer      = "SLOW_CONSUMER"
+	ErrCodeSubscriptionLimit = "SUBSCRIPTION_LIMIT"
 )
 
 // Status types
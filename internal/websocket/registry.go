@@ -5,9 +5,16 @@ type MessageTypeRegistry interface {
 	// HandleSubscribe routes subscription requests to appropriate handler
 	HandleSubscribe(msgType string, subscribeID string, options map[string]interface{}) error
 
+	// HandleSubscribed is called once a subscription's subscribe_response
+	// is queued, so its handler can send initial messages (replays) after it
+	HandleSubscribed(msgType string, subscribeID string)
+
 	// HandleUnsubscribe routes unsubscribe requests to appropriate handler
 	HandleUnsubscribe(msgType string, subscribeID string) error
 
+	// IsEnabled reports whether messages of the given type may be delivered
+	IsEnabled(msgType string) bool
+
 	// StartAll starts all registered handlers
 	StartAll() error
 
@@ -0,0 +1,166 @@
+package websocket
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+/*
+Session Resume Flow:
+
+1. Token:
+   With a resume TTL set (SetResumeTTL), every session message carries a
+   single-use resume token:
+   {"type": "session", "payload": {"session_id": "uuid-abc", "resume_token": "uuid-tok"}}
+
+2. Disconnect:
+   The client's subscriptions (subscribe ID, type, options) are released
+   from their handlers as usual and saved under its token until the TTL
+   expires.
+
+3. Reconnect (ws://localhost:8080/ws?resume=uuid-tok):
+   ├── token saved and not expired:
+   │   session message with "resumed": true and a new token, then each
+   │   saved subscription is re-subscribed under its previous subscribe ID
+   │   (one subscribe_response per subscription, or an error if its
+   │   handler now refuses it, e.g. a disabled feed)
+   ├── token of a session not saved yet (the hub has not seen the old
+   │   connection drop, or is still releasing it):
+   │   the new connection takes the session over. The old connection is
+   │   closed (1000, "session resumed"), its subscriptions are released
+   │   as on any disconnect, then restored on the new connection as above
+   └── unknown, used or expired token:
+       a fresh session ("resumed" omitted), with no subscriptions
+
+   Restored subscriptions count towards the per-client cap
+   (SetMaxSubscriptions); those beyond it are not restored and each gets a
+   SUBSCRIPTION_LIMIT error instead of a subscribe_response.
+
+   The session ID is always new, so disconnect hooks (stop_on_disconnect)
+   still fire for the dropped connection. Saved sessions live in memory
+   and do not survive a server restart.
+*/
+
+// DefaultResumeTTL is how long a disconnected session can be resumed when
+// no TTL is configured
+const DefaultResumeTTL = 30 * time.Second
+
+// resumedSubscription is a subscription saved for a resumable session
+type resumedSubscription struct {
+	SubscribeID string
+	Type        string
+	Options     map[string]interface{}
+}
+
+// resumeSession is a disconnected client's saved subscriptions
+type resumeSession struct {
+	subscriptions []resumedSubscription
+	expires       time.Time
+}
+
+// SetResumeTTL sets how long a disconnected client's subscriptions can be
+// resumed with its resume token; 0 disables resume tokens
+func (h *Hub) SetResumeTTL(ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resumeTTL = ttl
+}
+
+// newResumeToken issues a resume token, or "" when resuming is disabled
+// Caller must hold h.mu
+func (h *Hub) newResumeToken() string {
+	if h.resumeTTL <= 0 {
+		return ""
+	}
+	return uuid.New().String()
+}
+
+// takeResumeSession removes and returns the subscriptions saved under a
+// token; false if there are none or they expired
+// Caller must hold h.mu
+func (h *Hub) takeResumeSession(token string) ([]resumedSubscription, bool) {
+	if token == "" {
+		return nil, false
+	}
+	session, ok := h.resumeSessions[token]
+	if !ok {
+		return nil, false
+	}
+	delete(h.resumeSessions, token)
+	if time.Now().After(session.expires) {
+		return nil, false
+	}
+	return session.subscriptions, true
+}
+
+// takeOverSession hands the session holding a token to a resuming client
+// before its subscriptions are saved, closing the old connection if it is
+// still registered; false if no client holds the token
+// Caller must hold h.mu
+func (h *Hub) takeOverSession(token string) (*Client, bool) {
+	owner, ok := h.resumeOwners[token]
+	if token == "" || !ok {
+		return nil, false
+	}
+	delete(h.resumeOwners, token)
+	if _, connected := h.clients[owner]; connected {
+		owner.closeFrame = websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session resumed")
+		owner.closeSend()
+		delete(h.clients, owner)
+	}
+	return owner, true
+}
+
+// awaitRelease waits for the hub to release the client's subscriptions
+// after it unregisters and returns them; nil once the hub shuts down
+func (c *Client) awaitRelease() []resumedSubscription {
+	select {
+	case <-c.released:
+		return c.releasedSubscriptions
+	case <-c.hub.quit:
+		return nil
+	}
+}
+
+// saveResumeSession keeps a disconnected client's subscriptions under its
+// token until the TTL expires, dropping sessions that already expired.
+// Nothing is saved for a session another client took over
+func (h *Hub) saveResumeSession(client *Client, subscriptions []resumedSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	token := client.resumeToken
+	if token == "" || h.resumeOwners[token] != client {
+		return
+	}
+	delete(h.resumeOwners, token)
+	if h.resumeTTL <= 0 || h.closing != "" {
+		return
+	}
+
+	now := time.Now()
+	for key, session := range h.resumeSessions {
+		if now.After(session.expires) {
+			delete(h.resumeSessions, key)
+		}
+	}
+	h.resumeSessions[token] = &resumeSession{
+		subscriptions: subscriptions,
+		expires:       now.Add(h.resumeTTL),
+	}
+}
+
+// restoreSubscriptions re-subscribes a resumed session's subscriptions
+// under their previous subscribe IDs, up to the per-client cap
+func (c *Client) restoreSubscriptions(subscriptions []resumedSubscription) {
+	limit := c.hub.subscriptionLimit()
+	for _, sub := range subscriptions {
+		if limit > 0 && c.subscriptionCount() >= limit {
+			c.sendCodedError(ErrCodeSubscriptionLimit, fmt.Sprintf("Subscription limit reached: %d, %s subscription %s not resumed", limit, sub.Type, sub.SubscribeID))
+			continue
+		}
+		c.subscribe(sub.SubscribeID, sub.Type, sub.Options)
+	}
+}
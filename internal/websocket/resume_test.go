@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestResumeRightAfterDisconnect(t *testing.T) {
+	server := newTestHub(t)
+	first := server.dial(t, "")
+	id := first.subscribe("ticks")
+
+	// Resume without waiting for the hub to save the dropped session
+	first.conn.Close()
+	second := server.dial(t, first.session.ResumeToken)
+	if !second.session.Resumed {
+		t.Fatal("session not resumed")
+	}
+	if got := second.subscribeResponse(); got != id {
+		t.Errorf("restored subscribe ID %s, want %s", got, id)
+	}
+	if n := server.registry.activeCount(); n != 1 {
+		t.Errorf("%d active subscriptions, want 1", n)
+	}
+}
+
+func TestResumeTakesOverRegisteredSession(t *testing.T) {
+	server := newTestHub(t)
+	first := server.dial(t, "")
+	ids := []string{first.subscribe("ticks"), first.subscribe("trades")}
+	sort.Strings(ids)
+
+	// The first connection is still registered, e.g. a dead network the
+	// hub has not noticed yet
+	second := server.dial(t, first.session.ResumeToken)
+	if !second.session.Resumed {
+		t.Fatal("session not resumed")
+	}
+	first.expectClosed()
+	for i, id := range ids {
+		if got := second.subscribeResponse(); got != id {
+			t.Errorf("restored subscription %d has ID %s, want %s", i, got, id)
+		}
+	}
+	if n := server.registry.activeCount(); n != 2 {
+		t.Errorf("%d active subscriptions, want 2", n)
+	}
+
+	// The token was used; presenting it again starts a fresh session
+	third := server.dial(t, first.session.ResumeToken)
+	if third.session.Resumed {
+		t.Error("used token resumed a session")
+	}
+}
+
+func TestResumeRespectsSubscriptionLimit(t *testing.T) {
+	server := newTestHub(t)
+	first := server.dial(t, "")
+	for i := 0; i < 3; i++ {
+		first.subscribe("ticks")
+	}
+
+	server.hub.SetMaxSubscriptions(2)
+	second := server.dial(t, first.session.ResumeToken)
+	second.subscribeResponse()
+	second.subscribeResponse()
+
+	msg := second.next()
+	var errPayload map[string]string
+	second.decode(msg, &errPayload)
+	if msg.Type != MessageTypeError || errPayload["code"] != ErrCodeSubscriptionLimit {
+		t.Fatalf("got %s message %s, want a SUBSCRIPTION_LIMIT error", msg.Type, msg.Payload)
+	}
+	if !strings.Contains(errPayload["error"], "not resumed") {
+		t.Errorf("error %q does not say the subscription was not resumed", errPayload["error"])
+	}
+	if n := server.registry.activeCount(); n != 2 {
+		t.Errorf("%d active subscriptions, want 2", n)
+	}
+}